@@ -0,0 +1,120 @@
+// Package assets maps renamed exchange symbols onto one asset, so trades stored before and after a
+// rename (e.g. MATIC and POL on Hyperliquid) are grouped and filtered together
+package assets
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zif-terminal/lib/models"
+)
+
+// AliasLister is the subset of the database client Load needs
+// *db.Client satisfies it
+type AliasLister interface {
+	ListAssetAliases(ctx context.Context, exchange string) ([]*models.AssetAlias, error)
+}
+
+// Resolver answers alias lookups for a fixed set of asset aliases
+// It is immutable and safe for concurrent use; a nil *Resolver knows no aliases
+type Resolver struct {
+	aliases map[symbolKey]*models.AssetAlias   // Keyed by exchange and former symbol
+	renamed map[symbolKey][]*models.AssetAlias // Keyed by exchange and the symbol renamed to
+}
+
+// symbolKey identifies a symbol on one exchange
+type symbolKey struct {
+	exchange string
+	symbol   string
+}
+
+// NewResolver builds a Resolver from aliases
+// Renames may chain (A to B, later B to C); an alias listed twice keeps its last entry
+func NewResolver(aliases []*models.AssetAlias) *Resolver {
+	r := &Resolver{
+		aliases: make(map[symbolKey]*models.AssetAlias, len(aliases)),
+		renamed: make(map[symbolKey][]*models.AssetAlias),
+	}
+	for _, alias := range aliases {
+		r.aliases[symbolKey{alias.Exchange, alias.Alias}] = alias
+	}
+	for _, alias := range r.aliases {
+		key := symbolKey{alias.Exchange, alias.Asset}
+		r.renamed[key] = append(r.renamed[key], alias)
+	}
+	for _, renames := range r.renamed {
+		sort.Slice(renames, func(i, j int) bool { return renames[i].EffectiveFrom.After(renames[j].EffectiveFrom) })
+	}
+	return r
+}
+
+// Load builds a Resolver from every alias stored for the deployment
+func Load(ctx context.Context, lister AliasLister) (*Resolver, error) {
+	aliases, err := lister.ListAssetAliases(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load asset aliases: %w", err)
+	}
+	return NewResolver(aliases), nil
+}
+
+// Canonical returns the current symbol for symbol on exchange, following chained renames
+// Symbols without an alias are returned unchanged
+func (r *Resolver) Canonical(exchange, symbol string) string {
+	if r == nil {
+		return symbol
+	}
+	// Bounded by the alias count so a cycle in bad data cannot loop forever
+	for i := 0; i <= len(r.aliases); i++ {
+		alias, ok := r.aliases[symbolKey{exchange, symbol}]
+		if !ok {
+			return symbol
+		}
+		symbol = alias.Asset
+	}
+	return symbol
+}
+
+// Symbols returns every symbol asset has been stored under on exchange: the current symbol
+// first, then former symbols from the most recent rename back
+func (r *Resolver) Symbols(exchange, asset string) []string {
+	current := r.Canonical(exchange, asset)
+	symbols := []string{current}
+	if r == nil {
+		return symbols
+	}
+
+	seen := map[string]bool{current: true}
+	for i := 0; i < len(symbols); i++ {
+		for _, alias := range r.renamed[symbolKey{exchange, symbols[i]}] {
+			if !seen[alias.Alias] {
+				seen[alias.Alias] = true
+				symbols = append(symbols, alias.Alias)
+			}
+		}
+	}
+	return symbols
+}
+
+// SymbolAt returns the symbol exchange used for asset at the given time, e.g. MATIC for POL
+// before the rename took effect
+func (r *Resolver) SymbolAt(exchange, asset string, at time.Time) string {
+	symbol := r.Canonical(exchange, asset)
+	if r == nil {
+		return symbol
+	}
+	for i := 0; i <= len(r.aliases); i++ {
+		var previous *models.AssetAlias
+		for _, alias := range r.renamed[symbolKey{exchange, symbol}] {
+			if at.Before(alias.EffectiveFrom) {
+				previous = alias // Renames are newest first, so this ends on the earliest one after at
+			}
+		}
+		if previous == nil {
+			return symbol
+		}
+		symbol = previous.Alias
+	}
+	return symbol
+}
@@ -0,0 +1,125 @@
+package assets
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zif-terminal/lib/models"
+)
+
+var (
+	renamedAt   = time.Date(2024, 9, 4, 0, 0, 0, 0, time.UTC)
+	testAliases = []*models.AssetAlias{
+		{Exchange: "hyperliquid", Asset: "POL", Alias: "MATIC", EffectiveFrom: renamedAt},
+		{Exchange: "hyperliquid", Asset: "MATIC", Alias: "MATICX", EffectiveFrom: renamedAt.AddDate(-1, 0, 0)},
+		{Exchange: "lighter", Asset: "S", Alias: "FTM", EffectiveFrom: renamedAt},
+	}
+)
+
+func TestResolver_Canonical(t *testing.T) {
+	r := NewResolver(testAliases)
+
+	tests := []struct {
+		exchange, symbol, want string
+	}{
+		{"hyperliquid", "MATIC", "POL"},
+		{"hyperliquid", "MATICX", "POL"}, // Chained renames
+		{"hyperliquid", "POL", "POL"},
+		{"hyperliquid", "BTC", "BTC"},
+		{"hyperliquid", "FTM", "FTM"}, // Aliases are per exchange
+		{"lighter", "FTM", "S"},
+	}
+	for _, tt := range tests {
+		if got := r.Canonical(tt.exchange, tt.symbol); got != tt.want {
+			t.Errorf("Canonical(%s, %s) = %s, want %s", tt.exchange, tt.symbol, got, tt.want)
+		}
+	}
+}
+
+func TestResolver_Symbols(t *testing.T) {
+	r := NewResolver(testAliases)
+
+	want := []string{"POL", "MATIC", "MATICX"}
+	for _, asset := range want {
+		if got := r.Symbols("hyperliquid", asset); !reflect.DeepEqual(got, want) {
+			t.Errorf("Symbols(%s) = %v, want %v", asset, got, want)
+		}
+	}
+	if got := r.Symbols("hyperliquid", "BTC"); !reflect.DeepEqual(got, []string{"BTC"}) {
+		t.Errorf("Expected an unaliased asset alone, got %v", got)
+	}
+}
+
+func TestResolver_SymbolAt(t *testing.T) {
+	r := NewResolver(testAliases)
+
+	tests := []struct {
+		at   time.Time
+		want string
+	}{
+		{renamedAt.AddDate(-2, 0, 0), "MATICX"},
+		{renamedAt.AddDate(0, -1, 0), "MATIC"},
+		{renamedAt, "POL"},
+		{renamedAt.AddDate(1, 0, 0), "POL"},
+	}
+	for _, tt := range tests {
+		if got := r.SymbolAt("hyperliquid", "MATIC", tt.at); got != tt.want {
+			t.Errorf("SymbolAt(%s) = %s, want %s", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestResolver_Nil(t *testing.T) {
+	var r *Resolver
+	if r.Canonical("hyperliquid", "MATIC") != "MATIC" || r.SymbolAt("hyperliquid", "MATIC", renamedAt) != "MATIC" {
+		t.Error("Expected a nil resolver to leave symbols unchanged")
+	}
+	if got := r.Symbols("hyperliquid", "MATIC"); !reflect.DeepEqual(got, []string{"MATIC"}) {
+		t.Errorf("Expected a nil resolver to return the asset alone, got %v", got)
+	}
+}
+
+func TestResolver_CycleTerminates(t *testing.T) {
+	r := NewResolver([]*models.AssetAlias{
+		{Exchange: "x", Asset: "A", Alias: "B", EffectiveFrom: renamedAt},
+		{Exchange: "x", Asset: "B", Alias: "A", EffectiveFrom: renamedAt},
+	})
+	r.Canonical("x", "A")
+	if got := r.Symbols("x", "A"); len(got) != 2 {
+		t.Errorf("Expected both symbols of the cycle, got %v", got)
+	}
+}
+
+// aliasLister serves fixed aliases
+type aliasLister struct {
+	aliases  []*models.AssetAlias
+	err      error
+	exchange *string // Exchange argument of the last call
+}
+
+func (l *aliasLister) ListAssetAliases(ctx context.Context, exchange string) ([]*models.AssetAlias, error) {
+	l.exchange = &exchange
+	return l.aliases, l.err
+}
+
+func TestLoad(t *testing.T) {
+	lister := &aliasLister{aliases: testAliases}
+	r, err := Load(context.Background(), lister)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if lister.exchange == nil || *lister.exchange != "" {
+		t.Errorf("Expected aliases of every exchange to be loaded, got %v", lister.exchange)
+	}
+	if r.Canonical("lighter", "FTM") != "S" {
+		t.Error("Expected the loaded aliases to be used")
+	}
+
+	failing := &aliasLister{err: errors.New("connection refused")}
+	if _, err := Load(context.Background(), failing); !errors.Is(err, failing.err) {
+		t.Errorf("Expected the lister error to be wrapped, got %v", err)
+	}
+}
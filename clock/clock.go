@@ -0,0 +1,68 @@
+// Package clock abstracts time so retry backoff, rate-limit pauses, cache expiry and sync cursors
+// can be tested deterministically. Production code uses New (the time package); tests use a Fake
+// and move it forward by hand instead of sleeping
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// Clock supplies the current time and waits
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single-shot timer created by a Clock
+type Timer interface {
+	// C delivers the time once the timer fires
+	C() <-chan time.Time
+	// Stop prevents the timer from firing; it returns false if the timer already fired or was stopped
+	Stop() bool
+}
+
+// New returns the Clock backed by the time package
+func New() Clock {
+	return realClock{}
+}
+
+// OrReal returns c, or the real clock when c is nil, for optional Clock fields
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return realClock{}
+	}
+	return c
+}
+
+// Wait blocks for d on c or until ctx is done, whichever comes first
+// Returns ctx.Err() when ctx ends first and nil otherwise
+func Wait(ctx context.Context, c Clock, d time.Duration) error {
+	timer := c.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// realClock is the Clock backed by the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+// realTimer adapts *time.Timer to Timer
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time { return t.timer.C }
+
+func (t realTimer) Stop() bool { return t.timer.Stop() }
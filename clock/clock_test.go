@@ -0,0 +1,98 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFake_TimersFireOnAdvance(t *testing.T) {
+	fake := NewFake(epoch)
+	short := fake.NewTimer(time.Second)
+	long := fake.NewTimer(time.Hour)
+
+	fake.Advance(500 * time.Millisecond)
+	select {
+	case <-short.C():
+		t.Fatal("Expected the timer not to fire before its deadline")
+	default:
+	}
+
+	fake.Advance(500 * time.Millisecond)
+	select {
+	case at := <-short.C():
+		if !at.Equal(epoch.Add(time.Second)) {
+			t.Errorf("Expected the timer to deliver the fake time, got %s", at)
+		}
+	default:
+		t.Fatal("Expected the timer to fire at its deadline")
+	}
+
+	if !long.Stop() {
+		t.Error("Expected Stop to report a pending timer")
+	}
+	if long.Stop() {
+		t.Error("Expected a second Stop to report false")
+	}
+	fake.Advance(2 * time.Hour)
+	select {
+	case <-long.C():
+		t.Error("Expected a stopped timer never to fire")
+	default:
+	}
+	if got := fake.Now(); !got.Equal(epoch.Add(2*time.Hour + time.Second)) {
+		t.Errorf("Expected the fake time to add up, got %s", got)
+	}
+}
+
+func TestFake_SleepAndBlockUntil(t *testing.T) {
+	fake := NewFake(epoch)
+	done := make(chan struct{})
+	go func() {
+		fake.Sleep(time.Minute)
+		close(done)
+	}()
+
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	<-done
+
+	if fake.Waiters() != 0 {
+		t.Errorf("Expected no waiters after the sleep returned, got %d", fake.Waiters())
+	}
+}
+
+func TestWait(t *testing.T) {
+	fake := NewFake(epoch)
+	result := make(chan error, 1)
+	go func() { result <- Wait(context.Background(), fake, time.Hour) }()
+	fake.BlockUntil(1)
+	fake.Advance(time.Hour)
+	if err := <-result; err != nil {
+		t.Errorf("Expected the wait to finish, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { result <- Wait(ctx, fake, time.Hour) }()
+	fake.BlockUntil(1)
+	cancel()
+	if err := <-result; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the wait to end with the context, got %v", err)
+	}
+	if fake.Waiters() != 0 {
+		t.Errorf("Expected the abandoned timer to be stopped, got %d waiters", fake.Waiters())
+	}
+}
+
+func TestOrReal(t *testing.T) {
+	if _, ok := OrReal(nil).(realClock); !ok {
+		t.Error("Expected nil to fall back to the real clock")
+	}
+	fake := NewFake(epoch)
+	if OrReal(fake) != Clock(fake) {
+		t.Error("Expected a set clock to be kept")
+	}
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"sync"
+)
+
+// accountCacheContextKey is the context key under which WithAccountCache stores its cache
+type accountCacheContextKey struct{}
+
+// accountCache holds the accounts and exchanges looked up under one request context
+type accountCache struct {
+	mu        sync.Mutex
+	accounts  map[string]*ExchangeAccount
+	exchanges map[string]*Exchange
+}
+
+// WithAccountCache returns a context carrying a fresh cache for GetAccount and GetExchange, so
+// repeated lookups of the same id while serving one request are answered from memory
+// The cache lives only as long as the returned context and is never shared with other contexts;
+// call it once per request. Account and exchange updates made with the context evict their entry
+func WithAccountCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, accountCacheContextKey{}, &accountCache{
+		accounts:  make(map[string]*ExchangeAccount),
+		exchanges: make(map[string]*Exchange),
+	})
+}
+
+// accountCacheFromContext returns the cache installed by WithAccountCache, or nil
+func accountCacheFromContext(ctx context.Context) *accountCache {
+	cache, _ := ctx.Value(accountCacheContextKey{}).(*accountCache)
+	return cache
+}
+
+// cachedAccount returns a copy of the cached account with id, if ctx has one
+func cachedAccount(ctx context.Context, id string) (*ExchangeAccount, bool) {
+	cache := accountCacheFromContext(ctx)
+	if cache == nil {
+		return nil, false
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	account, ok := cache.accounts[id]
+	if !ok {
+		return nil, false
+	}
+	return cloneAccount(account), true
+}
+
+// cacheAccount stores a copy of account, and of its nested exchange, in ctx's cache if it has one
+func cacheAccount(ctx context.Context, account *ExchangeAccount) {
+	cache := accountCacheFromContext(ctx)
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.accounts[account.ID] = cloneAccount(account)
+	if account.Exchange != nil && account.Exchange.ID != "" {
+		exchange := *account.Exchange
+		cache.exchanges[exchange.ID] = &exchange
+	}
+}
+
+// cachedExchange returns a copy of the cached exchange with id, if ctx has one
+func cachedExchange(ctx context.Context, id string) (*Exchange, bool) {
+	cache := accountCacheFromContext(ctx)
+	if cache == nil {
+		return nil, false
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	exchange, ok := cache.exchanges[id]
+	if !ok {
+		return nil, false
+	}
+	clone := *exchange
+	return &clone, true
+}
+
+// cacheExchange stores a copy of exchange in ctx's cache if it has one
+func cacheExchange(ctx context.Context, exchange *Exchange) {
+	cache := accountCacheFromContext(ctx)
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	clone := *exchange
+	cache.exchanges[exchange.ID] = &clone
+}
+
+// evictAccount drops the account with id from ctx's cache
+func evictAccount(ctx context.Context, id string) {
+	if cache := accountCacheFromContext(ctx); cache != nil {
+		cache.mu.Lock()
+		delete(cache.accounts, id)
+		cache.mu.Unlock()
+	}
+}
+
+// evictExchange drops the exchange with id, and every account nesting it, from ctx's cache
+func evictExchange(ctx context.Context, id string) {
+	if cache := accountCacheFromContext(ctx); cache != nil {
+		cache.mu.Lock()
+		delete(cache.exchanges, id)
+		for accountID, account := range cache.accounts {
+			if account.Exchange != nil && account.Exchange.ID == id {
+				delete(cache.accounts, accountID)
+			}
+		}
+		cache.mu.Unlock()
+	}
+}
+
+// cloneAccount copies account, including its nested exchange and metadata, so callers can't
+// modify cached values
+func cloneAccount(account *ExchangeAccount) *ExchangeAccount {
+	clone := *account
+	if account.Exchange != nil {
+		exchange := *account.Exchange
+		clone.Exchange = &exchange
+	}
+	if account.PnLDenomination != nil {
+		denom := *account.PnLDenomination
+		clone.PnLDenomination = &denom
+	}
+	if account.AccountTypeMetadata != nil {
+		clone.AccountTypeMetadata = append([]byte(nil), account.AccountTypeMetadata...)
+	}
+	return &clone
+}
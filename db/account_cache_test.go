@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/machinebox/graphql"
+)
+
+// countingAccountClient answers GetAccount, GetExchange and SetAccountEnabled, counting calls per operation
+func countingAccountClient(calls map[string]int) *mockGraphQLClient {
+	return &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			r := requestFromContext(ctx)
+			calls[r.opName]++
+			exchange := map[string]interface{}{"id": "exchange-1", "name": "hyperliquid", "display_name": "Hyperliquid"}
+			var respData map[string]interface{}
+			switch r.opName {
+			case "GetAccount":
+				respData = map[string]interface{}{"exchange_accounts_by_pk": map[string]interface{}{
+					"id": r.vars["id"], "account_identifier": "0xabc", "account_type": "main", "exchange": exchange,
+				}}
+			case "GetExchange":
+				respData = map[string]interface{}{"exchanges_by_pk": exchange}
+			case "SetAccountEnabled":
+				respData = map[string]interface{}{"update_exchange_accounts_by_pk": map[string]interface{}{"id": r.vars["id"]}}
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+}
+
+func TestWithAccountCache_GetAccount(t *testing.T) {
+	calls := make(map[string]int)
+	client := NewClientWithGraphQL(countingAccountClient(calls), ClientConfig{})
+
+	ctx := WithAccountCache(context.Background())
+	first, err := client.GetAccount(ctx, "account-1")
+	if err != nil {
+		t.Fatalf("GetAccount failed: %v", err)
+	}
+	first.AccountIdentifier = "modified" // Must not leak into the cache
+	first.Exchange.Name = "modified"
+
+	second, err := client.GetAccount(ctx, "account-1")
+	if err != nil {
+		t.Fatalf("GetAccount failed: %v", err)
+	}
+	if calls["GetAccount"] != 1 {
+		t.Errorf("Expected the second GetAccount in the same ctx to issue no query, got %d queries", calls["GetAccount"])
+	}
+	if second.AccountIdentifier != "0xabc" || second.Exchange.Name != "hyperliquid" {
+		t.Errorf("Expected an unmodified cached account, got %+v (exchange %+v)", second, second.Exchange)
+	}
+
+	// The account's nested exchange is cached too
+	if _, err := client.GetExchange(ctx, "exchange-1"); err != nil {
+		t.Fatalf("GetExchange failed: %v", err)
+	}
+	if calls["GetExchange"] != 0 {
+		t.Errorf("Expected GetExchange to be answered from the cache, got %d queries", calls["GetExchange"])
+	}
+
+	// A fresh context does not see the cache
+	if _, err := client.GetAccount(WithAccountCache(context.Background()), "account-1"); err != nil {
+		t.Fatalf("GetAccount failed: %v", err)
+	}
+	if _, err := client.GetAccount(context.Background(), "account-1"); err != nil {
+		t.Fatalf("GetAccount failed: %v", err)
+	}
+	if calls["GetAccount"] != 3 {
+		t.Errorf("Expected fresh contexts to query again, got %d queries", calls["GetAccount"])
+	}
+}
+
+func TestWithAccountCache_NotUsedWithoutOptIn(t *testing.T) {
+	calls := make(map[string]int)
+	client := NewClientWithGraphQL(countingAccountClient(calls), ClientConfig{})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetExchange(ctx, "exchange-1"); err != nil {
+			t.Fatalf("GetExchange failed: %v", err)
+		}
+	}
+	if calls["GetExchange"] != 2 {
+		t.Errorf("Expected every GetExchange to query without WithAccountCache, got %d queries", calls["GetExchange"])
+	}
+}
+
+func TestWithAccountCache_UpdateEvicts(t *testing.T) {
+	calls := make(map[string]int)
+	client := NewClientWithGraphQL(countingAccountClient(calls), ClientConfig{})
+
+	ctx := WithAccountCache(context.Background())
+	if _, err := client.GetAccount(ctx, "account-1"); err != nil {
+		t.Fatalf("GetAccount failed: %v", err)
+	}
+	if err := client.SetAccountEnabled(ctx, "account-1", false); err != nil {
+		t.Fatalf("SetAccountEnabled failed: %v", err)
+	}
+	if _, err := client.GetAccount(ctx, "account-1"); err != nil {
+		t.Fatalf("GetAccount failed: %v", err)
+	}
+	if calls["GetAccount"] != 2 {
+		t.Errorf("Expected the update to evict the cached account, got %d queries", calls["GetAccount"])
+	}
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/zif-terminal/lib/errs"
+	"github.com/zif-terminal/lib/models"
+)
+
+func init() {
+	registerOperations(map[string]Idempotency{
+		"PatchAccount": Idempotent, // Update by primary key
+	})
+}
+
+// AccountPatch lists the account columns PatchAccount changes (aliased from models package)
+type AccountPatch = models.AccountPatch
+
+// accountMetadataPageSize is how many accounts MigrateAllAccountMetadata reads per page
+const accountMetadataPageSize = 100
+
+// MigrationReport summarizes a MigrateAllAccountMetadata run
+type MigrationReport struct {
+	DryRun   bool
+	Scanned  int      // Accounts read
+	Migrated int      // Accounts whose metadata was upgraded (or would be, in a dry run)
+	Failed   int      // Accounts whose metadata could not be migrated or written
+	Changed  []string // IDs of the migrated accounts, in id order
+}
+
+// PatchAccount sets the non-nil fields of patch on the account and returns the updated account
+func (c *Client) PatchAccount(ctx context.Context, id string, patch AccountPatch) (*ExchangeAccount, error) {
+	evictAccount(ctx, id)
+
+	if patch.AccountTypeMetadata == nil {
+		return nil, fmt.Errorf("failed to patch account: no fields to set")
+	}
+
+	var metadata interface{}
+	if err := json.Unmarshal(patch.AccountTypeMetadata, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to patch account: invalid account type metadata: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		mutation PatchAccount($id: uuid!, $account_type_metadata: jsonb) {
+			update_exchange_accounts_by_pk(pk_columns: {id: $id}, _set: {account_type_metadata: $account_type_metadata}) {
+				id
+				user_id
+				account_identifier
+				account_type
+				account_type_metadata
+				pnl_denomination
+				%s
+				exchange {
+					id
+					name
+					display_name
+				}
+			}
+		}
+	`, c.accountEnabledSelection())
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"id":                    id,
+		"account_type_metadata": metadata,
+	})
+
+	var resp struct {
+		UpdateExchangeAccountsByPk *ExchangeAccount `json:"update_exchange_accounts_by_pk"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to patch account: %w", err)
+	}
+
+	if resp.UpdateExchangeAccountsByPk == nil {
+		return nil, fmt.Errorf("account not found: %s", id)
+	}
+
+	return resp.UpdateExchangeAccountsByPk, nil
+}
+
+// MigrateAllAccountMetadata pages through every account and upgrades its account_type_metadata to
+// models.CurrentAccountMetadataVersion with PatchAccount. A dry run only counts what would change
+// An account that fails does not stop the others; the error is an *errs.Multi with one member
+// per failed account, and the report is returned either way
+func (c *Client) MigrateAllAccountMetadata(ctx context.Context, dryRun bool) (*MigrationReport, error) {
+	report := &MigrationReport{DryRun: dryRun}
+	failures := &errs.Multi{}
+
+	err := c.IterateAccounts(ctx, accountMetadataPageSize, func(accounts []*ExchangeAccount) error {
+		for _, account := range accounts {
+			report.Scanned++
+
+			migrated, changed, err := models.MigrateAccountMetadata(account.AccountTypeMetadata, account.AccountType)
+			if err != nil {
+				report.Failed++
+				failures.Append(fmt.Errorf("account %s: %w", account.ID, err))
+				continue
+			}
+			if !changed {
+				continue
+			}
+
+			if !dryRun {
+				if _, err := c.PatchAccount(ctx, account.ID, AccountPatch{AccountTypeMetadata: migrated}); err != nil {
+					report.Failed++
+					failures.Append(fmt.Errorf("account %s: %w", account.ID, err))
+					continue
+				}
+			}
+			report.Migrated++
+			report.Changed = append(report.Changed, account.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to migrate account metadata: %w", err)
+	}
+
+	return report, failures.ErrorOrNil()
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/errs"
+)
+
+// metadataAccountsClient serves one page of accounts to IterateAccounts and records PatchAccount calls
+func metadataAccountsClient(t *testing.T, patched map[string]string) *mockGraphQLClient {
+	accounts := []map[string]interface{}{
+		{"id": "00000000-0000-0000-0000-000000000001", "account_type": "main", "account_type_metadata": nil},
+		{"id": "00000000-0000-0000-0000-000000000002", "account_type": "vault", "account_type_metadata": map[string]interface{}{"address": "0xvault"}},
+		{"id": "00000000-0000-0000-0000-000000000003", "account_type": "sub_account", "account_type_metadata": map[string]interface{}{"index": 1}},
+		{"id": "00000000-0000-0000-0000-000000000004", "account_type": "vault", "account_type_metadata": map[string]interface{}{"version": 1, "address": "0xcurrent"}},
+		{"id": "00000000-0000-0000-0000-000000000005", "account_type": "vault", "account_type_metadata": map[string]interface{}{"version": 9}},
+	}
+
+	return &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			r := requestFromContext(ctx)
+			var respData map[string]interface{}
+			switch r.opName {
+			case "IterateAccounts":
+				respData = map[string]interface{}{"exchange_accounts": accounts}
+			case "PatchAccount":
+				id := r.vars["id"].(string)
+				encoded, _ := json.Marshal(r.vars["account_type_metadata"])
+				patched[id] = string(encoded)
+				respData = map[string]interface{}{"update_exchange_accounts_by_pk": map[string]interface{}{"id": id}}
+			default:
+				t.Fatalf("Unexpected operation %s", r.opName)
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+}
+
+func TestClient_MigrateAllAccountMetadata_DryRun(t *testing.T) {
+	patched := make(map[string]string)
+	client := NewClientWithGraphQL(metadataAccountsClient(t, patched), ClientConfig{})
+
+	report, err := client.MigrateAllAccountMetadata(context.Background(), true)
+
+	var multi *errs.Multi
+	if !errors.As(err, &multi) || multi.Len() != 1 {
+		t.Fatalf("Expected one failure for the newer-version metadata, got %v", err)
+	}
+	if !report.DryRun || report.Scanned != 5 || report.Migrated != 2 || report.Failed != 1 {
+		t.Errorf("Expected 5 scanned, 2 to migrate, 1 failed, got %+v", report)
+	}
+	if len(patched) != 0 {
+		t.Errorf("Expected a dry run to write nothing, got %v", patched)
+	}
+}
+
+func TestClient_MigrateAllAccountMetadata_Writes(t *testing.T) {
+	patched := make(map[string]string)
+	client := NewClientWithGraphQL(metadataAccountsClient(t, patched), ClientConfig{})
+
+	report, err := client.MigrateAllAccountMetadata(context.Background(), false)
+	if err == nil {
+		t.Fatal("Expected the newer-version metadata to be reported")
+	}
+
+	if report.Migrated != 2 || len(report.Changed) != 2 {
+		t.Errorf("Expected 2 migrated accounts, got %+v", report)
+	}
+	want := map[string]string{
+		"00000000-0000-0000-0000-000000000002": `{"address":"0xvault","version":1}`,
+		"00000000-0000-0000-0000-000000000003": `{"index":1,"version":1}`,
+	}
+	if len(patched) != len(want) {
+		t.Fatalf("Expected %d patches, got %v", len(want), patched)
+	}
+	for id, metadata := range want {
+		if patched[id] != metadata {
+			t.Errorf("Expected account %s patched with %s, got %s", id, metadata, patched[id])
+		}
+	}
+}
+
+func TestClient_PatchAccount_RequiresAField(t *testing.T) {
+	client := NewClientWithGraphQL(&mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			t.Error("Expected no request for an empty patch")
+			return nil
+		},
+	}, ClientConfig{})
+
+	if _, err := client.PatchAccount(context.Background(), "account-1", AccountPatch{}); err == nil {
+		t.Fatal("Expected an error for an empty patch")
+	}
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/models"
+)
+
+// AccountDataSummary describes what is stored for one account (aliased from models package)
+type AccountDataSummary = models.AccountDataSummary
+
+// timestampAggregate decodes "aggregate { count min { timestamp } max { timestamp } }"
+type timestampAggregate struct {
+	Aggregate *struct {
+		Count int `json:"count"`
+		Min   struct {
+			Timestamp json.RawMessage `json:"timestamp"`
+		} `json:"min"`
+		Max struct {
+			Timestamp json.RawMessage `json:"timestamp"`
+		} `json:"max"`
+	} `json:"aggregate"`
+}
+
+// GetAccountDataSummary gathers counts and time ranges of an account's stored data in a single request
+func (c *Client) GetAccountDataSummary(ctx context.Context, accountID uuid.UUID) (*AccountDataSummary, error) {
+	query := `
+		query GetAccountDataSummary($exchange_account_id: uuid!) {
+			trades: trades_aggregate(where: { exchange_account_id: { _eq: $exchange_account_id } }) {
+				aggregate {
+					count
+					min { timestamp }
+					max { timestamp }
+				}
+			}
+			funding: funding_payments_aggregate(where: { exchange_account_id: { _eq: $exchange_account_id } }) {
+				aggregate {
+					count
+					min { timestamp }
+					max { timestamp }
+				}
+			}
+			open_positions: positions_aggregate(where: {
+				exchange_account_id: { _eq: $exchange_account_id }
+				end_time: { _is_null: true }
+			}) {
+				aggregate {
+					count
+				}
+			}
+			closed_positions: positions_aggregate(where: {
+				exchange_account_id: { _eq: $exchange_account_id }
+				end_time: { _is_null: false }
+			}) {
+				aggregate {
+					count
+				}
+			}
+			last_processed: position_trades(
+				where: { position: { exchange_account_id: { _eq: $exchange_account_id } } }
+				order_by: [{ trade: { timestamp: desc } }, { trade: { id: desc } }]
+				limit: 1
+			) {
+				trade {
+					timestamp
+				}
+			}
+		}
+	`
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"exchange_account_id": uuidVar(accountID),
+	})
+
+	var resp struct {
+		Trades          timestampAggregate `json:"trades"`
+		Funding         timestampAggregate `json:"funding"`
+		OpenPositions   aggregateCount     `json:"open_positions"`
+		ClosedPositions aggregateCount     `json:"closed_positions"`
+		LastProcessed   []struct {
+			Trade struct {
+				Timestamp json.RawMessage `json:"timestamp"`
+			} `json:"trade"`
+		} `json:"last_processed"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get account data summary: %w", err)
+	}
+
+	summary := &AccountDataSummary{ExchangeAccountID: accountID}
+	var err error
+
+	if agg := resp.Trades.Aggregate; agg != nil {
+		summary.TradeCount = agg.Count
+		if summary.FirstTradeAt, err = millisToTime(agg.Min.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to get account data summary: %w", err)
+		}
+		if summary.LastTradeAt, err = millisToTime(agg.Max.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to get account data summary: %w", err)
+		}
+	}
+
+	if agg := resp.Funding.Aggregate; agg != nil {
+		summary.FundingPaymentCount = agg.Count
+		if summary.FirstFundingAt, err = millisToTime(agg.Min.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to get account data summary: %w", err)
+		}
+		if summary.LastFundingAt, err = millisToTime(agg.Max.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to get account data summary: %w", err)
+		}
+	}
+
+	if resp.OpenPositions.Aggregate != nil {
+		summary.OpenPositionCount = resp.OpenPositions.Aggregate.Count
+	}
+	if resp.ClosedPositions.Aggregate != nil {
+		summary.ClosedPositionCount = resp.ClosedPositions.Aggregate.Count
+	}
+
+	if len(resp.LastProcessed) > 0 {
+		if summary.LastSyncCursor, err = millisToTime(resp.LastProcessed[0].Trade.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to get account data summary: %w", err)
+		}
+	}
+
+	return summary, nil
+}
+
+// millisToTime decodes a BIGINT Unix-milliseconds value that Hasura may return as a number or string
+// Returns nil for null or missing values
+func millisToTime(raw json.RawMessage) (*time.Time, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		s = string(raw)
+	}
+
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %s: %w", raw, err)
+	}
+
+	t := time.UnixMilli(ms).UTC()
+	return &t, nil
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+)
+
+func TestClient_GetAccountDataSummary(t *testing.T) {
+	ctx := context.Background()
+	accountID := uuid.New()
+
+	// Trades present (bigint returned as numbers), funding and positions empty
+	fixture := `{
+		"trades": {"aggregate": {"count": 42, "min": {"timestamp": 1609459200000}, "max": {"timestamp": "1612137600000"}}},
+		"funding": {"aggregate": {"count": 0, "min": {"timestamp": null}, "max": {"timestamp": null}}},
+		"open_positions": {"aggregate": {"count": 0}},
+		"closed_positions": {"aggregate": {"count": 3}},
+		"last_processed": [{"trade": {"timestamp": 1610000000000}}]
+	}`
+
+	var query string
+	calls := 0
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			calls++
+			query = requestFromContext(ctx).query
+			return json.Unmarshal([]byte(fixture), resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	summary, err := client.GetAccountDataSummary(ctx, accountID)
+	if err != nil {
+		t.Fatalf("GetAccountDataSummary failed: %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected a single request, got %d", calls)
+	}
+	for _, alias := range []string{"trades: trades_aggregate", "funding: funding_payments_aggregate", "open_positions: positions_aggregate", "closed_positions: positions_aggregate", "last_processed: position_trades"} {
+		if !strings.Contains(query, alias) {
+			t.Errorf("Expected aliased selection %q in query", alias)
+		}
+	}
+
+	if summary.ExchangeAccountID != accountID {
+		t.Errorf("Expected account ID %s, got %s", accountID, summary.ExchangeAccountID)
+	}
+	if summary.TradeCount != 42 {
+		t.Errorf("Expected 42 trades, got %d", summary.TradeCount)
+	}
+	if summary.FirstTradeAt == nil || !summary.FirstTradeAt.Equal(time.UnixMilli(1609459200000)) {
+		t.Errorf("Unexpected FirstTradeAt %v", summary.FirstTradeAt)
+	}
+	if summary.LastTradeAt == nil || !summary.LastTradeAt.Equal(time.UnixMilli(1612137600000)) {
+		t.Errorf("Unexpected LastTradeAt %v", summary.LastTradeAt)
+	}
+	if summary.FundingPaymentCount != 0 || summary.FirstFundingAt != nil || summary.LastFundingAt != nil {
+		t.Errorf("Expected empty funding summary, got count=%d first=%v last=%v",
+			summary.FundingPaymentCount, summary.FirstFundingAt, summary.LastFundingAt)
+	}
+	if summary.OpenPositionCount != 0 || summary.ClosedPositionCount != 3 {
+		t.Errorf("Expected 0 open and 3 closed positions, got %d and %d", summary.OpenPositionCount, summary.ClosedPositionCount)
+	}
+	if summary.LastSyncCursor == nil || !summary.LastSyncCursor.Equal(time.UnixMilli(1610000000000)) {
+		t.Errorf("Unexpected LastSyncCursor %v", summary.LastSyncCursor)
+	}
+}
+
+func TestClient_GetAccountDataSummary_Empty(t *testing.T) {
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			return json.Unmarshal([]byte(`{"last_processed": []}`), resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	summary, err := client.GetAccountDataSummary(context.Background(), uuid.New())
+	if err != nil {
+		t.Fatalf("GetAccountDataSummary failed: %v", err)
+	}
+
+	if summary.TradeCount != 0 || summary.LastTradeAt != nil || summary.LastSyncCursor != nil {
+		t.Errorf("Expected zero summary, got %+v", summary)
+	}
+}
@@ -4,25 +4,53 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/zif-terminal/lib/models"
 )
 
+func init() {
+	registerOperations(map[string]Idempotency{
+		"CreateAccount":             NotIdempotent, // Plain insert
+		"CreateAccounts":            NotIdempotent, // Plain insert
+		"UpdateAccount":             Idempotent,    // Update by primary key
+		"DeleteAccount":             Idempotent,    // Delete by primary key
+		"SetAccountPnLDenomination": Idempotent,    // Update by primary key
+		"SetAccountEnabled":         Idempotent,    // Update by primary key
+		"EnsureAccountType":         Idempotent,    // Insert ignored on conflict
+	})
+}
+
 // ExchangeAccount represents an exchange account model (aliased from models package)
 type ExchangeAccount = models.ExchangeAccount
 
 // ExchangeAccountInput represents exchange account input for mutations (aliased from models package)
 type ExchangeAccountInput = models.ExchangeAccountInput
 
+// AccountType represents an exchange account type (aliased from models package)
+type AccountType = models.AccountType
+
+// DefaultAccountEnabledColumn is the boolean exchange_accounts column read into ExchangeAccount.Enabled
+const DefaultAccountEnabledColumn = "enabled"
+
 // GetAccount retrieves a single exchange account by ID
+// Within a context from WithAccountCache, repeated lookups are answered from the cache
 func (c *Client) GetAccount(ctx context.Context, id string) (*ExchangeAccount, error) {
-	query := `
+	if account, ok := cachedAccount(ctx, id); ok {
+		return account, nil
+	}
+
+	query := fmt.Sprintf(`
 		query GetAccount($id: uuid!) {
 			exchange_accounts_by_pk(id: $id) {
 				id
+				user_id
 				account_identifier
 				account_type
 				account_type_metadata
+				pnl_denomination
+				%s
 				exchange {
 					id
 					name
@@ -30,7 +58,7 @@ func (c *Client) GetAccount(ctx context.Context, id string) (*ExchangeAccount, e
 				}
 			}
 		}
-	`
+	`, c.accountEnabledSelection())
 
 	req := c.graphqlRequestWithVars(query, map[string]interface{}{
 		"id": id,
@@ -48,18 +76,22 @@ func (c *Client) GetAccount(ctx context.Context, id string) (*ExchangeAccount, e
 		return nil, fmt.Errorf("account not found: %s", id)
 	}
 
+	cacheAccount(ctx, resp.ExchangeAccountsByPk)
 	return resp.ExchangeAccountsByPk, nil
 }
 
 // ListAccounts retrieves all exchange accounts
 func (c *Client) ListAccounts(ctx context.Context) ([]*ExchangeAccount, error) {
-	query := `
+	query := fmt.Sprintf(`
 		query ListAccounts {
 			exchange_accounts {
 				id
+				user_id
 				account_identifier
 				account_type
 				account_type_metadata
+				pnl_denomination
+				%s
 				exchange {
 					id
 					name
@@ -67,7 +99,7 @@ func (c *Client) ListAccounts(ctx context.Context) ([]*ExchangeAccount, error) {
 				}
 			}
 		}
-	`
+	`, c.accountEnabledSelection())
 
 	req := c.graphqlRequest(query)
 
@@ -82,20 +114,112 @@ func (c *Client) ListAccounts(ctx context.Context) ([]*ExchangeAccount, error) {
 	return resp.ExchangeAccounts, nil
 }
 
+// AccountFilter represents filtering options for listing accounts (aliased from models package)
+type AccountFilter = models.AccountFilter
+
+// ExchangeIdentifier names an account by exchange name and identifier (aliased from models package)
+type ExchangeIdentifier = models.ExchangeIdentifier
+
+// ListAccountsFiltered retrieves exchange accounts matching filter, including the nested exchange
+// An empty filter returns the same accounts as ListAccounts
+func (c *Client) ListAccountsFiltered(ctx context.Context, filter AccountFilter) ([]*ExchangeAccount, error) {
+	b, err := buildAccountWhere(filter, c.accountEnabledColumn())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	args := ""
+	if !b.empty() {
+		args = "(" + b.whereArg() + ")"
+	}
+
+	query := fmt.Sprintf(`
+		query ListAccountsFiltered%s {
+			exchange_accounts%s {
+				id
+				user_id
+				account_identifier
+				account_type
+				account_type_metadata
+				pnl_denomination
+				%s
+				exchange {
+					id
+					name
+					display_name
+				}
+			}
+		}
+	`, b.declarations(), args, c.accountEnabledSelection())
+
+	req := c.graphqlRequestWithVars(query, b.variables())
+
+	var resp struct {
+		ExchangeAccounts []*ExchangeAccount `json:"exchange_accounts"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	return resp.ExchangeAccounts, nil
+}
+
+// buildAccountWhere translates an AccountFilter into where-clause conditions
+// enabledColumn is the boolean column ActiveOnly filters on
+func buildAccountWhere(filter AccountFilter, enabledColumn string) (*whereBuilder, error) {
+	b := newWhereBuilder()
+
+	if len(filter.ExchangeNames) > 0 {
+		b.add("exchange.name", "_in", "exchange_names", "[String!]!", filter.ExchangeNames)
+	}
+	if len(filter.AccountTypes) > 0 {
+		b.add("account_type", "_in", "account_types", "[String!]!", filter.AccountTypes)
+	}
+	if filter.ActiveOnly {
+		if err := b.allowColumn(enabledColumn); err != nil {
+			return nil, fmt.Errorf("invalid account enabled column: %w", err)
+		}
+		b.addRaw(enabledColumn, "_eq: true")
+	}
+	if len(filter.UserIDs) > 0 {
+		b.add("user_id", "_in", "user_ids", "[uuid!]!", filter.UserIDs)
+	}
+	if filter.UserID != nil {
+		b.add("user_id", "_eq", "user_id", "uuid!", *filter.UserID)
+	}
+
+	return b, nil
+}
+
 // CreateAccount creates a new exchange account
+// The account identifier is validated and normalized for the exchange first (see models.NormalizeAccountIdentifier)
 func (c *Client) CreateAccount(ctx context.Context, input *ExchangeAccountInput) (*ExchangeAccount, error) {
-	query := `
-		mutation CreateAccount($exchange_id: uuid!, $account_identifier: String!, $account_type: String!, $account_type_metadata: jsonb) {
+	identifier, err := c.normalizeAccountIdentifier(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account: %w", err)
+	}
+	denom, err := pnlDenominationValue(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		mutation CreateAccount($exchange_id: uuid!, $account_identifier: String!, $account_type: String!, $account_type_metadata: jsonb, $pnl_denomination: String) {
 			insert_exchange_accounts_one(object: {
 				exchange_id: $exchange_id
 				account_identifier: $account_identifier
 				account_type: $account_type
 				account_type_metadata: $account_type_metadata
+				pnl_denomination: $pnl_denomination
 			}) {
 				id
+				user_id
 				account_identifier
 				account_type
 				account_type_metadata
+				pnl_denomination
+				%s
 				exchange {
 					id
 					name
@@ -103,12 +227,13 @@ func (c *Client) CreateAccount(ctx context.Context, input *ExchangeAccountInput)
 				}
 			}
 		}
-	`
+	`, c.accountEnabledSelection())
 
 	vars := map[string]interface{}{
 		"exchange_id":       input.ExchangeID,
-		"account_identifier": input.AccountIdentifier,
+		"account_identifier": identifier,
 		"account_type":       input.AccountType,
+		"pnl_denomination":   denom,
 	}
 
 	// Only include metadata if it's not empty
@@ -136,20 +261,136 @@ func (c *Client) CreateAccount(ctx context.Context, input *ExchangeAccountInput)
 	return resp.InsertExchangeAccountsOne, nil
 }
 
+// CreateAccounts creates several exchange accounts in a batch insert, returning them in input order
+// Every input is validated and its identifier normalized first (see CreateAccount); nothing is sent
+// if any input is invalid. Batches over ClientConfig.MaxRequestBytes are split into several requests
+func (c *Client) CreateAccounts(ctx context.Context, inputs []*ExchangeAccountInput) ([]*ExchangeAccount, error) {
+	if len(inputs) == 0 {
+		return []*ExchangeAccount{}, nil
+	}
+
+	exchangeNames := make(map[string]string)
+	objects := make([]map[string]interface{}, len(inputs))
+	for i, input := range inputs {
+		name, ok := exchangeNames[input.ExchangeID]
+		if !ok {
+			exchange, err := c.GetExchange(ctx, input.ExchangeID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create accounts: input %d: %w", i, err)
+			}
+			name = exchange.Name
+			exchangeNames[input.ExchangeID] = name
+		}
+		identifier, err := models.NormalizeAccountIdentifier(name, input.AccountIdentifier)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create accounts: input %d: %w", i, err)
+		}
+		denom, err := pnlDenominationValue(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create accounts: input %d: %w", i, err)
+		}
+
+		objects[i] = map[string]interface{}{
+			"exchange_id":        input.ExchangeID,
+			"account_identifier": identifier,
+			"account_type":       input.AccountType,
+			"pnl_denomination":   denom,
+		}
+		if len(input.AccountTypeMetadata) > 0 {
+			var metadata interface{}
+			if err := json.Unmarshal(input.AccountTypeMetadata, &metadata); err != nil {
+				return nil, fmt.Errorf("failed to create accounts: input %d: invalid account_type_metadata: %w", i, err)
+			}
+			objects[i]["account_type_metadata"] = metadata
+		}
+	}
+
+	query := fmt.Sprintf(`
+		mutation CreateAccounts($objects: [exchange_accounts_insert_input!]!) {
+			insert_exchange_accounts(objects: $objects) {
+				returning {
+					id
+					user_id
+					account_identifier
+					account_type
+					account_type_metadata
+					pnl_denomination
+					%s
+					exchange {
+						id
+						name
+						display_name
+					}
+				}
+			}
+		}
+	`, c.accountEnabledSelection())
+
+	created, err := insertChunked(c, query, objects, func(req *request) ([]*ExchangeAccount, error) {
+		var resp struct {
+			InsertExchangeAccounts struct {
+				Returning []*ExchangeAccount `json:"returning"`
+			} `json:"insert_exchange_accounts"`
+		}
+		if err := c.execute(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+		return resp.InsertExchangeAccounts.Returning, nil
+	})
+	if err != nil {
+		return created, fmt.Errorf("failed to create accounts: %w", err)
+	}
+
+	keys := make([]string, len(objects))
+	for i, object := range objects {
+		keys[i] = fmt.Sprintf("%s/%s", object["exchange_id"], object["account_identifier"])
+	}
+	orderLikeInputs(created, keys, func(account *ExchangeAccount) string {
+		if account.Exchange == nil {
+			return ""
+		}
+		return account.Exchange.ID + "/" + account.AccountIdentifier
+	})
+
+	return created, nil
+}
+
 // UpdateAccount updates an existing exchange account
+// The account identifier is validated and normalized for the exchange first (see models.NormalizeAccountIdentifier)
+// A nil PnLDenomination keeps the stored preference
 func (c *Client) UpdateAccount(ctx context.Context, id string, input *ExchangeAccountInput) (*ExchangeAccount, error) {
-	query := `
-		mutation UpdateAccount($id: uuid!, $exchange_id: uuid!, $account_identifier: String!, $account_type: String!, $account_type_metadata: jsonb) {
+	evictAccount(ctx, id)
+
+	identifier, err := c.normalizeAccountIdentifier(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update account: %w", err)
+	}
+	denom, err := pnlDenominationValue(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update account: %w", err)
+	}
+
+	// An unset PnLDenomination leaves the stored preference alone (see SetAccountPnLDenomination to clear it)
+	denomDecl, denomSet := "", ""
+	if input.PnLDenomination != nil {
+		denomDecl, denomSet = ", $pnl_denomination: String", "\n\t\t\t\tpnl_denomination: $pnl_denomination"
+	}
+
+	query := fmt.Sprintf(`
+		mutation UpdateAccount($id: uuid!, $exchange_id: uuid!, $account_identifier: String!, $account_type: String!, $account_type_metadata: jsonb%s) {
 			update_exchange_accounts_by_pk(pk_columns: {id: $id}, _set: {
 				exchange_id: $exchange_id
 				account_identifier: $account_identifier
 				account_type: $account_type
-				account_type_metadata: $account_type_metadata
+				account_type_metadata: $account_type_metadata%s
 			}) {
 				id
+				user_id
 				account_identifier
 				account_type
 				account_type_metadata
+				pnl_denomination
+				%s
 				exchange {
 					id
 					name
@@ -157,14 +398,17 @@ func (c *Client) UpdateAccount(ctx context.Context, id string, input *ExchangeAc
 				}
 			}
 		}
-	`
+	`, denomDecl, denomSet, c.accountEnabledSelection())
 
 	vars := map[string]interface{}{
 		"id":                id,
 		"exchange_id":       input.ExchangeID,
-		"account_identifier": input.AccountIdentifier,
+		"account_identifier": identifier,
 		"account_type":       input.AccountType,
 	}
+	if input.PnLDenomination != nil {
+		vars["pnl_denomination"] = denom
+	}
 
 	// Only include metadata if it's not empty
 	if len(input.AccountTypeMetadata) > 0 {
@@ -193,6 +437,8 @@ func (c *Client) UpdateAccount(ctx context.Context, id string, input *ExchangeAc
 
 // DeleteAccount deletes an exchange account by ID
 func (c *Client) DeleteAccount(ctx context.Context, id string) error {
+	evictAccount(ctx, id)
+
 	query := `
 		mutation DeleteAccount($id: uuid!) {
 			delete_exchange_accounts_by_pk(id: $id) {
@@ -232,15 +478,329 @@ func (c *Client) ListAccountTypes(ctx context.Context) ([]*models.AccountType, e
 		}
 	`
 
-	req := c.graphqlRequest(query)
+	accountTypes, err := cachedReference(ctx, c, cacheGroupAccountTypes, referenceKey(query, nil), func(ctx context.Context) ([]*models.AccountType, error) {
+		req := c.graphqlRequest(query)
+
+		var resp struct {
+			AccountTypes []*models.AccountType `json:"exchange_account_types"`
+		}
+
+		if err := c.execute(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+		return resp.AccountTypes, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account types: %w", err)
+	}
+
+	clones := make([]*models.AccountType, len(accountTypes))
+	for i, accountType := range accountTypes {
+		clone := *accountType
+		clones[i] = &clone
+	}
+	return clones, nil
+}
+
+// EnsureAccountType creates the account type with the given code if it does not exist
+// Reports whether this call created it
+func (c *Client) EnsureAccountType(ctx context.Context, code string) (bool, error) {
+	query := `
+		mutation EnsureAccountType($code: String!) {
+			insert_exchange_account_types_one(
+				object: { code: $code }
+				on_conflict: { constraint: exchange_account_types_pkey, update_columns: [] }
+			) {
+				code
+			}
+		}
+	`
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"code": code,
+	})
 
 	var resp struct {
-		AccountTypes []*models.AccountType `json:"exchange_account_types"`
+		InsertExchangeAccountTypesOne *models.AccountType `json:"insert_exchange_account_types_one"`
 	}
 
+	defer c.invalidateReferences(cacheGroupAccountTypes)
 	if err := c.execute(ctx, req, &resp); err != nil {
-		return nil, fmt.Errorf("failed to list account types: %w", err)
+		return false, fmt.Errorf("failed to ensure account type: %w", err)
+	}
+
+	// An ignored conflict returns null
+	return resp.InsertExchangeAccountTypesOne != nil, nil
+}
+
+// IterateAccounts pages through all exchange accounts ordered by id, calling fn once per page
+// Uses keyset pagination (id > last seen id) so pages stay stable while accounts are added
+// Stops at the first error returned by fn or when ctx is cancelled
+func (c *Client) IterateAccounts(ctx context.Context, pageSize int, fn func([]*ExchangeAccount) error) error {
+	if pageSize <= 0 {
+		return fmt.Errorf("failed to iterate accounts: page size must be positive, got %d", pageSize)
+	}
+
+	after := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		b := newWhereBuilder()
+		if after != "" {
+			b.add("id", "_gt", "after", "uuid!", after)
+		}
+		b.declare("limit", "Int!", pageSize)
+
+		query := fmt.Sprintf(`
+			query IterateAccounts%s {
+				exchange_accounts(
+					%s
+					order_by: { id: asc }
+					limit: $limit
+				) {
+					id
+					user_id
+					account_identifier
+					account_type
+					account_type_metadata
+					pnl_denomination
+					%s
+					exchange {
+						id
+						name
+						display_name
+					}
+				}
+			}
+		`, b.declarations(), b.whereArg(), c.accountEnabledSelection())
+
+		req := c.graphqlRequestWithVars(query, b.variables())
+
+		var resp struct {
+			ExchangeAccounts []*ExchangeAccount `json:"exchange_accounts"`
+		}
+
+		if err := c.execute(ctx, req, &resp); err != nil {
+			return fmt.Errorf("failed to iterate accounts: %w", err)
+		}
+
+		if len(resp.ExchangeAccounts) == 0 {
+			return nil
+		}
+
+		if err := fn(resp.ExchangeAccounts); err != nil {
+			return err
+		}
+
+		if len(resp.ExchangeAccounts) < pageSize {
+			return nil
+		}
+		after = resp.ExchangeAccounts[len(resp.ExchangeAccounts)-1].ID
+	}
+}
+
+// normalizeAccountIdentifier looks up the input's exchange and returns its account identifier
+// in canonical form, or an *models.InvalidAccountError if it is malformed for that exchange
+func (c *Client) normalizeAccountIdentifier(ctx context.Context, input *ExchangeAccountInput) (string, error) {
+	exchange, err := c.GetExchange(ctx, input.ExchangeID)
+	if err != nil {
+		return "", err
+	}
+	return models.NormalizeAccountIdentifier(exchange.Name, input.AccountIdentifier)
+}
+
+// pnlDenominationValue returns the normalized PnL denomination of input as a GraphQL variable
+// (nil when unset, which stores NULL)
+func pnlDenominationValue(input *ExchangeAccountInput) (interface{}, error) {
+	if input.PnLDenomination == nil {
+		return nil, nil
+	}
+	return models.NormalizePnLDenomination(*input.PnLDenomination)
+}
+
+// SetAccountPnLDenomination sets the currency the account's PnL is displayed in
+// denom must be one of models.PnLDenominations (case-insensitive); an empty denom clears the preference
+func (c *Client) SetAccountPnLDenomination(ctx context.Context, id string, denom string) error {
+	evictAccount(ctx, id)
+
+	var value interface{} // nil clears the column
+	if denom != "" {
+		normalized, err := models.NormalizePnLDenomination(denom)
+		if err != nil {
+			return fmt.Errorf("failed to set PnL denomination: %w", err)
+		}
+		value = normalized
+	}
+
+	query := `
+		mutation SetAccountPnLDenomination($id: uuid!, $pnl_denomination: String) {
+			update_exchange_accounts_by_pk(pk_columns: {id: $id}, _set: {pnl_denomination: $pnl_denomination}) {
+				id
+			}
+		}
+	`
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"id":               id,
+		"pnl_denomination": value,
+	})
+
+	var resp struct {
+		UpdateExchangeAccountsByPk *struct {
+			ID string `json:"id"`
+		} `json:"update_exchange_accounts_by_pk"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return fmt.Errorf("failed to set PnL denomination: %w", err)
+	}
+
+	if resp.UpdateExchangeAccountsByPk == nil {
+		return fmt.Errorf("account not found: %s", id)
+	}
+
+	return nil
+}
+
+// SetAccountEnabled marks the account enabled or disabled for syncing without deleting it
+// Disabled accounts are left out of ListAccountsFiltered when AccountFilter.ActiveOnly is set
+func (c *Client) SetAccountEnabled(ctx context.Context, id string, enabled bool) error {
+	evictAccount(ctx, id)
+
+	query := fmt.Sprintf(`
+		mutation SetAccountEnabled($id: uuid!, $enabled: Boolean!) {
+			update_exchange_accounts_by_pk(pk_columns: {id: $id}, _set: {%s: $enabled}) {
+				id
+			}
+		}
+	`, c.accountEnabledColumn())
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"id":      id,
+		"enabled": enabled,
+	})
+
+	var resp struct {
+		UpdateExchangeAccountsByPk *struct {
+			ID string `json:"id"`
+		} `json:"update_exchange_accounts_by_pk"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return fmt.Errorf("failed to set account enabled: %w", err)
+	}
+
+	if resp.UpdateExchangeAccountsByPk == nil {
+		return fmt.Errorf("account not found: %s", id)
+	}
+
+	return nil
+}
+
+// accountEnabledColumn returns the configured accounts enabled column, or DefaultAccountEnabledColumn
+func (c *Client) accountEnabledColumn() string {
+	if c.config.AccountEnabledColumn != "" {
+		return c.config.AccountEnabledColumn
+	}
+	return DefaultAccountEnabledColumn
+}
+
+// accountEnabledSelection returns the selection reading the enabled column into the "enabled" field,
+// aliasing it when a different column is configured
+func (c *Client) accountEnabledSelection() string {
+	column := c.accountEnabledColumn()
+	if column == "enabled" {
+		return column
+	}
+	return "enabled: " + column
+}
+
+// ResolveAccountIDs maps (exchange name, account identifier) pairs to account IDs in a single query
+// Identifiers are normalized per exchange before matching, so e.g. lower-case EVM addresses resolve.
+// EVM addresses match case-insensitively (_ilike), so accounts stored before identifiers were
+// checksummed resolve too. Pairs with no matching account (or an invalid identifier) are absent from the map
+func (c *Client) ResolveAccountIDs(ctx context.Context, pairs []ExchangeIdentifier) (map[ExchangeIdentifier]string, error) {
+	resolved := make(map[ExchangeIdentifier]string)
+
+	// Several caller pairs may normalize to the same account
+	byKey := make(map[ExchangeIdentifier][]ExchangeIdentifier)
+	for _, pair := range pairs {
+		identifier, err := models.NormalizeAccountIdentifier(pair.Exchange, pair.Identifier)
+		if err != nil {
+			continue
+		}
+		key := ExchangeIdentifier{Exchange: pair.Exchange, Identifier: identifier}
+		byKey[key] = append(byKey[key], pair)
+	}
+	if len(byKey) == 0 {
+		return resolved, nil
+	}
+
+	keys := make([]ExchangeIdentifier, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Exchange != keys[j].Exchange {
+			return keys[i].Exchange < keys[j].Exchange
+		}
+		return keys[i].Identifier < keys[j].Identifier
+	})
+
+	declarations := make([]string, 0, 2*len(keys))
+	conditions := make([]string, 0, len(keys))
+	vars := make(map[string]interface{}, 2*len(keys))
+	for i, key := range keys {
+		exchangeVar, identifierVar := fmt.Sprintf("exchange_%d", i), fmt.Sprintf("identifier_%d", i)
+		declarations = append(declarations, fmt.Sprintf("$%s: String!, $%s: String!", exchangeVar, identifierVar))
+		// Normalized EVM addresses are hex digits only, so _ilike has no wildcards to match
+		op := "_eq"
+		if models.IdentifierIgnoresCase(key.Exchange) {
+			op = "_ilike"
+		}
+		conditions = append(conditions, fmt.Sprintf(
+			"{ exchange: { name: { _eq: $%s } }, account_identifier: { %s: $%s } }", exchangeVar, op, identifierVar))
+		vars[exchangeVar] = key.Exchange
+		vars[identifierVar] = key.Identifier
+	}
+
+	query := fmt.Sprintf(`
+		query ResolveAccountIDs(%s) {
+			exchange_accounts(where: { _or: [%s] }) {
+				id
+				account_identifier
+				exchange {
+					name
+				}
+			}
+		}
+	`, strings.Join(declarations, ", "), strings.Join(conditions, ", "))
+
+	req := c.graphqlRequestWithVars(query, vars)
+
+	var resp struct {
+		ExchangeAccounts []*ExchangeAccount `json:"exchange_accounts"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to resolve account IDs: %w", err)
+	}
+
+	for _, account := range resp.ExchangeAccounts {
+		if account.Exchange == nil {
+			continue
+		}
+		identifier := account.AccountIdentifier
+		if normalized, err := models.NormalizeAccountIdentifier(account.Exchange.Name, identifier); err == nil {
+			identifier = normalized
+		}
+		key := ExchangeIdentifier{Exchange: account.Exchange.Name, Identifier: identifier}
+		for _, pair := range byKey[key] {
+			resolved[pair] = account.ID
+		}
 	}
 
-	return resp.AccountTypes, nil
+	return resolved, nil
 }
@@ -3,6 +3,10 @@ package db
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/machinebox/graphql"
@@ -169,7 +173,7 @@ func TestClient_CreateAccount(t *testing.T) {
 	ctx := context.Background()
 	input := &models.ExchangeAccountInput{
 		ExchangeID:        "test-exchange-id",
-		AccountIdentifier: "0x123",
+		AccountIdentifier: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
 		AccountType:       "main",
 		AccountTypeMetadata: json.RawMessage(`{"address": "0x123"}`),
 	}
@@ -183,6 +187,10 @@ func TestClient_CreateAccount(t *testing.T) {
 
 	mockClient := &mockGraphQLClient{
 		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			if respondWithExchange(ctx, resp, "hyperliquid") {
+				return nil
+			}
+
 			respData := map[string]interface{}{
 				"insert_exchange_accounts_one": map[string]interface{}{
 					"id":                 expectedAccount.ID,
@@ -229,7 +237,7 @@ func TestClient_CreateAccount_WithoutMetadata(t *testing.T) {
 	ctx := context.Background()
 	input := &models.ExchangeAccountInput{
 		ExchangeID:        "test-exchange-id",
-		AccountIdentifier: "0x123",
+		AccountIdentifier: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
 		AccountType:       "main",
 		// No metadata
 	}
@@ -242,6 +250,10 @@ func TestClient_CreateAccount_WithoutMetadata(t *testing.T) {
 
 	mockClient := &mockGraphQLClient{
 		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			if respondWithExchange(ctx, resp, "hyperliquid") {
+				return nil
+			}
+
 			respData := map[string]interface{}{
 				"insert_exchange_accounts_one": map[string]interface{}{
 					"id":                 expectedAccount.ID,
@@ -293,6 +305,10 @@ func TestClient_UpdateAccount(t *testing.T) {
 
 	mockClient := &mockGraphQLClient{
 		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			if respondWithExchange(ctx, resp, "lighter") {
+				return nil
+			}
+
 			respData := map[string]interface{}{
 				"update_exchange_accounts_by_pk": map[string]interface{}{
 					"id":                 expectedAccount.ID,
@@ -340,6 +356,10 @@ func TestClient_UpdateAccount_NotFound(t *testing.T) {
 
 	mockClient := &mockGraphQLClient{
 		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			if respondWithExchange(ctx, resp, "lighter") {
+				return nil
+			}
+
 			respData := map[string]interface{}{
 				"update_exchange_accounts_by_pk": nil,
 			}
@@ -462,3 +482,695 @@ func TestClient_ListAccountTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestClient_IterateAccounts_MultiplePages(t *testing.T) {
+	ctx := context.Background()
+	allAccounts := []*models.ExchangeAccount{
+		{ID: "00000000-0000-0000-0000-000000000001", AccountIdentifier: "0x1"},
+		{ID: "00000000-0000-0000-0000-000000000002", AccountIdentifier: "0x2"},
+		{ID: "00000000-0000-0000-0000-000000000003", AccountIdentifier: "0x3"},
+		{ID: "00000000-0000-0000-0000-000000000004", AccountIdentifier: "0x4"},
+		{ID: "00000000-0000-0000-0000-000000000005", AccountIdentifier: "0x5"},
+	}
+
+	var cursors []interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			vars := requestFromContext(ctx).vars
+			cursors = append(cursors, vars["after"])
+
+			// Serve rows with id > after, up to limit
+			limit := vars["limit"].(int)
+			page := []*models.ExchangeAccount{}
+			for _, acc := range allAccounts {
+				if after, ok := vars["after"].(string); ok && acc.ID <= after {
+					continue
+				}
+				if len(page) == limit {
+					break
+				}
+				page = append(page, acc)
+			}
+
+			data, _ := json.Marshal(map[string]interface{}{"exchange_accounts": page})
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	var seen []string
+	var pageSizes []int
+	err := client.IterateAccounts(ctx, 2, func(accounts []*models.ExchangeAccount) error {
+		pageSizes = append(pageSizes, len(accounts))
+		for _, acc := range accounts {
+			seen = append(seen, acc.AccountIdentifier)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("IterateAccounts failed: %v", err)
+	}
+
+	if len(seen) != 5 {
+		t.Fatalf("Expected 5 accounts, got %d: %v", len(seen), seen)
+	}
+	if len(pageSizes) != 3 || pageSizes[0] != 2 || pageSizes[1] != 2 || pageSizes[2] != 1 {
+		t.Errorf("Expected page sizes [2 2 1], got %v", pageSizes)
+	}
+	if cursors[0] != nil {
+		t.Errorf("Expected first page without cursor, got %v", cursors[0])
+	}
+	if cursors[1] != allAccounts[1].ID || cursors[2] != allAccounts[3].ID {
+		t.Errorf("Expected cursors to advance by last id, got %v", cursors)
+	}
+}
+
+func TestClient_IterateAccounts_StopsOnError(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			calls++
+			page := []*models.ExchangeAccount{{ID: "a"}, {ID: "b"}}
+			data, _ := json.Marshal(map[string]interface{}{"exchange_accounts": page})
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	stop := errors.New("stop")
+	err := client.IterateAccounts(ctx, 2, func(accounts []*models.ExchangeAccount) error {
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("Expected callback error, got: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 query, got %d", calls)
+	}
+}
+
+func TestClient_IterateAccounts_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			t.Error("IterateAccounts should not query with a cancelled context")
+			return nil
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	err := client.IterateAccounts(ctx, 10, func([]*models.ExchangeAccount) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestClient_ListAccountsFiltered_NestedExchangeFilter(t *testing.T) {
+	ctx := context.Background()
+
+	var query string
+	var vars map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			vars = requestFromContext(ctx).vars
+			respData := map[string]interface{}{
+				"exchange_accounts": []*models.ExchangeAccount{
+					{
+						ID:                "account-1",
+						AccountIdentifier: "0xvault",
+						AccountType:       "vault",
+						Exchange:          &models.Exchange{ID: "exchange-1", Name: "hyperliquid", DisplayName: "Hyperliquid"},
+					},
+				},
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	accounts, err := client.ListAccountsFiltered(ctx, models.AccountFilter{
+		ExchangeNames: []string{"hyperliquid"},
+		AccountTypes:  []string{"vault"},
+		ActiveOnly:    true,
+	})
+	if err != nil {
+		t.Fatalf("ListAccountsFiltered failed: %v", err)
+	}
+
+	expectedWhere := "where: { exchange: { name: { _in: $exchange_names } }, account_type: { _in: $account_types }, enabled: { _eq: true } }"
+	if !strings.Contains(query, expectedWhere) {
+		t.Errorf("Expected where clause %q, got: %s", expectedWhere, query)
+	}
+	if names, ok := vars["exchange_names"].([]string); !ok || len(names) != 1 || names[0] != "hyperliquid" {
+		t.Errorf("Expected exchange_names [hyperliquid], got %v", vars["exchange_names"])
+	}
+	if _, ok := vars["user_ids"]; ok {
+		t.Error("Expected no user_ids variable when UserIDs is empty")
+	}
+
+	if len(accounts) != 1 || accounts[0].Exchange == nil || accounts[0].Exchange.Name != "hyperliquid" {
+		t.Errorf("Expected one account with nested exchange, got %+v", accounts)
+	}
+}
+
+func TestClient_ListAccountsFiltered_EmptyFilterMatchesListAccounts(t *testing.T) {
+	ctx := context.Background()
+
+	var queries []string
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			queries = append(queries, requestFromContext(ctx).query)
+			respData := map[string]interface{}{
+				"exchange_accounts": []*models.ExchangeAccount{{ID: "account-1"}, {ID: "account-2"}},
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	all, err := client.ListAccounts(ctx)
+	if err != nil {
+		t.Fatalf("ListAccounts failed: %v", err)
+	}
+	filtered, err := client.ListAccountsFiltered(ctx, models.AccountFilter{})
+	if err != nil {
+		t.Fatalf("ListAccountsFiltered failed: %v", err)
+	}
+
+	if strings.Contains(queries[1], "where") || strings.Contains(queries[1], "$") {
+		t.Errorf("Expected empty filter to produce an unfiltered query, got: %s", queries[1])
+	}
+	if len(filtered) != len(all) {
+		t.Errorf("Expected %d accounts, got %d", len(all), len(filtered))
+	}
+}
+
+// respondWithExchange answers the GetExchange lookup made before account mutations
+// Returns false for any other operation so the caller can handle it
+func respondWithExchange(ctx context.Context, resp interface{}, name string) bool {
+	if requestFromContext(ctx).opName != "GetExchange" {
+		return false
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"exchanges_by_pk": map[string]interface{}{"id": "test-exchange-id", "name": name, "display_name": name},
+	})
+	json.Unmarshal(data, resp)
+	return true
+}
+
+func TestClient_CreateAccount_InvalidIdentifier(t *testing.T) {
+	mutated := false
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			if respondWithExchange(ctx, resp, "hyperliquid") {
+				return nil
+			}
+			mutated = true
+			return nil
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	input := &models.ExchangeAccountInput{
+		ExchangeID:        "test-exchange-id",
+		AccountIdentifier: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", // Last letter's case breaks the checksum
+		AccountType:       "main",
+	}
+
+	_, err := client.CreateAccount(context.Background(), input)
+	var invalid *models.InvalidAccountError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Expected *models.InvalidAccountError, got %v", err)
+	}
+	if mutated {
+		t.Error("Expected no mutation for an invalid identifier")
+	}
+}
+
+func TestClient_CreateAccount_NormalizesIdentifier(t *testing.T) {
+	var sent interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			if respondWithExchange(ctx, resp, "hyperliquid") {
+				return nil
+			}
+			sent = requestFromContext(ctx).vars["account_identifier"]
+			data, _ := json.Marshal(map[string]interface{}{
+				"insert_exchange_accounts_one": map[string]interface{}{"id": "new-account-id"},
+			})
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	input := &models.ExchangeAccountInput{
+		ExchangeID:        "test-exchange-id",
+		AccountIdentifier: "0xdbf03b407c01e7cd3cbea99509d93f8dddc8c6fb",
+		AccountType:       "main",
+	}
+
+	if _, err := client.CreateAccount(context.Background(), input); err != nil {
+		t.Fatalf("CreateAccount failed: %v", err)
+	}
+	if sent != "0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB" {
+		t.Errorf("Expected checksummed identifier to be stored, got %v", sent)
+	}
+}
+
+func TestClient_GetAccount_PnLDenomination(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  *string
+	}{
+		{name: "null", value: nil, want: nil},
+		{name: "set", value: "USDT", want: func() *string { s := "USDT"; return &s }()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockGraphQLClient{
+				runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+					if !strings.Contains(requestFromContext(ctx).query, "pnl_denomination") {
+						t.Error("Expected pnl_denomination to be selected")
+					}
+					data, _ := json.Marshal(map[string]interface{}{
+						"exchange_accounts_by_pk": map[string]interface{}{"id": "account-id", "pnl_denomination": tt.value},
+					})
+					return json.Unmarshal(data, resp)
+				},
+			}
+
+			client := NewClientWithGraphQL(mockClient, ClientConfig{
+				URL:         "http://localhost:8080/v1/graphql",
+				AdminSecret: "test-secret",
+			})
+
+			account, err := client.GetAccount(context.Background(), "account-id")
+			if err != nil {
+				t.Fatalf("GetAccount failed: %v", err)
+			}
+			if !reflect.DeepEqual(account.PnLDenomination, tt.want) {
+				t.Errorf("Expected PnLDenomination %v, got %v", tt.want, account.PnLDenomination)
+			}
+		})
+	}
+}
+
+func TestClient_SetAccountPnLDenomination(t *testing.T) {
+	tests := []struct {
+		name      string
+		denom     string
+		wantValue interface{}
+		wantErr   bool
+	}{
+		{name: "normalized", denom: "usdt", wantValue: "USDT"},
+		{name: "cleared", denom: "", wantValue: nil},
+		{name: "invalid", denom: "EUR", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			mockClient := &mockGraphQLClient{
+				runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+					called = true
+					vars := requestFromContext(ctx).vars
+					if value, ok := vars["pnl_denomination"]; !ok || value != tt.wantValue {
+						t.Errorf("Expected pnl_denomination %v, got %v", tt.wantValue, value)
+					}
+					data, _ := json.Marshal(map[string]interface{}{
+						"update_exchange_accounts_by_pk": map[string]interface{}{"id": "account-id"},
+					})
+					return json.Unmarshal(data, resp)
+				},
+			}
+
+			client := NewClientWithGraphQL(mockClient, ClientConfig{
+				URL:         "http://localhost:8080/v1/graphql",
+				AdminSecret: "test-secret",
+			})
+
+			err := client.SetAccountPnLDenomination(context.Background(), "account-id", tt.denom)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error for invalid denomination")
+				}
+				if called {
+					t.Error("Expected no request for an invalid denomination")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetAccountPnLDenomination failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_CreateAccount_InvalidPnLDenomination(t *testing.T) {
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			if respondWithExchange(ctx, resp, "lighter") {
+				return nil
+			}
+			t.Error("Expected no mutation for an invalid denomination")
+			return nil
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	denom := "JPY"
+	input := &models.ExchangeAccountInput{ExchangeID: "test-exchange-id", AccountIdentifier: "1", AccountType: "main", PnLDenomination: &denom}
+	if _, err := client.CreateAccount(context.Background(), input); err == nil {
+		t.Fatal("Expected error for invalid denomination")
+	}
+}
+
+func TestClient_ResolveAccountIDs(t *testing.T) {
+	ctx := context.Background()
+
+	// stored emulates exchange_accounts rows keyed by (exchange name, normalized identifier)
+	stored := map[ExchangeIdentifier]string{
+		{Exchange: "hyperliquid", Identifier: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"}: "account-1",
+		{Exchange: "lighter", Identifier: "42"}:                                             "account-2",
+	}
+
+	var query string
+	calls := 0
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			calls++
+			query = requestFromContext(ctx).query
+			vars := requestFromContext(ctx).vars
+
+			rows := []map[string]interface{}{}
+			for i := 0; ; i++ {
+				exchange, ok := vars[fmt.Sprintf("exchange_%d", i)].(string)
+				if !ok {
+					break
+				}
+				identifier := vars[fmt.Sprintf("identifier_%d", i)].(string)
+				if id, ok := stored[ExchangeIdentifier{Exchange: exchange, Identifier: identifier}]; ok {
+					rows = append(rows, map[string]interface{}{
+						"id":                 id,
+						"account_identifier": identifier,
+						"exchange":           map[string]interface{}{"name": exchange},
+					})
+				}
+			}
+			data, _ := json.Marshal(map[string]interface{}{"exchange_accounts": rows})
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	lowercase := ExchangeIdentifier{Exchange: "hyperliquid", Identifier: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"}
+	checksummed := ExchangeIdentifier{Exchange: "hyperliquid", Identifier: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"}
+	lighter := ExchangeIdentifier{Exchange: "lighter", Identifier: " 42 "}
+	unknown := ExchangeIdentifier{Exchange: "hyperliquid", Identifier: "0x1234567890123456789012345678901234567890"}
+	wrongExchange := ExchangeIdentifier{Exchange: "drift", Identifier: "42"}
+	invalid := ExchangeIdentifier{Exchange: "hyperliquid", Identifier: "not-an-address"}
+
+	resolved, err := client.ResolveAccountIDs(ctx, []ExchangeIdentifier{lowercase, checksummed, lighter, unknown, wrongExchange, invalid})
+	if err != nil {
+		t.Fatalf("ResolveAccountIDs failed: %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected a single query, got %d", calls)
+	}
+	if !strings.Contains(query, "_or: [") {
+		t.Errorf("Expected an _or where clause, got: %s", query)
+	}
+
+	want := map[ExchangeIdentifier]string{
+		lowercase:   "account-1",
+		checksummed: "account-1",
+		lighter:     "account-2",
+	}
+	if len(resolved) != len(want) {
+		t.Errorf("Expected %d resolved pairs, got %d: %v", len(want), len(resolved), resolved)
+	}
+	for pair, id := range want {
+		if resolved[pair] != id {
+			t.Errorf("Expected %+v to resolve to %s, got %q", pair, id, resolved[pair])
+		}
+	}
+}
+
+func TestClient_ResolveAccountIDs_MatchesLowercaseRows(t *testing.T) {
+	// A row stored before identifiers were checksummed
+	var query string
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			return json.Unmarshal([]byte(`{"exchange_accounts": [
+				{"id": "account-1", "account_identifier": "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "exchange": {"name": "hyperliquid"}}
+			]}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	pair := ExchangeIdentifier{Exchange: "hyperliquid", Identifier: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"}
+	other := ExchangeIdentifier{Exchange: "lighter", Identifier: "42"}
+	resolved, err := client.ResolveAccountIDs(context.Background(), []ExchangeIdentifier{pair, other})
+	if err != nil {
+		t.Fatalf("ResolveAccountIDs failed: %v", err)
+	}
+	if resolved[pair] != "account-1" {
+		t.Errorf("Expected the lowercase row to resolve, got %v", resolved)
+	}
+	if !strings.Contains(query, "account_identifier: { _ilike: $identifier_0 }") {
+		t.Errorf("Expected EVM addresses to match case-insensitively, got: %s", query)
+	}
+	if !strings.Contains(query, "account_identifier: { _eq: $identifier_1 }") {
+		t.Errorf("Expected other identifiers to match exactly, got: %s", query)
+	}
+}
+
+func TestClient_ResolveAccountIDs_Empty(t *testing.T) {
+	client := NewClientWithGraphQL(&mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			t.Fatal("Expected no query for empty input")
+			return nil
+		},
+	}, ClientConfig{})
+
+	resolved, err := client.ResolveAccountIDs(context.Background(), nil)
+	if err != nil || len(resolved) != 0 {
+		t.Fatalf("Expected empty result, got %v, %v", resolved, err)
+	}
+}
+
+func TestClient_SetAccountEnabled_Toggle(t *testing.T) {
+	ctx := context.Background()
+
+	// A tiny in-memory table: the mutation flips the flag, the filtered query returns enabled rows
+	enabled := map[string]bool{"account-1": true, "account-2": true}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			r := requestFromContext(ctx)
+			var respData map[string]interface{}
+			switch r.opName {
+			case "SetAccountEnabled":
+				if !strings.Contains(r.query, "_set: {enabled: $enabled}") {
+					t.Errorf("Expected the enabled column to be set, got: %s", r.query)
+				}
+				id := r.vars["id"].(string)
+				if _, ok := enabled[id]; !ok {
+					respData = map[string]interface{}{"update_exchange_accounts_by_pk": nil}
+					break
+				}
+				enabled[id] = r.vars["enabled"].(bool)
+				respData = map[string]interface{}{"update_exchange_accounts_by_pk": map[string]interface{}{"id": id}}
+			case "ListAccountsFiltered":
+				if !strings.Contains(r.query, "enabled: { _eq: true }") {
+					t.Errorf("Expected an enabled filter, got: %s", r.query)
+				}
+				var rows []map[string]interface{}
+				for _, id := range []string{"account-1", "account-2"} {
+					if enabled[id] {
+						rows = append(rows, map[string]interface{}{"id": id, "enabled": true})
+					}
+				}
+				respData = map[string]interface{}{"exchange_accounts": rows}
+			default:
+				t.Fatalf("Unexpected operation %s", r.opName)
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	activeIDs := func() []string {
+		accounts, err := client.ListAccountsFiltered(ctx, AccountFilter{ActiveOnly: true})
+		if err != nil {
+			t.Fatalf("ListAccountsFiltered failed: %v", err)
+		}
+		ids := make([]string, len(accounts))
+		for i, account := range accounts {
+			if !account.Enabled {
+				t.Errorf("Expected account %s to decode as enabled", account.ID)
+			}
+			ids[i] = account.ID
+		}
+		return ids
+	}
+
+	if err := client.SetAccountEnabled(ctx, "account-1", false); err != nil {
+		t.Fatalf("SetAccountEnabled(false) failed: %v", err)
+	}
+	if ids := activeIDs(); len(ids) != 1 || ids[0] != "account-2" {
+		t.Errorf("Expected only account-2 after disabling account-1, got %v", ids)
+	}
+
+	if err := client.SetAccountEnabled(ctx, "account-1", true); err != nil {
+		t.Fatalf("SetAccountEnabled(true) failed: %v", err)
+	}
+	if ids := activeIDs(); len(ids) != 2 {
+		t.Errorf("Expected both accounts after re-enabling account-1, got %v", ids)
+	}
+
+	err := client.SetAccountEnabled(ctx, "missing", false)
+	if err == nil || !strings.Contains(err.Error(), "account not found") {
+		t.Errorf("Expected account not found for an unknown id, got %v", err)
+	}
+}
+
+func TestClient_AccountEnabledColumn_Configurable(t *testing.T) {
+	ctx := context.Background()
+
+	var queries []string
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			queries = append(queries, requestFromContext(ctx).query)
+			data, _ := json.Marshal(map[string]interface{}{
+				"exchange_accounts":              []map[string]interface{}{{"id": "account-1", "enabled": true}},
+				"update_exchange_accounts_by_pk": map[string]interface{}{"id": "account-1"},
+			})
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:                  "http://localhost:8080/v1/graphql",
+		AdminSecret:          "test-secret",
+		AccountEnabledColumn: "sync_enabled",
+	})
+
+	accounts, err := client.ListAccountsFiltered(ctx, AccountFilter{ActiveOnly: true})
+	if err != nil {
+		t.Fatalf("ListAccountsFiltered failed: %v", err)
+	}
+	if len(accounts) != 1 || !accounts[0].Enabled {
+		t.Errorf("Expected one enabled account, got %+v", accounts)
+	}
+	if err := client.SetAccountEnabled(ctx, "account-1", false); err != nil {
+		t.Fatalf("SetAccountEnabled failed: %v", err)
+	}
+
+	if !strings.Contains(queries[0], "where: { sync_enabled: { _eq: true } }") {
+		t.Errorf("Expected the filter on the configured column, got: %s", queries[0])
+	}
+	if !strings.Contains(queries[0], "enabled: sync_enabled") {
+		t.Errorf("Expected the configured column aliased to enabled, got: %s", queries[0])
+	}
+	if !strings.Contains(queries[1], "_set: {sync_enabled: $enabled}") {
+		t.Errorf("Expected the configured column to be set, got: %s", queries[1])
+	}
+}
+
+func TestClient_UpdateAccount_LeavesUnsetPnLDenomination(t *testing.T) {
+	usdt := "usdt"
+	tests := []struct {
+		name    string
+		denom   *string
+		wantSet bool
+	}{
+		{name: "unset", denom: nil, wantSet: false},
+		{name: "set", denom: &usdt, wantSet: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockGraphQLClient{
+				runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+					if respondWithExchange(ctx, resp, "lighter") {
+						return nil
+					}
+					inflight := requestFromContext(ctx)
+					if got := strings.Contains(inflight.query, "pnl_denomination: $pnl_denomination"); got != tt.wantSet {
+						t.Errorf("Expected pnl_denomination in _set = %v, query:\n%s", tt.wantSet, inflight.query)
+					}
+					if value, ok := inflight.vars["pnl_denomination"]; ok != tt.wantSet || (ok && value != "USDT") {
+						t.Errorf("Expected pnl_denomination variable = %v, got %v", tt.wantSet, value)
+					}
+					data, _ := json.Marshal(map[string]interface{}{
+						"update_exchange_accounts_by_pk": map[string]interface{}{"id": "account-id"},
+					})
+					return json.Unmarshal(data, resp)
+				},
+			}
+
+			client := NewClientWithGraphQL(mockClient, ClientConfig{})
+			_, err := client.UpdateAccount(context.Background(), "account-id", &models.ExchangeAccountInput{
+				ExchangeID:        "exchange-id",
+				AccountIdentifier: "0x456",
+				AccountType:       "main",
+				PnLDenomination:   tt.denom,
+			})
+			if err != nil {
+				t.Fatalf("UpdateAccount failed: %v", err)
+			}
+		})
+	}
+}
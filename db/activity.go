@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/models"
+)
+
+// Activity is an account's trades and funding payments for a window (aliased from models package)
+type Activity = models.Activity
+
+// GetActivity retrieves an account's trades and funding payments with timestamps in [since, until)
+// Both are read by one GraphQL document, so they come from a single consistent snapshot
+// Rows are ordered oldest first
+func (c *Client) GetActivity(ctx context.Context, accountID uuid.UUID, since, until time.Time) (*Activity, error) {
+	if !until.After(since) {
+		return nil, fmt.Errorf("failed to get activity: until (%s) must be after since (%s)", until, since)
+	}
+
+	b := newWhereBuilder()
+	b.add("exchange_account_id", "_eq", "exchange_account_id", "uuid!", uuidVar(accountID))
+	b.add("timestamp", "_gte", "since", "bigint!", since.UnixMilli())
+	b.add("timestamp", "_lt", "until", "bigint!", until.UnixMilli())
+
+	query := fmt.Sprintf(`
+		query GetActivity%[1]s {
+			trades(
+				%[2]s
+				order_by: [{ timestamp: asc }, { id: asc }]
+			) {
+				id
+				base_asset
+				quote_asset
+				side
+				price
+				quantity
+				timestamp
+				fee
+				order_id
+				trade_id
+				exchange_account_id
+				created_at
+				source
+				fee_asset
+				market_type
+				is_taker
+			}
+			funding_payments(
+				%[2]s
+				order_by: [{ timestamp: asc }, { id: asc }]
+			) {%[3]s}
+		}
+	`, b.declarations(), b.whereArg(), fundingPaymentFields)
+
+	req := c.graphqlRequestWithVars(query, b.variables())
+
+	var resp struct {
+		Trades          []*Trade          `json:"trades"`
+		FundingPayments []*FundingPayment `json:"funding_payments"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get activity: %w", err)
+	}
+
+	activity := &Activity{
+		ExchangeAccountID: accountID,
+		Since:             since,
+		Until:             until,
+		Trades:            resp.Trades,
+		FundingPayments:   resp.FundingPayments,
+	}
+	if activity.Trades == nil {
+		activity.Trades = []*Trade{}
+	}
+	if activity.FundingPayments == nil {
+		activity.FundingPayments = []*FundingPayment{}
+	}
+	return activity, nil
+}
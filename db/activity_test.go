@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+)
+
+func TestClient_GetActivity(t *testing.T) {
+	accountID := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	since := time.UnixMilli(1700000000000)
+	until := since.Add(24 * time.Hour)
+
+	requests := 0
+	mock := &rawMockGraphQLClient{
+		runRawFunc: func(ctx context.Context, req *graphql.Request) ([]byte, error) {
+			requests++
+			r := requestFromContext(ctx)
+			if !strings.Contains(r.query, "trades(") || !strings.Contains(r.query, "funding_payments(") {
+				t.Errorf("Expected trades and funding payments in one document, got %s", r.query)
+			}
+			if r.vars["since"] != since.UnixMilli() || r.vars["until"] != until.UnixMilli() || r.vars["exchange_account_id"] != accountID.String() {
+				t.Errorf("Unexpected variables: %+v", r.vars)
+			}
+			return []byte(`{"data":{
+				"trades":[
+					{"id":"33333333-3333-3333-3333-333333333333","exchange_account_id":"22222222-2222-2222-2222-222222222222",
+					 "base_asset":"BTC","quote_asset":"USDC","side":"buy","price":"50000","quantity":"0.2","fee":"0.5",
+					 "order_id":"o1","trade_id":"t1","timestamp":1700000000000,"created_at":"2023-11-14T22:13:20Z",
+					 "source":"exchange_sync","is_taker":true}
+				],
+				"funding_payments":[
+					{"id":"44444444-4444-4444-4444-444444444444","exchange_account_id":"22222222-2222-2222-2222-222222222222",
+					 "base_asset":"BTC","quote_asset":"USDC","amount":"-1.25","timestamp":1700003600000,"payment_id":"p1",
+					 "created_at":"2023-11-14T23:13:20Z","source":"exchange_sync"},
+					{"id":"55555555-5555-5555-5555-555555555555","exchange_account_id":"22222222-2222-2222-2222-222222222222",
+					 "base_asset":"BTC","quote_asset":"USDC","amount":"0.5","timestamp":1700007200000,"payment_id":"p2",
+					 "created_at":"2023-11-15T00:13:20Z","source":"exchange_sync"}
+				]
+			}}`), nil
+		},
+	}
+	client := NewClientWithGraphQL(mock, ClientConfig{StrictDecoding: true})
+
+	activity, err := client.GetActivity(context.Background(), accountID, since, until)
+	if err != nil {
+		t.Fatalf("GetActivity failed: %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected a single request, got %d", requests)
+	}
+	if len(activity.Trades) != 1 || activity.Trades[0].TradeID != "t1" || activity.Trades[0].Price != "50000" {
+		t.Errorf("Unexpected trades: %+v", activity.Trades)
+	}
+	if len(activity.FundingPayments) != 2 || activity.FundingPayments[0].Amount != "-1.25" || activity.FundingPayments[1].PaymentID != "p2" {
+		t.Errorf("Unexpected funding payments: %+v", activity.FundingPayments)
+	}
+	if activity.ExchangeAccountID != accountID || !activity.Since.Equal(since) || !activity.Until.Equal(until) {
+		t.Errorf("Unexpected window: %+v", activity)
+	}
+}
+
+func TestClient_GetActivity_EmptyAndInvalidWindow(t *testing.T) {
+	requests := 0
+	mock := &rawMockGraphQLClient{
+		runRawFunc: func(ctx context.Context, req *graphql.Request) ([]byte, error) {
+			requests++
+			return []byte(`{"data":{"trades":[],"funding_payments":[]}}`), nil
+		},
+	}
+	client := NewClientWithGraphQL(mock, ClientConfig{})
+	since := time.UnixMilli(1700000000000)
+
+	activity, err := client.GetActivity(context.Background(), uuid.New(), since, since.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetActivity failed: %v", err)
+	}
+	if activity.Trades == nil || activity.FundingPayments == nil || len(activity.Trades)+len(activity.FundingPayments) != 0 {
+		t.Errorf("Expected empty, non-nil slices, got %+v", activity)
+	}
+
+	if _, err := client.GetActivity(context.Background(), uuid.New(), since, since); err == nil {
+		t.Error("Expected an empty window to be rejected")
+	}
+	if requests != 1 {
+		t.Errorf("Expected the invalid window not to be sent, got %d requests", requests)
+	}
+}
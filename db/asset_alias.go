@@ -0,0 +1,258 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/assets"
+	"github.com/zif-terminal/lib/models"
+)
+
+func init() {
+	registerOperations(map[string]Idempotency{
+		"CreateAssetAlias":  NotIdempotent, // Plain insert
+		"DeleteAssetAlias":  Idempotent,    // Delete by primary key
+		"RewriteTradeAsset": Idempotent,    // Rewritten trades no longer match the old symbol
+	})
+}
+
+// AssetAlias represents an asset rename (aliased from models package)
+type AssetAlias = models.AssetAlias
+
+// AssetAliasInput represents asset alias input for mutations (aliased from models package)
+type AssetAliasInput = models.AssetAliasInput
+
+// CreateAssetAlias records that an exchange renamed an asset
+func (c *Client) CreateAssetAlias(ctx context.Context, input *AssetAliasInput) (*AssetAlias, error) {
+	if err := input.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to create asset alias: %w", err)
+	}
+
+	query := `
+		mutation CreateAssetAlias($object: asset_aliases_insert_input!) {
+			insert_asset_aliases_one(object: $object) {
+				id
+				exchange
+				asset
+				alias
+				effective_from
+				created_at
+			}
+		}
+	`
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"object": map[string]interface{}{
+			"exchange":       input.Exchange,
+			"asset":          input.Asset,
+			"alias":          input.Alias,
+			"effective_from": input.EffectiveFrom.UnixMilli(),
+		},
+	})
+
+	var resp struct {
+		InsertAssetAliasesOne *AssetAlias `json:"insert_asset_aliases_one"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to create asset alias: %w", err)
+	}
+
+	return resp.InsertAssetAliasesOne, nil
+}
+
+// ListAssetAliases retrieves the asset aliases of an exchange, or of every exchange when exchange
+// is empty, ordered by exchange, asset and effective time
+func (c *Client) ListAssetAliases(ctx context.Context, exchange string) ([]*AssetAlias, error) {
+	b := newWhereBuilder()
+	if exchange != "" {
+		b.add("exchange", "_eq", "exchange", "String!", exchange)
+	}
+
+	query := fmt.Sprintf(`
+		query ListAssetAliases%s {
+			asset_aliases(
+				%s
+				order_by: [{ exchange: asc }, { asset: asc }, { effective_from: asc }]
+			) {
+				id
+				exchange
+				asset
+				alias
+				effective_from
+				created_at
+			}
+		}
+	`, b.declarations(), b.whereArg())
+
+	req := c.graphqlRequestWithVars(query, b.variables())
+
+	var resp struct {
+		AssetAliases []*AssetAlias `json:"asset_aliases"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list asset aliases: %w", err)
+	}
+
+	return resp.AssetAliases, nil
+}
+
+// DeleteAssetAlias deletes an asset alias by ID
+func (c *Client) DeleteAssetAlias(ctx context.Context, id uuid.UUID) error {
+	query := `
+		mutation DeleteAssetAlias($id: uuid!) {
+			delete_asset_aliases_by_pk(id: $id) {
+				id
+			}
+		}
+	`
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"id": uuidVar(id),
+	})
+
+	var resp struct {
+		DeleteAssetAliasesByPk *struct {
+			ID string `json:"id"`
+		} `json:"delete_asset_aliases_by_pk"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return fmt.Errorf("failed to delete asset alias: %w", err)
+	}
+
+	if resp.DeleteAssetAliasesByPk == nil {
+		return fmt.Errorf("asset alias not found: %s", id)
+	}
+
+	return nil
+}
+
+// RewriteTradeAsset changes the base asset of an account's trades from one symbol to another,
+// for one-off corrections after a rename. Returns the number of trades rewritten, or with dryRun
+// the number that would be, without changing anything
+func (c *Client) RewriteTradeAsset(ctx context.Context, accountID uuid.UUID, from, to string, dryRun bool) (int, error) {
+	if from == "" || to == "" || from == to {
+		return 0, fmt.Errorf("failed to rewrite trade asset: need two different symbols, got %q and %q", from, to)
+	}
+
+	b := newWhereBuilder()
+	b.add("exchange_account_id", "_eq", "exchange_account_id", "uuid!", uuidVar(accountID))
+	b.add("base_asset", "_eq", "from", "String!", from)
+
+	if dryRun {
+		query := fmt.Sprintf(`
+			query RewriteTradeAssetDryRun%s {%s
+			}
+		`, b.declarations(), aggregateSelection("trades_aggregate", b))
+
+		req := c.graphqlRequestWithVars(query, b.variables())
+
+		var resp struct {
+			TradesAggregate aggregateCount `json:"trades_aggregate"`
+		}
+
+		if err := c.execute(ctx, req, &resp); err != nil {
+			return 0, fmt.Errorf("failed to count trades to rewrite: %w", err)
+		}
+
+		if resp.TradesAggregate.Aggregate == nil {
+			return 0, nil
+		}
+		return resp.TradesAggregate.Aggregate.Count, nil
+	}
+
+	b.declare("to", "String!", to)
+	query := fmt.Sprintf(`
+		mutation RewriteTradeAsset%s {
+			update_trades(%s, _set: { base_asset: $to }) {
+				affected_rows
+			}
+		}
+	`, b.declarations(), b.whereArg())
+
+	req := c.graphqlRequestWithVars(query, b.variables())
+
+	var resp struct {
+		UpdateTrades struct {
+			AffectedRows int `json:"affected_rows"`
+		} `json:"update_trades"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return 0, fmt.Errorf("failed to rewrite trade asset: %w", err)
+	}
+
+	return resp.UpdateTrades.AffectedRows, nil
+}
+
+// SetAssetResolver replaces the resolver set by ClientConfig.AssetResolver, e.g. with a fresh
+// assets.Load after CreateAssetAlias, since a Resolver is a snapshot of the aliases it was built
+// from. Safe to call while other calls are in flight; nil stops grouping renamed symbols
+func (c *Client) SetAssetResolver(resolver *assets.Resolver) {
+	c.resolver.Store(resolver)
+}
+
+// accountResolver returns the current asset resolver and the name of the account's exchange
+// Without a resolver it makes no lookups and returns nil
+func (c *Client) accountResolver(ctx context.Context, accountID uuid.UUID) (*assets.Resolver, string, error) {
+	resolver := c.resolver.Load()
+	if resolver == nil {
+		return nil, "", nil
+	}
+	exchange, err := c.accountExchangeName(ctx, accountID)
+	if err != nil {
+		return nil, "", err
+	}
+	return resolver, exchange, nil
+}
+
+// baseSymbols returns every symbol pair.Base is stored under on exchange (pair.Base alone
+// without a resolver), or nil without a pair
+func baseSymbols(resolver *assets.Resolver, exchange string, pair *AssetPair) []string {
+	if pair == nil {
+		return nil
+	}
+	if resolver == nil {
+		return []string{pair.Base}
+	}
+	return resolver.Symbols(exchange, pair.Base)
+}
+
+// accountExchangeName returns the name of the exchange an account belongs to
+// Goes through GetAccount, so WithAccountCache saves the lookup on repeated calls
+func (c *Client) accountExchangeName(ctx context.Context, accountID uuid.UUID) (string, error) {
+	account, err := c.GetAccount(ctx, accountID.String())
+	if err != nil {
+		return "", err
+	}
+	if account.Exchange == nil || account.Exchange.Name == "" {
+		return "", fmt.Errorf("account %s has no exchange", accountID)
+	}
+	return account.Exchange.Name, nil
+}
+
+// canonicalPairs renames base assets to their current symbol and merges the pairs that become
+// equal, keeping pairs ordered by base then quote asset
+func canonicalPairs(resolver *assets.Resolver, exchange string, pairs [][2]string) [][2]string {
+	seen := make(map[[2]string]bool, len(pairs))
+	merged := make([][2]string, 0, len(pairs))
+	for _, pair := range pairs {
+		pair[0] = resolver.Canonical(exchange, pair[0])
+		if seen[pair] {
+			continue
+		}
+		seen[pair] = true
+		merged = append(merged, pair)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i][0] != merged[j][0] {
+			return merged[i][0] < merged[j][0]
+		}
+		return merged[i][1] < merged[j][1]
+	})
+	return merged
+}
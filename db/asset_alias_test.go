@@ -0,0 +1,263 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/assets"
+	"github.com/zif-terminal/lib/models"
+)
+
+// polResolver knows Hyperliquid's MATIC to POL rename
+var polResolver = assets.NewResolver([]*models.AssetAlias{
+	{Exchange: "hyperliquid", Asset: "POL", Alias: "MATIC", EffectiveFrom: time.UnixMilli(1725408000000)},
+})
+
+// respondWithAccount answers GetAccount with an account on the named exchange, reporting whether
+// the request was a GetAccount
+func respondWithAccount(ctx context.Context, resp interface{}, exchange string) bool {
+	r := requestFromContext(ctx)
+	if r.opName != "GetAccount" {
+		return false
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"exchange_accounts_by_pk": map[string]interface{}{
+			"id": r.vars["id"], "account_identifier": "0xabc", "account_type": "main",
+			"exchange": map[string]interface{}{"id": "test-exchange-id", "name": exchange, "display_name": exchange},
+		},
+	})
+	json.Unmarshal(data, resp)
+	return true
+}
+
+func TestClient_AliasAwarePositionBuilding(t *testing.T) {
+	var requests []*request
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			if respondWithAccount(ctx, resp, "hyperliquid") {
+				return nil
+			}
+			r := requestFromContext(ctx)
+			requests = append(requests, r)
+			var body string
+			switch r.opName {
+			case "GetTradedPairs":
+				body = `{"trades": [
+					{"base_asset": "BTC", "quote_asset": "USDC"},
+					{"base_asset": "MATIC", "quote_asset": "USDC"},
+					{"base_asset": "POL", "quote_asset": "USDC"}
+				]}`
+			case "FindUnallocatedTrades":
+				body = `{"trades": [
+					{"id": "` + uuid.NewString() + `", "base_asset": "MATIC", "quote_asset": "USDC", "side": "buy", "price": "0.5", "quantity": "100", "fee": "0", "trade_id": "t1", "timestamp": 1725000000000},
+					{"id": "` + uuid.NewString() + `", "base_asset": "POL", "quote_asset": "USDC", "side": "sell", "price": "0.4", "quantity": "100", "fee": "0", "trade_id": "t2", "timestamp": 1726000000000}
+				]}`
+			default:
+				body = `{"trades_aggregate": {"aggregate": {"count": 2}}}`
+			}
+			return json.Unmarshal([]byte(body), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{AssetResolver: polResolver})
+	ctx := WithAccountCache(context.Background())
+	accountID := uuid.New()
+
+	pairs, err := client.GetTradedPairs(ctx, accountID)
+	if err != nil {
+		t.Fatalf("GetTradedPairs failed: %v", err)
+	}
+	if want := [][2]string{{"BTC", "USDC"}, {"POL", "USDC"}}; !reflect.DeepEqual(pairs, want) {
+		t.Errorf("Expected renamed pairs merged into %v, got %v", want, pairs)
+	}
+
+	trades, err := client.FindUnallocatedTrades(ctx, accountID, &AssetPair{Base: "POL", Quote: "USDC"}, TimeRange{})
+	if err != nil {
+		t.Fatalf("FindUnallocatedTrades failed: %v", err)
+	}
+	if len(trades) != 2 {
+		t.Errorf("Expected trades under both symbols to form one position, got %d", len(trades))
+	}
+	for _, trade := range trades {
+		if trade.BaseAsset != "POL" {
+			t.Errorf("Trade %s: expected the current symbol POL, got %s", trade.TradeID, trade.BaseAsset)
+		}
+	}
+	count, err := client.CountUnallocatedTrades(ctx, accountID, &AssetPair{Base: "MATIC", Quote: "USDC"}, TimeRange{})
+	if err != nil || count != 2 {
+		t.Errorf("Expected 2 unallocated trades, got %d (%v)", count, err)
+	}
+
+	for _, r := range requests[1:] {
+		if !strings.Contains(r.query, "base_asset: { _in: $base_assets }") {
+			t.Errorf("%s: expected the base asset to match every symbol, got %s", r.opName, r.query)
+		}
+		if got := r.vars["base_assets"]; !reflect.DeepEqual(got, []string{"POL", "MATIC"}) {
+			t.Errorf("%s: expected base_assets [POL MATIC], got %v", r.opName, got)
+		}
+	}
+}
+
+func TestClient_UnallocatedTrades_WithoutResolver(t *testing.T) {
+	var query string
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			if requestFromContext(ctx).opName == "GetAccount" {
+				t.Error("Expected no account lookup without a resolver")
+			}
+			query = requestFromContext(ctx).query
+			return json.Unmarshal([]byte(`{"trades_aggregate": {"aggregate": {"count": 0}}}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	if _, err := client.CountUnallocatedTrades(context.Background(), uuid.New(), &AssetPair{Base: "POL", Quote: "USDC"}, TimeRange{}); err != nil {
+		t.Fatalf("CountUnallocatedTrades failed: %v", err)
+	}
+	if !strings.Contains(query, "base_asset: { _eq: $base_asset }") {
+		t.Errorf("Expected a single base asset, got %s", query)
+	}
+}
+
+func TestClient_SetAssetResolver(t *testing.T) {
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			if respondWithAccount(ctx, resp, "hyperliquid") {
+				return nil
+			}
+			return json.Unmarshal([]byte(`{"trades": [
+				{"id": "`+uuid.NewString()+`", "base_asset": "MATIC", "quote_asset": "USDC", "side": "buy", "price": "0.5", "quantity": "100", "fee": "0", "trade_id": "t1", "timestamp": 1725000000000}
+			]}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+	ctx := context.Background()
+	accountID := uuid.New()
+
+	trades, err := client.FindUnallocatedTrades(ctx, accountID, nil, TimeRange{})
+	if err != nil {
+		t.Fatalf("FindUnallocatedTrades failed: %v", err)
+	}
+	if trades[0].BaseAsset != "MATIC" {
+		t.Errorf("Expected the stored symbol without a resolver, got %s", trades[0].BaseAsset)
+	}
+
+	// An alias created after the client was built applies once a new resolver is installed
+	client.SetAssetResolver(polResolver)
+	trades, err = client.FindUnallocatedTrades(ctx, accountID, nil, TimeRange{})
+	if err != nil {
+		t.Fatalf("FindUnallocatedTrades failed: %v", err)
+	}
+	if trades[0].BaseAsset != "POL" {
+		t.Errorf("Expected the reloaded resolver to rename MATIC to POL, got %s", trades[0].BaseAsset)
+	}
+}
+
+func TestClient_RewriteTradeAsset(t *testing.T) {
+	var requests []*request
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			r := requestFromContext(ctx)
+			requests = append(requests, r)
+			if r.opName == "RewriteTradeAssetDryRun" {
+				return json.Unmarshal([]byte(`{"trades_aggregate": {"aggregate": {"count": 42}}}`), resp)
+			}
+			return json.Unmarshal([]byte(`{"update_trades": {"affected_rows": 42}}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+	ctx := context.Background()
+	accountID := uuid.New()
+
+	count, err := client.RewriteTradeAsset(ctx, accountID, "MATIC", "POL", true)
+	if err != nil {
+		t.Fatalf("RewriteTradeAsset dry run failed: %v", err)
+	}
+	if count != 42 {
+		t.Errorf("Expected 42 trades to rewrite, got %d", count)
+	}
+	dryRun := requests[0]
+	if isMutation(dryRun.query) || strings.Contains(dryRun.query, "update_trades") {
+		t.Errorf("Expected the dry run to only read, got %s", dryRun.query)
+	}
+	if dryRun.vars["from"] != "MATIC" || dryRun.vars["exchange_account_id"] != accountID.String() {
+		t.Errorf("Unexpected dry run variables: %+v", dryRun.vars)
+	}
+
+	count, err = client.RewriteTradeAsset(ctx, accountID, "MATIC", "POL", false)
+	if err != nil {
+		t.Fatalf("RewriteTradeAsset failed: %v", err)
+	}
+	if count != 42 {
+		t.Errorf("Expected 42 trades rewritten, got %d", count)
+	}
+	rewrite := requests[1]
+	if !strings.Contains(rewrite.query, "_set: { base_asset: $to }") || rewrite.vars["to"] != "POL" || rewrite.vars["from"] != "MATIC" {
+		t.Errorf("Unexpected rewrite: %s %+v", rewrite.query, rewrite.vars)
+	}
+
+	if _, err := client.RewriteTradeAsset(ctx, accountID, "POL", "POL", false); err == nil {
+		t.Error("Expected rewriting a symbol to itself to fail")
+	}
+	if len(requests) != 2 {
+		t.Errorf("Expected the invalid rewrite not to be sent, got %d requests", len(requests))
+	}
+}
+
+func TestClient_AssetAliasCRUD(t *testing.T) {
+	effective := time.UnixMilli(1725408000000)
+	id := uuid.New()
+	var requests []*request
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			r := requestFromContext(ctx)
+			requests = append(requests, r)
+			row := `{"id": "` + id.String() + `", "exchange": "hyperliquid", "asset": "POL", "alias": "MATIC",
+				"effective_from": 1725408000000, "created_at": "2024-09-04T00:00:01Z"}`
+			switch r.opName {
+			case "CreateAssetAlias":
+				return json.Unmarshal([]byte(`{"insert_asset_aliases_one": `+row+`}`), resp)
+			case "ListAssetAliases":
+				return json.Unmarshal([]byte(`{"asset_aliases": [`+row+`]}`), resp)
+			}
+			return json.Unmarshal([]byte(`{"delete_asset_aliases_by_pk": null}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+	ctx := context.Background()
+
+	alias, err := client.CreateAssetAlias(ctx, &AssetAliasInput{Exchange: "hyperliquid", Asset: "POL", Alias: "MATIC", EffectiveFrom: effective})
+	if err != nil {
+		t.Fatalf("CreateAssetAlias failed: %v", err)
+	}
+	if alias.ID != id || !alias.EffectiveFrom.Equal(effective) || alias.CreatedAt.IsZero() {
+		t.Errorf("Unexpected decoded alias: %+v", alias)
+	}
+	if object := requests[0].vars["object"].(map[string]interface{}); object["effective_from"] != effective.UnixMilli() {
+		t.Errorf("Expected effective_from in milliseconds, got %v", object["effective_from"])
+	}
+
+	if _, err := client.CreateAssetAlias(ctx, &AssetAliasInput{Exchange: "hyperliquid", Asset: "POL", Alias: "POL", EffectiveFrom: effective}); err == nil {
+		t.Error("Expected an alias of itself to be rejected")
+	}
+
+	aliases, err := client.ListAssetAliases(ctx, "")
+	if err != nil || len(aliases) != 1 || aliases[0].Alias != "MATIC" {
+		t.Fatalf("Unexpected aliases: %v (%v)", aliases, err)
+	}
+	if strings.Contains(requests[1].query, "where") {
+		t.Errorf("Expected no exchange filter, got %s", requests[1].query)
+	}
+	if _, err := client.ListAssetAliases(ctx, "hyperliquid"); err != nil || requests[2].vars["exchange"] != "hyperliquid" {
+		t.Errorf("Expected an exchange filter, got %+v (%v)", requests[2].vars, err)
+	}
+
+	if err := client.DeleteAssetAlias(ctx, id); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
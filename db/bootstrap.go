@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zif-terminal/lib/models"
+)
+
+// BootstrapReport lists what Bootstrap created and what was already present
+type BootstrapReport struct {
+	CreatedExchanges     []string // Exchange names inserted by this run
+	ExistingExchanges    []string // Exchange names that were already present
+	CreatedAccountTypes  []string // Account type codes inserted by this run
+	ExistingAccountTypes []string // Account type codes that were already present
+}
+
+// Bootstrap seeds the reference data every environment needs: an exchange row for each of exchanges
+// (named by its display name; pass exchange.ListInfo() for the registered ones) and the canonical
+// account types in models.AccountTypes. Existing rows are left untouched, so it is safe to run on
+// every start
+func Bootstrap(ctx context.Context, client DBClient, exchanges []models.ExchangeInfo) (*BootstrapReport, error) {
+	report := &BootstrapReport{}
+
+	existing, err := client.ListExchanges(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bootstrap exchanges: %w", err)
+	}
+	present := make(map[string]bool, len(existing))
+	for _, ex := range existing {
+		present[ex.Name] = true
+	}
+
+	for _, info := range exchanges {
+		if present[info.Name] {
+			report.ExistingExchanges = append(report.ExistingExchanges, info.Name)
+			continue
+		}
+		if _, err := client.EnsureExchange(ctx, info.Name, info.DisplayName); err != nil {
+			return nil, fmt.Errorf("failed to bootstrap exchange %s: %w", info.Name, err)
+		}
+		report.CreatedExchanges = append(report.CreatedExchanges, info.Name)
+	}
+
+	for _, code := range models.AccountTypes {
+		created, err := client.EnsureAccountType(ctx, code)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bootstrap account type %s: %w", code, err)
+		}
+		if created {
+			report.CreatedAccountTypes = append(report.CreatedAccountTypes, code)
+		} else {
+			report.ExistingAccountTypes = append(report.ExistingAccountTypes, code)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/models"
+)
+
+// bootstrapDB is an in-memory DBClient covering the methods Bootstrap uses
+type bootstrapDB struct {
+	DBClient
+	exchanges    map[string]*Exchange
+	accountTypes map[string]bool
+}
+
+func (m *bootstrapDB) ListExchanges(ctx context.Context, activeOnly bool) ([]*Exchange, error) {
+	exchanges := make([]*Exchange, 0, len(m.exchanges))
+	for _, ex := range m.exchanges {
+		exchanges = append(exchanges, ex)
+	}
+	return exchanges, nil
+}
+
+func (m *bootstrapDB) EnsureExchange(ctx context.Context, name, displayName string) (*Exchange, error) {
+	if m.exchanges[name] == nil {
+		m.exchanges[name] = &Exchange{ID: uuid.New().String(), Name: name, DisplayName: displayName}
+	}
+	return m.exchanges[name], nil
+}
+
+func (m *bootstrapDB) EnsureAccountType(ctx context.Context, code string) (bool, error) {
+	if m.accountTypes[code] {
+		return false, nil
+	}
+	m.accountTypes[code] = true
+	return true, nil
+}
+
+func TestBootstrap_FirstRunThenRepeat(t *testing.T) {
+	ctx := context.Background()
+	fake := &bootstrapDB{
+		exchanges:    map[string]*Exchange{},
+		accountTypes: map[string]bool{models.AccountTypeMain: true}, // Seeded by hand earlier
+	}
+
+	exchanges := []models.ExchangeInfo{{Name: "hyperliquid", DisplayName: "Hyperliquid"}}
+
+	first, err := Bootstrap(ctx, fake, exchanges)
+	if err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+	want := &BootstrapReport{
+		CreatedExchanges:     []string{"hyperliquid"},
+		CreatedAccountTypes:  []string{models.AccountTypeSubAccount, models.AccountTypeVault},
+		ExistingAccountTypes: []string{models.AccountTypeMain},
+	}
+	if !reflect.DeepEqual(first, want) {
+		t.Errorf("First run report = %+v, want %+v", first, want)
+	}
+	if got := fake.exchanges["hyperliquid"].DisplayName; got != "Hyperliquid" {
+		t.Errorf("Expected display name Hyperliquid, got %q", got)
+	}
+
+	second, err := Bootstrap(ctx, fake, exchanges)
+	if err != nil {
+		t.Fatalf("Repeat Bootstrap failed: %v", err)
+	}
+	want = &BootstrapReport{
+		ExistingExchanges:    []string{"hyperliquid"},
+		ExistingAccountTypes: models.AccountTypes,
+	}
+	if !reflect.DeepEqual(second, want) {
+		t.Errorf("Repeat run report = %+v, want %+v", second, want)
+	}
+	if len(fake.exchanges) != 1 || len(fake.accountTypes) != len(models.AccountTypes) {
+		t.Errorf("Expected no duplicate rows, got %d exchanges and %d account types", len(fake.exchanges), len(fake.accountTypes))
+	}
+}
+
+func TestClient_EnsureAccountType(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    string
+		wantCreated bool
+	}{
+		{"inserted", `{"insert_exchange_account_types_one": {"code": "vault"}}`, true},
+		{"conflict ignored", `{"insert_exchange_account_types_one": null}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var vars map[string]interface{}
+			mockClient := &mockGraphQLClient{
+				runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+					vars = requestFromContext(ctx).vars
+					return json.Unmarshal([]byte(tt.response), resp)
+				},
+			}
+			client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+			created, err := client.EnsureAccountType(context.Background(), "vault")
+			if err != nil {
+				t.Fatalf("EnsureAccountType failed: %v", err)
+			}
+			if created != tt.wantCreated {
+				t.Errorf("created = %v, want %v", created, tt.wantCreated)
+			}
+			if vars["code"] != "vault" {
+				t.Errorf("Expected code variable vault, got %v", vars["code"])
+			}
+		})
+	}
+}
@@ -1,11 +1,22 @@
 package db
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/assets"
+	"github.com/zif-terminal/lib/clock"
 )
 
 // GraphQLClient is an interface for the GraphQL client (allows mocking for testing)
@@ -13,102 +24,563 @@ type GraphQLClient interface {
 	Run(ctx context.Context, req *graphql.Request, resp interface{}) error
 }
 
-// graphqlClientAdapter wraps a concrete *graphql.Client to implement GraphQLClient interface
+// rawGraphQLClient is implemented by GraphQL clients that can return the undecoded
+// response envelope ({"data": ..., "errors": [...]}) so the Client can decode it itself
+type rawGraphQLClient interface {
+	RunRaw(ctx context.Context, req *graphql.Request) ([]byte, error)
+}
+
+// graphqlClientAdapter wraps concrete *graphql.Clients to implement GraphQLClient interface
+// Queries go to the read endpoint and mutations to the write endpoint (see ClientConfig.ReadURL)
 type graphqlClientAdapter struct {
-	client *graphql.Client
+	client       *graphql.Client // Bound to endpoint
+	endpoint     string          // Write endpoint
+	readClient   *graphql.Client // Bound to readEndpoint
+	readEndpoint string
+	httpClient   *http.Client
 }
 
 func (a *graphqlClientAdapter) Run(ctx context.Context, req *graphql.Request, resp interface{}) error {
+	if a.isRead(ctx) {
+		return a.readClient.Run(ctx, req, resp)
+	}
 	return a.client.Run(ctx, req, resp)
 }
 
+// isRead reports whether the in-flight request (see requestFromContext) is a query
+// Requests without metadata are sent to the write endpoint
+func (a *graphqlClientAdapter) isRead(ctx context.Context) bool {
+	inflight := requestFromContext(ctx)
+	return inflight != nil && !isMutation(inflight.query)
+}
+
+// RunRaw posts the in-flight request (see requestFromContext) and returns the raw response body
+func (a *graphqlClientAdapter) RunRaw(ctx context.Context, req *graphql.Request) ([]byte, error) {
+	inflight := requestFromContext(ctx)
+	if inflight == nil {
+		return nil, fmt.Errorf("graphql: no request metadata in context")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     inflight.query,
+		"variables": inflight.vars,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode body: %w", err)
+	}
+
+	endpoint := a.endpoint
+	if a.isRead(ctx) {
+		endpoint = a.readEndpoint
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json; charset=utf-8")
+	httpReq.Header.Set("Accept", "application/json; charset=utf-8")
+	for key, values := range req.Header {
+		for _, value := range values {
+			httpReq.Header.Add(key, value)
+		}
+	}
+
+	res, err := a.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading body: %w", err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, &HTTPStatusError{StatusCode: res.StatusCode, Body: truncate(string(raw), 200)}
+	}
+
+	return raw, nil
+}
+
+// truncate shortens s to at most n bytes, marking the cut with "..."
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
 // Client provides methods to interact with the database through Hasura GraphQL API
+// A Client is safe for concurrent use by multiple goroutines: configuration is fixed once
+// NewClient returns and any state shared between calls (e.g. slow-query history) is guarded
 type Client struct {
 	graphql GraphQLClient
 	url     string
 	secret  string
+	config  ClientConfig
+	logger  *slog.Logger
+
+	slowMu      sync.Mutex
+	slowQueries []SlowQuery
+
+	responseHook ResponseHook // Receives raw responses (nil = off)
+	metricsHook  MetricsHook  // Receives per-operation metrics (nil = off)
+	tracer       Tracer       // Starts a span per operation (nil = off)
+	clock        Clock        // Source of "now" for operation deadlines and cache expiry
+	refCache     *referenceCache
+	resolver     atomic.Pointer[assets.Resolver] // See SetAssetResolver
+
+	maxAttempts  int           // Attempts per operation including the first (<= 1 = no retries)
+	retryBackoff time.Duration // Initial delay between attempts, doubled per retry
+
+	slots chan struct{} // Semaphore bounding requests in flight (nil = unlimited)
 }
 
 // ClientConfig holds configuration for creating a new Client
 type ClientConfig struct {
 	URL         string // Hasura GraphQL endpoint URL
 	AdminSecret string // Hasura admin secret
+
+	// ReadURL and WriteURL split traffic between endpoints, e.g. queries to a read replica and
+	// mutations to the primary. Either falls back to URL when empty. Reads on a replica may lag
+	// writes made just before them
+	ReadURL  string
+	WriteURL string
+
+	// SlowQueryThreshold enables slow-query reporting when > 0. Any operation taking
+	// longer than this is passed to SlowQueryHook, logged, and kept in SlowQueries().
+	SlowQueryThreshold time.Duration
+	// SlowQueryHook is called for every operation exceeding SlowQueryThreshold (optional)
+	SlowQueryHook func(SlowQuery)
+	// Logger receives client diagnostics such as slow-query warnings (defaults to slog.Default())
+	Logger *slog.Logger
+
+	// StrictDecoding rejects responses containing fields the lib's response structs don't
+	// declare (see json.Decoder.DisallowUnknownFields), and responses missing required fields
+	// on key models. Off by default.
+	// Only applies to GraphQL clients that expose the raw response (the default client does).
+	StrictDecoding bool
+
+	// ReadTimeout bounds each query; WriteTimeout bounds each mutation. Zero (unset) uses
+	// DefaultReadTimeout/DefaultWriteTimeout; NoTimeout disables the operation deadline so
+	// the call inherits the caller's ctx deadline. Override per call with WithTimeout.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// DecodeSnippetSize caps how many bytes of the offending JSON a DecodeError includes.
+	// Zero uses DefaultDecodeSnippetSize, negative omits the snippet.
+	DecodeSnippetSize int
+
+	// ExchangeActiveColumn names the boolean exchanges column ListExchanges(ctx, true)
+	// filters on. Empty uses DefaultExchangeActiveColumn. Like AccountEnabledColumn it must be
+	// a plain GraphQL name; a column missing from the schema surfaces as a *MissingColumnError.
+	ExchangeActiveColumn string
+
+	// AccountEnabledColumn names the boolean exchange_accounts column behind ExchangeAccount.Enabled,
+	// SetAccountEnabled and AccountFilter.ActiveOnly. Empty uses DefaultAccountEnabledColumn.
+	// It is spliced into queries, so the client panics on construction unless it is a plain GraphQL name.
+	AccountEnabledColumn string
+
+	// ReferenceCacheTTL caches the results of ListExchanges, ListAccountTypes and exchange lookups
+	// by name for this long when > 0. Exchange and account type mutations made through this
+	// client invalidate the cache; writes from elsewhere show up once entries expire
+	ReferenceCacheTTL time.Duration
+
+	// ConstraintMessages maps constraint names to the friendly messages GraphQLError reports for
+	// violations of them, on top of DefaultConstraintMessages (an entry overrides the default)
+	ConstraintMessages map[string]string
+
+	// Clock supplies the time for operation deadlines, retry backoff, cache expiry and operation
+	// durations. Nil uses the real clock; WithClock overrides it.
+	Clock clock.Clock
+
+	// MaxRequestBytes caps the request body of batch inserts (AddTrades, AddFundingPayments, their
+	// Count variants, CreatePositionTrades, CreateAccounts); larger batches are split into several requests,
+	// and a request failing after others were written returns a *PartialInsertError.
+	// Zero uses DefaultMaxRequestBytes, negative disables splitting.
+	MaxRequestBytes int
+
+	// AssetResolver groups renamed symbols (see assets.Resolver) in GetTradedPairs and the
+	// unallocated trade queries, so positions span a rename. Nil treats every symbol separately.
+	// A Resolver is a snapshot: aliases created later apply once SetAssetResolver installs a new one.
+	AssetResolver *assets.Resolver
+
+	// MaxConcurrentRequests caps how many requests the client has in flight at once; further
+	// calls wait for a free slot or for their ctx to end. Zero or negative means unlimited.
+	// WithMaxConcurrentRequests overrides it.
+	MaxConcurrentRequests int
 }
 
 // NewClient creates a new database client with a real GraphQL client
-func NewClient(config ClientConfig) *Client {
-	client := graphql.NewClient(config.URL)
-	return &Client{
-		graphql: &graphqlClientAdapter{client: client},
-		url:     config.URL,
-		secret:  config.AdminSecret,
+// It panics if a configured column name is not a plain GraphQL name
+func NewClient(config ClientConfig, opts ...Option) *Client {
+	httpClient := http.DefaultClient
+	readURL, writeURL := config.endpoints()
+	return newClient(&graphqlClientAdapter{
+		client:       graphql.NewClient(writeURL, graphql.WithHTTPClient(httpClient)),
+		endpoint:     writeURL,
+		readClient:   graphql.NewClient(readURL, graphql.WithHTTPClient(httpClient)),
+		readEndpoint: readURL,
+		httpClient:   httpClient,
+	}, config, opts...)
+}
+
+// endpoints returns the read and write endpoint URLs, falling back to URL
+func (config ClientConfig) endpoints() (readURL, writeURL string) {
+	readURL, writeURL = config.ReadURL, config.WriteURL
+	if readURL == "" {
+		readURL = config.URL
+	}
+	if writeURL == "" {
+		writeURL = config.URL
 	}
+	return readURL, writeURL
+}
+
+// validate checks the configured column names, which are spliced into queries rather than bound
+// as variables
+func (config ClientConfig) validate() error {
+	if config.AccountEnabledColumn != "" && !graphqlName.MatchString(config.AccountEnabledColumn) {
+		return fmt.Errorf("AccountEnabledColumn %q is not a GraphQL name", config.AccountEnabledColumn)
+	}
+	if config.ExchangeActiveColumn != "" && !graphqlName.MatchString(config.ExchangeActiveColumn) {
+		return fmt.Errorf("ExchangeActiveColumn %q is not a GraphQL name", config.ExchangeActiveColumn)
+	}
+	return nil
 }
 
 // NewClientWithGraphQL creates a client with a custom GraphQL client (for testing)
 // This allows injecting a mock GraphQL client for unit tests
-func NewClientWithGraphQL(graphql GraphQLClient, config ClientConfig) *Client {
-	return &Client{
-		graphql: graphql,
-		url:     config.URL,
-		secret:  config.AdminSecret,
+func NewClientWithGraphQL(graphql GraphQLClient, config ClientConfig, opts ...Option) *Client {
+	return newClient(graphql, config, opts...)
+}
+
+// newClient builds a Client around the given GraphQL client and applies config defaults
+func newClient(graphql GraphQLClient, config ClientConfig, opts ...Option) *Client {
+	if err := config.validate(); err != nil {
+		panic(fmt.Sprintf("db: invalid ClientConfig: %v", err))
+	}
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	c := &Client{
+		graphql:  graphql,
+		url:      config.URL,
+		secret:   config.AdminSecret,
+		config:   config,
+		logger:   logger,
+		clock:    clock.OrReal(config.Clock),
+		refCache: newReferenceCache(),
+		slots:    newSlots(config.MaxConcurrentRequests),
+	}
+	c.resolver.Store(config.AssetResolver)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// request wraps a graphql.Request with the metadata the client needs while executing it.
+// graphql.Request does not expose its query or variables, so we keep our own copy.
+type request struct {
+	*graphql.Request
+	query  string
+	opName string
+	vars   map[string]interface{}
+	err    error // Set when the request is malformed; execute returns it without sending
+}
+
+// requestContextKey is the context key under which execute stores the in-flight request
+type requestContextKey struct{}
+
+// requestFromContext returns the request being executed, if any.
+// GraphQLClient implementations (and test mocks) can use it to inspect the query text and variables.
+func requestFromContext(ctx context.Context) *request {
+	req, _ := ctx.Value(requestContextKey{}).(*request)
+	return req
+}
+
+// operationNamePattern extracts the operation name from "query Name(...)" or "mutation Name {"
+var operationNamePattern = regexp.MustCompile(`^\s*(?:query|mutation)\s+(\w+)`)
+
+// operationName returns the GraphQL operation name declared in query, or "anonymous"
+func operationName(query string) string {
+	if m := operationNamePattern.FindStringSubmatch(query); m != nil {
+		return m[1]
 	}
+	return "anonymous"
 }
 
 // graphqlRequest creates a new GraphQL request with admin secret header
-func (c *Client) graphqlRequest(query string) *graphql.Request {
+func (c *Client) graphqlRequest(query string) *request {
 	req := graphql.NewRequest(query)
 	req.Header.Set("X-Hasura-Admin-Secret", c.secret)
-	return req
+	return &request{
+		Request: req,
+		query:   query,
+		opName:  operationName(query),
+		vars:    map[string]interface{}{},
+	}
 }
 
 // graphqlRequestWithVars creates a new GraphQL request with variables
-func (c *Client) graphqlRequestWithVars(query string, vars map[string]interface{}) *graphql.Request {
+// Top-level [16]byte variables are rejected (see auditVars); execute then fails with that error
+func (c *Client) graphqlRequestWithVars(query string, vars map[string]interface{}) *request {
 	req := c.graphqlRequest(query)
 	for key, value := range vars {
 		req.Var(key, value)
+		req.vars[key] = value
 	}
+	req.err = auditVars(req.opName, vars)
 	return req
 }
 
 // execute executes a GraphQL request and unmarshals the response
-func (c *Client) execute(ctx context.Context, req *graphql.Request, resp interface{}) error {
-	return c.graphql.Run(ctx, req, resp)
+// Failed attempts are retried per the retry policy (see WithRetry) and the operation's idempotency
+func (c *Client) execute(ctx context.Context, req *request, resp interface{}) (err error) {
+	if req.err != nil {
+		return req.err
+	}
+
+	class := requestIdempotency(req)
+	backoff := c.retryBackoff
+
+	if c.tracer != nil {
+		var end func(error)
+		ctx, end = c.tracer.StartOperation(ctx, req.opName)
+		defer func() { end(err) }()
+	}
+
+	start := c.clock.Now()
+	attempt := 1
+	defer func() {
+		duration := c.clock.Now().Sub(start)
+		c.observeLatency(req, duration, err)
+		c.observeOperation(OperationMetrics{
+			Operation:   req.opName,
+			Idempotency: class,
+			Attempts:    attempt,
+			Duration:    duration,
+			Err:         err,
+		})
+	}()
+
+	for ; ; attempt++ {
+		err = c.executeLimited(ctx, req, resp)
+		if err == nil || attempt >= c.maxAttempts || ctx.Err() != nil || !shouldRetry(class, err) {
+			return err
+		}
+
+		if err := clock.Wait(ctx, c.clock, backoff); err != nil {
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// executeLimited makes a single attempt at req once a request slot is free
+// The slot is released between attempts so retry backoff doesn't hold it
+func (c *Client) executeLimited(ctx context.Context, req *request, resp interface{}) error {
+	if c.slots == nil {
+		return c.executeOnce(ctx, req, resp)
+	}
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-c.slots }()
+	return c.executeOnce(ctx, req, resp)
+}
+
+// executeOnce makes a single attempt at req under its operation timeout
+func (c *Client) executeOnce(ctx context.Context, req *request, resp interface{}) error {
+	ctx, cancel := c.withOperationTimeout(ctx, req)
+	defer cancel()
+
+	ctx = context.WithValue(ctx, requestContextKey{}, req)
+
+	var err error
+	var body []byte
+	if raw, ok := c.graphql.(rawGraphQLClient); ok {
+		body, err = raw.RunRaw(ctx, req.Request)
+		if err == nil {
+			err = c.decodeResponse(req, body, resp)
+		}
+	} else {
+		err = c.graphql.Run(ctx, req.Request, resp)
+		if (c.responseHook != nil || rawCaptureEnabled(ctx)) && err == nil {
+			body, _ = json.Marshal(resp)
+		}
+	}
+	if body != nil {
+		captureRaw(ctx, body)
+	}
+
+	if c.responseHook != nil {
+		c.responseHook(req.opName, body, err)
+	}
+	return err
+}
+
+// graphqlResponse is the standard GraphQL response envelope
+type graphqlResponse struct {
+	Data   json.RawMessage      `json:"data"`
+	Errors []GraphQLErrorDetail `json:"errors"`
+}
+
+// decodeResponse decodes a raw response envelope into resp, applying strict checks if enabled
+// When the envelope has both data and errors, the data is decoded and a partial *GraphQLError returned
+func (c *Client) decodeResponse(req *request, body []byte, resp interface{}) error {
+	var envelope graphqlResponse
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+
+	var gqlErr *GraphQLError
+	if len(envelope.Errors) > 0 {
+		gqlErr = &GraphQLError{Operation: req.opName, Errors: envelope.Errors}
+		c.annotateConstraints(gqlErr)
+	}
+
+	if len(envelope.Data) == 0 || string(envelope.Data) == "null" {
+		if gqlErr != nil {
+			return gqlErr
+		}
+		return nil
+	}
+
+	if c.config.StrictDecoding {
+		if err := checkStrict(envelope.Data, resp); err != nil {
+			return fmt.Errorf("strict decoding of %s response: %w", req.opName, err)
+		}
+	}
+
+	if err := json.Unmarshal(envelope.Data, resp); err != nil {
+		return c.newDecodeError(req, envelope.Data, resp, err)
+	}
+
+	if gqlErr != nil {
+		gqlErr.Partial = true
+		return gqlErr
+	}
+
+	return nil
+}
+
+// Ping checks that Hasura is reachable and answering queries with the configured credentials
+// It selects only __typename, so no table is read
+func (c *Client) Ping(ctx context.Context) error {
+	req := c.graphqlRequest(`
+		query Ping {
+			__typename
+		}
+	`)
+
+	var resp struct {
+		Typename string `json:"__typename"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return nil
 }
 
 // DBClient is an interface that Client implements
 type DBClient interface {
+	Ping(ctx context.Context) error
+
 	// Exchange methods
 	GetExchange(ctx context.Context, id string) (*Exchange, error)
-	ListExchanges(ctx context.Context) ([]*Exchange, error)
+	ListExchanges(ctx context.Context, activeOnly bool) ([]*Exchange, error)
 	CreateExchange(ctx context.Context, input *ExchangeInput) (*Exchange, error)
 	UpdateExchange(ctx context.Context, id string, input *ExchangeInput) (*Exchange, error)
+	EnsureExchange(ctx context.Context, name, displayName string) (*Exchange, error)
 
 	// Account methods
 	GetAccount(ctx context.Context, id string) (*ExchangeAccount, error)
 	ListAccounts(ctx context.Context) ([]*ExchangeAccount, error)
+	ListAccountsFiltered(ctx context.Context, filter AccountFilter) ([]*ExchangeAccount, error)
+	IterateAccounts(ctx context.Context, pageSize int, fn func([]*ExchangeAccount) error) error
+	ResolveAccountIDs(ctx context.Context, pairs []ExchangeIdentifier) (map[ExchangeIdentifier]string, error)
 	CreateAccount(ctx context.Context, input *ExchangeAccountInput) (*ExchangeAccount, error)
+	CreateAccounts(ctx context.Context, inputs []*ExchangeAccountInput) ([]*ExchangeAccount, error)
 	UpdateAccount(ctx context.Context, id string, input *ExchangeAccountInput) (*ExchangeAccount, error)
 	DeleteAccount(ctx context.Context, id string) error
+	SetAccountPnLDenomination(ctx context.Context, id string, denom string) error
+	SetAccountEnabled(ctx context.Context, id string, enabled bool) error
+	PatchAccount(ctx context.Context, id string, patch AccountPatch) (*ExchangeAccount, error)
+	MigrateAllAccountMetadata(ctx context.Context, dryRun bool) (*MigrationReport, error)
+	GetAccountDataSummary(ctx context.Context, accountID uuid.UUID) (*AccountDataSummary, error)
+	ListAccountTypes(ctx context.Context) ([]*AccountType, error)
+	EnsureAccountType(ctx context.Context, code string) (bool, error)
 
 	// Trade methods
 	GetTrade(ctx context.Context, id string) (*Trade, error)
+	GetTradesByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*Trade, error)
+	GetTradesByIDsStrict(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*Trade, error)
 	ListTrades(ctx context.Context, filter TradeFilter) ([]*Trade, error)
+	ListTradesPage(ctx context.Context, filter TradeFilter, opts PageOptions) (*Page[*Trade], error)
+	GetRecentTrades(ctx context.Context, limit int) ([]*Trade, error)
 	CreateTrade(ctx context.Context, input *TradeInput) (*Trade, error)
+	AddTrades(ctx context.Context, inputs []*TradeInput) ([]*Trade, error)
+	AddTradesCount(ctx context.Context, inputs []*TradeInput) (int, error)
+	AddTradesIdempotent(ctx context.Context, inputs []*TradeInput) (*AddTradesResult, error)
+	ExistingTradeIDs(ctx context.Context, exchangeAccountID uuid.UUID, tradeIDs []string) (map[string]bool, error)
+	FindUnallocatedTrades(ctx context.Context, exchangeAccountID uuid.UUID, pair *AssetPair, window TimeRange) ([]*Trade, error)
+	CountUnallocatedTrades(ctx context.Context, exchangeAccountID uuid.UUID, pair *AssetPair, window TimeRange) (int, error)
 	UpdateTrade(ctx context.Context, id string, input *TradeInput) (*Trade, error)
 	DeleteTrade(ctx context.Context, id string) error
 	LatestTrade(ctx context.Context, exchangeAccountIDs []uuid.UUID) (map[uuid.UUID]*Trade, error)
+	GetTradedPairs(ctx context.Context, accountID uuid.UUID) ([][2]string, error)
 
 	// Funding payment methods
 	GetLatestFundingPayment(ctx context.Context, exchangeAccountID uuid.UUID) (*FundingPayment, error)
+	GetLatestFundingPayments(ctx context.Context, exchangeAccountIDs []uuid.UUID) (map[uuid.UUID]*FundingPayment, error)
+	GetLatestFundingPaymentsByAsset(ctx context.Context, exchangeAccountID uuid.UUID) (map[string]time.Time, error)
+	GetFundingForPosition(ctx context.Context, position *Position) ([]*FundingPayment, string, error)
+	GetFundingForPositions(ctx context.Context, positions []*Position) (map[uuid.UUID]*PositionFunding, error)
 	AddFundingPayments(ctx context.Context, inputs []*FundingPaymentInput) ([]*FundingPayment, error)
+	AddFundingPaymentsCount(ctx context.Context, inputs []*FundingPaymentInput) (int, error)
+	GetActivity(ctx context.Context, accountID uuid.UUID, since, until time.Time) (*Activity, error)
+	ListFundingPayments(ctx context.Context, filter FundingPaymentFilter) ([]*FundingPayment, error)
+	ListFundingPaymentsPage(ctx context.Context, filter FundingPaymentFilter, opts PageOptions) (*Page[*FundingPayment], error)
+
+	// Order methods
+	AddOrders(ctx context.Context, inputs []*OrderInput) ([]*Order, error)
+	GetOrdersByAccount(ctx context.Context, exchangeAccountID uuid.UUID, filter OrderFilter) ([]*Order, error)
+
+	// Sync run methods
+	CreateSyncRun(ctx context.Context, input *SyncRunInput) (*SyncRun, error)
+	ListSyncRuns(ctx context.Context, exchangeAccountID uuid.UUID, limit int) ([]*SyncRun, error)
+
+	// Asset alias methods
+	CreateAssetAlias(ctx context.Context, input *AssetAliasInput) (*AssetAlias, error)
+	ListAssetAliases(ctx context.Context, exchange string) ([]*AssetAlias, error)
+	DeleteAssetAlias(ctx context.Context, id uuid.UUID) error
+	RewriteTradeAsset(ctx context.Context, accountID uuid.UUID, from, to string, dryRun bool) (int, error)
+
+	// Dead letter methods
+	AddDeadLetters(ctx context.Context, inputs []*DeadLetterInput) ([]*DeadLetter, error)
+	ListDeadLetters(ctx context.Context, exchangeAccountID uuid.UUID, includeResolved bool) ([]*DeadLetter, error)
+	ResolveDeadLetter(ctx context.Context, id uuid.UUID) (*DeadLetter, error)
 
 	// Position methods
 	GetLastProcessedTradeTimestamp(ctx context.Context, exchangeAccountID uuid.UUID, baseAsset string, quoteAsset string) (*time.Time, error)
 	CreatePosition(ctx context.Context, input *PositionInput) (*Position, error)
 	CreatePositionTrades(ctx context.Context, inputs []*PositionTradeInput) ([]*PositionTrade, error)
+	FindOrphanedPositionTrades(ctx context.Context) ([]*PositionTrade, error)
+	DeleteOrphanedPositionTrades(ctx context.Context) (int, error)
 	GetPositions(ctx context.Context, filter PositionFilter) ([]*Position, error)
+	GetPositionsPage(ctx context.Context, filter PositionFilter, opts PageOptions) (*Page[*Position], error)
+	GetRealizedPnLBuckets(ctx context.Context, accountID uuid.UUID, filter PositionFilter, bucket time.Duration) ([]PnLBucket, error)
 	GetPositionByID(ctx context.Context, positionID string) (*Position, []*PositionTrade, error)
+	GetPositionDetail(ctx context.Context, positionID string) (*Position, []*PositionTrade, []*Trade, error)
 }
 
 // Ensure Client implements DBClient
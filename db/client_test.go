@@ -0,0 +1,321 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/machinebox/graphql"
+)
+
+// rawMockGraphQLClient is a mock that returns raw response envelopes, exercising the
+// Client's own decoding path (as the default HTTP-backed client does)
+type rawMockGraphQLClient struct {
+	runRawFunc func(ctx context.Context, req *graphql.Request) ([]byte, error)
+}
+
+func (m *rawMockGraphQLClient) Run(ctx context.Context, req *graphql.Request, resp interface{}) error {
+	return errors.New("rawMockGraphQLClient: Run should not be called")
+}
+
+func (m *rawMockGraphQLClient) RunRaw(ctx context.Context, req *graphql.Request) ([]byte, error) {
+	return m.runRawFunc(ctx, req)
+}
+
+// rawResponse returns a raw mock that always responds with body
+func rawResponse(body string) *rawMockGraphQLClient {
+	return &rawMockGraphQLClient{
+		runRawFunc: func(ctx context.Context, req *graphql.Request) ([]byte, error) {
+			return []byte(body), nil
+		},
+	}
+}
+
+func TestClient_DefaultClient_PostsQueryAndDecodes(t *testing.T) {
+	var received struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	var secret string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret = r.Header.Get("X-Hasura-Admin-Secret")
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"exchanges_by_pk":{"id":"ex-1","name":"hyperliquid","display_name":"Hyperliquid"}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{URL: server.URL, AdminSecret: "test-secret"})
+
+	exchange, err := client.GetExchange(context.Background(), "ex-1")
+	if err != nil {
+		t.Fatalf("GetExchange failed: %v", err)
+	}
+
+	if exchange.Name != "hyperliquid" {
+		t.Errorf("Expected name 'hyperliquid', got '%s'", exchange.Name)
+	}
+	if secret != "test-secret" {
+		t.Errorf("Expected admin secret header, got '%s'", secret)
+	}
+	if received.Variables["id"] != "ex-1" {
+		t.Errorf("Expected id variable 'ex-1', got %v", received.Variables["id"])
+	}
+	if operationName(received.Query) != "GetExchange" {
+		t.Errorf("Expected GetExchange query, got: %s", received.Query)
+	}
+}
+
+func TestClient_DefaultClient_GraphQLError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors":[{"message":"field 'foo' not found in type: 'query_root'"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{URL: server.URL, AdminSecret: "test-secret"})
+
+	_, err := client.ListExchanges(context.Background(), false)
+	if err == nil {
+		t.Fatal("Expected error from GraphQL errors response")
+	}
+}
+
+func TestClient_DefaultClient_HTTPStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("upstream unavailable"))
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{URL: server.URL, AdminSecret: "test-secret"})
+
+	_, err := client.ListExchanges(context.Background(), false)
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Expected *HTTPStatusError, got %v", err)
+	}
+	if statusErr.StatusCode != http.StatusServiceUnavailable || statusErr.Body != "upstream unavailable" {
+		t.Errorf("Unexpected error details: %+v", statusErr)
+	}
+}
+
+func TestClient_PartialResponse(t *testing.T) {
+	client := NewClientWithGraphQL(rawResponse(`{
+		"data": {"exchanges": [{"id": "ex-1", "name": "hyperliquid"}], "accounts": null},
+		"errors": [{"message": "permission denied for accounts", "path": ["accounts"], "extensions": {"code": "permission-error"}}]
+	}`), ClientConfig{URL: "http://localhost:8080/v1/graphql", AdminSecret: "test-secret"})
+
+	var resp struct {
+		Exchanges []*Exchange        `json:"exchanges"`
+		Accounts  []*ExchangeAccount `json:"accounts"`
+	}
+	err := client.execute(context.Background(), client.graphqlRequest(`query Dashboard { exchanges { id name } accounts { id } }`), &resp)
+
+	var gqlErr *GraphQLError
+	if !errors.As(err, &gqlErr) {
+		t.Fatalf("Expected *GraphQLError, got %v", err)
+	}
+	if !gqlErr.Partial {
+		t.Error("Expected error to be marked partial")
+	}
+	if gqlErr.Operation != "Dashboard" || len(gqlErr.Errors) != 1 || gqlErr.Errors[0].Extensions["code"] != "permission-error" {
+		t.Errorf("Unexpected error details: %+v", gqlErr)
+	}
+	if len(resp.Exchanges) != 1 || resp.Exchanges[0].Name != "hyperliquid" {
+		t.Errorf("Expected partial data to be decoded, got %+v", resp.Exchanges)
+	}
+}
+
+func TestClient_ErrorWithoutData(t *testing.T) {
+	client := NewClientWithGraphQL(rawResponse(`{"errors": [{"message": "first"}, {"message": "second"}]}`),
+		ClientConfig{URL: "http://localhost:8080/v1/graphql", AdminSecret: "test-secret"})
+
+	_, err := client.ListExchanges(context.Background(), false)
+
+	var gqlErr *GraphQLError
+	if !errors.As(err, &gqlErr) {
+		t.Fatalf("Expected *GraphQLError, got %v", err)
+	}
+	if gqlErr.Partial {
+		t.Error("Expected error without data not to be partial")
+	}
+	if len(gqlErr.Errors) != 2 {
+		t.Errorf("Expected both errors to be kept, got %+v", gqlErr.Errors)
+	}
+}
+
+func TestClient_WithResponseHook(t *testing.T) {
+	body := `{"data":{"exchanges":[{"id":"ex-1","name":"hyperliquid","display_name":"Hyperliquid"}]}}`
+
+	var hookOp string
+	var hookRaw json.RawMessage
+	var hookCalls int
+	client := NewClientWithGraphQL(rawResponse(body), ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	}, WithResponseHook(func(opName string, raw json.RawMessage, err error) {
+		hookCalls++
+		hookOp = opName
+		hookRaw = raw
+		if err != nil {
+			t.Errorf("Expected no error in hook, got %v", err)
+		}
+	}))
+
+	exchanges, err := client.ListExchanges(context.Background(), false)
+	if err != nil {
+		t.Fatalf("ListExchanges failed: %v", err)
+	}
+
+	if hookCalls != 1 || hookOp != "ListExchanges" {
+		t.Errorf("Expected one ListExchanges hook call, got %d calls (last %q)", hookCalls, hookOp)
+	}
+	if string(hookRaw) != body {
+		t.Errorf("Expected hook to receive the raw body, got %s", hookRaw)
+	}
+	if len(exchanges) != 1 || exchanges[0].Name != "hyperliquid" {
+		t.Errorf("Expected normal decoding to be unaffected, got %+v", exchanges)
+	}
+}
+
+func TestClient_WithResponseHook_Error(t *testing.T) {
+	body := `{"errors":[{"message":"boom"}]}`
+
+	var hookRaw json.RawMessage
+	var hookErr error
+	client := NewClientWithGraphQL(rawResponse(body), ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	}, WithResponseHook(func(opName string, raw json.RawMessage, err error) {
+		hookRaw, hookErr = raw, err
+	}))
+
+	if _, err := client.ListExchanges(context.Background(), false); err == nil {
+		t.Fatal("Expected error from GraphQL errors response")
+	}
+	if string(hookRaw) != body || hookErr == nil {
+		t.Errorf("Expected hook to receive the raw error body and error, got %s / %v", hookRaw, hookErr)
+	}
+}
+
+func TestClient_Ping(t *testing.T) {
+	var received struct {
+		Query string `json:"query"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.Write([]byte(`{"data":{"__typename":"query_root"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{URL: server.URL, AdminSecret: "test-secret"})
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if operationName(received.Query) != "Ping" {
+		t.Errorf("Expected Ping query, got: %s", received.Query)
+	}
+
+	failing := NewClientWithGraphQL(rawResponse(`{"errors":[{"message":"invalid x-hasura-admin-secret/x-hasura-access-key"}]}`),
+		ClientConfig{URL: "http://localhost:8080/v1/graphql"})
+	if err := failing.Ping(context.Background()); err == nil {
+		t.Fatal("Expected Ping to fail on a GraphQL error")
+	}
+}
+
+func TestClient_ReadAndWriteURLs(t *testing.T) {
+	// endpointServer records the operations it receives
+	endpointServer := func(ops *[]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var received struct {
+				Query string `json:"query"`
+			}
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &received)
+			*ops = append(*ops, operationName(received.Query))
+			w.Write([]byte(`{"data":{
+				"exchanges_by_pk":{"id":"ex-1","name":"hyperliquid","display_name":"Hyperliquid"},
+				"insert_exchanges_one":{"id":"ex-2","name":"lighter","display_name":"Lighter"}
+			}}`))
+		}))
+	}
+
+	var primaryOps, replicaOps, defaultOps []string
+	primary := endpointServer(&primaryOps)
+	defer primary.Close()
+	replica := endpointServer(&replicaOps)
+	defer replica.Close()
+	fallback := endpointServer(&defaultOps)
+	defer fallback.Close()
+
+	ctx := context.Background()
+	exchange := &ExchangeInput{Name: "lighter", DisplayName: "Lighter"}
+
+	client := NewClient(ClientConfig{URL: fallback.URL, ReadURL: replica.URL, WriteURL: primary.URL})
+	if _, err := client.GetExchange(ctx, "ex-1"); err != nil {
+		t.Fatalf("GetExchange failed: %v", err)
+	}
+	if _, err := client.CreateExchange(ctx, exchange); err != nil {
+		t.Fatalf("CreateExchange failed: %v", err)
+	}
+	if len(replicaOps) != 1 || replicaOps[0] != "GetExchange" {
+		t.Errorf("Expected the query on the read URL, got %v", replicaOps)
+	}
+	if len(primaryOps) != 1 || primaryOps[0] != "CreateExchange" {
+		t.Errorf("Expected the mutation on the write URL, got %v", primaryOps)
+	}
+	if len(defaultOps) != 0 {
+		t.Errorf("Expected nothing on URL when both are set, got %v", defaultOps)
+	}
+
+	// WriteURL unset: mutations fall back to URL
+	replicaOps = nil
+	client = NewClient(ClientConfig{URL: fallback.URL, ReadURL: replica.URL})
+	if _, err := client.GetExchange(ctx, "ex-1"); err != nil {
+		t.Fatalf("GetExchange failed: %v", err)
+	}
+	if _, err := client.CreateExchange(ctx, exchange); err != nil {
+		t.Fatalf("CreateExchange failed: %v", err)
+	}
+	if len(replicaOps) != 1 || replicaOps[0] != "GetExchange" {
+		t.Errorf("Expected the query on the read URL, got %v", replicaOps)
+	}
+	if len(defaultOps) != 1 || defaultOps[0] != "CreateExchange" {
+		t.Errorf("Expected the mutation to fall back to URL, got %v", defaultOps)
+	}
+}
+
+func TestNewClient_RejectsInvalidColumnNames(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ClientConfig
+	}{
+		{"account enabled column", ClientConfig{AccountEnabledColumn: "enabled: { _eq: true } }) { id } #"}},
+		{"exchange active column", ClientConfig{ExchangeActiveColumn: "enabled: { _eq: true } } #"}},
+		{"exchange active column with spaces", ClientConfig{ExchangeActiveColumn: "is active"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "invalid ClientConfig") {
+					t.Errorf("Expected construction to panic, got %v", r)
+				}
+			}()
+			NewClientWithGraphQL(&mockGraphQLClient{}, tt.config)
+		})
+	}
+
+	// Valid names are accepted
+	NewClientWithGraphQL(&mockGraphQLClient{}, ClientConfig{AccountEnabledColumn: "sync_enabled", ExchangeActiveColumn: "is_active"})
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/machinebox/graphql"
+)
+
+// stressGoroutines is the number of goroutines the concurrency tests run at once
+const stressGoroutines = 50
+
+// TestClient_ConcurrentUse hammers one Client from many goroutines with slow-query tracking and
+// retries enabled; run with -race to check the shared state is guarded
+func TestClient_ConcurrentUse(t *testing.T) {
+	exchangeID := "550e8400-e29b-41d4-a716-446655440000"
+	responses := map[string]string{
+		"ListAccountTypes": `{"exchange_account_types": [{"code": "main"}, {"code": "sub_account"}]}`,
+		"GetExchange":      fmt.Sprintf(`{"exchanges_by_pk": {"id": %q, "name": "hyperliquid", "display_name": "Hyperliquid"}}`, exchangeID),
+		"CreateAccount":    `{"insert_exchange_accounts_one": {"id": "660e8400-e29b-41d4-a716-446655440000", "account_identifier": "0x1234567890123456789012345678901234567890", "account_type": "main"}}`,
+	}
+
+	mock := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			body, ok := responses[requestFromContext(ctx).opName]
+			if !ok {
+				return fmt.Errorf("unexpected operation %s", requestFromContext(ctx).opName)
+			}
+			return json.Unmarshal([]byte(body), resp)
+		},
+	}
+
+	var hookCalls sync.Map
+	client := NewClientWithGraphQL(mock, ClientConfig{
+		SlowQueryThreshold: time.Nanosecond,
+		SlowQueryHook:      func(q SlowQuery) { hookCalls.Store(q.Operation, true) },
+	}, WithRetry(2, time.Millisecond))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, stressGoroutines)
+	for i := 0; i < stressGoroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := WithRawCapture(context.Background())
+
+			types, err := client.ListAccountTypes(ctx)
+			if err != nil {
+				errs <- err
+				return
+			}
+			valid := false
+			for _, accountType := range types {
+				valid = valid || accountType.Code == "main"
+			}
+			if !valid {
+				errs <- fmt.Errorf("goroutine %d: account type main not listed", i)
+				return
+			}
+
+			if _, err := client.CreateAccount(ctx, &ExchangeAccountInput{
+				ExchangeID:        exchangeID,
+				AccountIdentifier: "0x1234567890123456789012345678901234567890",
+				AccountType:       "main",
+			}); err != nil {
+				errs <- err
+				return
+			}
+			_ = client.SlowQueries()
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if len(client.SlowQueries()) == 0 {
+		t.Error("Expected slow queries to be recorded")
+	}
+}
+
+// blockingPingMock answers Ping only once release is closed, tracking how many calls are in flight
+type blockingPingMock struct {
+	release chan struct{}
+	entered chan struct{}
+
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (m *blockingPingMock) Run(ctx context.Context, req *graphql.Request, resp interface{}) error {
+	m.mu.Lock()
+	m.inFlight++
+	if m.inFlight > m.peak {
+		m.peak = m.inFlight
+	}
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.inFlight--
+		m.mu.Unlock()
+	}()
+
+	m.entered <- struct{}{}
+	select {
+	case <-m.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return json.Unmarshal([]byte(`{"__typename": "query_root"}`), resp)
+}
+
+func TestClient_MaxConcurrentRequests(t *testing.T) {
+	const limit = 3
+	mock := &blockingPingMock{release: make(chan struct{}), entered: make(chan struct{}, stressGoroutines)}
+	client := NewClientWithGraphQL(mock, ClientConfig{MaxConcurrentRequests: limit})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, stressGoroutines)
+	for i := 0; i < stressGoroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- client.Ping(context.Background())
+		}()
+	}
+
+	for i := 0; i < limit; i++ {
+		<-mock.entered
+	}
+	select {
+	case <-mock.entered:
+		t.Fatalf("more than %d requests started before any finished", limit)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(mock.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Ping() error = %v", err)
+		}
+	}
+	if mock.peak > limit {
+		t.Errorf("peak in-flight requests = %d, want at most %d", mock.peak, limit)
+	}
+}
+
+func TestClient_MaxConcurrentRequests_WaitRespectsContext(t *testing.T) {
+	mock := &blockingPingMock{release: make(chan struct{}), entered: make(chan struct{}, 2)}
+	client := NewClientWithGraphQL(mock, ClientConfig{}, WithMaxConcurrentRequests(1))
+
+	done := make(chan error, 1)
+	go func() { done <- client.Ping(context.Background()) }()
+	<-mock.entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := client.Ping(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Ping() while the only slot is taken error = %v, want context.DeadlineExceeded", err)
+	}
+
+	close(mock.release)
+	if err := <-done; err != nil {
+		t.Errorf("first Ping() error = %v", err)
+	}
+}
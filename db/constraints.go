@@ -0,0 +1,53 @@
+package db
+
+import "regexp"
+
+// DefaultConstraintMessages maps constraint names Hasura reports on violations to operator-facing
+// messages. ClientConfig.ConstraintMessages extends or overrides it
+var DefaultConstraintMessages = map[string]string{
+	"trades_exchange_account_id_trade_id_key":             "duplicate trade_id for the account",
+	"trades_trade_id_key":                                 "duplicate trade_id",
+	"trades_exchange_account_id_fkey":                     "trade references an unknown exchange account",
+	"funding_payments_exchange_account_id_payment_id_key": "duplicate payment_id for the account",
+	"funding_payments_exchange_account_id_fkey":           "funding payment references an unknown exchange account",
+	"orders_exchange_account_id_order_id_key":             "duplicate order_id for the account",
+	"orders_exchange_account_id_fkey":                     "order references an unknown exchange account",
+	"exchanges_name_key":                                  "duplicate exchange name",
+	"exchange_accounts_exchange_id_fkey":                  "account references an unknown exchange",
+	"exchange_accounts_account_type_fkey":                 "unknown account type",
+	"exchange_account_types_pkey":                         "duplicate account type",
+	"position_trades_position_id_fkey":                    "position trade references an unknown position",
+	"position_trades_trade_id_fkey":                       "position trade references an unknown trade",
+}
+
+// constraintNamePattern extracts the constraint name from Postgres violation messages, e.g.
+// `duplicate key value violates unique constraint "trades_exchange_account_id_trade_id_key"`
+var constraintNamePattern = regexp.MustCompile(`constraint "([^"]+)"`)
+
+// constraintName returns the constraint named in a violation message, or ""
+func constraintName(message string) string {
+	if m := constraintNamePattern.FindStringSubmatch(message); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// constraintMessage returns the friendly message for constraint, preferring the client's
+// configured messages over DefaultConstraintMessages. An empty configured message hides the default
+func (c *Client) constraintMessage(constraint string) string {
+	if message, ok := c.config.ConstraintMessages[constraint]; ok {
+		return message
+	}
+	return DefaultConstraintMessages[constraint]
+}
+
+// annotateConstraints fills in the constraint and friendly message of each violation in gqlErr
+func (c *Client) annotateConstraints(gqlErr *GraphQLError) {
+	for i := range gqlErr.Errors {
+		detail := &gqlErr.Errors[i]
+		detail.Constraint = constraintName(detail.Message)
+		if detail.Constraint != "" {
+			detail.FriendlyMessage = c.constraintMessage(detail.Constraint)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestClient_ConstraintViolationIsTranslated(t *testing.T) {
+	client := NewClientWithGraphQL(rawResponse(`{
+		"errors": [{
+			"message": "Uniqueness violation. duplicate key value violates unique constraint \"trades_exchange_account_id_trade_id_key\"",
+			"extensions": {"code": "constraint-violation"}
+		}]
+	}`), ClientConfig{})
+
+	_, err := client.ListExchanges(context.Background(), false)
+
+	var gqlErr *GraphQLError
+	if !errors.As(err, &gqlErr) {
+		t.Fatalf("Expected *GraphQLError, got %T: %v", err, err)
+	}
+	detail := gqlErr.Errors[0]
+	if detail.Constraint != "trades_exchange_account_id_trade_id_key" {
+		t.Errorf("Expected the constraint name to be parsed, got %q", detail.Constraint)
+	}
+	if detail.FriendlyMessage != "duplicate trade_id for the account" {
+		t.Errorf("Expected a friendly message, got %q", detail.FriendlyMessage)
+	}
+	if !strings.Contains(err.Error(), "duplicate trade_id for the account (trades_exchange_account_id_trade_id_key)") {
+		t.Errorf("Expected the friendly message in the error, got %v", err)
+	}
+	if !strings.Contains(detail.Message, "Uniqueness violation") {
+		t.Errorf("Expected the raw message to be kept, got %q", detail.Message)
+	}
+}
+
+func TestClient_ConstraintMessagesConfig(t *testing.T) {
+	body := `{
+		"errors": [
+			{"message": "Check constraint violation. new row for relation \"trades\" violates check constraint \"trades_side_check\""},
+			{"message": "Uniqueness violation. duplicate key value violates unique constraint \"exchanges_name_key\""},
+			{"message": "field 'foo' not found in type: 'query_root'"}
+		]
+	}`
+	client := NewClientWithGraphQL(rawResponse(body), ClientConfig{
+		ConstraintMessages: map[string]string{
+			"trades_side_check":  "side must be buy or sell",
+			"exchanges_name_key": "", // Hide the default
+		},
+	})
+
+	_, err := client.ListExchanges(context.Background(), false)
+
+	var gqlErr *GraphQLError
+	if !errors.As(err, &gqlErr) {
+		t.Fatalf("Expected *GraphQLError, got %T: %v", err, err)
+	}
+	if got := gqlErr.Errors[0].FriendlyMessage; got != "side must be buy or sell" {
+		t.Errorf("Expected the configured message, got %q", got)
+	}
+	if got := gqlErr.Errors[1]; got.Constraint != "exchanges_name_key" || got.FriendlyMessage != "" {
+		t.Errorf("Expected the overridden default to be hidden, got %+v", got)
+	}
+	if got := gqlErr.Errors[2]; got.Constraint != "" || got.FriendlyMessage != "" {
+		t.Errorf("Expected no constraint for a non-violation error, got %+v", got)
+	}
+
+	want := "graphql: side must be buy or sell (trades_side_check); Uniqueness violation. duplicate key value violates unique constraint \"exchanges_name_key\"; field 'foo' not found in type: 'query_root'"
+	if !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("Expected error %q, got %q", want, err.Error())
+	}
+}
@@ -0,0 +1,69 @@
+// Package dbtest seeds and resets databases for integration tests against a real Hasura instance
+package dbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/zif-terminal/lib/models"
+)
+
+// Fixture declares the rows Seed inserts
+// Accounts refer to exchanges by name, and trades, positions and funding payments refer to
+// accounts by Key, so a fixture needs no database-generated IDs
+type Fixture struct {
+	Exchanges       []Exchange       `json:"exchanges"`
+	Accounts        []Account        `json:"accounts"`
+	Trades          []Trade          `json:"trades"`
+	Positions       []Position       `json:"positions"`
+	FundingPayments []FundingPayment `json:"funding_payments"`
+}
+
+// Exchange is a fixture exchange
+type Exchange struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// Account is a fixture exchange account
+type Account struct {
+	Key                 string          `json:"key"`      // Referenced by trades, positions and funding payments
+	Exchange            string          `json:"exchange"` // Exchange name
+	AccountIdentifier   string          `json:"account_identifier"`
+	AccountType         string          `json:"account_type"`
+	AccountTypeMetadata json.RawMessage `json:"account_type_metadata,omitempty"`
+}
+
+// Trade is a fixture trade; ExchangeAccountID is filled in from Account
+type Trade struct {
+	Account string `json:"account"`
+	models.TradeInput
+}
+
+// Position is a fixture position; ExchangeAccountID is filled in from Account
+type Position struct {
+	Account string `json:"account"`
+	models.PositionInput
+}
+
+// FundingPayment is a fixture funding payment; ExchangeAccountID is filled in from Account
+type FundingPayment struct {
+	Account string `json:"account"`
+	models.FundingPaymentInput
+}
+
+// LoadFixture reads a JSON fixture file
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to decode fixture %s: %w", path, err)
+	}
+
+	return &fixture, nil
+}
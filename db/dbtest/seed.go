@@ -0,0 +1,228 @@
+package dbtest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/db"
+	"github.com/zif-terminal/lib/models"
+)
+
+// DefaultTables lists every table Seed writes, plus sync_runs, dead_letters and asset_aliases,
+// dependents first, so deleting in this order never violates a foreign key
+var DefaultTables = []string{
+	"sync_runs",
+	"dead_letters",
+	"position_trades",
+	"positions",
+	"funding_payments",
+	"orders",
+	"trades",
+	"exchange_accounts",
+	"exchanges",
+	"asset_aliases",
+}
+
+// Seeded holds the rows created or found by Seed
+type Seeded struct {
+	Exchanges map[string]*models.Exchange        // By exchange name
+	Accounts  map[string]*models.ExchangeAccount // By fixture account key
+}
+
+// Seed inserts fixture rows in dependency order: exchanges, accounts, then trades, positions and
+// funding payments. Seeding the same fixture again is a no-op: exchanges and trades are upserted,
+// and accounts, positions and funding payments that already exist are reused
+func Seed(ctx context.Context, client db.DBClient, fixture Fixture) (*Seeded, error) {
+	seeded := &Seeded{
+		Exchanges: make(map[string]*models.Exchange, len(fixture.Exchanges)),
+		Accounts:  make(map[string]*models.ExchangeAccount, len(fixture.Accounts)),
+	}
+
+	for _, exchange := range fixture.Exchanges {
+		created, err := client.EnsureExchange(ctx, exchange.Name, exchange.DisplayName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seed exchange %s: %w", exchange.Name, err)
+		}
+		seeded.Exchanges[exchange.Name] = created
+	}
+
+	for _, account := range fixture.Accounts {
+		created, err := seedAccount(ctx, client, seeded, account)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seed account %s: %w", account.Key, err)
+		}
+		seeded.Accounts[account.Key] = created
+	}
+
+	if err := seedTrades(ctx, client, seeded, fixture.Trades); err != nil {
+		return nil, err
+	}
+	if err := seedPositions(ctx, client, seeded, fixture.Positions); err != nil {
+		return nil, err
+	}
+	if err := seedFundingPayments(ctx, client, seeded, fixture.FundingPayments); err != nil {
+		return nil, err
+	}
+
+	return seeded, nil
+}
+
+// Truncater deletes every row of a table; HasuraTruncater implements it with a bulk delete mutation
+type Truncater interface {
+	DeleteAll(ctx context.Context, table string) (int, error)
+}
+
+// Truncate deletes every row of tables in the given order (DefaultTables when none are given)
+func Truncate(ctx context.Context, client Truncater, tables ...string) error {
+	if len(tables) == 0 {
+		tables = DefaultTables
+	}
+	for _, table := range tables {
+		if _, err := client.DeleteAll(ctx, table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedAccount returns the existing account with the same exchange and identifier, or creates it
+func seedAccount(ctx context.Context, client db.DBClient, seeded *Seeded, account Account) (*models.ExchangeAccount, error) {
+	exchange, ok := seeded.Exchanges[account.Exchange]
+	if !ok {
+		return nil, fmt.Errorf("unknown exchange %q", account.Exchange)
+	}
+
+	identifier, err := models.NormalizeAccountIdentifier(exchange.Name, account.AccountIdentifier)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := client.ListAccountsFiltered(ctx, db.AccountFilter{ExchangeNames: []string{exchange.Name}})
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range existing {
+		if candidate.AccountIdentifier == identifier {
+			return candidate, nil
+		}
+	}
+
+	return client.CreateAccount(ctx, &models.ExchangeAccountInput{
+		ExchangeID:          exchange.ID,
+		AccountIdentifier:   identifier,
+		AccountType:         account.AccountType,
+		AccountTypeMetadata: account.AccountTypeMetadata,
+	})
+}
+
+// accountID resolves a fixture account key to the seeded account's ID
+func (s *Seeded) accountID(key string) (uuid.UUID, error) {
+	account, ok := s.Accounts[key]
+	if !ok {
+		return uuid.Nil, fmt.Errorf("unknown account %q", key)
+	}
+	id, err := uuid.Parse(account.ID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("account %q has invalid ID: %w", key, err)
+	}
+	return id, nil
+}
+
+// seedTrades inserts trades in one batch; AddTrades skips trades that already exist
+func seedTrades(ctx context.Context, client db.DBClient, seeded *Seeded, trades []Trade) error {
+	if len(trades) == 0 {
+		return nil
+	}
+
+	inputs := make([]*models.TradeInput, len(trades))
+	for i, trade := range trades {
+		accountID, err := seeded.accountID(trade.Account)
+		if err != nil {
+			return fmt.Errorf("failed to seed trade %s: %w", trade.TradeID, err)
+		}
+		input := trade.TradeInput
+		input.ExchangeAccountID = accountID
+		inputs[i] = &input
+	}
+
+	if _, err := client.AddTrades(ctx, inputs); err != nil {
+		return fmt.Errorf("failed to seed trades: %w", err)
+	}
+	return nil
+}
+
+// seedPositions creates positions not already present for the same account, pair, side and start time
+func seedPositions(ctx context.Context, client db.DBClient, seeded *Seeded, positions []Position) error {
+	for _, position := range positions {
+		accountID, err := seeded.accountID(position.Account)
+		if err != nil {
+			return fmt.Errorf("failed to seed position: %w", err)
+		}
+
+		input := position.PositionInput
+		input.ExchangeAccountID = accountID
+
+		existing, err := client.GetPositions(ctx, db.PositionFilter{
+			ExchangeAccountIDs: []uuid.UUID{accountID},
+			BaseAsset:          &input.BaseAsset,
+			QuoteAsset:         &input.QuoteAsset,
+			Side:               &input.Side,
+			StartTimeGte:       &input.StartTime,
+			StartTimeLte:       &input.StartTime,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to seed position: %w", err)
+		}
+		if len(existing) > 0 {
+			continue
+		}
+
+		if _, err := client.CreatePosition(ctx, &input); err != nil {
+			return fmt.Errorf("failed to seed position: %w", err)
+		}
+	}
+	return nil
+}
+
+// seedFundingPayments inserts funding payments whose payment IDs are not yet stored for their account
+func seedFundingPayments(ctx context.Context, client db.DBClient, seeded *Seeded, payments []FundingPayment) error {
+	if len(payments) == 0 {
+		return nil
+	}
+
+	existing := make(map[uuid.UUID]map[string]bool)
+	inputs := make([]*models.FundingPaymentInput, 0, len(payments))
+	for _, payment := range payments {
+		accountID, err := seeded.accountID(payment.Account)
+		if err != nil {
+			return fmt.Errorf("failed to seed funding payment %s: %w", payment.PaymentID, err)
+		}
+
+		if existing[accountID] == nil {
+			stored, err := client.ListFundingPayments(ctx, db.FundingPaymentFilter{ExchangeAccountIDs: []uuid.UUID{accountID}})
+			if err != nil {
+				return fmt.Errorf("failed to seed funding payments: %w", err)
+			}
+			existing[accountID] = make(map[string]bool, len(stored))
+			for _, p := range stored {
+				existing[accountID][p.PaymentID] = true
+			}
+		}
+		if existing[accountID][payment.PaymentID] {
+			continue
+		}
+
+		input := payment.FundingPaymentInput
+		input.ExchangeAccountID = accountID
+		inputs = append(inputs, &input)
+	}
+
+	if len(inputs) == 0 {
+		return nil
+	}
+	if _, err := client.AddFundingPayments(ctx, inputs); err != nil {
+		return fmt.Errorf("failed to seed funding payments: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package dbtest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/db"
+	"github.com/zif-terminal/lib/models"
+)
+
+// memoryDB is an in-memory DBClient covering the methods Seed uses
+// AddTrades skips existing trade IDs like the real on_conflict upsert
+type memoryDB struct {
+	db.DBClient
+	calls     []string
+	exchanges map[string]*models.Exchange
+	accounts  []*models.ExchangeAccount
+	trades    map[string]bool
+	positions []*models.Position
+	payments  []*models.FundingPayment
+	deleted   []string
+}
+
+func newMemoryDB() *memoryDB {
+	return &memoryDB{exchanges: make(map[string]*models.Exchange), trades: make(map[string]bool)}
+}
+
+func (m *memoryDB) EnsureExchange(ctx context.Context, name, displayName string) (*db.Exchange, error) {
+	m.calls = append(m.calls, "EnsureExchange")
+	if m.exchanges[name] == nil {
+		m.exchanges[name] = &models.Exchange{ID: uuid.New().String(), Name: name, DisplayName: displayName}
+	}
+	return m.exchanges[name], nil
+}
+
+func (m *memoryDB) ListAccountsFiltered(ctx context.Context, filter db.AccountFilter) ([]*db.ExchangeAccount, error) {
+	return m.accounts, nil
+}
+
+func (m *memoryDB) CreateAccount(ctx context.Context, input *db.ExchangeAccountInput) (*db.ExchangeAccount, error) {
+	m.calls = append(m.calls, "CreateAccount")
+	account := &models.ExchangeAccount{ID: uuid.New().String(), AccountIdentifier: input.AccountIdentifier, AccountType: input.AccountType}
+	m.accounts = append(m.accounts, account)
+	return account, nil
+}
+
+func (m *memoryDB) AddTrades(ctx context.Context, inputs []*db.TradeInput) ([]*db.Trade, error) {
+	m.calls = append(m.calls, "AddTrades")
+	inserted := make([]*db.Trade, 0, len(inputs))
+	for _, input := range inputs {
+		if input.ExchangeAccountID == uuid.Nil {
+			panic("trade seeded without an account ID")
+		}
+		if m.trades[input.TradeID] {
+			continue
+		}
+		m.trades[input.TradeID] = true
+		inserted = append(inserted, &models.Trade{TradeID: input.TradeID})
+	}
+	return inserted, nil
+}
+
+func (m *memoryDB) GetPositions(ctx context.Context, filter db.PositionFilter) ([]*db.Position, error) {
+	var matches []*db.Position
+	for _, p := range m.positions {
+		if p.BaseAsset == *filter.BaseAsset && p.Side == *filter.Side && p.StartTime.Equal(*filter.StartTimeGte) {
+			matches = append(matches, p)
+		}
+	}
+	return matches, nil
+}
+
+func (m *memoryDB) CreatePosition(ctx context.Context, input *db.PositionInput) (*db.Position, error) {
+	m.calls = append(m.calls, "CreatePosition")
+	position := &models.Position{ID: uuid.New(), BaseAsset: input.BaseAsset, Side: input.Side, StartTime: input.StartTime}
+	m.positions = append(m.positions, position)
+	return position, nil
+}
+
+func (m *memoryDB) ListFundingPayments(ctx context.Context, filter db.FundingPaymentFilter) ([]*db.FundingPayment, error) {
+	return m.payments, nil
+}
+
+func (m *memoryDB) AddFundingPayments(ctx context.Context, inputs []*db.FundingPaymentInput) ([]*db.FundingPayment, error) {
+	m.calls = append(m.calls, "AddFundingPayments")
+	for _, input := range inputs {
+		m.payments = append(m.payments, &models.FundingPayment{PaymentID: input.PaymentID})
+	}
+	return m.payments, nil
+}
+
+func (m *memoryDB) DeleteAll(ctx context.Context, table string) (int, error) {
+	m.deleted = append(m.deleted, table)
+	return 0, nil
+}
+
+func TestSeed_DependencyOrder(t *testing.T) {
+	fixture, err := LoadFixture("testdata/fixture.json")
+	if err != nil {
+		t.Fatalf("LoadFixture failed: %v", err)
+	}
+
+	mem := newMemoryDB()
+	seeded, err := Seed(context.Background(), mem, *fixture)
+	if err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+
+	want := []string{"EnsureExchange", "CreateAccount", "AddTrades", "CreatePosition", "AddFundingPayments"}
+	if !reflect.DeepEqual(mem.calls, want) {
+		t.Errorf("Expected calls %v, got %v", want, mem.calls)
+	}
+	if seeded.Accounts["main"] == nil || seeded.Exchanges["hyperliquid"] == nil {
+		t.Errorf("Expected seeded account and exchange, got %+v", seeded)
+	}
+	if len(mem.trades) != 2 {
+		t.Errorf("Expected 2 trades, got %d", len(mem.trades))
+	}
+}
+
+func TestSeed_Idempotent(t *testing.T) {
+	fixture, err := LoadFixture("testdata/fixture.json")
+	if err != nil {
+		t.Fatalf("LoadFixture failed: %v", err)
+	}
+
+	mem := newMemoryDB()
+	first, err := Seed(context.Background(), mem, *fixture)
+	if err != nil {
+		t.Fatalf("first Seed failed: %v", err)
+	}
+	mem.calls = nil
+
+	second, err := Seed(context.Background(), mem, *fixture)
+	if err != nil {
+		t.Fatalf("second Seed failed: %v", err)
+	}
+
+	// Only the upserting calls repeat; nothing new is created
+	want := []string{"EnsureExchange", "AddTrades"}
+	if !reflect.DeepEqual(mem.calls, want) {
+		t.Errorf("Expected re-seed calls %v, got %v", want, mem.calls)
+	}
+	if first.Accounts["main"].ID != second.Accounts["main"].ID {
+		t.Error("Expected re-seed to reuse the existing account")
+	}
+	if len(mem.accounts) != 1 || len(mem.positions) != 1 || len(mem.payments) != 1 || len(mem.trades) != 2 {
+		t.Errorf("Expected no duplicates, got %d accounts, %d positions, %d payments, %d trades",
+			len(mem.accounts), len(mem.positions), len(mem.payments), len(mem.trades))
+	}
+}
+
+func TestSeed_UnknownAccount(t *testing.T) {
+	fixture := Fixture{Trades: []Trade{{Account: "missing", TradeInput: models.TradeInput{TradeID: "t1"}}}}
+	if _, err := Seed(context.Background(), newMemoryDB(), fixture); err == nil {
+		t.Fatal("Expected error for a trade referencing an unknown account")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	mem := newMemoryDB()
+	if err := Truncate(context.Background(), mem); err != nil {
+		t.Fatalf("Truncate failed: %v", err)
+	}
+	if !reflect.DeepEqual(mem.deleted, DefaultTables) {
+		t.Errorf("Expected tables %v, got %v", DefaultTables, mem.deleted)
+	}
+
+	mem.deleted = nil
+	if err := Truncate(context.Background(), mem, "trades"); err != nil {
+		t.Fatalf("Truncate failed: %v", err)
+	}
+	if !reflect.DeepEqual(mem.deleted, []string{"trades"}) {
+		t.Errorf("Expected only trades, got %v", mem.deleted)
+	}
+}
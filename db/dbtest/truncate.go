@@ -0,0 +1,66 @@
+package dbtest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/db"
+)
+
+// deletableTables are the tables HasuraTruncater may clear
+var deletableTables = map[string]bool{
+	"exchanges":         true,
+	"exchange_accounts": true,
+	"trades":            true,
+	"orders":            true,
+	"funding_payments":  true,
+	"positions":         true,
+	"position_trades":   true,
+	"sync_runs":         true,
+	"dead_letters":      true,
+	"asset_aliases":     true,
+}
+
+// HasuraTruncater is a Truncater sending bulk delete mutations straight to Hasura, so the
+// production db.Client carries no delete-everything method. Requests are never retried
+// Clients with a ReferenceCacheTTL may keep serving deleted exchanges until their entries expire
+type HasuraTruncater struct {
+	client *graphql.Client
+	secret string
+}
+
+// NewTruncater creates a HasuraTruncater for the write endpoint (WriteURL, else URL) and admin
+// secret of config
+func NewTruncater(config db.ClientConfig) *HasuraTruncater {
+	url := config.WriteURL
+	if url == "" {
+		url = config.URL
+	}
+	return &HasuraTruncater{client: graphql.NewClient(url), secret: config.AdminSecret}
+}
+
+// DeleteAll deletes every row of table and returns the number of rows removed
+func (t *HasuraTruncater) DeleteAll(ctx context.Context, table string) (int, error) {
+	if !deletableTables[table] {
+		return 0, fmt.Errorf("failed to delete all rows: unknown table %q", table)
+	}
+
+	req := graphql.NewRequest(fmt.Sprintf(`
+		mutation DeleteAll {
+			delete_%s(where: {}) {
+				affected_rows
+			}
+		}
+	`, table))
+	req.Header.Set("X-Hasura-Admin-Secret", t.secret)
+
+	var resp map[string]struct {
+		AffectedRows int `json:"affected_rows"`
+	}
+	if err := t.client.Run(ctx, req, &resp); err != nil {
+		return 0, fmt.Errorf("failed to delete all rows from %s: %w", table, err)
+	}
+
+	return resp["delete_"+table].AffectedRows, nil
+}
@@ -0,0 +1,53 @@
+package dbtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zif-terminal/lib/db"
+)
+
+func TestHasuraTruncater_DeleteAll(t *testing.T) {
+	var query, secret string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query string `json:"query"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		query, secret = body.Query, r.Header.Get("X-Hasura-Admin-Secret")
+		w.Write([]byte(`{"data": {"delete_trades": {"affected_rows": 3}}}`))
+	}))
+	defer server.Close()
+
+	truncater := NewTruncater(db.ClientConfig{URL: server.URL, AdminSecret: "test-secret"})
+
+	deleted, err := truncater.DeleteAll(context.Background(), "trades")
+	if err != nil {
+		t.Fatalf("DeleteAll failed: %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("Expected 3 deleted rows, got %d", deleted)
+	}
+	if !strings.Contains(query, "delete_trades(where: {})") {
+		t.Errorf("Expected bulk delete mutation, got %s", query)
+	}
+	if secret != "test-secret" {
+		t.Errorf("Expected the admin secret header, got %q", secret)
+	}
+}
+
+func TestHasuraTruncater_UnknownTable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no request for an unknown table")
+	}))
+	defer server.Close()
+
+	truncater := NewTruncater(db.ClientConfig{URL: server.URL})
+	if _, err := truncater.DeleteAll(context.Background(), "users; drop"); err == nil {
+		t.Fatal("Expected error for unknown table")
+	}
+}
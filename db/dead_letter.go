@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/models"
+)
+
+func init() {
+	registerOperations(map[string]Idempotency{
+		"AddDeadLetters":    NotIdempotent, // Plain insert; a retried insert records the row twice
+		"ResolveDeadLetter": Idempotent,    // Update by primary key
+	})
+}
+
+// DeadLetter represents a dead-lettered row (aliased from models package)
+type DeadLetter = models.DeadLetter
+
+// DeadLetterInput represents dead letter input for mutations (aliased from models package)
+type DeadLetterInput = models.DeadLetterInput
+
+// AddDeadLetters records rows that repeatedly failed to persist
+// Batches over ClientConfig.MaxRequestBytes are split into several requests
+func (c *Client) AddDeadLetters(ctx context.Context, inputs []*DeadLetterInput) ([]*DeadLetter, error) {
+	if len(inputs) == 0 {
+		return []*DeadLetter{}, nil
+	}
+
+	query := `
+		mutation AddDeadLetters($objects: [dead_letters_insert_input!]!) {
+			insert_dead_letters(objects: $objects) {
+				returning {
+					id
+					exchange_account_id
+					kind
+					row_key
+					payload
+					error
+					attempts
+					resolved_at
+					created_at
+				}
+			}
+		}
+	`
+
+	objects := make([]map[string]interface{}, len(inputs))
+	for i, input := range inputs {
+		if err := input.Validate(); err != nil {
+			return nil, fmt.Errorf("failed to add dead letters: input %d: %w", i, err)
+		}
+		objects[i] = map[string]interface{}{
+			"exchange_account_id": uuidVar(input.ExchangeAccountID),
+			"kind":                input.Kind,
+			"row_key":             input.RowKey,
+			"payload":             input.Payload,
+			"error":               input.Error,
+			"attempts":            input.Attempts,
+		}
+	}
+
+	inserted, err := insertChunked(c, query, objects, func(req *request) ([]*DeadLetter, error) {
+		var resp struct {
+			InsertDeadLetters struct {
+				Returning []*DeadLetter `json:"returning"`
+			} `json:"insert_dead_letters"`
+		}
+		if err := c.execute(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+		return resp.InsertDeadLetters.Returning, nil
+	})
+	if err != nil {
+		return inserted, fmt.Errorf("failed to add dead letters: %w", err)
+	}
+
+	return inserted, nil
+}
+
+// ListDeadLetters retrieves an account's dead letters (newest first)
+// Resolved dead letters are only included when includeResolved is set
+func (c *Client) ListDeadLetters(ctx context.Context, exchangeAccountID uuid.UUID, includeResolved bool) ([]*DeadLetter, error) {
+	where := "exchange_account_id: { _eq: $exchange_account_id }"
+	if !includeResolved {
+		where += ", resolved_at: { _is_null: true }"
+	}
+
+	query := fmt.Sprintf(`
+		query ListDeadLetters($exchange_account_id: uuid!) {
+			dead_letters(
+				where: { %s }
+				order_by: [{ created_at: desc }, { id: desc }]
+			) {
+				id
+				exchange_account_id
+				kind
+				row_key
+				payload
+				error
+				attempts
+				resolved_at
+				created_at
+			}
+		}
+	`, where)
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"exchange_account_id": uuidVar(exchangeAccountID),
+	})
+
+	var resp struct {
+		DeadLetters []*DeadLetter `json:"dead_letters"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	return resp.DeadLetters, nil
+}
+
+// ResolveDeadLetter marks a dead letter as handled, e.g. once its row has been fixed and stored
+func (c *Client) ResolveDeadLetter(ctx context.Context, id uuid.UUID) (*DeadLetter, error) {
+	query := `
+		mutation ResolveDeadLetter($id: uuid!, $resolved_at: bigint!) {
+			update_dead_letters_by_pk(pk_columns: {id: $id}, _set: {resolved_at: $resolved_at}) {
+				id
+				exchange_account_id
+				kind
+				row_key
+				payload
+				error
+				attempts
+				resolved_at
+				created_at
+			}
+		}
+	`
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"id":          uuidVar(id),
+		"resolved_at": c.clock.Now().UnixMilli(),
+	})
+
+	var resp struct {
+		UpdateDeadLettersByPk *DeadLetter `json:"update_dead_letters_by_pk"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to resolve dead letter: %w", err)
+	}
+
+	if resp.UpdateDeadLettersByPk == nil {
+		return nil, fmt.Errorf("dead letter not found: %s", id)
+	}
+
+	return resp.UpdateDeadLettersByPk, nil
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/clock"
+)
+
+const deadLetterRow = `{
+	"id": "770e8400-e29b-41d4-a716-446655440000",
+	"exchange_account_id": "550e8400-e29b-41d4-a716-446655440000",
+	"kind": "trades", "row_key": "t17", "payload": {"trade_id": "t17", "quantity": "1e"},
+	"error": "invalid input syntax for type numeric", "attempts": 3,
+	"resolved_at": %s, "created_at": "2023-11-14T22:13:21Z"
+}`
+
+func TestClient_AddDeadLetters(t *testing.T) {
+	accountID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	payload := json.RawMessage(`{"trade_id":"t17","quantity":"1e"}`)
+
+	var objects []map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			objects = requestFromContext(ctx).vars["objects"].([]map[string]interface{})
+			return json.Unmarshal([]byte(`{"insert_dead_letters": {"returning": [`+strings.Replace(deadLetterRow, "%s", "null", 1)+`]}}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	letters, err := client.AddDeadLetters(context.Background(), []*DeadLetterInput{{
+		ExchangeAccountID: accountID,
+		Kind:              "trades",
+		RowKey:            "t17",
+		Payload:           payload,
+		Error:             "invalid input syntax for type numeric",
+		Attempts:          3,
+	}})
+	if err != nil {
+		t.Fatalf("AddDeadLetters failed: %v", err)
+	}
+
+	if len(objects) != 1 || objects[0]["row_key"] != "t17" || objects[0]["attempts"] != 3 {
+		t.Errorf("Unexpected objects: %+v", objects)
+	}
+	if sent, _ := json.Marshal(objects[0]["payload"]); string(sent) != string(payload) {
+		t.Errorf("Expected the payload to be sent as JSON, got %s", sent)
+	}
+
+	if len(letters) != 1 {
+		t.Fatalf("Expected 1 dead letter, got %d", len(letters))
+	}
+	letter := letters[0]
+	if letter.RowKey != "t17" || letter.Attempts != 3 || letter.ResolvedAt != nil || letter.CreatedAt.IsZero() {
+		t.Errorf("Unexpected decoded dead letter: %+v", letter)
+	}
+	if !strings.Contains(string(letter.Payload), `"quantity": "1e"`) {
+		t.Errorf("Expected the stored payload, got %s", letter.Payload)
+	}
+}
+
+func TestClient_AddDeadLetters_ValidatesInput(t *testing.T) {
+	calls := 0
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			calls++
+			return nil
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	_, err := client.AddDeadLetters(context.Background(), []*DeadLetterInput{{
+		ExchangeAccountID: uuid.New(), Kind: "trades", RowKey: "t1", Payload: json.RawMessage(`{`), Attempts: 1,
+	}})
+	if err == nil || !strings.Contains(err.Error(), "payload") {
+		t.Errorf("Expected validation error for payload, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected invalid input not to be sent, got %d calls", calls)
+	}
+}
+
+func TestClient_ListDeadLetters(t *testing.T) {
+	var queries []string
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			queries = append(queries, requestFromContext(ctx).query)
+			return json.Unmarshal([]byte(`{"dead_letters": [`+strings.Replace(deadLetterRow, "%s", "1700000000000", 1)+`]}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+	ctx := context.Background()
+
+	if _, err := client.ListDeadLetters(ctx, uuid.New(), false); err != nil {
+		t.Fatalf("ListDeadLetters failed: %v", err)
+	}
+	letters, err := client.ListDeadLetters(ctx, uuid.New(), true)
+	if err != nil {
+		t.Fatalf("ListDeadLetters failed: %v", err)
+	}
+
+	if !strings.Contains(queries[0], "resolved_at: { _is_null: true }") {
+		t.Errorf("Expected resolved dead letters to be excluded, got %s", queries[0])
+	}
+	if strings.Contains(queries[1], "_is_null") {
+		t.Errorf("Expected resolved dead letters to be included, got %s", queries[1])
+	}
+	if len(letters) != 1 || letters[0].ResolvedAt == nil || !letters[0].ResolvedAt.Equal(time.UnixMilli(1700000000000)) {
+		t.Errorf("Expected a decoded resolved_at, got %+v", letters)
+	}
+}
+
+func TestClient_ResolveDeadLetter(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	found := true
+	var vars map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			vars = requestFromContext(ctx).vars
+			if !found {
+				return json.Unmarshal([]byte(`{"update_dead_letters_by_pk": null}`), resp)
+			}
+			return json.Unmarshal([]byte(`{"update_dead_letters_by_pk": `+strings.Replace(deadLetterRow, "%s", "1700000000000", 1)+`}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{Clock: clock.NewFake(now)})
+	id := uuid.MustParse("770e8400-e29b-41d4-a716-446655440000")
+
+	letter, err := client.ResolveDeadLetter(context.Background(), id)
+	if err != nil {
+		t.Fatalf("ResolveDeadLetter failed: %v", err)
+	}
+	if vars["id"] != id.String() || vars["resolved_at"] != now.UnixMilli() {
+		t.Errorf("Expected the id and the clock's time, got %+v", vars)
+	}
+	if letter.ResolvedAt == nil || !letter.ResolvedAt.Equal(now) {
+		t.Errorf("Expected the dead letter to be resolved, got %+v", letter)
+	}
+
+	found = false
+	if _, err := client.ResolveDeadLetter(context.Background(), id); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"bufio"
+	"go/build"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// modulePath is the import path of this module
+const modulePath = "github.com/zif-terminal/lib"
+
+// corePackages are the packages tools embed on their own (e.g. an ops CLI using GetClient and
+// db.Client), relative to the module root
+var corePackages = []string{"db", "exchange", "exchange/hyperliquid"}
+
+// coreDependencyBudget lists the modules the core packages may import, directly or through other
+// packages of this module. Optional integrations such as metrics, tracing or streaming transports
+// plug in through narrow interfaces (Tracer, MetricsHook, sync.Counter) with their adapters kept
+// in packages the core never imports, so embedding the core stays this small
+var coreDependencyBudget = map[string]bool{
+	"github.com/google/uuid":        true,
+	"github.com/machinebox/graphql": true,
+	"golang.org/x/crypto":           true, // Keccak-256 for EIP-55 address checksums (models)
+}
+
+// forbiddenDependencies are import path fragments that must never reach the core packages
+var forbiddenDependencies = []string{"github.com/prometheus/", "go.opentelemetry.io/", "websocket"}
+
+// requiredModules parses the module paths of go.mod's require directives
+func requiredModules(t *testing.T, goMod string) []string {
+	t.Helper()
+	f, err := os.Open(goMod)
+	if err != nil {
+		t.Fatalf("Failed to open go.mod: %v", err)
+	}
+	defer f.Close()
+
+	var modules []string
+	inBlock := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "require (":
+			inBlock = true
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "" && !strings.HasPrefix(line, "//"):
+			modules = append(modules, strings.Fields(line)[0])
+		case strings.HasPrefix(line, "require "):
+			modules = append(modules, strings.Fields(line)[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Failed to read go.mod: %v", err)
+	}
+	return modules
+}
+
+// externalImports walks the non-test imports of pkgs and of every package of this module they
+// reach, and returns each import outside the module and the standard library with its importers
+func externalImports(t *testing.T, root string, pkgs []string) map[string][]string {
+	t.Helper()
+	external := make(map[string][]string)
+	seen := make(map[string]bool)
+	queue := append([]string(nil), pkgs...)
+	for len(queue) > 0 {
+		rel := queue[0]
+		queue = queue[1:]
+		if seen[rel] {
+			continue
+		}
+		seen[rel] = true
+
+		pkg, err := build.Default.ImportDir(filepath.Join(root, rel), 0)
+		if err != nil {
+			t.Fatalf("Failed to read package %s: %v", rel, err)
+		}
+		for _, imp := range pkg.Imports {
+			switch {
+			case strings.HasPrefix(imp, modulePath+"/"):
+				queue = append(queue, strings.TrimPrefix(imp, modulePath+"/"))
+			case !strings.Contains(strings.Split(imp, "/")[0], "."):
+				// Standard library
+			default:
+				external[imp] = append(external[imp], rel)
+			}
+		}
+	}
+	return external
+}
+
+// moduleOf returns the module of modules that provides the package imp ("" if none does)
+func moduleOf(imp string, modules []string) string {
+	best := ""
+	for _, module := range modules {
+		if (imp == module || strings.HasPrefix(imp, module+"/")) && len(module) > len(best) {
+			best = module
+		}
+	}
+	return best
+}
+
+func TestCorePackages_DependencyBudget(t *testing.T) {
+	root := ".."
+	modules := requiredModules(t, filepath.Join(root, "go.mod"))
+	external := externalImports(t, root, corePackages)
+
+	if _, ok := external["github.com/machinebox/graphql"]; !ok {
+		t.Fatalf("Expected the walk to reach the GraphQL client, got %v", external)
+	}
+
+	imports := make([]string, 0, len(external))
+	for imp := range external {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+
+	for _, imp := range imports {
+		for _, fragment := range forbiddenDependencies {
+			if strings.Contains(imp, fragment) {
+				t.Errorf("%s is imported by %v; move the integration behind an interface in a separate package", imp, external[imp])
+			}
+		}
+		module := moduleOf(imp, modules)
+		if module == "" {
+			t.Errorf("%s (imported by %v) is not provided by any module in go.mod", imp, external[imp])
+			continue
+		}
+		if !coreDependencyBudget[module] {
+			t.Errorf("%s (imported by %v) is outside the core dependency budget %v", module, external[imp], coreDependencyBudget)
+		}
+	}
+}
+
+// TestDB_DoesNotImportExchange keeps the storage layer independent of the exchange abstraction;
+// types both need belong in models
+func TestDB_DoesNotImportExchange(t *testing.T) {
+	root := ".."
+	seen := map[string]bool{}
+	queue := []string{"db"}
+	for len(queue) > 0 {
+		rel := queue[0]
+		queue = queue[1:]
+		if seen[rel] {
+			continue
+		}
+		seen[rel] = true
+
+		pkg, err := build.Default.ImportDir(filepath.Join(root, rel), 0)
+		if err != nil {
+			t.Fatalf("Failed to read package %s: %v", rel, err)
+		}
+		for _, imp := range pkg.Imports {
+			if !strings.HasPrefix(imp, modulePath+"/") {
+				continue
+			}
+			dep := strings.TrimPrefix(imp, modulePath+"/")
+			if dep == "exchange" || strings.HasPrefix(dep, "exchange/") {
+				t.Errorf("%s imports %s; move the shared type into models", rel, imp)
+			}
+			queue = append(queue, dep)
+		}
+	}
+}
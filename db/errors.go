@@ -0,0 +1,132 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// GraphQLErrorDetail is a single entry of a GraphQL response's "errors" array
+type GraphQLErrorDetail struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`       // Response field the error applies to
+	Extensions map[string]interface{} `json:"extensions,omitempty"` // Hasura puts "code" and "path" here
+
+	Constraint      string `json:"-"` // Constraint named by a violation message (empty otherwise)
+	FriendlyMessage string `json:"-"` // Operator-facing message for Constraint, if it is a known one
+}
+
+// GraphQLError is returned when a response carries GraphQL errors
+// If the response also carried data, it has been decoded anyway and Partial is set,
+// so callers of execute can use what succeeded and decide whether the errors matter
+type GraphQLError struct {
+	Operation string
+	Errors    []GraphQLErrorDetail
+	Partial   bool // Data was present and decoded alongside the errors
+}
+
+func (e *GraphQLError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, detail := range e.Errors {
+		messages[i] = detail.Message
+		if detail.FriendlyMessage != "" {
+			messages[i] = fmt.Sprintf("%s (%s)", detail.FriendlyMessage, detail.Constraint)
+		}
+	}
+	msg := fmt.Sprintf("graphql: %s", strings.Join(messages, "; "))
+	if e.Partial {
+		msg += " (partial data returned)"
+	}
+	return msg
+}
+
+// HTTPStatusError is returned when the GraphQL endpoint answers with a non-2xx status, e.g. a
+// 5xx while Hasura is down or a 401 for a wrong admin secret
+type HTTPStatusError struct {
+	StatusCode int
+	Body       string // Start of the response body, truncated to 200 bytes
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("graphql: server returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// DuplicateError is returned when an insert violates a unique constraint, so callers can treat
+// re-inserting an existing row as a no-op
+type DuplicateError struct {
+	Resource string // Kind of row, e.g. "trade"
+	Key      string // Natural key of the existing row, e.g. the exchange trade ID
+	Err      error  // Underlying *GraphQLError
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("duplicate %s: %s already exists", e.Resource, e.Key)
+}
+
+func (e *DuplicateError) Unwrap() error {
+	return e.Err
+}
+
+// MissingIDsError is returned by strict lookups when some requested IDs have no row
+type MissingIDsError struct {
+	Resource string      // Kind of row, e.g. "trade"
+	IDs      []uuid.UUID // Requested IDs without a row, in request order
+}
+
+func (e *MissingIDsError) Error() string {
+	ids := make([]string, len(e.IDs))
+	for i, id := range e.IDs {
+		ids[i] = id.String()
+	}
+	return fmt.Sprintf("%d %s(s) not found: %s", len(e.IDs), e.Resource, strings.Join(ids, ", "))
+}
+
+// MissingColumnError is returned when a configured column (e.g. ClientConfig.ExchangeActiveColumn)
+// does not exist in the Hasura schema, so callers can fall back to an unfiltered query
+type MissingColumnError struct {
+	Table  string
+	Column string
+	Err    error // Underlying *GraphQLError
+}
+
+func (e *MissingColumnError) Error() string {
+	return fmt.Sprintf("column %s.%s does not exist", e.Table, e.Column)
+}
+
+func (e *MissingColumnError) Unwrap() error {
+	return e.Err
+}
+
+// isMissingField reports whether err carries Hasura's validation error for an unknown field name
+func isMissingField(err error, field string) bool {
+	var gqlErr *GraphQLError
+	if !errors.As(err, &gqlErr) {
+		return false
+	}
+	for _, detail := range gqlErr.Errors {
+		code, _ := detail.Extensions["code"].(string)
+		if code == "validation-failed" && strings.Contains(detail.Message, fmt.Sprintf("field '%s' not found", field)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUniqueViolation reports whether err carries Hasura's constraint-violation error for a unique
+// constraint (foreign key and check violations share the code but not the message)
+func isUniqueViolation(err error) bool {
+	var gqlErr *GraphQLError
+	if !errors.As(err, &gqlErr) {
+		return false
+	}
+	for _, detail := range gqlErr.Errors {
+		code, _ := detail.Extensions["code"].(string)
+		message := strings.ToLower(detail.Message)
+		if code == "constraint-violation" && (strings.Contains(message, "uniqueness violation") || strings.Contains(message, "duplicate key")) {
+			return true
+		}
+	}
+	return false
+}
@@ -7,14 +7,30 @@ import (
 	"github.com/zif-terminal/lib/models"
 )
 
+func init() {
+	registerOperations(map[string]Idempotency{
+		"CreateExchange": NotIdempotent, // Plain insert
+		"UpdateExchange": Idempotent,    // Update by primary key
+		"EnsureExchange": Idempotent,    // Conflicting names are ignored
+	})
+}
+
 // Exchange represents an exchange model (aliased from models package)
 type Exchange = models.Exchange
 
 // ExchangeInput represents exchange input for mutations (aliased from models package)
 type ExchangeInput = models.ExchangeInput
 
+// DefaultExchangeActiveColumn is the boolean exchanges column ListExchanges filters on when activeOnly is set
+const DefaultExchangeActiveColumn = "enabled"
+
 // GetExchange retrieves a single exchange by ID
+// Within a context from WithAccountCache, repeated lookups are answered from the cache
 func (c *Client) GetExchange(ctx context.Context, id string) (*Exchange, error) {
+	if exchange, ok := cachedExchange(ctx, id); ok {
+		return exchange, nil
+	}
+
 	query := `
 		query GetExchange($id: uuid!) {
 			exchanges_by_pk(id: $id) {
@@ -41,36 +57,66 @@ func (c *Client) GetExchange(ctx context.Context, id string) (*Exchange, error)
 		return nil, fmt.Errorf("exchange not found: %s", id)
 	}
 
+	cacheExchange(ctx, resp.ExchangesByPk)
 	return resp.ExchangesByPk, nil
 }
 
-// ListExchanges retrieves all exchanges
-func (c *Client) ListExchanges(ctx context.Context) ([]*Exchange, error) {
-	query := `
+// ListExchanges retrieves all exchanges ordered by display name
+// When activeOnly is set, only exchanges whose active column (ClientConfig.ExchangeActiveColumn) is true are returned;
+// if the schema has no such column the error is a *MissingColumnError
+func (c *Client) ListExchanges(ctx context.Context, activeOnly bool) ([]*Exchange, error) {
+	where := ""
+	if activeOnly {
+		where = fmt.Sprintf("where: { %s: { _eq: true } }", c.exchangeActiveColumn())
+	}
+
+	query := fmt.Sprintf(`
 		query ListExchanges {
-			exchanges {
+			exchanges(
+				%s
+				order_by: [{ display_name: asc }, { id: asc }]
+			) {
 				id
 				name
 				display_name
 			}
 		}
-	`
+	`, where)
 
-	req := c.graphqlRequest(query)
+	exchanges, err := cachedReference(ctx, c, cacheGroupExchanges, referenceKey(query, nil), func(ctx context.Context) ([]*Exchange, error) {
+		req := c.graphqlRequest(query)
 
-	var resp struct {
-		Exchanges []*Exchange `json:"exchanges"`
-	}
+		var resp struct {
+			Exchanges []*Exchange `json:"exchanges"`
+		}
 
-	if err := c.execute(ctx, req, &resp); err != nil {
+		if err := c.execute(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+		return resp.Exchanges, nil
+	})
+	if err != nil {
+		if activeOnly && isMissingField(err, c.exchangeActiveColumn()) {
+			err = &MissingColumnError{Table: "exchanges", Column: c.exchangeActiveColumn(), Err: err}
+		}
 		return nil, fmt.Errorf("failed to list exchanges: %w", err)
 	}
 
-	return resp.Exchanges, nil
+	return cloneExchanges(exchanges), nil
+}
+
+// exchangeActiveColumn returns the configured exchanges active column, or DefaultExchangeActiveColumn
+func (c *Client) exchangeActiveColumn() string {
+	if c.config.ExchangeActiveColumn != "" {
+		return c.config.ExchangeActiveColumn
+	}
+	return DefaultExchangeActiveColumn
 }
 
 // CreateExchange creates a new exchange
 func (c *Client) CreateExchange(ctx context.Context, input *ExchangeInput) (*Exchange, error) {
+	defer c.invalidateReferences(cacheGroupExchanges)
+
 	query := `
 		mutation CreateExchange($name: String!, $display_name: String!) {
 			insert_exchanges_one(object: {
@@ -106,6 +152,10 @@ func (c *Client) CreateExchange(ctx context.Context, input *ExchangeInput) (*Exc
 
 // UpdateExchange updates an existing exchange
 func (c *Client) UpdateExchange(ctx context.Context, id string, input *ExchangeInput) (*Exchange, error) {
+	evictExchange(ctx, id)
+
+	defer c.invalidateReferences(cacheGroupExchanges)
+
 	query := `
 		mutation UpdateExchange($id: uuid!, $name: String!, $display_name: String!) {
 			update_exchanges_by_pk(pk_columns: {id: $id}, _set: {
@@ -139,3 +189,100 @@ func (c *Client) UpdateExchange(ctx context.Context, id string, input *ExchangeI
 
 	return resp.UpdateExchangesByPk, nil
 }
+
+// EnsureExchange returns the exchange with the given name, creating it if it does not exist
+// Concurrent callers are safe: a create that loses the race on the unique name is ignored
+// and the row written by the winner is returned
+func (c *Client) EnsureExchange(ctx context.Context, name, displayName string) (*Exchange, error) {
+	exchange, err := c.getExchangeByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ensure exchange: %w", err)
+	}
+	if exchange != nil {
+		return exchange, nil
+	}
+
+	query := `
+		mutation EnsureExchange($name: String!, $display_name: String!) {
+			insert_exchanges_one(
+				object: {
+					name: $name
+					display_name: $display_name
+				}
+				on_conflict: { constraint: exchanges_name_key, update_columns: [] }
+			) {
+				id
+				name
+				display_name
+			}
+		}
+	`
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"name":         name,
+		"display_name": displayName,
+	})
+
+	var resp struct {
+		InsertExchangesOne *Exchange `json:"insert_exchanges_one"`
+	}
+
+	defer c.invalidateReferences(cacheGroupExchanges)
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to ensure exchange: %w", err)
+	}
+
+	if resp.InsertExchangesOne != nil {
+		return resp.InsertExchangesOne, nil
+	}
+
+	// Another caller created it between the lookup and the insert
+	exchange, err = c.getExchangeByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ensure exchange: %w", err)
+	}
+	if exchange == nil {
+		return nil, fmt.Errorf("failed to ensure exchange: %s not found after insert", name)
+	}
+
+	return exchange, nil
+}
+
+// getExchangeByName retrieves an exchange by its unique name, returning nil if it does not exist
+func (c *Client) getExchangeByName(ctx context.Context, name string) (*Exchange, error) {
+	query := `
+		query GetExchangeByName($name: String!) {
+			exchanges(where: { name: { _eq: $name } }, limit: 1) {
+				id
+				name
+				display_name
+			}
+		}
+	`
+
+	vars := map[string]interface{}{
+		"name": name,
+	}
+
+	exchanges, err := cachedReference(ctx, c, cacheGroupExchanges, referenceKey(query, vars), func(ctx context.Context) ([]*Exchange, error) {
+		req := c.graphqlRequestWithVars(query, vars)
+
+		var resp struct {
+			Exchanges []*Exchange `json:"exchanges"`
+		}
+
+		if err := c.execute(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+		return resp.Exchanges, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(exchanges) == 0 {
+		return nil, nil
+	}
+
+	return cloneExchanges(exchanges[:1])[0], nil
+}
@@ -3,6 +3,8 @@ package db
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/machinebox/graphql"
@@ -112,7 +114,7 @@ func TestClient_ListExchanges(t *testing.T) {
 		AdminSecret: "test-secret",
 	})
 
-	exchanges, err := client.ListExchanges(ctx)
+	exchanges, err := client.ListExchanges(ctx, false)
 	if err != nil {
 		t.Fatalf("ListExchanges failed: %v", err)
 	}
@@ -131,6 +133,79 @@ func TestClient_ListExchanges(t *testing.T) {
 	}
 }
 
+func TestClient_ListExchanges_OrderAndActiveFilter(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		activeOnly bool
+		column     string
+		wantWhere  string
+	}{
+		{name: "all exchanges", activeOnly: false},
+		{name: "active only uses default column", activeOnly: true, wantWhere: "where: { enabled: { _eq: true } }"},
+		{name: "active only uses configured column", activeOnly: true, column: "active", wantWhere: "where: { active: { _eq: true } }"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query string
+			mockClient := &mockGraphQLClient{
+				runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+					query = requestFromContext(ctx).query
+					return json.Unmarshal([]byte(`{"exchanges": []}`), resp)
+				},
+			}
+
+			client := NewClientWithGraphQL(mockClient, ClientConfig{
+				URL:                  "http://localhost:8080/v1/graphql",
+				AdminSecret:          "test-secret",
+				ExchangeActiveColumn: tt.column,
+			})
+
+			if _, err := client.ListExchanges(ctx, tt.activeOnly); err != nil {
+				t.Fatalf("ListExchanges failed: %v", err)
+			}
+
+			if !strings.Contains(query, "order_by: [{ display_name: asc }, { id: asc }]") {
+				t.Errorf("Expected query to order by display_name, got: %s", query)
+			}
+			if tt.wantWhere == "" {
+				if strings.Contains(query, "where:") {
+					t.Errorf("Expected no where clause, got: %s", query)
+				}
+			} else if !strings.Contains(query, tt.wantWhere) {
+				t.Errorf("Expected query to contain %q, got: %s", tt.wantWhere, query)
+			}
+		})
+	}
+}
+
+func TestClient_ListExchanges_MissingActiveColumn(t *testing.T) {
+	client := NewClientWithGraphQL(rawResponse(`{"errors":[{"message":"field 'is_active' not found in type: 'exchanges_bool_exp'","extensions":{"code":"validation-failed","path":"$.selectionSet.exchanges.args.where.is_active"}}]}`),
+		ClientConfig{ExchangeActiveColumn: "is_active"})
+
+	_, err := client.ListExchanges(context.Background(), true)
+	var missing *MissingColumnError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected a MissingColumnError, got %v", err)
+	}
+	if missing.Table != "exchanges" || missing.Column != "is_active" {
+		t.Errorf("Expected exchanges.is_active, got %s.%s", missing.Table, missing.Column)
+	}
+	var gqlErr *GraphQLError
+	if !errors.As(err, &gqlErr) {
+		t.Errorf("Expected the GraphQL error to stay reachable, got %v", err)
+	}
+
+	// Other validation errors are passed through unchanged
+	client = NewClientWithGraphQL(rawResponse(`{"errors":[{"message":"field 'display_name' not found in type: 'exchanges'","extensions":{"code":"validation-failed"}}]}`),
+		ClientConfig{ExchangeActiveColumn: "is_active"})
+	if _, err := client.ListExchanges(context.Background(), true); err == nil || errors.As(err, &missing) {
+		t.Errorf("Expected a plain GraphQL error, got %v", err)
+	}
+}
+
 func TestClient_CreateExchange(t *testing.T) {
 	ctx := context.Background()
 	input := &models.ExchangeInput{
@@ -249,3 +324,94 @@ func TestClient_UpdateExchange_NotFound(t *testing.T) {
 		t.Errorf("Expected 'exchange not found' error, got: %v", err)
 	}
 }
+
+// ensureExchangeMock answers EnsureExchange's lookup and insert from scripted responses
+func ensureExchangeMock(lookups [][]*models.Exchange, inserted *models.Exchange, ops *[]string) *mockGraphQLClient {
+	return &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			op := requestFromContext(ctx).opName
+			*ops = append(*ops, op)
+
+			var respData map[string]interface{}
+			switch op {
+			case "GetExchangeByName":
+				respData = map[string]interface{}{"exchanges": lookups[0]}
+				lookups = lookups[1:]
+			case "EnsureExchange":
+				respData = map[string]interface{}{"insert_exchanges_one": inserted}
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+}
+
+func TestClient_EnsureExchange_Creates(t *testing.T) {
+	created := &models.Exchange{ID: "exchange-1", Name: "hyperliquid", DisplayName: "Hyperliquid"}
+
+	var ops []string
+	mockClient := ensureExchangeMock([][]*models.Exchange{{}}, created, &ops)
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	exchange, err := client.EnsureExchange(context.Background(), "hyperliquid", "Hyperliquid")
+	if err != nil {
+		t.Fatalf("EnsureExchange failed: %v", err)
+	}
+
+	if exchange.ID != created.ID {
+		t.Errorf("Expected ID %s, got %s", created.ID, exchange.ID)
+	}
+	if len(ops) != 2 || ops[0] != "GetExchangeByName" || ops[1] != "EnsureExchange" {
+		t.Errorf("Expected lookup then insert, got %v", ops)
+	}
+}
+
+func TestClient_EnsureExchange_AlreadyExists(t *testing.T) {
+	existing := &models.Exchange{ID: "exchange-1", Name: "hyperliquid", DisplayName: "Hyperliquid"}
+
+	var ops []string
+	mockClient := ensureExchangeMock([][]*models.Exchange{{existing}}, nil, &ops)
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	exchange, err := client.EnsureExchange(context.Background(), "hyperliquid", "Hyperliquid")
+	if err != nil {
+		t.Fatalf("EnsureExchange failed: %v", err)
+	}
+
+	if exchange.ID != existing.ID {
+		t.Errorf("Expected ID %s, got %s", existing.ID, exchange.ID)
+	}
+	if len(ops) != 1 {
+		t.Errorf("Expected a single lookup, got %v", ops)
+	}
+}
+
+func TestClient_EnsureExchange_ConcurrentCreate(t *testing.T) {
+	winner := &models.Exchange{ID: "exchange-1", Name: "hyperliquid", DisplayName: "Hyperliquid"}
+
+	// Lookup misses, the insert conflicts (returns null), the second lookup sees the winner's row
+	var ops []string
+	mockClient := ensureExchangeMock([][]*models.Exchange{{}, {winner}}, nil, &ops)
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	exchange, err := client.EnsureExchange(context.Background(), "hyperliquid", "Hyperliquid")
+	if err != nil {
+		t.Fatalf("EnsureExchange failed: %v", err)
+	}
+
+	if exchange.ID != winner.ID {
+		t.Errorf("Expected ID %s, got %s", winner.ID, exchange.ID)
+	}
+	if len(ops) != 3 {
+		t.Errorf("Expected lookup, insert, lookup, got %v", ops)
+	}
+}
@@ -3,17 +3,30 @@ package db
 import (
 	"context"
 	"fmt"
+	"math/big"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/zif-terminal/lib/models"
 )
 
+func init() {
+	registerOperations(map[string]Idempotency{
+		"AddFundingPayments":      Idempotent, // Conflicting payment_ids are ignored
+		"AddFundingPaymentsCount": Idempotent, // Same insert as AddFundingPayments
+	})
+}
+
 // FundingPayment represents a funding payment model (aliased from models package)
 type FundingPayment = models.FundingPayment
 
 // FundingPaymentInput represents funding payment input for mutations (aliased from models package)
 type FundingPaymentInput = models.FundingPaymentInput
 
+// FundingPaymentFilter represents filtering options for listing funding payments
+type FundingPaymentFilter = models.FundingPaymentFilter
+
 // GetLatestFundingPayment retrieves the latest funding payment for an exchange account
 func (c *Client) GetLatestFundingPayment(ctx context.Context, exchangeAccountID uuid.UUID) (*FundingPayment, error) {
 	query := `
@@ -24,7 +37,7 @@ func (c *Client) GetLatestFundingPayment(ctx context.Context, exchangeAccountID
 						_eq: $exchange_account_id
 					}
 				}
-				order_by: { timestamp: desc }
+				order_by: [{ timestamp: desc }, { id: desc }]
 				limit: 1
 			) {
 				id
@@ -34,12 +47,14 @@ func (c *Client) GetLatestFundingPayment(ctx context.Context, exchangeAccountID
 				amount
 				timestamp
 				payment_id
+				created_at
+				source
 			}
 		}
 	`
 
 	req := c.graphqlRequestWithVars(query, map[string]interface{}{
-		"exchange_account_id": exchangeAccountID.String(),
+		"exchange_account_id": uuidVar(exchangeAccountID),
 	})
 
 	var resp struct {
@@ -57,31 +72,305 @@ func (c *Client) GetLatestFundingPayment(ctx context.Context, exchangeAccountID
 	return resp.FundingPayments[0], nil
 }
 
+// GetLatestFundingPayments retrieves the latest funding payment for each of the given exchange accounts
+// in one query. Accounts without funding payments are absent from the map
+func (c *Client) GetLatestFundingPayments(ctx context.Context, exchangeAccountIDs []uuid.UUID) (map[uuid.UUID]*FundingPayment, error) {
+	if len(exchangeAccountIDs) == 0 {
+		return make(map[uuid.UUID]*FundingPayment), nil
+	}
+
+	query := `
+		query GetLatestFundingPayments($exchange_account_ids: [uuid!]!) {
+			funding_payments(
+				where: {
+					exchange_account_id: {
+						_in: $exchange_account_ids
+					}
+				}
+				order_by: [{ timestamp: desc }, { id: desc }]
+			) {
+				id
+				exchange_account_id
+				base_asset
+				quote_asset
+				amount
+				timestamp
+				payment_id
+				created_at
+				source
+			}
+		}
+	`
+
+	accountIDs := make([]string, len(exchangeAccountIDs))
+	for i, id := range exchangeAccountIDs {
+		accountIDs[i] = uuidVar(id)
+	}
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"exchange_account_ids": accountIDs,
+	})
+
+	var resp struct {
+		FundingPayments []*FundingPayment `json:"funding_payments"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get latest funding payments: %w", err)
+	}
+
+	// Payments are ordered newest first, so the first one seen per account is its latest
+	result := make(map[uuid.UUID]*FundingPayment)
+	for _, payment := range resp.FundingPayments {
+		if _, ok := result[payment.ExchangeAccountID]; !ok {
+			result[payment.ExchangeAccountID] = payment
+		}
+	}
+
+	return result, nil
+}
+
+// GetLatestFundingPaymentsByAsset retrieves the newest funding payment timestamp per base asset
+// for an exchange account. Assets without payments are absent from the map
+// The result can be passed as iface.FundingFetchOptions.AssetCursors
+func (c *Client) GetLatestFundingPaymentsByAsset(ctx context.Context, exchangeAccountID uuid.UUID) (map[string]time.Time, error) {
+	query := `
+		query GetLatestFundingPaymentsByAsset($exchange_account_id: uuid!) {
+			funding_payments(
+				where: {
+					exchange_account_id: {
+						_eq: $exchange_account_id
+					}
+				}
+				distinct_on: base_asset
+				order_by: [{ base_asset: asc }, { timestamp: desc }]
+			) {
+				base_asset
+				timestamp
+			}
+		}
+	`
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"exchange_account_id": uuidVar(exchangeAccountID),
+	})
+
+	var resp struct {
+		FundingPayments []*FundingPayment `json:"funding_payments"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get latest funding payments by asset: %w", err)
+	}
+
+	latest := make(map[string]time.Time, len(resp.FundingPayments))
+	for _, payment := range resp.FundingPayments {
+		latest[payment.BaseAsset] = payment.Timestamp
+	}
+
+	return latest, nil
+}
+
 // AddFundingPayments adds one or many funding payments
-// Uses batch insert for all cases (even single payment)
+// Uses batch insert for all cases (even single payment), split into several requests when the
+// batch exceeds ClientConfig.MaxRequestBytes
+// Every input is validated first; nothing is sent if any input is invalid
+// Payments that already exist for the account (same payment_id) are ignored, so re-syncing an
+// overlapping window is safe. Returns only the newly inserted payments
 func (c *Client) AddFundingPayments(ctx context.Context, inputs []*FundingPaymentInput) ([]*FundingPayment, error) {
 	if len(inputs) == 0 {
 		return []*FundingPayment{}, nil
 	}
 
-	// Convert inputs to GraphQL format
+	objects, err := fundingPaymentObjects(inputs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add funding payments: %w", err)
+	}
+
+	// Always use batch insert, even for single payment
+	query := fmt.Sprintf(addFundingPaymentsMutation, "AddFundingPayments", `returning {
+					id
+					exchange_account_id
+					base_asset
+					quote_asset
+					amount
+					timestamp
+					payment_id
+					created_at
+					source
+				}`)
+
+	inserted, err := insertChunked(c, query, objects, func(req *request) ([]*FundingPayment, error) {
+		var resp struct {
+			InsertFundingPayments struct {
+				Returning []*FundingPayment `json:"returning"`
+			} `json:"insert_funding_payments"`
+		}
+		if err := c.execute(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+		return resp.InsertFundingPayments.Returning, nil
+	})
+	if err != nil {
+		return inserted, fmt.Errorf("failed to add funding payments: %w", err)
+	}
+
+	return inserted, nil
+}
+
+// AddFundingPaymentsCount inserts funding payments like AddFundingPayments but asks Hasura only
+// for the number of rows inserted, for large batches whose rows the caller discards
+// On a *PartialInsertError the count covers the requests that were written
+func (c *Client) AddFundingPaymentsCount(ctx context.Context, inputs []*FundingPaymentInput) (int, error) {
+	if len(inputs) == 0 {
+		return 0, nil
+	}
+
+	objects, err := fundingPaymentObjects(inputs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add funding payments: %w", err)
+	}
+
+	query := fmt.Sprintf(addFundingPaymentsMutation, "AddFundingPaymentsCount", "affected_rows")
+	inserted, err := insertCount(ctx, c, query, "funding_payments", objects)
+	if err != nil {
+		return inserted, fmt.Errorf("failed to add funding payments: %w", err)
+	}
+	return inserted, nil
+}
+
+// addFundingPaymentsMutation is the batch funding payment insert behind AddFundingPayments and
+// AddFundingPaymentsCount, formatted with the operation name and the selection
+const addFundingPaymentsMutation = `
+		mutation %s($objects: [funding_payments_insert_input!]!) {
+			insert_funding_payments(
+				objects: $objects
+				on_conflict: { constraint: funding_payments_exchange_account_id_payment_id_key, update_columns: [] }
+			) {
+				%s
+			}
+		}
+	`
+
+// fundingPaymentObjects validates funding payment inputs and converts them to insert objects
+func fundingPaymentObjects(inputs []*FundingPaymentInput) ([]map[string]interface{}, error) {
+	for i, input := range inputs {
+		if err := input.Validate(); err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+	}
+
 	objects := make([]map[string]interface{}, len(inputs))
 	for i, input := range inputs {
 		objects[i] = map[string]interface{}{
-			"exchange_account_id": input.ExchangeAccountID.String(),
+			"exchange_account_id": uuidVar(input.ExchangeAccountID),
 			"base_asset":          input.BaseAsset,
 			"quote_asset":         input.QuoteAsset,
 			"amount":              input.Amount,
 			"timestamp":           input.Timestamp.UnixMilli(),
 			"payment_id":          input.PaymentID,
 		}
+		if input.Source != "" {
+			objects[i]["source"] = input.Source
+		}
+		if err := encodeNumericFields("funding payment", objects[i], "amount"); err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
 	}
+	return objects, nil
+}
 
-	// Always use batch insert, even for single payment
-	query := `
-		mutation AddFundingPayments($objects: [funding_payments_insert_input!]!) {
-			insert_funding_payments(objects: $objects) {
-				returning {
+// ListFundingPayments retrieves funding payments with optional filtering (newest first)
+func (c *Client) ListFundingPayments(ctx context.Context, filter FundingPaymentFilter) ([]*FundingPayment, error) {
+	b := buildFundingPaymentWhere(filter)
+	query := listFundingPaymentsQuery(b, paginationArgs(b, filter.Limit, filter.Offset), false)
+
+	req := c.graphqlRequestWithVars(query, b.variables())
+
+	var resp struct {
+		FundingPayments []*FundingPayment `json:"funding_payments"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list funding payments: %w", err)
+	}
+
+	return resp.FundingPayments, nil
+}
+
+// ListFundingPaymentsPage retrieves a single page of funding payments using filter.Limit/filter.Offset
+// When opts.IncludeTotalCount is set, the matching row count is fetched in the same request
+func (c *Client) ListFundingPaymentsPage(ctx context.Context, filter FundingPaymentFilter, opts PageOptions) (*Page[*FundingPayment], error) {
+	b := buildFundingPaymentWhere(filter)
+	limit := filter.Limit
+	if limit > 0 {
+		limit++ // Look ahead one row to determine HasMore
+	}
+	query := listFundingPaymentsQuery(b, paginationArgs(b, limit, filter.Offset), opts.IncludeTotalCount)
+
+	req := c.graphqlRequestWithVars(query, b.variables())
+
+	var resp struct {
+		FundingPayments          []*FundingPayment `json:"funding_payments"`
+		FundingPaymentsAggregate aggregateCount    `json:"funding_payments_aggregate"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list funding payments page: %w", err)
+	}
+
+	return newPage(resp.FundingPayments, filter.Limit, filter.Offset, resp.FundingPaymentsAggregate.total(opts)), nil
+}
+
+// buildFundingPaymentWhere translates a FundingPaymentFilter into where-clause conditions
+func buildFundingPaymentWhere(filter FundingPaymentFilter) *whereBuilder {
+	b := newWhereBuilder()
+
+	if len(filter.ExchangeAccountIDs) > 0 {
+		accountIDs := make([]string, len(filter.ExchangeAccountIDs))
+		for i, id := range filter.ExchangeAccountIDs {
+			accountIDs[i] = uuidVar(id)
+		}
+		b.add("exchange_account_id", "_in", "exchange_account_ids", "[uuid!]!", accountIDs)
+	}
+
+	if filter.BaseAsset != nil {
+		b.add("base_asset", "_eq", "base_asset", "String!", *filter.BaseAsset)
+	}
+
+	if filter.QuoteAsset != nil {
+		b.add("quote_asset", "_eq", "quote_asset", "String!", *filter.QuoteAsset)
+	}
+
+	if filter.TimestampGte != nil {
+		b.add("timestamp", "_gte", "timestamp_gte", "bigint!", filter.TimestampGte.UnixMilli())
+	}
+
+	if filter.TimestampLte != nil {
+		b.add("timestamp", "_lte", "timestamp_lte", "bigint!", filter.TimestampLte.UnixMilli())
+	}
+
+	if filter.UserID != nil {
+		b.add("exchange_account.user_id", "_eq", "user_id", "uuid!", *filter.UserID)
+	}
+
+	return b
+}
+
+// listFundingPaymentsQuery builds the ListFundingPayments query, optionally including an aggregate count
+func listFundingPaymentsQuery(b *whereBuilder, pagination string, withCount bool) string {
+	aggregate := ""
+	if withCount {
+		aggregate = aggregateSelection("funding_payments_aggregate", b)
+	}
+
+	return fmt.Sprintf(`
+			query ListFundingPayments%s {
+				funding_payments(
+					%s
+					order_by: [{ timestamp: desc }, { id: desc }]
+					%s
+				) {
 					id
 					exchange_account_id
 					base_asset
@@ -89,26 +378,118 @@ func (c *Client) AddFundingPayments(ctx context.Context, inputs []*FundingPaymen
 					amount
 					timestamp
 					payment_id
-				}
+					created_at
+					source
+				}%s
 			}
-		}
-	`
+		`, b.declarations(), b.whereArg(), pagination, aggregate)
+}
 
-	vars := map[string]interface{}{
-		"objects": objects,
-	}
+// fundingPaymentFields is the selection set for funding payment rows
+const fundingPaymentFields = `
+	id
+	exchange_account_id
+	base_asset
+	quote_asset
+	amount
+	timestamp
+	payment_id
+	created_at
+	source
+`
 
-	req := c.graphqlRequestWithVars(query, vars)
+// fundingForPositionsChunkSize caps how many positions GetFundingForPositions asks about per request
+var fundingForPositionsChunkSize = 50
 
-	var resp struct {
-		InsertFundingPayments struct {
-			Returning []*FundingPayment `json:"returning"`
-		} `json:"insert_funding_payments"`
+// PositionFunding is the funding paid or received while a position was open
+type PositionFunding struct {
+	Payments []*FundingPayment
+	Net      string // Exact sum of the payments' amounts (NUMERIC string, "0" when there are none)
+}
+
+// GetFundingForPosition retrieves the funding payments for the position's account and base asset
+// in [StartTime, EndTime) and their exact net amount. An open position (nil EndTime) runs until now
+func (c *Client) GetFundingForPosition(ctx context.Context, position *Position) ([]*FundingPayment, string, error) {
+	funding, err := c.GetFundingForPositions(ctx, []*Position{position})
+	if err != nil {
+		return nil, "", err
 	}
+	result := funding[position.ID]
+	return result.Payments, result.Net, nil
+}
 
-	if err := c.execute(ctx, req, &resp); err != nil {
-		return nil, fmt.Errorf("failed to add funding payments: %w", err)
+// GetFundingForPositions is the batch form of GetFundingForPosition, keyed by position ID
+// Positions are queried fundingForPositionsChunkSize at a time, one aliased field per position
+func (c *Client) GetFundingForPositions(ctx context.Context, positions []*Position) (map[uuid.UUID]*PositionFunding, error) {
+	result := make(map[uuid.UUID]*PositionFunding, len(positions))
+	now := c.clock.Now()
+
+	for start := 0; start < len(positions); start += fundingForPositionsChunkSize {
+		chunk := positions[start:min(start+fundingForPositionsChunkSize, len(positions))]
+
+		declarations := make([]string, 0, 4*len(chunk))
+		fields := make([]string, 0, len(chunk))
+		vars := make(map[string]interface{}, 4*len(chunk))
+		for i, position := range chunk {
+			end := now
+			if position.EndTime != nil {
+				end = *position.EndTime
+			}
+
+			declarations = append(declarations, fmt.Sprintf(
+				"$account_%[1]d: uuid!, $asset_%[1]d: String!, $start_%[1]d: bigint!, $end_%[1]d: bigint!", i))
+			fields = append(fields, fmt.Sprintf(`
+				p%[1]d: funding_payments(
+					where: {
+						exchange_account_id: { _eq: $account_%[1]d }
+						base_asset: { _eq: $asset_%[1]d }
+						timestamp: { _gte: $start_%[1]d, _lt: $end_%[1]d }
+					}
+					order_by: [{ timestamp: asc }, { id: asc }]
+				) {%[2]s}`, i, fundingPaymentFields))
+			vars[fmt.Sprintf("account_%d", i)] = uuidVar(position.ExchangeAccountID)
+			vars[fmt.Sprintf("asset_%d", i)] = position.BaseAsset
+			vars[fmt.Sprintf("start_%d", i)] = position.StartTime.UnixMilli()
+			vars[fmt.Sprintf("end_%d", i)] = end.UnixMilli()
+		}
+
+		query := fmt.Sprintf(`
+			query GetFundingForPositions(%s) {%s
+			}
+		`, strings.Join(declarations, ", "), strings.Join(fields, ""))
+
+		req := c.graphqlRequestWithVars(query, vars)
+
+		var resp map[string][]*FundingPayment
+		if err := c.execute(ctx, req, &resp); err != nil {
+			return nil, fmt.Errorf("failed to get funding for positions: %w", err)
+		}
+
+		for i, position := range chunk {
+			payments := resp[fmt.Sprintf("p%d", i)]
+			if payments == nil {
+				payments = []*FundingPayment{}
+			}
+			net, err := sumFundingAmounts(payments)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get funding for position %s: %w", position.ID, err)
+			}
+			result[position.ID] = &PositionFunding{Payments: payments, Net: net}
+		}
 	}
 
-	return resp.InsertFundingPayments.Returning, nil
+	return result, nil
+}
+
+// sumFundingAmounts returns the exact sum of the payments' amounts as a NUMERIC string
+func sumFundingAmounts(payments []*FundingPayment) (string, error) {
+	total := new(big.Rat)
+	for _, payment := range payments {
+		amount, err := models.ParseNumeric(payment.Amount)
+		if err != nil {
+			return "", fmt.Errorf("payment %s: %w", payment.PaymentID, err)
+		}
+		total.Add(total, amount)
+	}
+	return formatExactDecimal(total), nil
 }
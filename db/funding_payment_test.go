@@ -3,12 +3,15 @@ package db
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/clock"
 	"github.com/zif-terminal/lib/models"
 )
 
@@ -85,6 +88,90 @@ func TestClient_GetLatestFundingPayment_NotFound(t *testing.T) {
 	}
 }
 
+func TestClient_GetLatestFundingPayments(t *testing.T) {
+	ctx := context.Background()
+	accountID1 := uuid.New()
+	accountID2 := uuid.New()
+	accountID3 := uuid.New() // No payments
+	now := time.Now().Truncate(time.Millisecond)
+
+	// Ordered by timestamp desc, as the query asks; accounts interleave
+	payments := []*models.FundingPayment{
+		{ID: uuid.New(), ExchangeAccountID: accountID2, BaseAsset: "ETH", QuoteAsset: "USDC", Amount: "-1.2", Timestamp: now, PaymentID: "p-2b"},
+		{ID: uuid.New(), ExchangeAccountID: accountID1, BaseAsset: "BTC", QuoteAsset: "USDC", Amount: "3.4", Timestamp: now.Add(-time.Hour), PaymentID: "p-1b"},
+		{ID: uuid.New(), ExchangeAccountID: accountID2, BaseAsset: "SOL", QuoteAsset: "USDC", Amount: "0.5", Timestamp: now.Add(-2 * time.Hour), PaymentID: "p-2a"},
+		{ID: uuid.New(), ExchangeAccountID: accountID1, BaseAsset: "BTC", QuoteAsset: "USDC", Amount: "2.1", Timestamp: now.Add(-3 * time.Hour), PaymentID: "p-1a"},
+	}
+
+	var vars map[string]interface{}
+	var query string
+	calls := 0
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			calls++
+			query = requestFromContext(ctx).query
+			vars = requestFromContext(ctx).vars
+			data, _ := json.Marshal(map[string]interface{}{"funding_payments": payments})
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	latest, err := client.GetLatestFundingPayments(ctx, []uuid.UUID{accountID1, accountID2, accountID3})
+	if err != nil {
+		t.Fatalf("GetLatestFundingPayments failed: %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected a single query, got %d", calls)
+	}
+	if !strings.Contains(query, "order_by: [{ timestamp: desc }, { id: desc }]") {
+		t.Errorf("Expected newest-first ordering, got: %s", query)
+	}
+	if ids, ok := vars["exchange_account_ids"].([]string); !ok || len(ids) != 3 {
+		t.Errorf("Expected 3 exchange_account_ids, got %v", vars["exchange_account_ids"])
+	}
+
+	if len(latest) != 2 {
+		t.Fatalf("Expected latest payments for 2 accounts, got %d", len(latest))
+	}
+	if got := latest[accountID1]; got == nil || got.PaymentID != "p-1b" {
+		t.Errorf("Expected account1 latest payment p-1b, got %+v", got)
+	}
+	if got := latest[accountID2]; got == nil || got.PaymentID != "p-2b" {
+		t.Errorf("Expected account2 latest payment p-2b, got %+v", got)
+	}
+	if _, ok := latest[accountID3]; ok {
+		t.Error("Expected no entry for an account without payments")
+	}
+}
+
+func TestClient_GetLatestFundingPayments_EmptyInput(t *testing.T) {
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			t.Error("GetLatestFundingPayments should not call GraphQL with empty input")
+			return nil
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	latest, err := client.GetLatestFundingPayments(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetLatestFundingPayments failed: %v", err)
+	}
+	if len(latest) != 0 {
+		t.Errorf("Expected an empty map, got %v", latest)
+	}
+}
+
 func TestClient_AddFundingPayments_Single(t *testing.T) {
 	ctx := context.Background()
 	accountID := uuid.New()
@@ -242,14 +329,54 @@ func TestClient_AddFundingPayments_EmptyInput(t *testing.T) {
 	}
 }
 
-func TestClient_AddFundingPayments_Duplicate(t *testing.T) {
+func TestClient_AddFundingPayments_IgnoresDuplicates(t *testing.T) {
 	ctx := context.Background()
 	accountID := uuid.New()
 
+	var query string
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			// payment-123 already exists, so only payment-456 comes back
+			data, _ := json.Marshal(map[string]interface{}{
+				"insert_funding_payments": map[string]interface{}{
+					"returning": []map[string]interface{}{{"id": uuid.New(), "payment_id": "payment-456", "exchange_account_id": accountID}},
+				},
+			})
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	inputs := []*FundingPaymentInput{
+		{ExchangeAccountID: accountID, BaseAsset: "BTC", QuoteAsset: "USDC", Amount: "10.5", Timestamp: time.Now(), PaymentID: "payment-123"},
+		{ExchangeAccountID: accountID, BaseAsset: "BTC", QuoteAsset: "USDC", Amount: "-1", Timestamp: time.Now(), PaymentID: "payment-456"},
+	}
+
+	payments, err := client.AddFundingPayments(ctx, inputs)
+	if err != nil {
+		t.Fatalf("AddFundingPayments failed: %v", err)
+	}
+
+	if !strings.Contains(query, "on_conflict: { constraint: funding_payments_exchange_account_id_payment_id_key, update_columns: [] }") {
+		t.Errorf("Expected conflicting payments to be ignored, got: %s", query)
+	}
+	if len(payments) != 1 || payments[0].PaymentID != "payment-456" {
+		t.Errorf("Expected only the newly inserted payment, got %+v", payments)
+	}
+}
+
+func TestClient_AddFundingPayments_InvalidInput(t *testing.T) {
+	ctx := context.Background()
+
 	mockClient := &mockGraphQLClient{
 		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
-			// Simulate duplicate error - should fail fast in dev mode
-			return fmt.Errorf("duplicate key value violates unique constraint")
+			t.Error("AddFundingPayments should not call GraphQL with invalid input")
+			return nil
 		},
 	}
 
@@ -260,21 +387,177 @@ func TestClient_AddFundingPayments_Duplicate(t *testing.T) {
 
 	inputs := []*FundingPaymentInput{
 		{
-			ExchangeAccountID: accountID,
+			ExchangeAccountID: uuid.New(),
 			BaseAsset:         "BTC",
 			QuoteAsset:        "USDC",
-			Amount:            "10.5",
+			Amount:            "not-a-number",
 			Timestamp:         time.Now(),
-			PaymentID:         "payment-123",
+			PaymentID:         "",
 		},
 	}
 
 	_, err := client.AddFundingPayments(ctx, inputs)
-	// Should return error - fail fast in dev mode
 	if err == nil {
-		t.Fatal("Expected error for duplicate payment")
+		t.Fatal("Expected validation error")
+	}
+
+	var verr *models.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *models.ValidationError, got: %v", err)
+	}
+	if len(verr.Fields) != 2 {
+		t.Errorf("Expected 2 invalid fields, got %+v", verr.Fields)
+	}
+}
+
+func TestClient_GetLatestFundingPaymentsByAsset(t *testing.T) {
+	ctx := context.Background()
+	accountID := uuid.New()
+
+	var query string
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			data := `{"funding_payments": [
+				{"base_asset": "BTC", "timestamp": 1700000300000},
+				{"base_asset": "ETH", "timestamp": 1700000100000}
+			]}`
+			return json.Unmarshal([]byte(data), resp)
+		},
 	}
-	if err.Error() == "" {
-		t.Error("Error message should not be empty")
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	latest, err := client.GetLatestFundingPaymentsByAsset(ctx, accountID)
+	if err != nil {
+		t.Fatalf("GetLatestFundingPaymentsByAsset failed: %v", err)
+	}
+
+	if !strings.Contains(query, "distinct_on: base_asset") {
+		t.Errorf("Expected one row per asset, got: %s", query)
+	}
+	if len(latest) != 2 {
+		t.Fatalf("Expected 2 assets, got %d", len(latest))
+	}
+	if !latest["BTC"].Equal(time.UnixMilli(1700000300000)) || !latest["ETH"].Equal(time.UnixMilli(1700000100000)) {
+		t.Errorf("Unexpected cursors: %v", latest)
+	}
+}
+
+// fundingStoreMock answers aliased GetFundingForPositions queries from stored payments,
+// applying each alias's account, asset and [start, end) timestamp conditions
+func fundingStoreMock(stored []*models.FundingPayment, calls *int, seenVars *[]map[string]interface{}) *mockGraphQLClient {
+	return &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			*calls++
+			vars := requestFromContext(ctx).vars
+			*seenVars = append(*seenVars, vars)
+
+			data := map[string][]*models.FundingPayment{}
+			for i := 0; ; i++ {
+				account, ok := vars[fmt.Sprintf("account_%d", i)].(string)
+				if !ok {
+					break
+				}
+				asset := vars[fmt.Sprintf("asset_%d", i)].(string)
+				start := vars[fmt.Sprintf("start_%d", i)].(int64)
+				end := vars[fmt.Sprintf("end_%d", i)].(int64)
+
+				matched := []*models.FundingPayment{}
+				for _, payment := range stored {
+					ts := payment.Timestamp.UnixMilli()
+					if payment.ExchangeAccountID.String() == account && payment.BaseAsset == asset && ts >= start && ts < end {
+						matched = append(matched, payment)
+					}
+				}
+				data[fmt.Sprintf("p%d", i)] = matched
+			}
+
+			encoded, _ := json.Marshal(data)
+			return json.Unmarshal(encoded, resp)
+		},
+	}
+}
+
+func TestClient_GetFundingForPosition_Boundaries(t *testing.T) {
+	accountID := uuid.New()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	payment := func(id, asset, amount string, at time.Time) *models.FundingPayment {
+		return &models.FundingPayment{ID: uuid.New(), ExchangeAccountID: accountID, BaseAsset: asset, Amount: amount, Timestamp: at, PaymentID: id}
+	}
+	stored := []*models.FundingPayment{
+		payment("before-start", "BTC", "100", start.Add(-time.Millisecond)),
+		payment("at-start", "BTC", "-0.1", start),
+		payment("inside", "BTC", "0.0000001", start.Add(time.Hour)),
+		payment("before-end", "BTC", "-1.2", end.Add(-time.Millisecond)),
+		payment("at-end", "BTC", "1000", end),
+		payment("other-asset", "ETH", "5", start.Add(time.Hour)),
+	}
+
+	calls := 0
+	var seenVars []map[string]interface{}
+	client := NewClientWithGraphQL(fundingStoreMock(stored, &calls, &seenVars), ClientConfig{})
+
+	position := &models.Position{ID: uuid.New(), ExchangeAccountID: accountID, BaseAsset: "BTC", StartTime: start, EndTime: &end}
+	payments, net, err := client.GetFundingForPosition(context.Background(), position)
+	if err != nil {
+		t.Fatalf("GetFundingForPosition failed: %v", err)
+	}
+
+	var ids []string
+	for _, p := range payments {
+		ids = append(ids, p.PaymentID)
+	}
+	if got := strings.Join(ids, ","); got != "at-start,inside,before-end" {
+		t.Errorf("Expected start-inclusive, end-exclusive payments, got %s", got)
+	}
+	if net != "-1.2999999" {
+		t.Errorf("Expected exact net -1.2999999, got %s", net)
+	}
+}
+
+func TestClient_GetFundingForPositions_OpenPositionAndChunks(t *testing.T) {
+	original := fundingForPositionsChunkSize
+	fundingForPositionsChunkSize = 2
+	defer func() { fundingForPositionsChunkSize = original }()
+
+	accountID := uuid.New()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	stored := []*models.FundingPayment{
+		{ID: uuid.New(), ExchangeAccountID: accountID, BaseAsset: "SOL", Amount: "2.5", Timestamp: now.Add(-time.Hour), PaymentID: "recent"},
+	}
+
+	calls := 0
+	var seenVars []map[string]interface{}
+	client := NewClientWithGraphQL(fundingStoreMock(stored, &calls, &seenVars), ClientConfig{}, WithClock(clock.NewFake(now)))
+
+	open := &models.Position{ID: uuid.New(), ExchangeAccountID: accountID, BaseAsset: "SOL", StartTime: now.Add(-48 * time.Hour)}
+	closed1 := &models.Position{ID: uuid.New(), ExchangeAccountID: accountID, BaseAsset: "BTC", StartTime: now.Add(-48 * time.Hour), EndTime: &now}
+	closed2End := now.Add(-2 * time.Hour)
+	closed2 := &models.Position{ID: uuid.New(), ExchangeAccountID: accountID, BaseAsset: "SOL", StartTime: now.Add(-48 * time.Hour), EndTime: &closed2End}
+
+	funding, err := client.GetFundingForPositions(context.Background(), []*models.Position{closed1, closed2, open})
+	if err != nil {
+		t.Fatalf("GetFundingForPositions failed: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("Expected 2 chunked requests, got %d", calls)
+	}
+	if got := seenVars[1]["end_0"]; got != now.UnixMilli() {
+		t.Errorf("Expected open position to end at now (%d), got %v", now.UnixMilli(), got)
+	}
+	if f := funding[open.ID]; f == nil || f.Net != "2.5" || len(f.Payments) != 1 {
+		t.Errorf("Expected open position to include the recent payment, got %+v", f)
+	}
+	for _, position := range []*models.Position{closed1, closed2} {
+		if f := funding[position.ID]; f == nil || f.Net != "0" || len(f.Payments) != 0 {
+			t.Errorf("Expected no funding for position %s, got %+v", position.ID, f)
+		}
 	}
 }
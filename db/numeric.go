@@ -0,0 +1,81 @@
+package db
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/zif-terminal/lib/models"
+)
+
+// encodeNumeric validates a NUMERIC string and normalizes it to the plain decimal form Hasura
+// accepts for numeric! variables: surrounding whitespace and a leading "+" are dropped and
+// exponent notation is expanded exactly ("1e-7" -> "0.0000001", "1.5E+3" -> "1500")
+func encodeNumeric(s string) (string, error) {
+	r, err := models.ParseNumeric(s)
+	if err != nil {
+		return "", err
+	}
+	return formatExactDecimal(r), nil
+}
+
+// formatExactDecimal formats a terminating decimal r without rounding or trailing zeros
+func formatExactDecimal(r *big.Rat) string {
+	s := r.FloatString(decimalScale(r.Denom()))
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// decimalScale returns the number of fractional digits needed to write 1/denom exactly,
+// i.e. the larger of its powers of 2 and 5 (a parsed decimal has no other prime factors)
+func decimalScale(denom *big.Int) int {
+	d := new(big.Int).Set(denom)
+	two, five := big.NewInt(2), big.NewInt(5)
+	mod := new(big.Int)
+	count := func(p *big.Int) int {
+		n := 0
+		for {
+			q, m := new(big.Int).QuoRem(d, p, mod)
+			if m.Sign() != 0 {
+				return n
+			}
+			d = q
+			n++
+		}
+	}
+	twos, fives := count(two), count(five)
+	if twos > fives {
+		return twos
+	}
+	return fives
+}
+
+// encodeNumericFields normalizes the named NUMERIC values of vars in place with encodeNumeric
+// Every invalid field is reported in a single *models.ValidationError for resource
+func encodeNumericFields(resource string, vars map[string]interface{}, fields ...string) error {
+	verr := &models.ValidationError{Resource: resource}
+	for _, field := range fields {
+		value, ok := vars[field].(string)
+		if !ok {
+			continue
+		}
+		encoded, err := encodeNumeric(value)
+		if err != nil {
+			verr.Fields = append(verr.Fields, models.FieldError{
+				Field:   field,
+				Message: fmt.Sprintf("must be a decimal number, got %q", value),
+			})
+			continue
+		}
+		vars[field] = encoded
+	}
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/models"
+)
+
+func TestEncodeNumeric(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "10.5", want: "10.5"},
+		{in: "-3", want: "-3"},
+		{in: "0.00100", want: "0.001"},
+		{in: "1e-7", want: "0.0000001"},
+		{in: "1.5E+3", want: "1500"},
+		{in: "-2.5e-20", want: "-0.000000000000000000025"},
+		{in: "+42.0", want: "42"},
+		{in: "  7 ", want: "7"},
+		{in: "-0", want: "0"},
+		{in: ".5", want: "0.5"},
+		{in: "", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "1,000", wantErr: true},
+		{in: "NaN", wantErr: true},
+		{in: "1e", wantErr: true},
+		{in: "0x10", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := encodeNumeric(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error for %q, got %q", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("encodeNumeric(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeNumericFields_ReportsFieldNames(t *testing.T) {
+	vars := map[string]interface{}{"price": "1e2", "quantity": "lots", "fee": "+0.5", "side": "buy"}
+
+	err := encodeNumericFields("trade", vars, "price", "quantity", "fee")
+
+	var verr *models.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *models.ValidationError, got %T: %v", err, err)
+	}
+	if len(verr.Fields) != 1 || verr.Fields[0].Field != "quantity" {
+		t.Errorf("Expected only quantity to be invalid, got %+v", verr.Fields)
+	}
+	if vars["price"] != "100" || vars["fee"] != "0.5" {
+		t.Errorf("Expected valid fields to be normalized, got price=%v fee=%v", vars["price"], vars["fee"])
+	}
+}
+
+func TestClient_CreateTrade_NormalizesNumericVariables(t *testing.T) {
+	ctx := context.Background()
+
+	var vars map[string]interface{}
+	calls := 0
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			calls++
+			vars = requestFromContext(ctx).vars
+			return nil
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	input := &TradeInput{
+		BaseAsset:         "BTC",
+		QuoteAsset:        "USDC",
+		Side:              "buy",
+		Price:             "6.5e4",
+		Quantity:          "+1e-7",
+		Fee:               "0",
+		Timestamp:         time.Now(),
+		ExchangeAccountID: uuid.New(),
+	}
+	// The mock returns no row, so only the sent variables matter here
+	_, _ = client.CreateTrade(ctx, input)
+
+	if vars["price"] != "65000" || vars["quantity"] != "0.0000001" {
+		t.Errorf("Expected normalized numerics, got price=%v quantity=%v", vars["price"], vars["quantity"])
+	}
+
+	input.Fee = "n/a"
+	_, err := client.CreateTrade(ctx, input)
+	var verr *models.ValidationError
+	if !errors.As(err, &verr) || verr.Fields[0].Field != "fee" {
+		t.Fatalf("Expected validation error for fee, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected invalid input not to be sent, got %d calls", calls)
+	}
+}
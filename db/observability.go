@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// OperationMetrics describes a finished operation, including all of its retry attempts
+type OperationMetrics struct {
+	Operation   string        // GraphQL operation name (e.g. "AddTrades")
+	Idempotency Idempotency   // ReadOnly for queries, the registered class for mutations
+	Attempts    int           // Attempts made, including the first
+	Duration    time.Duration // Total time spent in execute, including retry backoff
+	Err         error         // nil if the operation eventually succeeded
+}
+
+// MetricsHook receives OperationMetrics after every operation
+type MetricsHook func(OperationMetrics)
+
+// Tracer starts a span for each operation
+// StartOperation returns the context to run the operation under and a function ending the span
+// with the operation's final error. Adapters for tracing libraries implement it outside this package
+type Tracer interface {
+	StartOperation(ctx context.Context, operation string) (context.Context, func(err error))
+}
+
+// Observability bundles the logger, metrics hook and tracer a Client reports to
+// Nil fields leave the corresponding component as configured elsewhere
+type Observability struct {
+	Logger  *slog.Logger // Replaces ClientConfig.Logger; operations are logged at debug level
+	Metrics MetricsHook
+	Tracer  Tracer
+}
+
+// WithObservability applies every component of obs in one option
+func WithObservability(obs Observability) Option {
+	return func(c *Client) {
+		if obs.Logger != nil {
+			c.logger = obs.Logger
+		}
+		if obs.Metrics != nil {
+			WithMetricsHook(obs.Metrics)(c)
+		}
+		if obs.Tracer != nil {
+			WithTracer(obs.Tracer)(c)
+		}
+	}
+}
+
+// WithMetricsHook calls fn after each operation with its name, attempts, duration and error
+func WithMetricsHook(fn MetricsHook) Option {
+	return func(c *Client) {
+		c.metricsHook = fn
+	}
+}
+
+// WithTracer wraps each operation, retries included, in a span started by tracer
+func WithTracer(tracer Tracer) Option {
+	return func(c *Client) {
+		c.tracer = tracer
+	}
+}
+
+// observeOperation reports a finished operation to the logger and metrics hook
+func (c *Client) observeOperation(m OperationMetrics) {
+	c.logger.Debug("graphql operation",
+		"operation", m.Operation,
+		"attempts", m.Attempts,
+		"duration", m.Duration,
+		"error", m.Err,
+	)
+	if c.metricsHook != nil {
+		c.metricsHook(m)
+	}
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/machinebox/graphql"
+)
+
+// spanKey marks contexts started by recordingTracer
+type spanKey struct{}
+
+// recordingTracer records started and ended spans
+type recordingTracer struct {
+	started []string
+	ended   []error
+}
+
+func (r *recordingTracer) StartOperation(ctx context.Context, operation string) (context.Context, func(error)) {
+	r.started = append(r.started, operation)
+	return context.WithValue(ctx, spanKey{}, operation), func(err error) {
+		r.ended = append(r.ended, err)
+	}
+}
+
+func TestWithObservability_ReportsToAllComponents(t *testing.T) {
+	errRefused := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+
+	calls := 0
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			if ctx.Value(spanKey{}) != "ListExchanges" {
+				t.Errorf("Expected operation to run under the tracer's context")
+			}
+			calls++
+			if calls == 1 {
+				return errRefused
+			}
+			return nil
+		},
+	}
+
+	var logs bytes.Buffer
+	var metrics []OperationMetrics
+	tracer := &recordingTracer{}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{}, WithRetry(3, time.Millisecond), WithObservability(Observability{
+		Logger:  slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Metrics: func(m OperationMetrics) { metrics = append(metrics, m) },
+		Tracer:  tracer,
+	}))
+
+	if _, err := client.ListExchanges(context.Background(), false); err != nil {
+		t.Fatalf("ListExchanges failed: %v", err)
+	}
+
+	if len(tracer.started) != 1 || tracer.started[0] != "ListExchanges" || len(tracer.ended) != 1 || tracer.ended[0] != nil {
+		t.Errorf("Expected one successful ListExchanges span, got started=%v ended=%v", tracer.started, tracer.ended)
+	}
+	if len(metrics) != 1 {
+		t.Fatalf("Expected 1 metrics event, got %d", len(metrics))
+	}
+	if m := metrics[0]; m.Operation != "ListExchanges" || m.Idempotency != ReadOnly || m.Attempts != 2 || m.Err != nil {
+		t.Errorf("Unexpected metrics: %+v", m)
+	}
+	if !strings.Contains(logs.String(), "operation=ListExchanges attempts=2") {
+		t.Errorf("Expected operation to be logged, got %q", logs.String())
+	}
+}
+
+func TestWithObservability_ReportsFailures(t *testing.T) {
+	errBoom := errors.New("boom")
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			return errBoom
+		},
+	}
+
+	var metrics []OperationMetrics
+	tracer := &recordingTracer{}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{}, WithObservability(Observability{
+		Metrics: func(m OperationMetrics) { metrics = append(metrics, m) },
+		Tracer:  tracer,
+	}))
+
+	if _, err := client.ListExchanges(context.Background(), false); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if len(tracer.ended) != 1 || !errors.Is(tracer.ended[0], errBoom) {
+		t.Errorf("Expected span to end with the error, got %v", tracer.ended)
+	}
+	if len(metrics) != 1 || !errors.Is(metrics[0].Err, errBoom) || metrics[0].Attempts != 1 {
+		t.Errorf("Expected failed single-attempt metrics, got %+v", metrics)
+	}
+}
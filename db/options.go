@@ -0,0 +1,50 @@
+package db
+
+import (
+	"encoding/json"
+
+	"github.com/zif-terminal/lib/clock"
+)
+
+// Option configures optional Client behavior not covered by ClientConfig
+type Option func(*Client)
+
+// ResponseHook receives the raw response of every executed operation along with its error
+type ResponseHook func(opName string, raw json.RawMessage, err error)
+
+// WithResponseHook calls fn after each operation with the raw response body, for debugging
+// schema mismatches without a proxy. raw is nil when no response was received. The body is
+// shared with normal decoding, so fn must not modify it. GraphQL clients that don't expose
+// the raw body (see NewClientWithGraphQL) pass the decoded data re-encoded as JSON instead
+func WithResponseHook(fn ResponseHook) Option {
+	return func(c *Client) {
+		c.responseHook = fn
+	}
+}
+
+// Clock supplies the time used for operation deadlines, retry backoff, cache expiry and
+// operation durations (aliased from the clock package)
+type Clock = clock.Clock
+
+// WithClock replaces the client's clock (see ClientConfig.Clock), so time-dependent tests can be deterministic
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// WithMaxConcurrentRequests caps how many requests the client has in flight at once
+// (see ClientConfig.MaxConcurrentRequests); n <= 0 removes the cap
+func WithMaxConcurrentRequests(n int) Option {
+	return func(c *Client) {
+		c.slots = newSlots(n)
+	}
+}
+
+// newSlots returns a semaphore with n slots, or nil (unlimited) when n <= 0
+func newSlots(n int) chan struct{} {
+	if n <= 0 {
+		return nil
+	}
+	return make(chan struct{}, n)
+}
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/models"
+)
+
+func init() {
+	registerOperations(map[string]Idempotency{
+		"AddOrders": Idempotent, // Conflicting order_ids are ignored
+	})
+}
+
+// Order represents an order model (aliased from models package)
+type Order = models.Order
+
+// OrderInput represents order input for mutations (aliased from models package)
+type OrderInput = models.OrderInput
+
+// OrderFilter represents filtering options for listing orders (aliased from models package)
+type OrderFilter = models.OrderFilter
+
+// AddOrders adds one or many orders in a single batch insert
+// Orders that already exist for the account (same order_id) are ignored, so re-syncing an
+// overlapping window is safe. Returns only the newly inserted orders
+// Every input is validated first; nothing is sent if any input is invalid
+func (c *Client) AddOrders(ctx context.Context, inputs []*OrderInput) ([]*Order, error) {
+	if len(inputs) == 0 {
+		return []*Order{}, nil
+	}
+
+	for i, input := range inputs {
+		if err := input.Validate(); err != nil {
+			return nil, fmt.Errorf("failed to add orders: input %d: %w", i, err)
+		}
+	}
+
+	// Convert inputs to GraphQL format
+	objects := make([]map[string]interface{}, len(inputs))
+	for i, input := range inputs {
+		objects[i] = map[string]interface{}{
+			"exchange_account_id": uuidVar(input.ExchangeAccountID),
+			"order_id":            input.OrderID,
+			"base_asset":          input.BaseAsset,
+			"quote_asset":         input.QuoteAsset,
+			"side":                input.Side,
+			"price":               input.Price,
+			"size":                input.Size,
+			"status":              input.Status,
+			"timestamp":           input.Timestamp.UnixMilli(),
+		}
+		if err := encodeNumericFields("order", objects[i], "price", "size"); err != nil {
+			return nil, fmt.Errorf("failed to add orders: input %d: %w", i, err)
+		}
+	}
+
+	query := `
+		mutation AddOrders($objects: [orders_insert_input!]!) {
+			insert_orders(
+				objects: $objects
+				on_conflict: { constraint: orders_exchange_account_id_order_id_key, update_columns: [] }
+			) {
+				returning {
+					id
+					order_id
+					base_asset
+					quote_asset
+					side
+					price
+					size
+					status
+					timestamp
+					exchange_account_id
+					created_at
+				}
+			}
+		}
+	`
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"objects": objects,
+	})
+
+	var resp struct {
+		InsertOrders struct {
+			Returning []*Order `json:"returning"`
+		} `json:"insert_orders"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to add orders: %w", err)
+	}
+
+	return resp.InsertOrders.Returning, nil
+}
+
+// GetOrdersByAccount retrieves an account's orders with optional filtering (newest first)
+func (c *Client) GetOrdersByAccount(ctx context.Context, exchangeAccountID uuid.UUID, filter OrderFilter) ([]*Order, error) {
+	b := buildOrderWhere(exchangeAccountID, filter)
+
+	query := fmt.Sprintf(`
+		query GetOrdersByAccount%s {
+			orders(
+				%s
+				order_by: [{ timestamp: desc }, { id: desc }]
+				%s
+			) {
+				id
+				order_id
+				base_asset
+				quote_asset
+				side
+				price
+				size
+				status
+				timestamp
+				exchange_account_id
+				created_at
+			}
+		}
+	`, b.declarations(), b.whereArg(), paginationArgs(b, filter.Limit, filter.Offset))
+
+	req := c.graphqlRequestWithVars(query, b.variables())
+
+	var resp struct {
+		Orders []*Order `json:"orders"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get orders by account: %w", err)
+	}
+
+	return resp.Orders, nil
+}
+
+// buildOrderWhere translates an account ID and OrderFilter into where-clause conditions
+func buildOrderWhere(exchangeAccountID uuid.UUID, filter OrderFilter) *whereBuilder {
+	b := newWhereBuilder()
+	b.add("exchange_account_id", "_eq", "exchange_account_id", "uuid!", uuidVar(exchangeAccountID))
+
+	if filter.BaseAsset != nil {
+		b.add("base_asset", "_eq", "base_asset", "String!", *filter.BaseAsset)
+	}
+
+	if len(filter.Statuses) > 0 {
+		b.add("status", "_in", "statuses", "[String!]!", filter.Statuses)
+	}
+
+	if filter.TimestampGte != nil {
+		b.add("timestamp", "_gte", "timestamp_gte", "bigint!", filter.TimestampGte.UnixMilli())
+	}
+
+	if filter.TimestampLte != nil {
+		b.add("timestamp", "_lte", "timestamp_lte", "bigint!", filter.TimestampLte.UnixMilli())
+	}
+
+	return b
+}
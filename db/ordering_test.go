@@ -0,0 +1,182 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+)
+
+// orderKeyPattern matches flat order_by terms such as "{ timestamp: desc }"
+var orderKeyPattern = regexp.MustCompile(`\{\s*(\w+):\s*(asc|desc)\s*\}`)
+
+// orderByKeys returns the flat order_by terms of query in declaration order
+func orderByKeys(query string) [][2]string {
+	var keys [][2]string
+	for _, m := range orderKeyPattern.FindAllStringSubmatch(query, -1) {
+		keys = append(keys, [2]string{m[1], m[2]})
+	}
+	return keys
+}
+
+// compareOrderValues compares two decoded JSON values, numerically when both are numbers
+func compareOrderValues(a, b interface{}) int {
+	if x, ok := a.(float64); ok {
+		if y, ok := b.(float64); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	x, y := fmt.Sprint(a), fmt.Sprint(b)
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// shuffledOrderingMock returns a raw mock that emulates the database's freedom to return
+// rows that tie on every order_by key in any order: each call shuffles rows with a new
+// seed and then stable-sorts them by the order_by terms of the incoming query
+func shuffledOrderingMock(t *testing.T, root string, rows []map[string]interface{}) *rawMockGraphQLClient {
+	t.Helper()
+	calls := 0
+	return &rawMockGraphQLClient{
+		runRawFunc: func(ctx context.Context, req *graphql.Request) ([]byte, error) {
+			calls++
+			keys := orderByKeys(requestFromContext(ctx).query)
+			if len(keys) == 0 {
+				t.Fatalf("query has no order_by terms")
+			}
+
+			shuffled := make([]map[string]interface{}, len(rows))
+			copy(shuffled, rows)
+			rng := rand.New(rand.NewSource(int64(calls)))
+			rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+			sort.SliceStable(shuffled, func(i, j int) bool {
+				for _, key := range keys {
+					c := compareOrderValues(shuffled[i][key[0]], shuffled[j][key[0]])
+					if key[1] == "desc" {
+						c = -c
+					}
+					if c != 0 {
+						return c < 0
+					}
+				}
+				return false
+			})
+
+			return json.Marshal(map[string]interface{}{
+				"data": map[string]interface{}{root: shuffled},
+			})
+		},
+	}
+}
+
+// assertStableOrdering runs list repeatedly and fails if the returned IDs ever differ between runs
+func assertStableOrdering(t *testing.T, runs int, list func() ([]uuid.UUID, error)) {
+	t.Helper()
+	var first []uuid.UUID
+	for run := 0; run < runs; run++ {
+		ids, err := list()
+		if err != nil {
+			t.Fatalf("run %d: unexpected error: %v", run, err)
+		}
+		if run == 0 {
+			first = ids
+			continue
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(first) {
+			t.Fatalf("run %d: ordering changed between runs\nfirst: %v\ngot:   %v", run, first, ids)
+		}
+	}
+}
+
+// tiedRows builds rows that share the same timeField value and differ only by id
+func tiedRows(n int, timeField string, extra map[string]interface{}) []map[string]interface{} {
+	rows := make([]map[string]interface{}, n)
+	for i := range rows {
+		row := map[string]interface{}{
+			"id":      uuid.New().String(),
+			timeField: float64(1700000000000),
+		}
+		for k, v := range extra {
+			row[k] = v
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+func TestListQueries_StableOrderingForTiedTimestamps(t *testing.T) {
+	ctx := context.Background()
+	accountID := uuid.New()
+	config := ClientConfig{URL: "http://localhost:8080/v1/graphql", AdminSecret: "test-secret"}
+	const runs = 10
+
+	t.Run("ListTrades", func(t *testing.T) {
+		rows := tiedRows(5, "timestamp", map[string]interface{}{"price": "1", "size": "1", "fee": "0"})
+		client := NewClientWithGraphQL(shuffledOrderingMock(t, "trades", rows), config)
+		assertStableOrdering(t, runs, func() ([]uuid.UUID, error) {
+			trades, err := client.ListTrades(ctx, TradeFilter{})
+			ids := make([]uuid.UUID, len(trades))
+			for i, trade := range trades {
+				ids[i] = trade.ID
+			}
+			return ids, err
+		})
+	})
+
+	t.Run("ListFundingPayments", func(t *testing.T) {
+		rows := tiedRows(5, "timestamp", map[string]interface{}{"amount": "1"})
+		client := NewClientWithGraphQL(shuffledOrderingMock(t, "funding_payments", rows), config)
+		assertStableOrdering(t, runs, func() ([]uuid.UUID, error) {
+			payments, err := client.ListFundingPayments(ctx, FundingPaymentFilter{})
+			ids := make([]uuid.UUID, len(payments))
+			for i, payment := range payments {
+				ids[i] = payment.ID
+			}
+			return ids, err
+		})
+	})
+
+	t.Run("GetOrdersByAccount", func(t *testing.T) {
+		rows := tiedRows(5, "timestamp", map[string]interface{}{"price": "1", "size": "1"})
+		client := NewClientWithGraphQL(shuffledOrderingMock(t, "orders", rows), config)
+		assertStableOrdering(t, runs, func() ([]uuid.UUID, error) {
+			orders, err := client.GetOrdersByAccount(ctx, accountID, OrderFilter{})
+			ids := make([]uuid.UUID, len(orders))
+			for i, order := range orders {
+				ids[i] = order.ID
+			}
+			return ids, err
+		})
+	})
+
+	t.Run("GetPositions", func(t *testing.T) {
+		rows := tiedRows(5, "end_time", map[string]interface{}{"start_time": float64(1690000000000)})
+		client := NewClientWithGraphQL(shuffledOrderingMock(t, "positions", rows), config)
+		assertStableOrdering(t, runs, func() ([]uuid.UUID, error) {
+			positions, err := client.GetPositions(ctx, PositionFilter{})
+			ids := make([]uuid.UUID, len(positions))
+			for i, position := range positions {
+				ids[i] = position.ID
+			}
+			return ids, err
+		})
+	})
+}
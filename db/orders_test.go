@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/models"
+)
+
+func testOrderInput(accountID uuid.UUID, orderID string) *OrderInput {
+	return &OrderInput{
+		OrderID:           orderID,
+		BaseAsset:         "BTC",
+		QuoteAsset:        "USDC",
+		Side:              "buy",
+		Price:             "65000.5",
+		Size:              "0.1",
+		Status:            "filled",
+		Timestamp:         time.UnixMilli(1700000000000),
+		ExchangeAccountID: accountID,
+	}
+}
+
+func TestClient_AddOrders(t *testing.T) {
+	ctx := context.Background()
+	accountID := uuid.New()
+	orderRowID := uuid.New()
+
+	var objects []map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			objects = requestFromContext(ctx).vars["objects"].([]map[string]interface{})
+			// Hasura returns BIGINT timestamps and NUMERIC values as JSON numbers
+			data := `{"insert_orders": {"returning": [{
+				"id": "` + orderRowID.String() + `",
+				"order_id": "101",
+				"base_asset": "BTC",
+				"quote_asset": "USDC",
+				"side": "buy",
+				"price": 65000.5,
+				"size": 0.25,
+				"status": "filled",
+				"timestamp": 1700000000000,
+				"exchange_account_id": "` + accountID.String() + `",
+				"created_at": "2023-11-14T22:13:25.123456+00:00"
+			}]}}`
+			return json.Unmarshal([]byte(data), resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	orders, err := client.AddOrders(ctx, []*OrderInput{testOrderInput(accountID, "101")})
+	if err != nil {
+		t.Fatalf("AddOrders failed: %v", err)
+	}
+
+	if len(objects) != 1 || objects[0]["timestamp"] != int64(1700000000000) || objects[0]["size"] != "0.1" {
+		t.Errorf("Unexpected insert objects: %v", objects)
+	}
+	if len(orders) != 1 {
+		t.Fatalf("Expected 1 order, got %d", len(orders))
+	}
+	order := orders[0]
+	if order.ID != orderRowID || order.OrderID != "101" {
+		t.Errorf("Expected order %s/101, got %s/%s", orderRowID, order.ID, order.OrderID)
+	}
+	if order.Price != "65000.5" || order.Size != "0.25" {
+		t.Errorf("Expected NUMERIC strings 65000.5/0.25, got %s/%s", order.Price, order.Size)
+	}
+	if !order.Timestamp.Equal(time.UnixMilli(1700000000000)) {
+		t.Errorf("Expected timestamp from millis, got %v", order.Timestamp)
+	}
+	if order.CreatedAt.IsZero() {
+		t.Error("Expected created_at to be parsed")
+	}
+}
+
+func TestClient_AddOrders_IgnoresExisting(t *testing.T) {
+	ctx := context.Background()
+	accountID := uuid.New()
+
+	var query string
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			respData := map[string]interface{}{
+				"insert_orders": map[string]interface{}{
+					"returning": []*models.Order{{ID: uuid.New(), OrderID: "102", ExchangeAccountID: accountID}},
+				},
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	orders, err := client.AddOrders(ctx, []*OrderInput{testOrderInput(accountID, "101"), testOrderInput(accountID, "102")})
+	if err != nil {
+		t.Fatalf("AddOrders failed: %v", err)
+	}
+
+	if !strings.Contains(query, "on_conflict: { constraint: orders_exchange_account_id_order_id_key, update_columns: [] }") {
+		t.Errorf("Expected conflicting orders to be ignored, got: %s", query)
+	}
+	if len(orders) != 1 || orders[0].OrderID != "102" {
+		t.Errorf("Expected only the newly inserted order, got %d", len(orders))
+	}
+}
+
+func TestClient_AddOrders_InvalidInput(t *testing.T) {
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			t.Error("AddOrders should not call GraphQL with invalid input")
+			return nil
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	input := testOrderInput(uuid.New(), "")
+	input.Side = "long"
+
+	_, err := client.AddOrders(context.Background(), []*OrderInput{input})
+	var verr *models.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *models.ValidationError, got: %v", err)
+	}
+	if len(verr.Fields) != 2 {
+		t.Errorf("Expected 2 invalid fields, got %+v", verr.Fields)
+	}
+}
+
+func TestClient_GetOrdersByAccount(t *testing.T) {
+	ctx := context.Background()
+	accountID := uuid.New()
+	asset := "BTC"
+	since := time.UnixMilli(1700000000000)
+
+	var query string
+	var vars map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			vars = requestFromContext(ctx).vars
+			respData := map[string]interface{}{
+				"orders": []*models.Order{
+					{ID: uuid.New(), OrderID: "102", ExchangeAccountID: accountID, Status: "open"},
+					{ID: uuid.New(), OrderID: "101", ExchangeAccountID: accountID, Status: "open"},
+				},
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	orders, err := client.GetOrdersByAccount(ctx, accountID, OrderFilter{
+		BaseAsset:    &asset,
+		Statuses:     []string{"open"},
+		TimestampGte: &since,
+		Limit:        10,
+	})
+	if err != nil {
+		t.Fatalf("GetOrdersByAccount failed: %v", err)
+	}
+
+	if len(orders) != 2 {
+		t.Fatalf("Expected 2 orders, got %d", len(orders))
+	}
+	if vars["exchange_account_id"] != accountID.String() || vars["base_asset"] != "BTC" || vars["timestamp_gte"] != since.UnixMilli() {
+		t.Errorf("Unexpected variables: %v", vars)
+	}
+	if statuses, ok := vars["statuses"].([]string); !ok || len(statuses) != 1 || statuses[0] != "open" {
+		t.Errorf("Expected statuses [open], got %v", vars["statuses"])
+	}
+	for _, want := range []string{"status: { _in: $statuses }", "order_by: [{ timestamp: desc }, { id: desc }]", "limit: $limit"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("Expected query to contain %q, got: %s", want, query)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package db
+
+import "fmt"
+
+// Page is a single page of results returned by the *Page list methods
+type Page[T any] struct {
+	Items      []T  // Rows in this page (at most Limit)
+	TotalCount *int // Total rows matching the filter; nil unless PageOptions.IncludeTotalCount was set
+	HasMore    bool // Whether at least one more row exists after this page
+	NextOffset int  // Offset to pass to fetch the next page
+}
+
+// PageOptions controls what a *Page list method returns in addition to the rows
+type PageOptions struct {
+	// IncludeTotalCount adds an aggregate count to the same GraphQL request (one round trip)
+	IncludeTotalCount bool
+}
+
+// paginationArgs declares $limit/$offset on the builder and returns the matching query arguments.
+// limit <= 0 means no limit. Returns "" when neither limit nor offset applies.
+func paginationArgs(b *whereBuilder, limit, offset int) string {
+	args := ""
+	if limit > 0 {
+		b.declare("limit", "Int!", limit)
+		args += "limit: $limit\n"
+	}
+	if offset > 0 {
+		b.declare("offset", "Int!", offset)
+		args += "offset: $offset\n"
+	}
+	return args
+}
+
+// newPage builds a Page from rows fetched with a one-row look-ahead (limit+1).
+// The extra row, if present, is dropped and signals HasMore.
+func newPage[T any](rows []T, limit, offset int, total *int) *Page[T] {
+	page := &Page[T]{
+		Items:      rows,
+		TotalCount: total,
+	}
+
+	if limit > 0 && len(rows) > limit {
+		page.Items = rows[:limit]
+		page.HasMore = true
+	}
+	page.NextOffset = offset + len(page.Items)
+
+	return page
+}
+
+// aggregateCount decodes a Hasura "<table>_aggregate { aggregate { count } }" selection
+type aggregateCount struct {
+	Aggregate *struct {
+		Count int `json:"count"`
+	} `json:"aggregate"`
+}
+
+// total returns the decoded count when it was requested, nil otherwise
+func (a aggregateCount) total(opts PageOptions) *int {
+	if !opts.IncludeTotalCount || a.Aggregate == nil {
+		return nil
+	}
+	count := a.Aggregate.Count
+	return &count
+}
+
+// aggregateSelection returns a "<field>(where: ...) { aggregate { count } }" selection
+// sharing the builder's where clause, for inclusion next to the list selection
+func aggregateSelection(field string, b *whereBuilder) string {
+	args := ""
+	if !b.empty() {
+		args = "(" + b.whereArg() + ")"
+	}
+	return fmt.Sprintf(`
+				%s%s {
+					aggregate {
+						count
+					}
+				}`, field, args)
+}
@@ -0,0 +1,215 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/models"
+)
+
+func TestClient_ListTradesPage_WithTotalCount(t *testing.T) {
+	ctx := context.Background()
+	accountID := uuid.New()
+
+	trades := make([]*models.Trade, 3)
+	for i := range trades {
+		trades[i] = &models.Trade{
+			ID:                uuid.New(),
+			BaseAsset:         "BTC",
+			QuoteAsset:        "USDC",
+			Side:              "buy",
+			Price:             "50000",
+			Quantity:          "0.1",
+			Timestamp:         time.Now(),
+			Fee:               "1",
+			TradeID:           uuid.New().String(),
+			ExchangeAccountID: accountID,
+		}
+	}
+
+	var query string
+	var vars map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			vars = requestFromContext(ctx).vars
+			respData := map[string]interface{}{
+				"trades": trades, // limit+1 rows -> more pages exist
+				"trades_aggregate": map[string]interface{}{
+					"aggregate": map[string]interface{}{"count": 7},
+				},
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	filter := models.TradeFilter{
+		ExchangeAccountIDs: []uuid.UUID{accountID},
+		Limit:              2,
+		Offset:             4,
+	}
+
+	page, err := client.ListTradesPage(ctx, filter, PageOptions{IncludeTotalCount: true})
+	if err != nil {
+		t.Fatalf("ListTradesPage failed: %v", err)
+	}
+
+	if !strings.Contains(query, "trades(") || !strings.Contains(query, "trades_aggregate(") {
+		t.Errorf("Expected query to select both trades and trades_aggregate, got: %s", query)
+	}
+	if !strings.Contains(query, "aggregate {") || !strings.Contains(query, "count") {
+		t.Errorf("Expected aggregate count selection in query, got: %s", query)
+	}
+	if vars["limit"] != 3 {
+		t.Errorf("Expected look-ahead limit 3, got %v", vars["limit"])
+	}
+	if vars["offset"] != 4 {
+		t.Errorf("Expected offset 4, got %v", vars["offset"])
+	}
+
+	if len(page.Items) != 2 {
+		t.Errorf("Expected 2 items, got %d", len(page.Items))
+	}
+	if !page.HasMore {
+		t.Error("Expected HasMore to be true")
+	}
+	if page.NextOffset != 6 {
+		t.Errorf("Expected NextOffset 6, got %d", page.NextOffset)
+	}
+	if page.TotalCount == nil || *page.TotalCount != 7 {
+		t.Errorf("Expected TotalCount 7, got %v", page.TotalCount)
+	}
+}
+
+func TestClient_ListTradesPage_WithoutTotalCount(t *testing.T) {
+	ctx := context.Background()
+
+	var query string
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			respData := map[string]interface{}{
+				"trades": []*models.Trade{{ID: uuid.New(), Timestamp: time.Now()}},
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	page, err := client.ListTradesPage(ctx, models.TradeFilter{Limit: 10}, PageOptions{})
+	if err != nil {
+		t.Fatalf("ListTradesPage failed: %v", err)
+	}
+
+	if strings.Contains(query, "trades_aggregate") {
+		t.Errorf("Expected no aggregate selection when count not requested, got: %s", query)
+	}
+	if page.TotalCount != nil {
+		t.Errorf("Expected nil TotalCount, got %d", *page.TotalCount)
+	}
+	if page.HasMore {
+		t.Error("Expected HasMore to be false")
+	}
+	if page.NextOffset != 1 {
+		t.Errorf("Expected NextOffset 1, got %d", page.NextOffset)
+	}
+}
+
+func TestClient_GetPositionsPage_WithTotalCount(t *testing.T) {
+	ctx := context.Background()
+	side := "long"
+
+	var query string
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			respData := map[string]interface{}{
+				"positions": []map[string]interface{}{
+					{"id": uuid.New().String(), "side": "long", "start_time": 1, "end_time": 2},
+				},
+				"positions_aggregate": map[string]interface{}{
+					"aggregate": map[string]interface{}{"count": 1},
+				},
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	page, err := client.GetPositionsPage(ctx, models.PositionFilter{Side: &side, Limit: 5}, PageOptions{IncludeTotalCount: true})
+	if err != nil {
+		t.Fatalf("GetPositionsPage failed: %v", err)
+	}
+
+	if !strings.Contains(query, "positions_aggregate(where: { side: { _eq: $side } })") {
+		t.Errorf("Expected aggregate to share the where clause, got: %s", query)
+	}
+	if len(page.Items) != 1 || page.HasMore {
+		t.Errorf("Expected 1 item and no more pages, got %d items (HasMore=%v)", len(page.Items), page.HasMore)
+	}
+	if page.TotalCount == nil || *page.TotalCount != 1 {
+		t.Errorf("Expected TotalCount 1, got %v", page.TotalCount)
+	}
+}
+
+func TestClient_ListFundingPaymentsPage_WithTotalCount(t *testing.T) {
+	ctx := context.Background()
+
+	var query string
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			respData := map[string]interface{}{
+				"funding_payments": []map[string]interface{}{
+					{"id": uuid.New().String(), "amount": "1.5", "timestamp": 1609459200000},
+				},
+				"funding_payments_aggregate": map[string]interface{}{
+					"aggregate": map[string]interface{}{"count": 1},
+				},
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	page, err := client.ListFundingPaymentsPage(ctx, models.FundingPaymentFilter{}, PageOptions{IncludeTotalCount: true})
+	if err != nil {
+		t.Fatalf("ListFundingPaymentsPage failed: %v", err)
+	}
+
+	if !strings.Contains(query, "funding_payments(") || !strings.Contains(query, "funding_payments_aggregate {") {
+		t.Errorf("Expected list and aggregate selections in query, got: %s", query)
+	}
+	if len(page.Items) != 1 || page.Items[0].Amount != "1.5" {
+		t.Errorf("Expected one payment with amount 1.5, got %+v", page.Items)
+	}
+	if page.TotalCount == nil || *page.TotalCount != 1 {
+		t.Errorf("Expected TotalCount 1, got %v", page.TotalCount)
+	}
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/models"
+)
+
+// PnLBucket represents the realized PnL of one time bucket (aliased from models package)
+type PnLBucket = models.PnLBucket
+
+// GetRealizedPnLBuckets sums the realized PnL of an account's closed positions per time bucket
+// of the given size, by position end_time. Buckets are aligned to multiples of bucket since the
+// zero time, so hours and days start on UTC boundaries. filter narrows the positions as in
+// GetPositions; its ExchangeAccountIDs are replaced by accountID
+// Buckets are returned oldest first; buckets without closed positions are omitted
+func (c *Client) GetRealizedPnLBuckets(ctx context.Context, accountID uuid.UUID, filter PositionFilter, bucket time.Duration) ([]PnLBucket, error) {
+	if bucket <= 0 {
+		return nil, fmt.Errorf("failed to get realized PnL buckets: bucket must be positive, got %s", bucket)
+	}
+
+	filter.ExchangeAccountIDs = []uuid.UUID{accountID}
+	b, err := buildPositionWhere(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get realized PnL buckets: %w", err)
+	}
+	pagination := paginationArgs(b, filter.Limit, filter.Offset)
+
+	// Only the columns bucketing needs; sums are done in Go to keep NUMERIC precision
+	query := fmt.Sprintf(`
+		query GetRealizedPnLBuckets%s {
+			positions(
+				%s
+				order_by: [{ end_time: asc }, { id: asc }]
+				%s
+			) {
+				end_time
+				realized_pnl
+			}
+		}
+	`, b.declarations(), b.whereArg(), pagination)
+
+	req := c.graphqlRequestWithVars(query, b.variables())
+
+	var resp struct {
+		Positions []*Position `json:"positions"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get realized PnL buckets: %w", err)
+	}
+
+	buckets := []PnLBucket{}
+	var sum *big.Rat
+	flush := func() {
+		if sum != nil {
+			buckets[len(buckets)-1].RealizedPnL = models.FormatNumeric(sum)
+		}
+	}
+	for _, position := range resp.Positions {
+		if position.EndTime == nil {
+			continue // still open, nothing realized yet
+		}
+		pnl, err := models.ParseNumeric(position.RealizedPnL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get realized PnL buckets: position closed at %s: %w", position.EndTime, err)
+		}
+
+		start := position.EndTime.Truncate(bucket).UTC()
+		if len(buckets) == 0 || !buckets[len(buckets)-1].Start.Equal(start) {
+			flush()
+			buckets = append(buckets, PnLBucket{Start: start})
+			sum = new(big.Rat)
+		}
+		sum.Add(sum, pnl)
+		buckets[len(buckets)-1].Positions++
+	}
+	flush()
+
+	return buckets, nil
+}
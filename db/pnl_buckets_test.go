@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+)
+
+func TestClient_GetRealizedPnLBuckets(t *testing.T) {
+	accountID := uuid.New()
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// Rows come back ordered by end_time (nulls last), as the query asks
+	rows := []map[string]interface{}{
+		{"end_time": day.Add(1 * time.Hour).UnixMilli(), "realized_pnl": "0.1"},
+		{"end_time": day.Add(23 * time.Hour).UnixMilli(), "realized_pnl": "0.2"},
+		{"end_time": day.Add(24 * time.Hour).UnixMilli(), "realized_pnl": "-5"},
+		{"end_time": day.Add(72*time.Hour + time.Minute).UnixMilli(), "realized_pnl": "12.000000000000000001"},
+		{"end_time": day.Add(72*time.Hour + 2*time.Minute).UnixMilli(), "realized_pnl": "-2"},
+		{"end_time": nil, "realized_pnl": "7"}, // open positions sort last and are skipped
+	}
+
+	var query string
+	var vars map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			vars = requestFromContext(ctx).vars
+			data, _ := json.Marshal(map[string]interface{}{"positions": rows})
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	other := uuid.New()
+	base := "BTC"
+	buckets, err := client.GetRealizedPnLBuckets(context.Background(), accountID, PositionFilter{
+		ExchangeAccountIDs: []uuid.UUID{other},
+		BaseAsset:          &base,
+	}, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("GetRealizedPnLBuckets failed: %v", err)
+	}
+
+	if !strings.Contains(query, "end_time: asc") {
+		t.Errorf("Expected positions ordered by end_time, got: %s", query)
+	}
+	if ids, _ := vars["exchange_account_ids"].([]string); len(ids) != 1 || ids[0] != accountID.String() {
+		t.Errorf("Expected the filter to be scoped to the account, got %v", vars["exchange_account_ids"])
+	}
+	if vars["base_asset"] != "BTC" {
+		t.Errorf("Expected the rest of the filter to apply, got %v", vars)
+	}
+
+	want := []PnLBucket{
+		{Start: day, RealizedPnL: "0.3", Positions: 2},
+		{Start: day.Add(24 * time.Hour), RealizedPnL: "-5", Positions: 1},
+		{Start: day.Add(72 * time.Hour), RealizedPnL: "10.000000000000000001", Positions: 2},
+	}
+	if len(buckets) != len(want) {
+		t.Fatalf("Expected %d buckets, got %+v", len(want), buckets)
+	}
+	for i, bucket := range buckets {
+		if !bucket.Start.Equal(want[i].Start) || bucket.RealizedPnL != want[i].RealizedPnL || bucket.Positions != want[i].Positions {
+			t.Errorf("Bucket %d: expected %+v, got %+v", i, want[i], bucket)
+		}
+	}
+
+	hourly, err := client.GetRealizedPnLBuckets(context.Background(), accountID, PositionFilter{}, time.Hour)
+	if err != nil {
+		t.Fatalf("GetRealizedPnLBuckets failed: %v", err)
+	}
+	if len(hourly) != 4 || !hourly[1].Start.Equal(day.Add(23*time.Hour)) || hourly[1].RealizedPnL != "0.2" {
+		t.Errorf("Expected 4 hourly buckets with the 23:00 bucket holding 0.2, got %+v", hourly)
+	}
+}
+
+func TestClient_GetRealizedPnLBuckets_Empty(t *testing.T) {
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			return json.Unmarshal([]byte(`{"positions": []}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	buckets, err := client.GetRealizedPnLBuckets(context.Background(), uuid.New(), PositionFilter{}, time.Hour)
+	if err != nil {
+		t.Fatalf("GetRealizedPnLBuckets failed: %v", err)
+	}
+	if buckets == nil || len(buckets) != 0 {
+		t.Errorf("Expected an empty non-nil slice, got %v", buckets)
+	}
+
+	if _, err := client.GetRealizedPnLBuckets(context.Background(), uuid.New(), PositionFilter{}, 0); err == nil {
+		t.Error("Expected a zero bucket size to be rejected")
+	}
+}
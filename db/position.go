@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -9,6 +10,14 @@ import (
 	"github.com/zif-terminal/lib/models"
 )
 
+func init() {
+	registerOperations(map[string]Idempotency{
+		"CreatePosition":               NotIdempotent, // Plain insert
+		"CreatePositionTrades":         NotIdempotent, // Plain insert
+		"DeleteOrphanedPositionTrades": Idempotent,    // Deleting by condition again removes nothing new
+	})
+}
+
 // Position represents a position model (aliased from models package)
 type Position = models.Position
 
@@ -46,7 +55,7 @@ func (c *Client) GetLastProcessedTradeTimestamp(
 						quote_asset: { _eq: $quote_asset }
 					}
 				}
-				order_by: { trade: { timestamp: desc } }
+				order_by: [{ trade: { timestamp: desc } }, { trade: { id: desc } }]
 				limit: 1
 			) {
 				trade {
@@ -57,7 +66,7 @@ func (c *Client) GetLastProcessedTradeTimestamp(
 	`
 
 	vars := map[string]interface{}{
-		"exchange_account_id": exchangeAccountID.String(),
+		"exchange_account_id": uuidVar(exchangeAccountID),
 		"base_asset":          baseAsset,
 		"quote_asset":         quoteAsset,
 	}
@@ -130,7 +139,7 @@ func (c *Client) CreatePosition(ctx context.Context, input *PositionInput) (*Pos
 	`
 
 	vars := map[string]interface{}{
-		"exchange_account_id": input.ExchangeAccountID.String(),
+		"exchange_account_id": uuidVar(input.ExchangeAccountID),
 		"base_asset":          input.BaseAsset,
 		"quote_asset":         input.QuoteAsset,
 		"side":                input.Side,
@@ -143,6 +152,13 @@ func (c *Client) CreatePosition(ctx context.Context, input *PositionInput) (*Pos
 		"realized_pnl":        input.RealizedPnL,
 	}
 
+	if err := normalizeSideField("position", vars, models.NormalizePositionSide); err != nil {
+		return nil, fmt.Errorf("failed to create position: %w", err)
+	}
+	if err := encodeNumericFields("position", vars, "entry_avg_price", "exit_avg_price", "total_quantity", "total_fees", "realized_pnl"); err != nil {
+		return nil, fmt.Errorf("failed to create position: %w", err)
+	}
+
 	req := c.graphqlRequestWithVars(query, vars)
 
 	var resp struct {
@@ -161,6 +177,7 @@ func (c *Client) CreatePosition(ctx context.Context, input *PositionInput) (*Pos
 }
 
 // CreatePositionTrades batch inserts trade allocations for positions
+// Batches over ClientConfig.MaxRequestBytes are split into several requests
 func (c *Client) CreatePositionTrades(ctx context.Context, inputs []*PositionTradeInput) ([]*PositionTrade, error) {
 	if len(inputs) == 0 {
 		return []*PositionTrade{}, nil
@@ -184,124 +201,231 @@ func (c *Client) CreatePositionTrades(ctx context.Context, inputs []*PositionTra
 	objects := make([]map[string]interface{}, len(inputs))
 	for i, input := range inputs {
 		objects[i] = map[string]interface{}{
-			"position_id":           input.PositionID.String(),
-			"trade_id":              input.TradeID.String(),
+			"position_id":           uuidVar(input.PositionID),
+			"trade_id":              uuidVar(input.TradeID),
 			"allocation_percentage": input.AllocationPercentage,
 			"allocated_quantity":    input.AllocatedQuantity,
 			"allocated_fees":        input.AllocatedFees,
 		}
+		if err := encodeNumericFields("position trade", objects[i], "allocation_percentage", "allocated_quantity", "allocated_fees"); err != nil {
+			return nil, fmt.Errorf("failed to create position trades: input %d: %w", i, err)
+		}
 	}
 
-	vars := map[string]interface{}{
-		"objects": objects,
+	inserted, err := insertChunked(c, query, objects, func(req *request) ([]*PositionTrade, error) {
+		var resp struct {
+			InsertPositionTrades struct {
+				Returning []*PositionTrade `json:"returning"`
+			} `json:"insert_position_trades"`
+		}
+		if err := c.execute(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+		return resp.InsertPositionTrades.Returning, nil
+	})
+	if err != nil {
+		return inserted, fmt.Errorf("failed to create position trades: %w", err)
 	}
 
-	req := c.graphqlRequestWithVars(query, vars)
+	return inserted, nil
+}
+
+// FindOrphanedPositionTrades returns trade allocations whose position no longer exists
+// Deleting a position does not delete its allocations in the same transaction, so a failure in
+// between leaves them behind; this and DeleteOrphanedPositionTrades are maintenance tools for that
+func (c *Client) FindOrphanedPositionTrades(ctx context.Context) ([]*PositionTrade, error) {
+	query := `
+		query FindOrphanedPositionTrades {
+			position_trades(
+				where: { _not: { position: {} } }
+				order_by: [{ position_id: asc }, { trade_id: asc }]
+			) {
+				position_id
+				trade_id
+				allocation_percentage
+				allocated_quantity
+				allocated_fees
+			}
+		}
+	`
+
+	req := c.graphqlRequest(query)
 
 	var resp struct {
-		InsertPositionTrades struct {
-			Returning []*PositionTrade `json:"returning"`
-		} `json:"insert_position_trades"`
+		PositionTrades []*PositionTrade `json:"position_trades"`
 	}
 
 	if err := c.execute(ctx, req, &resp); err != nil {
-		return nil, fmt.Errorf("failed to create position trades: %w", err)
+		return nil, fmt.Errorf("failed to find orphaned position trades: %w", err)
 	}
 
-	return resp.InsertPositionTrades.Returning, nil
+	return resp.PositionTrades, nil
+}
+
+// DeleteOrphanedPositionTrades deletes trade allocations whose position no longer exists
+// Returns the number of allocations removed
+func (c *Client) DeleteOrphanedPositionTrades(ctx context.Context) (int, error) {
+	query := `
+		mutation DeleteOrphanedPositionTrades {
+			delete_position_trades(where: { _not: { position: {} } }) {
+				affected_rows
+			}
+		}
+	`
+
+	req := c.graphqlRequest(query)
+
+	var resp struct {
+		DeletePositionTrades struct {
+			AffectedRows int `json:"affected_rows"`
+		} `json:"delete_position_trades"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return 0, fmt.Errorf("failed to delete orphaned position trades: %w", err)
+	}
+
+	return resp.DeletePositionTrades.AffectedRows, nil
 }
 
 // GetPositions queries closed positions with various filters
 func (c *Client) GetPositions(ctx context.Context, filter PositionFilter) ([]*Position, error) {
-	// Build where clause dynamically based on filter
-	whereClause := ""
-	vars := make(map[string]interface{})
+	b, err := buildPositionWhere(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+	query := getPositionsQuery(b, paginationArgs(b, filter.Limit, filter.Offset), false)
+
+	req := c.graphqlRequestWithVars(query, b.variables())
+
+	var resp struct {
+		Positions []*Position `json:"positions"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	return resp.Positions, nil
+}
+
+// GetPositionsPage retrieves a single page of closed positions using filter.Limit/filter.Offset
+// When opts.IncludeTotalCount is set, the matching row count is fetched in the same request
+func (c *Client) GetPositionsPage(ctx context.Context, filter PositionFilter, opts PageOptions) (*Page[*Position], error) {
+	b, err := buildPositionWhere(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions page: %w", err)
+	}
+	limit := filter.Limit
+	if limit > 0 {
+		limit++ // Look ahead one row to determine HasMore
+	}
+	query := getPositionsQuery(b, paginationArgs(b, limit, filter.Offset), opts.IncludeTotalCount)
+
+	req := c.graphqlRequestWithVars(query, b.variables())
+
+	var resp struct {
+		Positions          []*Position    `json:"positions"`
+		PositionsAggregate aggregateCount `json:"positions_aggregate"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get positions page: %w", err)
+	}
+
+	return newPage(resp.Positions, filter.Limit, filter.Offset, resp.PositionsAggregate.total(opts)), nil
+}
+
+// buildPositionWhere translates a PositionFilter into where-clause conditions
+// PnL thresholds are validated and sent as numeric variables to match the NUMERIC column
+func buildPositionWhere(filter PositionFilter) (*whereBuilder, error) {
+	b := newWhereBuilder()
 
 	if len(filter.ExchangeAccountIDs) > 0 {
 		accountIDs := make([]string, len(filter.ExchangeAccountIDs))
 		for i, id := range filter.ExchangeAccountIDs {
-			accountIDs[i] = id.String()
+			accountIDs[i] = uuidVar(id)
 		}
-		vars["exchange_account_ids"] = accountIDs
-		whereClause += "exchange_account_id: { _in: $exchange_account_ids }\n"
+		b.add("exchange_account_id", "_in", "exchange_account_ids", "[uuid!]!", accountIDs)
 	}
 
 	if filter.BaseAsset != nil {
-		vars["base_asset"] = *filter.BaseAsset
-		whereClause += "base_asset: { _eq: $base_asset }\n"
+		b.add("base_asset", "_eq", "base_asset", "String!", *filter.BaseAsset)
 	}
 
 	if filter.QuoteAsset != nil {
-		vars["quote_asset"] = *filter.QuoteAsset
-		whereClause += "quote_asset: { _eq: $quote_asset }\n"
+		b.add("quote_asset", "_eq", "quote_asset", "String!", *filter.QuoteAsset)
 	}
 
 	if filter.Side != nil {
-		vars["side"] = *filter.Side
-		whereClause += "side: { _eq: $side }\n"
+		b.add("side", "_eq", "side", "String!", *filter.Side)
 	}
 
 	if filter.StartTimeGte != nil {
-		vars["start_time_gte"] = filter.StartTimeGte.UnixMilli()
-		whereClause += "start_time: { _gte: $start_time_gte }\n"
+		b.add("start_time", "_gte", "start_time_gte", "bigint!", filter.StartTimeGte.UnixMilli())
 	}
 
 	if filter.StartTimeLte != nil {
-		vars["start_time_lte"] = filter.StartTimeLte.UnixMilli()
-		whereClause += "start_time: { _lte: $start_time_lte }\n"
+		b.add("start_time", "_lte", "start_time_lte", "bigint!", filter.StartTimeLte.UnixMilli())
 	}
 
 	if filter.EndTimeGte != nil {
-		vars["end_time_gte"] = filter.EndTimeGte.UnixMilli()
-		whereClause += "end_time: { _gte: $end_time_gte }\n"
+		b.add("end_time", "_gte", "end_time_gte", "bigint!", filter.EndTimeGte.UnixMilli())
 	}
 
 	if filter.EndTimeLte != nil {
-		vars["end_time_lte"] = filter.EndTimeLte.UnixMilli()
-		whereClause += "end_time: { _lte: $end_time_lte }\n"
+		b.add("end_time", "_lte", "end_time_lte", "bigint!", filter.EndTimeLte.UnixMilli())
 	}
 
-	// Build query with dynamic variable declarations
-	varDeclarations := ""
-	if len(filter.ExchangeAccountIDs) > 0 {
-		varDeclarations += "$exchange_account_ids: [uuid!]!, "
-	}
-	if filter.BaseAsset != nil {
-		varDeclarations += "$base_asset: String!, "
+	// A position overlaps the window if it opened at or before the window ends and closed at or
+	// after the window starts, or is still open; a zero bound leaves that side open. The conditions
+	// are separate _and entries so they combine with StartTimeLte and EndTimeGte
+	if filter.Overlaps != nil {
+		if !filter.Overlaps.End.IsZero() {
+			b.addAnd("start_time", "_lte", "overlaps_end", "bigint!", filter.Overlaps.End.UnixMilli())
+		}
+		if !filter.Overlaps.Start.IsZero() {
+			b.addOrNull("end_time", "_gte", "overlaps_start", "bigint!", filter.Overlaps.Start.UnixMilli())
+		}
 	}
-	if filter.QuoteAsset != nil {
-		varDeclarations += "$quote_asset: String!, "
+
+	if filter.UserID != nil {
+		b.add("exchange_account.user_id", "_eq", "user_id", "uuid!", *filter.UserID)
 	}
-	if filter.Side != nil {
-		varDeclarations += "$side: String!, "
+
+	thresholds := map[string]interface{}{}
+	if filter.RealizedPnLLte != nil {
+		thresholds["realized_pnl_lte"] = *filter.RealizedPnLLte
 	}
-	if filter.StartTimeGte != nil {
-		varDeclarations += "$start_time_gte: bigint!, "
+	if filter.RealizedPnLGte != nil {
+		thresholds["realized_pnl_gte"] = *filter.RealizedPnLGte
 	}
-	if filter.StartTimeLte != nil {
-		varDeclarations += "$start_time_lte: bigint!, "
+	if err := encodeNumericFields("position filter", thresholds, "realized_pnl_lte", "realized_pnl_gte"); err != nil {
+		return nil, err
 	}
-	if filter.EndTimeGte != nil {
-		varDeclarations += "$end_time_gte: bigint!, "
+	if v, ok := thresholds["realized_pnl_lte"]; ok {
+		b.add("realized_pnl", "_lte", "realized_pnl_lte", "numeric!", v)
 	}
-	if filter.EndTimeLte != nil {
-		varDeclarations += "$end_time_lte: bigint!, "
+	if v, ok := thresholds["realized_pnl_gte"]; ok {
+		b.add("realized_pnl", "_gte", "realized_pnl_gte", "numeric!", v)
 	}
 
-	// Remove trailing comma and space
-	if len(varDeclarations) > 0 {
-		varDeclarations = varDeclarations[:len(varDeclarations)-2]
+	return b, nil
+}
+
+// getPositionsQuery builds the GetPositions query, optionally including an aggregate count
+func getPositionsQuery(b *whereBuilder, pagination string, withCount bool) string {
+	aggregate := ""
+	if withCount {
+		aggregate = aggregateSelection("positions_aggregate", b)
 	}
 
-	var query string
-	if whereClause != "" {
-		query = fmt.Sprintf(`
-			query GetPositions(%s) {
+	return fmt.Sprintf(`
+			query GetPositions%s {
 				positions(
-					where: {
-						%s
-					}
-					order_by: { end_time: desc }
+					%s
+					order_by: [{ end_time: desc }, { id: desc }]
+					%s
 				) {
 					id
 					exchange_account_id
@@ -315,47 +439,66 @@ func (c *Client) GetPositions(ctx context.Context, filter PositionFilter) ([]*Po
 					total_quantity
 					total_fees
 					realized_pnl
-				}
+				}%s
 			}
-		`, varDeclarations, whereClause)
-	} else {
-		query = `
-			query GetPositions {
-				positions(order_by: { end_time: desc }) {
-					id
-					exchange_account_id
-					base_asset
-					quote_asset
-					side
-					start_time
-					end_time
-					entry_avg_price
-					exit_avg_price
-					total_quantity
-					total_fees
-					realized_pnl
+		`, b.declarations(), b.whereArg(), pagination, aggregate)
+}
+
+// GetPositionByID retrieves a single position with all associated trades
+func (c *Client) GetPositionByID(ctx context.Context, positionID string) (*Position, []*PositionTrade, error) {
+	query := `
+		query GetPositionWithTrades($id: uuid!) {
+			positions_by_pk(id: $id) {
+				id
+				exchange_account_id
+				base_asset
+				quote_asset
+				side
+				start_time
+				end_time
+				entry_avg_price
+				exit_avg_price
+				total_quantity
+				total_fees
+				realized_pnl
+				position_trades {
+					position_id
+					trade_id
+					allocation_percentage
+					allocated_quantity
+					allocated_fees
 				}
 			}
-		`
+		}
+	`
+
+	vars := map[string]interface{}{
+		"id": positionID,
 	}
 
 	req := c.graphqlRequestWithVars(query, vars)
 
 	var resp struct {
-		Positions []*Position `json:"positions"`
+		PositionsByPk *positionDetailRow `json:"positions_by_pk"`
 	}
 
 	if err := c.execute(ctx, req, &resp); err != nil {
-		return nil, fmt.Errorf("failed to get positions: %w", err)
+		return nil, nil, fmt.Errorf("failed to get position: %w", err)
 	}
 
-	return resp.Positions, nil
+	if resp.PositionsByPk == nil {
+		return nil, nil, fmt.Errorf("position not found: %s", positionID)
+	}
+
+	position := &resp.PositionsByPk.Position
+	return position, resp.PositionsByPk.allocations(), nil
 }
 
-// GetPositionByID retrieves a single position with all associated trades
-func (c *Client) GetPositionByID(ctx context.Context, positionID string) (*Position, []*PositionTrade, error) {
+// GetPositionDetail retrieves a position, its trade allocations and the allocated trades in one query
+// Trades are returned in allocation order
+func (c *Client) GetPositionDetail(ctx context.Context, positionID string) (*Position, []*PositionTrade, []*Trade, error) {
 	query := `
-		query GetPositionWithTrades($id: uuid!) {
+		query GetPositionDetail($id: uuid!) {
 			positions_by_pk(id: $id) {
 				id
 				exchange_account_id
@@ -375,32 +518,103 @@ func (c *Client) GetPositionByID(ctx context.Context, positionID string) (*Posit
 					allocation_percentage
 					allocated_quantity
 					allocated_fees
+					trade {
+						id
+						base_asset
+						quote_asset
+						side
+						price
+						quantity
+						timestamp
+						fee
+						order_id
+						trade_id
+						exchange_account_id
+						created_at
+						source
+						fee_asset
+						market_type
+						is_taker
+					}
 				}
 			}
 		}
 	`
 
-	vars := map[string]interface{}{
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
 		"id": positionID,
-	}
-
-	req := c.graphqlRequestWithVars(query, vars)
+	})
 
 	var resp struct {
-		PositionsByPk *struct {
-			Position
-			PositionTrades []*PositionTrade `json:"position_trades"`
-		} `json:"positions_by_pk"`
+		PositionsByPk *positionDetailRow `json:"positions_by_pk"`
 	}
 
 	if err := c.execute(ctx, req, &resp); err != nil {
-		return nil, nil, fmt.Errorf("failed to get position: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to get position detail: %w", err)
 	}
 
 	if resp.PositionsByPk == nil {
-		return nil, nil, fmt.Errorf("position not found: %s", positionID)
+		return nil, nil, nil, fmt.Errorf("position not found: %s", positionID)
 	}
 
-	position := &resp.PositionsByPk.Position
-	return position, resp.PositionsByPk.PositionTrades, nil
+	row := resp.PositionsByPk
+	trades := make([]*Trade, 0, len(row.PositionTrades))
+	for _, allocation := range row.PositionTrades {
+		if allocation.Trade != nil {
+			trades = append(trades, allocation.Trade)
+		}
+	}
+
+	return &row.Position, row.allocations(), trades, nil
+}
+
+// positionDetailRow is a position with its nested allocations
+// Position and PositionTrade have custom unmarshalers that would otherwise be promoted and
+// swallow the nested fields, so each level decodes its parts separately
+type positionDetailRow struct {
+	Position
+	PositionTrades []*positionTradeRow `json:"position_trades"`
+}
+
+func (r *positionDetailRow) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &r.Position); err != nil {
+		return err
+	}
+	var nested struct {
+		PositionTrades []*positionTradeRow `json:"position_trades"`
+	}
+	if err := json.Unmarshal(data, &nested); err != nil {
+		return err
+	}
+	r.PositionTrades = nested.PositionTrades
+	return nil
+}
+
+// allocations returns the position's trade allocations without the nested trades
+func (r *positionDetailRow) allocations() []*PositionTrade {
+	allocations := make([]*PositionTrade, len(r.PositionTrades))
+	for i, row := range r.PositionTrades {
+		allocations[i] = &row.PositionTrade
+	}
+	return allocations
+}
+
+// positionTradeRow is an allocation with its trade (nil unless the trade was selected)
+type positionTradeRow struct {
+	PositionTrade
+	Trade *Trade `json:"trade"`
+}
+
+func (r *positionTradeRow) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &r.PositionTrade); err != nil {
+		return err
+	}
+	var nested struct {
+		Trade *Trade `json:"trade"`
+	}
+	if err := json.Unmarshal(data, &nested); err != nil {
+		return err
+	}
+	r.Trade = nested.Trade
+	return nil
 }
@@ -0,0 +1,301 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/models"
+)
+
+const positionDetailBody = `{"data":{"positions_by_pk":{
+	"id":"11111111-1111-1111-1111-111111111111",
+	"exchange_account_id":"22222222-2222-2222-2222-222222222222",
+	"base_asset":"BTC","quote_asset":"USDC","side":"long",
+	"start_time":1700000000000,"end_time":1700003600000,
+	"entry_avg_price":"50000","exit_avg_price":"51000","total_quantity":"0.2","total_fees":"1","realized_pnl":"199",
+	"position_trades":[
+		{"position_id":"11111111-1111-1111-1111-111111111111","trade_id":"33333333-3333-3333-3333-333333333333",
+		 "allocation_percentage":1,"allocated_quantity":"0.2","allocated_fees":"0.5",
+		 "trade":{"id":"33333333-3333-3333-3333-333333333333","exchange_account_id":"22222222-2222-2222-2222-222222222222",
+		          "base_asset":"BTC","quote_asset":"USDC","side":"buy","price":"50000","quantity":"0.2","fee":"0.5",
+		          "order_id":"o1","trade_id":"t1","timestamp":1700000000000,"created_at":"2023-11-14T22:13:20Z"}},
+		{"position_id":"11111111-1111-1111-1111-111111111111","trade_id":"44444444-4444-4444-4444-444444444444",
+		 "allocation_percentage":1,"allocated_quantity":"0.2","allocated_fees":"0.5",
+		 "trade":{"id":"44444444-4444-4444-4444-444444444444","exchange_account_id":"22222222-2222-2222-2222-222222222222",
+		          "base_asset":"BTC","quote_asset":"USDC","side":"sell","price":"51000","quantity":"0.2","fee":"0.5",
+		          "order_id":"o2","trade_id":"t2","timestamp":1700003600000,"created_at":"2023-11-14T23:13:20Z"}}
+	]}}}`
+
+func TestClient_GetPositionDetail(t *testing.T) {
+	requests := 0
+	mock := &rawMockGraphQLClient{
+		runRawFunc: func(ctx context.Context, req *graphql.Request) ([]byte, error) {
+			requests++
+			query := requestFromContext(ctx).query
+			if !strings.Contains(query, "position_trades {") || !strings.Contains(query, "trade {") {
+				t.Errorf("Expected nested trade selection, got %s", query)
+			}
+			return []byte(positionDetailBody), nil
+		},
+	}
+	client := NewClientWithGraphQL(mock, ClientConfig{StrictDecoding: true})
+
+	position, allocations, trades, err := client.GetPositionDetail(context.Background(), "11111111-1111-1111-1111-111111111111")
+	if err != nil {
+		t.Fatalf("GetPositionDetail failed: %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected a single request, got %d", requests)
+	}
+	if position.RealizedPnL != "199" || position.BaseAsset != "BTC" {
+		t.Errorf("Unexpected position: %+v", position)
+	}
+	if len(allocations) != 2 || allocations[0].AllocatedQuantity != "0.2" || allocations[1].TradeID.String() != "44444444-4444-4444-4444-444444444444" {
+		t.Errorf("Unexpected allocations: %+v", allocations)
+	}
+	if len(trades) != 2 || trades[0].TradeID != "t1" || trades[1].Side != "sell" || trades[1].Price != "51000" {
+		t.Fatalf("Unexpected trades: %+v", trades)
+	}
+	if trades[0].ID != allocations[0].TradeID {
+		t.Error("Expected trades in allocation order")
+	}
+}
+
+func TestClient_GetPositionByID_ReturnsAllocations(t *testing.T) {
+	client := NewClientWithGraphQL(rawResponse(positionDetailBody), ClientConfig{})
+
+	_, allocations, err := client.GetPositionByID(context.Background(), "11111111-1111-1111-1111-111111111111")
+	if err != nil {
+		t.Fatalf("GetPositionByID failed: %v", err)
+	}
+	if len(allocations) != 2 {
+		t.Errorf("Expected 2 allocations, got %d", len(allocations))
+	}
+}
+
+func TestClient_GetPositionDetail_NotFound(t *testing.T) {
+	client := NewClientWithGraphQL(rawResponse(`{"data":{"positions_by_pk":null}}`), ClientConfig{})
+
+	if _, _, _, err := client.GetPositionDetail(context.Background(), "missing"); err == nil {
+		t.Fatal("Expected error for a missing position")
+	}
+}
+
+func TestClient_GetPositions_RealizedPnLThresholds(t *testing.T) {
+	var query string
+	var vars map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			vars = requestFromContext(ctx).vars
+			return nil
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	lte, gte := "-1e2", "-5000.50"
+	if _, err := client.GetPositions(context.Background(), PositionFilter{RealizedPnLLte: &lte, RealizedPnLGte: &gte}); err != nil {
+		t.Fatalf("GetPositions failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"$realized_pnl_lte: numeric!",
+		"$realized_pnl_gte: numeric!",
+		"_lte: $realized_pnl_lte",
+		"_gte: $realized_pnl_gte",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("Expected query to contain %q, got:\n%s", want, query)
+		}
+	}
+	if strings.Count(query, "realized_pnl: {") != 1 {
+		t.Errorf("Expected both thresholds in a single realized_pnl condition, got:\n%s", query)
+	}
+	if vars["realized_pnl_lte"] != "-100" || vars["realized_pnl_gte"] != "-5000.5" {
+		t.Errorf("Expected normalized numeric thresholds, got lte=%v gte=%v", vars["realized_pnl_lte"], vars["realized_pnl_gte"])
+	}
+}
+
+func TestClient_GetPositions_InvalidPnLThreshold(t *testing.T) {
+	calls := 0
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			calls++
+			return nil
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	bad := "-100 USD"
+	_, err := client.GetPositions(context.Background(), PositionFilter{RealizedPnLLte: &bad})
+
+	var verr *models.ValidationError
+	if !errors.As(err, &verr) || verr.Fields[0].Field != "realized_pnl_lte" {
+		t.Fatalf("Expected validation error for realized_pnl_lte, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected invalid filter not to be sent, got %d calls", calls)
+	}
+}
+
+func TestClient_GetPositions_Overlaps(t *testing.T) {
+	base := time.UnixMilli(1700000000000).UTC()
+	at := func(minutes int) int64 { return base.Add(time.Duration(minutes) * time.Minute).UnixMilli() }
+	window := TimeRange{Start: base.Add(60 * time.Minute), End: base.Add(120 * time.Minute)}
+
+	// Positions as [start_time, end_time] in minutes after base; an end of -1 is still open
+	positions := []struct {
+		id         string
+		start, end int
+	}{
+		{"inside", 70, 110},
+		{"overlaps-start", 30, 80},
+		{"overlaps-end", 100, 150},
+		{"spans", 0, 200},
+		{"closes-at-start", 30, 60},
+		{"before", 0, 50},
+		{"after", 130, 180},
+		{"opens-at-end", 120, 150},
+		{"open", 90, -1},
+		{"open-after", 130, -1},
+	}
+
+	names := make(map[uuid.UUID]string, len(positions))
+	for _, p := range positions {
+		names[uuid.NewSHA1(uuid.Nil, []byte(p.id))] = p.id
+	}
+
+	var query string
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			vars := requestFromContext(ctx).vars
+			end, _ := vars["overlaps_end"].(int64)
+			start, _ := vars["overlaps_start"].(int64)
+
+			// Evaluate the conditions the query asserts below, as Hasura would
+			var rows []map[string]interface{}
+			for _, p := range positions {
+				if at(p.start) > end || (p.end >= 0 && at(p.end) < start) {
+					continue
+				}
+				row := map[string]interface{}{"id": uuid.NewSHA1(uuid.Nil, []byte(p.id)), "start_time": at(p.start), "end_time": nil}
+				if p.end >= 0 {
+					row["end_time"] = at(p.end)
+				}
+				rows = append(rows, row)
+			}
+			data, _ := json.Marshal(map[string]interface{}{"positions": rows})
+			return json.Unmarshal(data, resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	got, err := client.GetPositions(context.Background(), PositionFilter{Overlaps: &window})
+	if err != nil {
+		t.Fatalf("GetPositions failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"{ start_time: { _lte: $overlaps_end } }",
+		"{ _or: [{ end_time: { _gte: $overlaps_start } }, { end_time: { _is_null: true } }] }",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("Expected query to contain %q, got:\n%s", want, query)
+		}
+	}
+
+	var ids []string
+	for _, position := range got {
+		ids = append(ids, names[position.ID])
+	}
+	want := []string{"inside", "overlaps-start", "overlaps-end", "spans", "closes-at-start", "opens-at-end", "open"}
+	if strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected overlapping positions %v, got %v", want, ids)
+	}
+}
+
+func TestBuildPositionWhere_OverlapsOpenEnded(t *testing.T) {
+	since := time.UnixMilli(1700000000000)
+	b, err := buildPositionWhere(PositionFilter{Overlaps: &TimeRange{Start: since}})
+	if err != nil {
+		t.Fatalf("buildPositionWhere failed: %v", err)
+	}
+	if got, want := b.where(), "{ _and: [{ _or: [{ end_time: { _gte: $overlaps_start } }, { end_time: { _is_null: true } }] }] }"; got != want {
+		t.Errorf("where = %s, want %s", got, want)
+	}
+	if b.variables()["overlaps_start"] != since.UnixMilli() {
+		t.Errorf("Expected overlaps_start %d, got %v", since.UnixMilli(), b.variables()["overlaps_start"])
+	}
+}
+
+func TestBuildPositionWhere_OverlapsWithTimeBounds(t *testing.T) {
+	at := time.UnixMilli(1700000000000)
+	b, err := buildPositionWhere(PositionFilter{
+		StartTimeLte: &at,
+		EndTimeGte:   &at,
+		Overlaps:     &TimeRange{Start: at, End: at.Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("buildPositionWhere failed: %v", err)
+	}
+	want := "{ _and: [{ start_time: { _lte: $overlaps_end } }, " +
+		"{ _or: [{ end_time: { _gte: $overlaps_start } }, { end_time: { _is_null: true } }] }], " +
+		"start_time: { _lte: $start_time_lte }, end_time: { _gte: $end_time_gte } }"
+	if got := b.where(); got != want {
+		t.Errorf("where = %s, want %s", got, want)
+	}
+}
+
+func TestClient_OrphanedPositionTrades(t *testing.T) {
+	var ops []string
+	mock := &rawMockGraphQLClient{
+		runRawFunc: func(ctx context.Context, req *graphql.Request) ([]byte, error) {
+			inflight := requestFromContext(ctx)
+			ops = append(ops, inflight.opName)
+			if !strings.Contains(inflight.query, "_not: { position: {} }") {
+				t.Errorf("Expected allocations without a position to be matched, got %s", inflight.query)
+			}
+			if inflight.opName == "DeleteOrphanedPositionTrades" {
+				return []byte(`{"data":{"delete_position_trades":{"affected_rows":2}}}`), nil
+			}
+			return []byte(`{"data":{"position_trades":[
+				{"position_id":"11111111-1111-1111-1111-111111111111","trade_id":"33333333-3333-3333-3333-333333333333",
+				 "allocation_percentage":1,"allocated_quantity":"0.2","allocated_fees":"0.5"},
+				{"position_id":"11111111-1111-1111-1111-111111111111","trade_id":"44444444-4444-4444-4444-444444444444",
+				 "allocation_percentage":"0.5","allocated_quantity":"0.1","allocated_fees":"0.25"}
+			]}}`), nil
+		},
+	}
+	client := NewClientWithGraphQL(mock, ClientConfig{StrictDecoding: true})
+	ctx := context.Background()
+
+	orphans, err := client.FindOrphanedPositionTrades(ctx)
+	if err != nil {
+		t.Fatalf("FindOrphanedPositionTrades failed: %v", err)
+	}
+	if len(orphans) != 2 {
+		t.Fatalf("Expected 2 orphaned allocations, got %d", len(orphans))
+	}
+	if orphans[0].AllocationPercentage != "1" || orphans[1].TradeID.String() != "44444444-4444-4444-4444-444444444444" || orphans[1].AllocatedFees != "0.25" {
+		t.Errorf("Unexpected orphaned allocations: %+v, %+v", orphans[0], orphans[1])
+	}
+
+	deleted, err := client.DeleteOrphanedPositionTrades(ctx)
+	if err != nil {
+		t.Fatalf("DeleteOrphanedPositionTrades failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 allocations deleted, got %d", deleted)
+	}
+	if strings.Join(ops, ",") != "FindOrphanedPositionTrades,DeleteOrphanedPositionTrades" {
+		t.Errorf("Unexpected operations: %v", ops)
+	}
+}
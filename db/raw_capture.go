@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// DefaultDecodeSnippetSize is the maximum number of bytes of offending JSON included in a DecodeError
+const DefaultDecodeSnippetSize = 256
+
+// DecodeError is returned when a response's data cannot be decoded into the lib's models
+// Path locates the offending value (e.g. "trades[3].timestamp") and Snippet holds its raw JSON,
+// truncated to ClientConfig.DecodeSnippetSize
+type DecodeError struct {
+	Operation string
+	Path      string
+	Snippet   string
+	Err       error
+}
+
+func (e *DecodeError) Error() string {
+	msg := fmt.Sprintf("decoding %s response", e.Operation)
+	if e.Path != "" {
+		msg += " at " + e.Path
+	}
+	msg += ": " + e.Err.Error()
+	if e.Snippet != "" {
+		msg += fmt.Sprintf(" (near %s)", e.Snippet)
+	}
+	return msg
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// newDecodeError builds a DecodeError for data that failed to decode into resp
+func (c *Client) newDecodeError(req *request, data json.RawMessage, resp interface{}, err error) *DecodeError {
+	path, raw := locateDecodeFailure(data, reflect.TypeOf(resp), "")
+	return &DecodeError{
+		Operation: req.opName,
+		Path:      path,
+		Snippet:   truncateSnippet(raw, c.decodeSnippetSize()),
+		Err:       err,
+	}
+}
+
+// decodeSnippetSize returns the configured snippet size, or DefaultDecodeSnippetSize when unset
+func (c *Client) decodeSnippetSize() int {
+	if c.config.DecodeSnippetSize == 0 {
+		return DefaultDecodeSnippetSize
+	}
+	return c.config.DecodeSnippetSize
+}
+
+// truncateSnippet returns raw as a string of at most size bytes; a negative size omits the snippet
+func truncateSnippet(raw json.RawMessage, size int) string {
+	if size < 0 {
+		return ""
+	}
+	s := strings.TrimSpace(string(raw))
+	if len(s) <= size {
+		return s
+	}
+	return s[:size] + "..."
+}
+
+// locateDecodeFailure narrows a decode failure of raw into t down to the innermost slice element
+// or struct field that fails on its own, returning its path and raw JSON
+// Types with a custom UnmarshalJSON are treated as leaves since their fields can't be decoded separately
+func locateDecodeFailure(raw json.RawMessage, t reflect.Type, path string) (string, json.RawMessage) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return path, raw
+		}
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return path, raw
+		}
+		for i, elem := range elems {
+			if !decodes(elem, t.Elem()) {
+				return locateDecodeFailure(elem, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case reflect.Struct:
+		if reflect.PtrTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+			return path, raw
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return path, raw
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			value, ok := fields[name]
+			if name == "" || name == "-" || !ok {
+				continue
+			}
+			if !decodes(value, field.Type) {
+				return locateDecodeFailure(value, field.Type, joinPath(path, name))
+			}
+		}
+	}
+
+	return path, raw
+}
+
+// decodes reports whether raw decodes into a fresh value of type t
+func decodes(raw json.RawMessage, t reflect.Type) bool {
+	return json.Unmarshal(raw, reflect.New(t).Interface()) == nil
+}
+
+// joinPath appends a field name to a dotted JSON path
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// rawCapture holds the most recent raw response body seen for a context
+type rawCapture struct {
+	mu   sync.Mutex
+	body json.RawMessage
+}
+
+// rawCaptureContextKey is the context key under which WithRawCapture stores its capture
+type rawCaptureContextKey struct{}
+
+// WithRawCapture makes operations run with the returned context retain their raw response body,
+// readable afterwards with RawFromContext. Capture is off by default to avoid holding on to
+// large responses. Clients without raw access (see NewClientWithGraphQL) capture the decoded
+// data re-encoded as JSON instead
+func WithRawCapture(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rawCaptureContextKey{}, &rawCapture{})
+}
+
+// RawFromContext returns the raw response body of the last operation run with ctx,
+// or nil if ctx was not created by WithRawCapture or no response has been received yet
+func RawFromContext(ctx context.Context) json.RawMessage {
+	capture, ok := ctx.Value(rawCaptureContextKey{}).(*rawCapture)
+	if !ok {
+		return nil
+	}
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+	return capture.body
+}
+
+// captureRaw records body on ctx's capture, if raw capture is enabled
+func captureRaw(ctx context.Context, body []byte) {
+	capture, ok := ctx.Value(rawCaptureContextKey{}).(*rawCapture)
+	if !ok {
+		return
+	}
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+	capture.body = body
+}
+
+// rawCaptureEnabled reports whether ctx was created by WithRawCapture
+func rawCaptureEnabled(ctx context.Context) bool {
+	_, ok := ctx.Value(rawCaptureContextKey{}).(*rawCapture)
+	return ok
+}
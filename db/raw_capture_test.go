@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+)
+
+const badTradesResponse = `{"data":{"trades":[
+	{"id":"11111111-1111-1111-1111-111111111111","price":"1","size":"1","fee":"0","timestamp":1700000000000},
+	{"id":"22222222-2222-2222-2222-222222222222","price":"1","size":"1","fee":"0","timestamp":"not-a-time"}
+]}}`
+
+func TestClient_DecodeError_IncludesPathAndSnippet(t *testing.T) {
+	client := NewClientWithGraphQL(rawResponse(badTradesResponse), ClientConfig{})
+
+	_, err := client.ListTrades(context.Background(), TradeFilter{})
+	if err == nil {
+		t.Fatal("Expected decode error")
+	}
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("Expected *DecodeError, got %T: %v", err, err)
+	}
+	if decodeErr.Operation != "ListTrades" {
+		t.Errorf("Expected operation ListTrades, got %q", decodeErr.Operation)
+	}
+	if decodeErr.Path != "trades[1]" {
+		t.Errorf("Expected path trades[1], got %q", decodeErr.Path)
+	}
+	if !strings.Contains(decodeErr.Snippet, `"not-a-time"`) || strings.Contains(decodeErr.Snippet, "11111111") {
+		t.Errorf("Expected snippet of the offending row only, got %q", decodeErr.Snippet)
+	}
+	if !strings.Contains(err.Error(), "not-a-time") {
+		t.Errorf("Expected error message to include the snippet, got %v", err)
+	}
+}
+
+func TestClient_DecodeError_SnippetSize(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		want string
+	}{
+		{name: "truncated", size: 10, want: `{"id":"222...`},
+		{name: "omitted", size: -1, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClientWithGraphQL(rawResponse(badTradesResponse), ClientConfig{DecodeSnippetSize: tt.size})
+
+			_, err := client.ListTrades(context.Background(), TradeFilter{})
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("Expected *DecodeError, got %T: %v", err, err)
+			}
+			if decodeErr.Snippet != tt.want {
+				t.Errorf("Expected snippet %q, got %q", tt.want, decodeErr.Snippet)
+			}
+		})
+	}
+}
+
+func TestClient_DecodeError_NestedField(t *testing.T) {
+	body := `{"data":{"insert_trades":{"returning":[{"id":"not-a-uuid"}]}}}`
+	client := NewClientWithGraphQL(rawResponse(body), ClientConfig{})
+
+	var resp struct {
+		InsertTrades struct {
+			Returning []struct {
+				ID uuid.UUID `json:"id"`
+			} `json:"returning"`
+		} `json:"insert_trades"`
+	}
+	err := client.execute(context.Background(), client.graphqlRequest("mutation AddTrades { x }"), &resp)
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("Expected *DecodeError, got %T: %v", err, err)
+	}
+	if decodeErr.Path != "insert_trades.returning[0].id" {
+		t.Errorf("Expected path insert_trades.returning[0].id, got %q", decodeErr.Path)
+	}
+	if decodeErr.Snippet != `"not-a-uuid"` {
+		t.Errorf("Expected snippet of the id value, got %q", decodeErr.Snippet)
+	}
+}
+
+func TestRawCapture(t *testing.T) {
+	body := `{"data":{"exchanges":[{"id":"id1","name":"hyperliquid","display_name":"Hyperliquid"}]}}`
+	client := NewClientWithGraphQL(rawResponse(body), ClientConfig{})
+
+	ctx := context.Background()
+	if _, err := client.ListExchanges(ctx, false); err != nil {
+		t.Fatalf("ListExchanges failed: %v", err)
+	}
+	if raw := RawFromContext(ctx); raw != nil {
+		t.Errorf("Expected no capture without WithRawCapture, got %s", raw)
+	}
+
+	ctx = WithRawCapture(ctx)
+	if _, err := client.ListExchanges(ctx, false); err != nil {
+		t.Fatalf("ListExchanges failed: %v", err)
+	}
+	if raw := RawFromContext(ctx); string(raw) != body {
+		t.Errorf("Expected captured body %s, got %s", body, raw)
+	}
+}
+
+func TestRawCapture_DecodedClient(t *testing.T) {
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			return json.Unmarshal([]byte(`{"exchanges":[{"id":"id1","name":"drift","display_name":"Drift"}]}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	ctx := WithRawCapture(context.Background())
+	if _, err := client.ListExchanges(ctx, false); err != nil {
+		t.Fatalf("ListExchanges failed: %v", err)
+	}
+	if raw := RawFromContext(ctx); !strings.Contains(string(raw), `"drift"`) {
+		t.Errorf("Expected re-encoded data to be captured, got %s", raw)
+	}
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Reference cache groups; a mutation writing a group's table invalidates every entry in it
+const (
+	cacheGroupExchanges    = "exchanges"
+	cacheGroupAccountTypes = "account_types"
+)
+
+// referenceCache holds decoded results of reference queries (ListExchanges, ListAccountTypes,
+// getExchangeByName) when ClientConfig.ReferenceCacheTTL is set
+type referenceCache struct {
+	mu          sync.Mutex
+	entries     map[string]*referenceEntry
+	generations map[string]uint64 // Bumped per group on invalidation
+}
+
+// referenceEntry is a cached or in-flight result
+type referenceEntry struct {
+	group   string
+	done    chan struct{} // Closed once the load has finished
+	value   interface{}
+	err     error
+	expires time.Time
+}
+
+func newReferenceCache() *referenceCache {
+	return &referenceCache{
+		entries:     make(map[string]*referenceEntry),
+		generations: make(map[string]uint64),
+	}
+}
+
+// referenceKey identifies a cached query by its text and variables
+func referenceKey(query string, vars map[string]interface{}) string {
+	encoded, _ := json.Marshal(vars) // Map keys are sorted, so equal variables encode equally
+	return query + "\x00" + string(encoded)
+}
+
+// cachedReference returns load's result for key, reusing it until ReferenceCacheTTL passes
+// Concurrent callers for the same key share a single load, which runs detached from any one
+// caller's cancellation (see sharedLoadContext); each caller still stops waiting when its own ctx
+// is done. Failed loads are not cached, and neither are loads that overlapped an invalidation of
+// their group. Without a TTL load runs directly under ctx
+func cachedReference[T any](ctx context.Context, c *Client, group, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	ttl := c.config.ReferenceCacheTTL
+	if ttl <= 0 || c.refCache == nil {
+		return load(ctx)
+	}
+	cache := c.refCache
+
+	cache.mu.Lock()
+	if entry, ok := cache.entries[key]; ok {
+		select {
+		case <-entry.done:
+			if c.clock.Now().Before(entry.expires) {
+				cache.mu.Unlock()
+				return entry.value.(T), nil
+			}
+			delete(cache.entries, key)
+		default:
+			cache.mu.Unlock()
+			return awaitReference[T](ctx, entry)
+		}
+	}
+	entry := &referenceEntry{group: group, done: make(chan struct{})}
+	cache.entries[key] = entry
+	generation := cache.generations[group]
+	cache.mu.Unlock()
+
+	loadCtx, cancel := c.sharedLoadContext(ctx)
+	go func() {
+		defer cancel()
+		value, err := load(loadCtx)
+
+		cache.mu.Lock()
+		entry.value, entry.err = value, err
+		entry.expires = c.clock.Now().Add(ttl)
+		if (err != nil || cache.generations[group] != generation) && cache.entries[key] == entry {
+			delete(cache.entries, key)
+		}
+		close(entry.done)
+		cache.mu.Unlock()
+	}()
+
+	return awaitReference[T](ctx, entry)
+}
+
+// awaitReference waits for entry's load to finish, or for ctx to be done
+func awaitReference[T any](ctx context.Context, entry *referenceEntry) (T, error) {
+	var zero T
+	select {
+	case <-entry.done:
+		if entry.err != nil {
+			return zero, entry.err
+		}
+		return entry.value.(T), nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// sharedLoadContext returns the context a shared load runs under: ctx's values without its
+// cancellation, bounded by the read timeout (DefaultReadTimeout when none applies) so the load
+// can't outlive its waiters indefinitely
+func (c *Client) sharedLoadContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout, ok := ctx.Value(timeoutContextKey{}).(time.Duration)
+	if !ok {
+		timeout = resolveTimeout(c.config.ReadTimeout, DefaultReadTimeout)
+	}
+	if timeout <= 0 {
+		timeout = DefaultReadTimeout
+	}
+	return context.WithDeadline(context.WithoutCancel(ctx), c.clock.Now().Add(timeout))
+}
+
+// invalidateReferences drops every cached entry of group, including loads still in flight
+func (c *Client) invalidateReferences(group string) {
+	cache := c.refCache
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.generations[group]++
+	for key, entry := range cache.entries {
+		if entry.group == group {
+			delete(cache.entries, key)
+		}
+	}
+}
+
+// cloneExchanges copies exchanges so callers can't modify cached values
+func cloneExchanges(exchanges []*Exchange) []*Exchange {
+	clones := make([]*Exchange, len(exchanges))
+	for i, exchange := range exchanges {
+		clone := *exchange
+		clones[i] = &clone
+	}
+	return clones
+}
@@ -0,0 +1,200 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/clock"
+)
+
+// referenceMock answers reference queries and mutations, counting requests by operation name
+func referenceMock(counts map[string]int, mu *sync.Mutex) *mockGraphQLClient {
+	responses := map[string]string{
+		"ListExchanges":     `{"exchanges": [{"id": "550e8400-e29b-41d4-a716-446655440000", "name": "hyperliquid", "display_name": "Hyperliquid"}]}`,
+		"GetExchangeByName": `{"exchanges": [{"id": "550e8400-e29b-41d4-a716-446655440000", "name": "hyperliquid", "display_name": "Hyperliquid"}]}`,
+		"ListAccountTypes":  `{"exchange_account_types": [{"code": "main"}]}`,
+		"CreateExchange":    `{"insert_exchanges_one": {"id": "660e8400-e29b-41d4-a716-446655440000", "name": "drift", "display_name": "Drift"}}`,
+		"EnsureAccountType": `{"insert_exchange_account_types_one": {"code": "vault"}}`,
+	}
+	return &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			opName := requestFromContext(ctx).opName
+			mu.Lock()
+			counts[opName]++
+			mu.Unlock()
+			return json.Unmarshal([]byte(responses[opName]), resp)
+		},
+	}
+}
+
+func TestClient_ReferenceCache_HitsWithinTTL(t *testing.T) {
+	ctx := context.Background()
+	counts := map[string]int{}
+	fake := clock.NewFake(time.Now())
+	client := NewClientWithGraphQL(referenceMock(counts, &sync.Mutex{}), ClientConfig{ReferenceCacheTTL: time.Minute}, WithClock(fake))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.ListExchanges(ctx, false); err != nil {
+			t.Fatalf("ListExchanges failed: %v", err)
+		}
+		if _, err := client.ListAccountTypes(ctx); err != nil {
+			t.Fatalf("ListAccountTypes failed: %v", err)
+		}
+		if _, err := client.EnsureExchange(ctx, "hyperliquid", "Hyperliquid"); err != nil {
+			t.Fatalf("EnsureExchange failed: %v", err)
+		}
+	}
+	for _, op := range []string{"ListExchanges", "ListAccountTypes", "GetExchangeByName"} {
+		if counts[op] != 1 {
+			t.Errorf("Expected 1 %s request within the TTL, got %d", op, counts[op])
+		}
+	}
+
+	// Cached values are copies, so callers can't corrupt the cache
+	exchanges, _ := client.ListExchanges(ctx, false)
+	exchanges[0].DisplayName = "changed"
+	if again, _ := client.ListExchanges(ctx, false); again[0].DisplayName != "Hyperliquid" {
+		t.Errorf("Expected cached exchange to be unaffected, got %q", again[0].DisplayName)
+	}
+
+	// Active-only listing is a different query, so it has its own entry
+	if _, err := client.ListExchanges(ctx, true); err != nil {
+		t.Fatalf("ListExchanges failed: %v", err)
+	}
+	if counts["ListExchanges"] != 2 {
+		t.Errorf("Expected active-only listing to miss the cache, got %d requests", counts["ListExchanges"])
+	}
+
+	fake.Advance(time.Minute)
+	if _, err := client.ListAccountTypes(ctx); err != nil {
+		t.Fatalf("ListAccountTypes failed: %v", err)
+	}
+	if counts["ListAccountTypes"] != 2 {
+		t.Errorf("Expected a request after the TTL expired, got %d", counts["ListAccountTypes"])
+	}
+}
+
+func TestClient_ReferenceCache_MutationsInvalidate(t *testing.T) {
+	ctx := context.Background()
+	counts := map[string]int{}
+	client := NewClientWithGraphQL(referenceMock(counts, &sync.Mutex{}), ClientConfig{ReferenceCacheTTL: time.Hour})
+
+	client.ListExchanges(ctx, false)
+	client.ListAccountTypes(ctx)
+	if _, err := client.CreateExchange(ctx, &ExchangeInput{Name: "drift", DisplayName: "Drift"}); err != nil {
+		t.Fatalf("CreateExchange failed: %v", err)
+	}
+	client.ListExchanges(ctx, false)
+	client.ListAccountTypes(ctx)
+
+	if counts["ListExchanges"] != 2 {
+		t.Errorf("Expected CreateExchange to invalidate ListExchanges, got %d requests", counts["ListExchanges"])
+	}
+	if counts["ListAccountTypes"] != 1 {
+		t.Errorf("Expected CreateExchange to leave account types cached, got %d requests", counts["ListAccountTypes"])
+	}
+
+	if _, err := client.EnsureAccountType(ctx, "vault"); err != nil {
+		t.Fatalf("EnsureAccountType failed: %v", err)
+	}
+	client.ListAccountTypes(ctx)
+	if counts["ListAccountTypes"] != 2 {
+		t.Errorf("Expected EnsureAccountType to invalidate ListAccountTypes, got %d requests", counts["ListAccountTypes"])
+	}
+}
+
+func TestClient_ReferenceCache_SharesConcurrentLoads(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			atomic.AddInt32(&requests, 1)
+			<-release
+			return json.Unmarshal([]byte(`{"exchange_account_types": [{"code": "main"}]}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{ReferenceCacheTTL: time.Minute})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			types, err := client.ListAccountTypes(context.Background())
+			if err != nil || len(types) != 1 {
+				t.Errorf("ListAccountTypes = %v, %v", types, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond) // Let every goroutine reach the cache
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("Expected concurrent callers to share 1 request, got %d", got)
+	}
+}
+
+func TestClient_ReferenceCache_LoadSurvivesFirstCallerCancel(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var loadErr error
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			close(started)
+			<-release
+			if loadErr = ctx.Err(); loadErr != nil {
+				return loadErr
+			}
+			if _, ok := ctx.Deadline(); !ok {
+				t.Error("Expected the shared load to be bounded by the read timeout")
+			}
+			return json.Unmarshal([]byte(`{"exchange_account_types": [{"code": "main"}]}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{ReferenceCacheTTL: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := client.ListAccountTypes(ctx)
+		firstErr <- err
+	}()
+	<-started
+
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := client.ListAccountTypes(context.Background())
+		waiter <- err
+	}()
+	time.Sleep(20 * time.Millisecond) // Let the waiter join the load
+
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled caller to return context.Canceled, got %v", err)
+	}
+	close(release)
+	if err := <-waiter; err != nil {
+		t.Errorf("Expected the waiter to get the shared result, got %v", err)
+	}
+	if loadErr != nil {
+		t.Errorf("Expected the load to ignore the first caller's cancellation, got %v", loadErr)
+	}
+}
+
+func TestClient_ReferenceCache_OffByDefault(t *testing.T) {
+	counts := map[string]int{}
+	client := NewClientWithGraphQL(referenceMock(counts, &sync.Mutex{}), ClientConfig{})
+
+	client.ListExchanges(context.Background(), false)
+	client.ListExchanges(context.Background(), false)
+	if counts["ListExchanges"] != 2 {
+		t.Errorf("Expected no caching without ReferenceCacheTTL, got %d requests", counts["ListExchanges"])
+	}
+}
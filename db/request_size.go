@@ -0,0 +1,123 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DefaultMaxRequestBytes is the request body size batch inserts stay within when
+// ClientConfig.MaxRequestBytes is zero
+const DefaultMaxRequestBytes = 1 << 20
+
+// RowTooLargeError is returned by batch inserts when a single row cannot fit in a request of
+// ClientConfig.MaxRequestBytes on its own
+type RowTooLargeError struct {
+	Operation string
+	Row       int // Index of the row in the caller's inputs
+	Size      int // Encoded size of the request carrying only this row, in bytes
+	Limit     int
+}
+
+func (e *RowTooLargeError) Error() string {
+	return fmt.Sprintf("%s: row %d needs a %d byte request, over the %d byte limit", e.Operation, e.Row, e.Size, e.Limit)
+}
+
+// PartialInsertError is returned by a batch insert split into several requests when a request
+// fails after earlier ones were written. Each request is atomic, so the first Committed inputs
+// are stored and the rest are not: retry with inputs[Committed:]. The insert also returns the
+// rows (or, for the Count variants, the number of rows) the written requests inserted
+type PartialInsertError struct {
+	Operation string
+	Committed int // Leading inputs stored by the requests that succeeded
+	Total     int // Inputs in the batch
+	Err       error
+}
+
+func (e *PartialInsertError) Error() string {
+	return fmt.Sprintf("%s: %d of %d rows written before a request failed: %v", e.Operation, e.Committed, e.Total, e.Err)
+}
+
+func (e *PartialInsertError) Unwrap() error {
+	return e.Err
+}
+
+// maxRequestBytes returns the request size limit for batch inserts (<= 0 = no limit)
+func (c *Client) maxRequestBytes() int {
+	switch {
+	case c.config.MaxRequestBytes == 0:
+		return DefaultMaxRequestBytes
+	case c.config.MaxRequestBytes < 0:
+		return 0
+	}
+	return c.config.MaxRequestBytes
+}
+
+// chunkObjects splits the insert objects of query into consecutive chunks whose encoded request
+// body, query included, stays within limit. A limit <= 0 returns a single chunk
+func chunkObjects(query string, objects []map[string]interface{}, limit int) ([][]map[string]interface{}, error) {
+	if limit <= 0 || len(objects) == 0 {
+		return [][]map[string]interface{}{objects}, nil
+	}
+
+	// The body is {"query":...,"variables":{"objects":[...]}}; rows add their size plus a comma
+	empty, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": map[string]interface{}{"objects": []interface{}{}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	overhead := len(empty)
+	opName := operationName(query)
+
+	var chunks [][]map[string]interface{}
+	start, size := 0, overhead
+	for i, object := range objects {
+		encoded, err := json.Marshal(object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode row %d: %w", i, err)
+		}
+		rowSize := len(encoded)
+		if overhead+rowSize > limit {
+			return nil, &RowTooLargeError{Operation: opName, Row: i, Size: overhead + rowSize, Limit: limit}
+		}
+		if i > start {
+			rowSize++ // Separating comma
+		}
+		if size+rowSize > limit {
+			chunks = append(chunks, objects[start:i])
+			start, size = i, overhead
+			rowSize = len(encoded)
+		}
+		size += rowSize
+	}
+	return append(chunks, objects[start:]), nil
+}
+
+// insertChunked runs insert once per chunk of objects that fits within the client's request size
+// limit, in order, and concatenates the returned rows. The request for each chunk carries the
+// chunk as its "objects" variable. A failing chunk stops the insert; when earlier chunks were
+// written, their rows are returned with a *PartialInsertError
+func insertChunked[T any](c *Client, query string, objects []map[string]interface{}, insert func(req *request) ([]T, error)) ([]T, error) {
+	chunks, err := chunkObjects(query, objects, c.maxRequestBytes())
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]T, 0, len(objects))
+	committed := 0
+	for _, chunk := range chunks {
+		inserted, err := insert(c.graphqlRequestWithVars(query, map[string]interface{}{
+			"objects": chunk,
+		}))
+		if err != nil {
+			if committed == 0 {
+				return nil, err
+			}
+			return rows, &PartialInsertError{Operation: operationName(query), Committed: committed, Total: len(objects), Err: err}
+		}
+		rows = append(rows, inserted...)
+		committed += len(chunk)
+	}
+	return rows, nil
+}
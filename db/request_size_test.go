@@ -0,0 +1,321 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+)
+
+// requestBodySize returns the encoded size of the body the default client would send for req
+func requestBodySize(t *testing.T, req *request) int {
+	t.Helper()
+	body, err := json.Marshal(map[string]interface{}{"query": req.query, "variables": req.vars})
+	if err != nil {
+		t.Fatalf("Failed to encode request: %v", err)
+	}
+	return len(body)
+}
+
+// metadataBlob returns account_type_metadata carrying a padding field of n bytes
+func metadataBlob(n int) json.RawMessage {
+	return json.RawMessage(fmt.Sprintf(`{"version":1,"padding":%q}`, strings.Repeat("x", n)))
+}
+
+func TestChunkObjects(t *testing.T) {
+	query := "mutation Insert($objects: [rows_insert_input!]!) { insert_rows(objects: $objects) { affected_rows } }"
+	objects := make([]map[string]interface{}, 10)
+	for i := range objects {
+		objects[i] = map[string]interface{}{"n": i, "blob": strings.Repeat("y", 100)}
+	}
+
+	chunks, err := chunkObjects(query, objects, 500)
+	if err != nil {
+		t.Fatalf("chunkObjects failed: %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("Expected the batch to be split, got %d chunk(s)", len(chunks))
+	}
+
+	next := 0
+	for _, chunk := range chunks {
+		body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": map[string]interface{}{"objects": chunk}})
+		if len(body) > 500 {
+			t.Errorf("Expected every chunk within 500 bytes, got %d", len(body))
+		}
+		for _, object := range chunk {
+			if object["n"] != next {
+				t.Fatalf("Expected rows in order, got %v at position %d", object["n"], next)
+			}
+			next++
+		}
+	}
+	if next != len(objects) {
+		t.Errorf("Expected all %d rows, got %d", len(objects), next)
+	}
+
+	unlimited, err := chunkObjects(query, objects, 0)
+	if err != nil || len(unlimited) != 1 || len(unlimited[0]) != len(objects) {
+		t.Errorf("Expected a single chunk without a limit, got %d (%v)", len(unlimited), err)
+	}
+}
+
+func TestClient_CreateAccounts_SplitsLargeBatch(t *testing.T) {
+	var batches []int
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			if respondWithExchange(ctx, resp, "hyperliquid") {
+				return nil
+			}
+			inflight := requestFromContext(ctx)
+			if size := requestBodySize(t, inflight); size > 4096 {
+				t.Errorf("Expected requests within 4096 bytes, got %d", size)
+			}
+			objects := inflight.vars["objects"].([]map[string]interface{})
+			batches = append(batches, len(objects))
+
+			returning := make([]map[string]interface{}, len(objects))
+			for i, object := range objects {
+				returning[i] = map[string]interface{}{
+					"id":                 object["account_identifier"],
+					"account_identifier": object["account_identifier"],
+					"account_type":       object["account_type"],
+				}
+			}
+			data, _ := json.Marshal(map[string]interface{}{
+				"insert_exchange_accounts": map[string]interface{}{"returning": returning},
+			})
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:             "http://localhost:8080/v1/graphql",
+		AdminSecret:     "test-secret",
+		MaxRequestBytes: 4096,
+	})
+
+	inputs := make([]*ExchangeAccountInput, 12)
+	for i := range inputs {
+		inputs[i] = &ExchangeAccountInput{
+			ExchangeID:          "test-exchange-id",
+			AccountIdentifier:   fmt.Sprintf("0x%040d", i),
+			AccountType:         "vault",
+			AccountTypeMetadata: metadataBlob(1000),
+		}
+	}
+
+	accounts, err := client.CreateAccounts(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("CreateAccounts failed: %v", err)
+	}
+
+	if len(batches) < 2 {
+		t.Errorf("Expected the batch to be split across requests, got %v", batches)
+	}
+	if len(accounts) != len(inputs) {
+		t.Fatalf("Expected %d accounts, got %d", len(inputs), len(accounts))
+	}
+	for i, account := range accounts {
+		if account.AccountIdentifier != inputs[i].AccountIdentifier {
+			t.Errorf("Expected account %d to be %s, got %s", i, inputs[i].AccountIdentifier, account.AccountIdentifier)
+		}
+	}
+}
+
+func TestClient_CreateAccounts_RowTooLarge(t *testing.T) {
+	mutated := false
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			if respondWithExchange(ctx, resp, "hyperliquid") {
+				return nil
+			}
+			mutated = true
+			return nil
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:             "http://localhost:8080/v1/graphql",
+		AdminSecret:     "test-secret",
+		MaxRequestBytes: 4096,
+	})
+
+	inputs := make([]*ExchangeAccountInput, 3)
+	for i := range inputs {
+		inputs[i] = &ExchangeAccountInput{
+			ExchangeID:          "test-exchange-id",
+			AccountIdentifier:   fmt.Sprintf("0x%040d", i),
+			AccountType:         "vault",
+			AccountTypeMetadata: metadataBlob(100),
+		}
+	}
+	inputs[2].AccountTypeMetadata = metadataBlob(8000)
+
+	_, err := client.CreateAccounts(context.Background(), inputs)
+
+	var tooLarge *RowTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("Expected a RowTooLargeError, got %v", err)
+	}
+	if tooLarge.Row != 2 || tooLarge.Size <= 8000 || tooLarge.Limit != 4096 {
+		t.Errorf("Expected row 2 over the 4096 byte limit, got %+v", tooLarge)
+	}
+	if !strings.Contains(err.Error(), "row 2") {
+		t.Errorf("Expected the error to name the row, got %v", err)
+	}
+	if mutated {
+		t.Error("Expected nothing to be inserted")
+	}
+}
+
+func TestClient_BatchInserts_RespectMaxRequestBytes(t *testing.T) {
+	var requests int
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			inflight := requestFromContext(ctx)
+			if size := requestBodySize(t, inflight); size > 2048 {
+				t.Errorf("Expected %s requests within 2048 bytes, got %d", inflight.opName, size)
+			}
+			requests++
+
+			objects := inflight.vars["objects"].([]map[string]interface{})
+			returning := make([]map[string]interface{}, len(objects))
+			for i := range objects {
+				returning[i] = map[string]interface{}{"id": uuid.New()}
+			}
+			table := map[string]string{
+				"AddTrades":            "insert_trades",
+				"AddFundingPayments":   "insert_funding_payments",
+				"CreatePositionTrades": "insert_position_trades",
+			}[inflight.opName]
+			data, _ := json.Marshal(map[string]interface{}{table: map[string]interface{}{"returning": returning}})
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:             "http://localhost:8080/v1/graphql",
+		AdminSecret:     "test-secret",
+		MaxRequestBytes: 2048,
+	})
+	ctx := context.Background()
+	accountID := uuid.New()
+	pad := strings.Repeat("z", 400)
+
+	trades := make([]*TradeInput, 10)
+	payments := make([]*FundingPaymentInput, 10)
+	allocations := make([]*PositionTradeInput, 40)
+	for i := range trades {
+		trades[i] = &TradeInput{
+			ExchangeAccountID: accountID, BaseAsset: "BTC", QuoteAsset: "USDC", Side: "buy",
+			Price: "100", Quantity: "1", Fee: "0", Timestamp: time.Now(),
+			TradeID: fmt.Sprintf("trade-%d", i), OrderID: pad,
+		}
+		payments[i] = &FundingPaymentInput{
+			ExchangeAccountID: accountID, BaseAsset: "BTC", QuoteAsset: "USDC",
+			Amount: "1", Timestamp: time.Now(), PaymentID: fmt.Sprintf("payment-%d-%s", i, pad),
+		}
+	}
+	for i := range allocations {
+		allocations[i] = &PositionTradeInput{
+			PositionID: uuid.New(), TradeID: uuid.New(),
+			AllocationPercentage: "1", AllocatedQuantity: "1", AllocatedFees: "0",
+		}
+	}
+
+	steps := []struct {
+		name string
+		run  func() (int, error)
+	}{
+		{"AddTrades", func() (int, error) {
+			rows, err := client.AddTrades(ctx, trades)
+			return len(rows), err
+		}},
+		{"AddFundingPayments", func() (int, error) {
+			rows, err := client.AddFundingPayments(ctx, payments)
+			return len(rows), err
+		}},
+		{"CreatePositionTrades", func() (int, error) {
+			rows, err := client.CreatePositionTrades(ctx, allocations)
+			return len(rows), err
+		}},
+	}
+	for _, step := range steps {
+		requests = 0
+		got, err := step.run()
+		if err != nil {
+			t.Fatalf("%s failed: %v", step.name, err)
+		}
+		if requests < 2 {
+			t.Errorf("Expected %s to be split across requests, got %d", step.name, requests)
+		}
+		if want := map[string]int{"AddTrades": 10, "AddFundingPayments": 10, "CreatePositionTrades": 40}[step.name]; got != want {
+			t.Errorf("Expected %d rows from %s, got %d", want, step.name, got)
+		}
+	}
+
+	trades[3].OrderID = strings.Repeat("z", 4000)
+	_, err := client.AddTrades(ctx, trades)
+	var tooLarge *RowTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Row != 3 {
+		t.Errorf("Expected trade 3 to be reported as too large, got %v", err)
+	}
+}
+
+func TestClient_AddFundingPayments_PartialInsert(t *testing.T) {
+	var requests int
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			requests++
+			if requests == 2 {
+				return &GraphQLError{Operation: "AddFundingPayments", Errors: []GraphQLErrorDetail{{Message: "database is shutting down"}}}
+			}
+			objects := requestFromContext(ctx).vars["objects"].([]map[string]interface{})
+			returning := make([]map[string]interface{}, len(objects))
+			for i, object := range objects {
+				returning[i] = map[string]interface{}{"id": uuid.New(), "payment_id": object["payment_id"]}
+			}
+			data, _ := json.Marshal(map[string]interface{}{"insert_funding_payments": map[string]interface{}{"returning": returning}})
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{MaxRequestBytes: 2048})
+	pad := strings.Repeat("z", 400)
+	payments := make([]*FundingPaymentInput, 10)
+	for i := range payments {
+		payments[i] = &FundingPaymentInput{
+			ExchangeAccountID: uuid.New(), BaseAsset: "BTC", QuoteAsset: "USDC",
+			Amount: "1", Timestamp: time.Now(), PaymentID: fmt.Sprintf("payment-%d-%s", i, pad),
+		}
+	}
+
+	rows, err := client.AddFundingPayments(context.Background(), payments)
+
+	var partial *PartialInsertError
+	if !errors.As(err, &partial) {
+		t.Fatalf("Expected a PartialInsertError, got %v", err)
+	}
+	if partial.Committed == 0 || partial.Committed >= len(payments) || partial.Total != len(payments) {
+		t.Errorf("Expected some but not all of %d rows committed, got %+v", len(payments), partial)
+	}
+	if len(rows) != partial.Committed {
+		t.Fatalf("Expected the %d committed rows back, got %d", partial.Committed, len(rows))
+	}
+	for i, row := range rows {
+		if row.PaymentID != payments[i].PaymentID {
+			t.Errorf("Expected committed row %d to be %s, got %s", i, payments[i].PaymentID, row.PaymentID)
+		}
+	}
+	var gqlErr *GraphQLError
+	if !errors.As(err, &gqlErr) {
+		t.Errorf("Expected the failing request's error to be wrapped, got %v", err)
+	}
+}
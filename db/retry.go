@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// Idempotency classifies whether repeating an operation can change its outcome
+type Idempotency int
+
+const (
+	// IdempotencyUnknown is reported for operations that were never classified; they retry like NotIdempotent
+	IdempotencyUnknown Idempotency = iota
+	// ReadOnly operations are queries and can always be repeated
+	ReadOnly
+	// Idempotent mutations (upserts, on-conflict-ignore inserts, updates and deletes by key)
+	// have the same effect however many times they land
+	Idempotent
+	// NotIdempotent mutations (plain inserts) may write twice if repeated after they landed
+	NotIdempotent
+)
+
+func (i Idempotency) String() string {
+	switch i {
+	case ReadOnly:
+		return "read-only"
+	case Idempotent:
+		return "idempotent"
+	case NotIdempotent:
+		return "not-idempotent"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	operationsMu sync.RWMutex
+	operations   = map[string]Idempotency{}
+)
+
+// registerOperations records the idempotency class of mutations by operation name
+// Each file registers the mutations it defines; queries don't need registering
+func registerOperations(classes map[string]Idempotency) {
+	operationsMu.Lock()
+	defer operationsMu.Unlock()
+	for name, class := range classes {
+		operations[name] = class
+	}
+}
+
+// OperationIdempotency reports the registered idempotency class of a mutation by operation name
+// (e.g. "AddTrades"), or IdempotencyUnknown if it isn't registered
+func OperationIdempotency(name string) Idempotency {
+	operationsMu.RLock()
+	defer operationsMu.RUnlock()
+	return operations[name]
+}
+
+// requestIdempotency classifies req: queries are ReadOnly, mutations use the registry
+func requestIdempotency(req *request) Idempotency {
+	if !isMutation(req.query) {
+		return ReadOnly
+	}
+	return OperationIdempotency(req.opName)
+}
+
+// WithRetry retries failed operations up to maxAttempts in total, starting at backoff and doubling
+// per attempt. Read-only and idempotent operations retry on network errors and operation timeouts;
+// other mutations retry only on errors that show the request never left (connection refused, DNS),
+// since after a timeout the write may have landed. Retrying is off by default
+func WithRetry(maxAttempts int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts = maxAttempts
+		c.retryBackoff = backoff
+	}
+}
+
+// shouldRetry reports whether err from an attempt of an operation with the given class may be retried
+func shouldRetry(class Idempotency, err error) bool {
+	if errBeforeSend(err) {
+		return true
+	}
+	switch class {
+	case ReadOnly, Idempotent:
+		return errTransient(err)
+	default:
+		return false
+	}
+}
+
+// errBeforeSend reports whether err shows the request could not have reached the server
+func errBeforeSend(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// errTransient reports whether err is a network failure or timeout that may succeed on a repeat
+func errTransient(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
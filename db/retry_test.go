@@ -0,0 +1,234 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/clock"
+)
+
+func TestOperationIdempotency(t *testing.T) {
+	tests := []struct {
+		name string
+		want Idempotency
+	}{
+		{"AddTrades", Idempotent},
+		{"AddOrders", Idempotent},
+		{"EnsureExchange", Idempotent},
+		{"DeleteAccount", Idempotent},
+		{"CreateTrade", NotIdempotent},
+		{"AddFundingPayments", Idempotent},
+		{"AddTradesCount", Idempotent},
+		{"AddFundingPaymentsCount", Idempotent},
+		{"NoSuchOperation", IdempotencyUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := OperationIdempotency(tt.name); got != tt.want {
+			t.Errorf("OperationIdempotency(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestOperationIdempotency_EveryMutationRegistered guards against adding a mutation without classifying it
+func TestOperationIdempotency_EveryMutationRegistered(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mutationPattern := regexp.MustCompile(`(?m)^\s*mutation (\w+)`)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range mutationPattern.FindAllStringSubmatch(string(src), -1) {
+			if OperationIdempotency(m[1]) == IdempotencyUnknown {
+				t.Errorf("%s: mutation %s has no registered idempotency class", file, m[1])
+			}
+		}
+	}
+}
+
+// timeoutError is a net.Error reporting a timeout, as returned when a response never arrives
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClient_Retry_ByIdempotency(t *testing.T) {
+	faults := map[string]error{
+		"connection refused": &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+		"dns":                &net.DNSError{Err: "no such host", Name: "hasura"},
+		"read timeout":       &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}},
+		"deadline exceeded":  context.DeadlineExceeded,
+		"graphql error":      &GraphQLError{Errors: []GraphQLErrorDetail{{Message: "constraint violation"}}},
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		retried map[string]bool // Faults that should be retried
+	}{
+		{
+			name:    "read-only query",
+			query:   `query GetThing { things { id } }`,
+			retried: map[string]bool{"connection refused": true, "dns": true, "read timeout": true, "deadline exceeded": true},
+		},
+		{
+			name:    "idempotent mutation",
+			query:   `mutation AddTrades { insert_trades { affected_rows } }`,
+			retried: map[string]bool{"connection refused": true, "dns": true, "read timeout": true, "deadline exceeded": true},
+		},
+		{
+			name:    "non-idempotent mutation",
+			query:   `mutation CreateTrade { insert_trades_one { id } }`,
+			retried: map[string]bool{"connection refused": true, "dns": true},
+		},
+		{
+			name:    "unclassified mutation",
+			query:   `mutation SomethingNew { insert_things { affected_rows } }`,
+			retried: map[string]bool{"connection refused": true, "dns": true},
+		},
+	}
+
+	for _, tt := range tests {
+		for faultName, fault := range faults {
+			t.Run(tt.name+"/"+faultName, func(t *testing.T) {
+				attempts := 0
+				mock := &mockGraphQLClient{
+					runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+						attempts++
+						return fault
+					},
+				}
+				client := NewClientWithGraphQL(mock, ClientConfig{}, WithRetry(3, time.Millisecond))
+
+				var resp struct{}
+				err := client.execute(context.Background(), client.graphqlRequest(tt.query), &resp)
+				if !errors.Is(err, fault) {
+					t.Errorf("Expected the injected fault, got %v", err)
+				}
+
+				want := 1
+				if tt.retried[faultName] {
+					want = 3
+				}
+				if attempts != want {
+					t.Errorf("Expected %d attempts, got %d", want, attempts)
+				}
+			})
+		}
+	}
+}
+
+func TestClient_Retry_RecoversAfterTransientFault(t *testing.T) {
+	attempts := 0
+	mock := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			attempts++
+			if attempts == 1 {
+				return &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}
+			}
+			return nil
+		},
+	}
+	client := NewClientWithGraphQL(mock, ClientConfig{}, WithRetry(3, time.Millisecond))
+
+	var resp struct{}
+	if err := client.execute(context.Background(), client.graphqlRequest(`mutation AddTrades { x }`), &resp); err != nil {
+		t.Fatalf("Expected retry to succeed, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestClient_Retry_OffByDefault(t *testing.T) {
+	attempts := 0
+	mock := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			attempts++
+			return &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+		},
+	}
+	client := NewClientWithGraphQL(mock, ClientConfig{})
+
+	var resp struct{}
+	client.execute(context.Background(), client.graphqlRequest(`query GetThing { things { id } }`), &resp)
+	if attempts != 1 {
+		t.Errorf("Expected no retries without WithRetry, got %d attempts", attempts)
+	}
+}
+
+func TestClient_Retry_StopsWhenCallerContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	mock := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			attempts++
+			cancel()
+			return context.DeadlineExceeded
+		},
+	}
+	client := NewClientWithGraphQL(mock, ClientConfig{}, WithRetry(5, time.Millisecond))
+
+	var resp struct{}
+	client.execute(ctx, client.graphqlRequest(`query GetThing { things { id } }`), &resp)
+	if attempts != 1 {
+		t.Errorf("Expected retries to stop once the caller's context is done, got %d attempts", attempts)
+	}
+}
+
+func TestClient_Retry_BackoffFollowsClock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+	var attempts atomic.Int32
+	mock := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			attempts.Add(1)
+			return &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+		},
+	}
+	client := NewClientWithGraphQL(mock, ClientConfig{Clock: fake}, WithRetry(3, time.Hour))
+
+	done := make(chan error, 1)
+	go func() {
+		var resp struct{}
+		done <- client.execute(context.Background(), client.graphqlRequest(`query GetThing { things { id } }`), &resp)
+	}()
+
+	// The backoff doubles: one hour after the first attempt, two after the second
+	fake.BlockUntil(1)
+	fake.Advance(59 * time.Minute)
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("Expected no retry before the backoff elapsed, got %d attempts", got)
+	}
+	fake.Advance(time.Minute)
+	fake.BlockUntil(1)
+	if got := attempts.Load(); got != 2 {
+		t.Fatalf("Expected a retry once the backoff elapsed, got %d attempts", got)
+	}
+	fake.Advance(2 * time.Hour)
+
+	if err := <-done; err == nil {
+		t.Fatal("Expected the last attempt's error")
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
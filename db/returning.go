@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+	"sort"
+)
+
+// insertCount runs a batch insert into table whose query selects only affected_rows and returns
+// how many rows were inserted. On a *PartialInsertError the count covers the written requests
+func insertCount(ctx context.Context, c *Client, query, table string, objects []map[string]interface{}) (int, error) {
+	counts, err := insertChunked(c, query, objects, func(req *request) ([]int, error) {
+		var resp map[string]struct {
+			AffectedRows int `json:"affected_rows"`
+		}
+		if err := c.execute(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+		return []int{resp["insert_"+table].AffectedRows}, nil
+	})
+
+	inserted := 0
+	for _, n := range counts {
+		inserted += n
+	}
+	return inserted, err
+}
+
+// orderLikeInputs stably reorders rows to follow the order in which their keys first appear in
+// inputKeys. Hasura does not guarantee that an insert's returning rows follow the order of its
+// objects. Rows whose key is not among inputKeys go last, in the order returned
+func orderLikeInputs[T any](rows []T, inputKeys []string, key func(T) string) {
+	position := make(map[string]int, len(inputKeys))
+	for i, k := range inputKeys {
+		if _, ok := position[k]; !ok {
+			position[k] = i
+		}
+	}
+	rank := func(row T) int {
+		if p, ok := position[key(row)]; ok {
+			return p
+		}
+		return len(inputKeys)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rank(rows[i]) < rank(rows[j]) })
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+)
+
+func TestAddTradesCount(t *testing.T) {
+	var query string
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			return json.Unmarshal([]byte(`{"insert_trades": {"affected_rows": 1}}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	inserted, err := client.AddTradesCount(context.Background(), []*TradeInput{{
+		BaseAsset:         "BTC",
+		QuoteAsset:        "USDC",
+		Side:              "buy",
+		Price:             "65000",
+		Quantity:          "0.1",
+		Fee:               "0",
+		Timestamp:         time.UnixMilli(1700000000000),
+		TradeID:           "t1",
+		ExchangeAccountID: uuid.New(),
+	}})
+	if err != nil {
+		t.Fatalf("AddTradesCount failed: %v", err)
+	}
+	if inserted != 1 {
+		t.Errorf("Expected 1 inserted trade, got %d", inserted)
+	}
+	if !strings.Contains(query, "mutation AddTradesCount") || !strings.Contains(query, "affected_rows") || strings.Contains(query, "returning") {
+		t.Errorf("Expected only affected_rows to be selected, got:\n%s", query)
+	}
+	if !strings.Contains(query, "trades_exchange_account_id_trade_id_key") {
+		t.Errorf("Expected duplicates to be ignored like AddTrades, got:\n%s", query)
+	}
+}
+
+func TestAddFundingPaymentsCount_SumsChunks(t *testing.T) {
+	var calls int
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			calls++
+			objects := requestFromContext(ctx).vars["objects"].([]map[string]interface{})
+			return json.Unmarshal([]byte(fmt.Sprintf(`{"insert_funding_payments": {"affected_rows": %d}}`, len(objects))), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{MaxRequestBytes: 1200})
+
+	inputs := countTestPayments(6)
+	inserted, err := client.AddFundingPaymentsCount(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("AddFundingPaymentsCount failed: %v", err)
+	}
+	if calls < 2 {
+		t.Fatalf("Expected the batch to be split, got %d requests", calls)
+	}
+	if inserted != len(inputs) {
+		t.Errorf("Expected %d inserted payments, got %d", len(inputs), inserted)
+	}
+}
+
+func TestAddFundingPaymentsCount_PartialInsert(t *testing.T) {
+	var calls int
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			calls++
+			if calls > 1 {
+				return errors.New("connection reset")
+			}
+			objects := requestFromContext(ctx).vars["objects"].([]map[string]interface{})
+			return json.Unmarshal([]byte(fmt.Sprintf(`{"insert_funding_payments": {"affected_rows": %d}}`, len(objects))), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{MaxRequestBytes: 1200})
+
+	inputs := countTestPayments(6)
+	inserted, err := client.AddFundingPaymentsCount(context.Background(), inputs)
+	var partial *PartialInsertError
+	if !errors.As(err, &partial) {
+		t.Fatalf("Expected a PartialInsertError, got %v", err)
+	}
+	if inserted != partial.Committed || inserted == 0 {
+		t.Errorf("Expected the count of the written request (%d), got %d", partial.Committed, inserted)
+	}
+}
+
+// countTestPayments returns n valid funding payment inputs with distinct payment IDs
+func countTestPayments(n int) []*FundingPaymentInput {
+	inputs := make([]*FundingPaymentInput, n)
+	for i := range inputs {
+		inputs[i] = &FundingPaymentInput{
+			ExchangeAccountID: uuid.New(),
+			BaseAsset:         "BTC",
+			QuoteAsset:        "USDC",
+			Amount:            "1.5",
+			Timestamp:         time.UnixMilli(1700000000000 + int64(i)),
+			PaymentID:         fmt.Sprintf("p%d", i),
+		}
+	}
+	return inputs
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// uuidVar converts a UUID to the string form Hasura expects for uuid variables
+func uuidVar(id uuid.UUID) string {
+	return id.String()
+}
+
+// auditVars rejects top-level [16]byte variables, which encode as a JSON number array Hasura can't
+// take as a uuid. uuid.UUID is fine since it marshals as its string form. Nested values such as
+// insert objects are not walked, and keys are checked in sorted order so the error is stable
+func auditVars(opName string, vars map[string]interface{}) error {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch value := vars[key].(type) {
+		case [16]byte:
+			return fmt.Errorf("invalid variable %s in %s: pass uuid values as strings (see uuidVar), not [16]byte", key, opName)
+		case *[16]byte:
+			if value != nil {
+				return fmt.Errorf("invalid variable %s in %s: pass uuid values as strings (see uuidVar), not [16]byte", key, opName)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+)
+
+func TestExecute_RejectsByteArrayVariables(t *testing.T) {
+	id := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	raw := [16]byte(id)
+
+	tests := []struct {
+		name     string
+		vars     map[string]interface{}
+		wantPath string
+	}{
+		{name: "byte array", vars: map[string]interface{}{"id": raw}, wantPath: "id"},
+		{name: "pointer", vars: map[string]interface{}{"id": &raw}, wantPath: "id"},
+		{name: "first key in sorted order", vars: map[string]interface{}{"b": raw, "a": raw, "c": raw}, wantPath: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := false
+			client := NewClientWithGraphQL(&mockGraphQLClient{
+				runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+					sent = true
+					return nil
+				},
+			}, ClientConfig{})
+
+			req := client.graphqlRequestWithVars(`query GetThing($id: uuid!) { things_by_pk(id: $id) { id } }`, tt.vars)
+			var resp struct{}
+			err := client.execute(context.Background(), req, &resp)
+			if err == nil {
+				t.Fatal("execute() error = nil, want an error naming the [16]byte variable")
+			}
+			if !strings.Contains(err.Error(), "invalid variable "+tt.wantPath+" in GetThing") {
+				t.Errorf("execute() error = %q, want it to name variable %s", err, tt.wantPath)
+			}
+			if sent {
+				t.Error("request was sent despite the [16]byte variable")
+			}
+		})
+	}
+}
+
+func TestExecute_AcceptsUUIDVariables(t *testing.T) {
+	id := uuid.New()
+	client := NewClientWithGraphQL(&mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			return nil
+		},
+	}, ClientConfig{})
+
+	// uuid.UUID marshals as its string form, and nested values are not audited
+	req := client.graphqlRequestWithVars(`query GetThing($id: uuid!, $other: uuid!, $ids: [uuid!]!) { things_by_pk(id: $id) { id } }`, map[string]interface{}{
+		"id":      uuidVar(id),
+		"other":   id,
+		"ids":     []string{uuidVar(id)},
+		"objects": []map[string]interface{}{{"exchange_account_id": [16]byte(id)}},
+	})
+	var resp struct{}
+	if err := client.execute(context.Background(), req, &resp); err != nil {
+		t.Fatalf("execute() error = %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrUnscoped is returned by ScopedClient when a call would not be limited to its user, either
+// because the client has no valid user ID or because the filter asks for a different user
+var ErrUnscoped = errors.New("query is not scoped to a user")
+
+// ScopedClient limits list calls to the data of a single user
+// Every filter passed through it gets UserID set to the client's user, so rows belonging to other
+// users are filtered out by Hasura rather than in memory. Only filtered list calls are exposed;
+// use the underlying Client for writes and lookups by ID
+type ScopedClient struct {
+	client *Client
+	userID string
+}
+
+// ScopedClient returns a client whose list calls only return rows owned by userID
+// An empty or malformed userID is not rejected here, but every call on the result fails with ErrUnscoped
+func (c *Client) ScopedClient(userID string) *ScopedClient {
+	return &ScopedClient{client: c, userID: userID}
+}
+
+// UserID returns the user the client is scoped to
+func (s *ScopedClient) UserID() string {
+	return s.userID
+}
+
+// scope returns the user ID to put in a filter whose current UserID is requested
+func (s *ScopedClient) scope(requested *string) (*string, error) {
+	if _, err := uuid.Parse(s.userID); err != nil {
+		return nil, fmt.Errorf("%w: invalid user ID %q", ErrUnscoped, s.userID)
+	}
+	if requested != nil && *requested != s.userID {
+		return nil, fmt.Errorf("%w: filter requests user %s but client is scoped to %s", ErrUnscoped, *requested, s.userID)
+	}
+	userID := s.userID
+	return &userID, nil
+}
+
+// ListAccounts retrieves the user's exchange accounts matching filter
+func (s *ScopedClient) ListAccounts(ctx context.Context, filter AccountFilter) ([]*ExchangeAccount, error) {
+	userID, err := s.scope(filter.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	filter.UserID = userID
+	return s.client.ListAccountsFiltered(ctx, filter)
+}
+
+// ListTrades retrieves the user's trades matching filter
+func (s *ScopedClient) ListTrades(ctx context.Context, filter TradeFilter) ([]*Trade, error) {
+	userID, err := s.scope(filter.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trades: %w", err)
+	}
+	filter.UserID = userID
+	return s.client.ListTrades(ctx, filter)
+}
+
+// ListTradesPage retrieves a single page of the user's trades matching filter
+func (s *ScopedClient) ListTradesPage(ctx context.Context, filter TradeFilter, opts PageOptions) (*Page[*Trade], error) {
+	userID, err := s.scope(filter.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trades page: %w", err)
+	}
+	filter.UserID = userID
+	return s.client.ListTradesPage(ctx, filter, opts)
+}
+
+// ListFundingPayments retrieves the user's funding payments matching filter
+func (s *ScopedClient) ListFundingPayments(ctx context.Context, filter FundingPaymentFilter) ([]*FundingPayment, error) {
+	userID, err := s.scope(filter.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list funding payments: %w", err)
+	}
+	filter.UserID = userID
+	return s.client.ListFundingPayments(ctx, filter)
+}
+
+// ListFundingPaymentsPage retrieves a single page of the user's funding payments matching filter
+func (s *ScopedClient) ListFundingPaymentsPage(ctx context.Context, filter FundingPaymentFilter, opts PageOptions) (*Page[*FundingPayment], error) {
+	userID, err := s.scope(filter.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list funding payments page: %w", err)
+	}
+	filter.UserID = userID
+	return s.client.ListFundingPaymentsPage(ctx, filter, opts)
+}
+
+// GetPositions retrieves the user's closed positions matching filter
+func (s *ScopedClient) GetPositions(ctx context.Context, filter PositionFilter) ([]*Position, error) {
+	userID, err := s.scope(filter.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+	filter.UserID = userID
+	return s.client.GetPositions(ctx, filter)
+}
+
+// GetPositionsPage retrieves a single page of the user's closed positions matching filter
+func (s *ScopedClient) GetPositionsPage(ctx context.Context, filter PositionFilter, opts PageOptions) (*Page[*Position], error) {
+	userID, err := s.scope(filter.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions page: %w", err)
+	}
+	filter.UserID = userID
+	return s.client.GetPositionsPage(ctx, filter, opts)
+}
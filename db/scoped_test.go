@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/machinebox/graphql"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestBuildWhere_UserScope(t *testing.T) {
+	userID := "770e8400-e29b-41d4-a716-446655440000"
+
+	accounts, err := buildAccountWhere(AccountFilter{UserID: &userID}, DefaultAccountEnabledColumn)
+	if err != nil {
+		t.Fatalf("buildAccountWhere failed: %v", err)
+	}
+	positions, err := buildPositionWhere(PositionFilter{UserID: &userID})
+	if err != nil {
+		t.Fatalf("buildPositionWhere failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		b         *whereBuilder
+		wantWhere string
+	}{
+		{"accounts", accounts, "{ user_id: { _eq: $user_id } }"},
+		{"trades", buildTradeWhere(TradeFilter{UserID: &userID}), "{ exchange_account: { user_id: { _eq: $user_id } } }"},
+		{"funding payments", buildFundingPaymentWhere(FundingPaymentFilter{UserID: &userID}), "{ exchange_account: { user_id: { _eq: $user_id } } }"},
+		{"positions", positions, "{ exchange_account: { user_id: { _eq: $user_id } } }"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.where(); got != tt.wantWhere {
+				t.Errorf("where = %s, want %s", got, tt.wantWhere)
+			}
+			if got := tt.b.declarations(); got != "($user_id: uuid!)" {
+				t.Errorf("declarations = %s, want ($user_id: uuid!)", got)
+			}
+			if got := tt.b.variables()["user_id"]; got != userID {
+				t.Errorf("user_id = %v, want %s", got, userID)
+			}
+		})
+	}
+}
+
+func TestScopedClient_InjectsUserScope(t *testing.T) {
+	userID := "770e8400-e29b-41d4-a716-446655440000"
+
+	var queries []string
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			r := requestFromContext(ctx)
+			if r.vars["user_id"] != userID {
+				t.Errorf("%s: expected user_id %s, got %v", r.opName, userID, r.vars["user_id"])
+			}
+			queries = append(queries, r.query)
+			return nil
+		},
+	}
+	scoped := NewClientWithGraphQL(mockClient, ClientConfig{}).ScopedClient(userID)
+
+	ctx := context.Background()
+	calls := []func() error{
+		func() error { _, err := scoped.ListAccounts(ctx, AccountFilter{}); return err },
+		func() error { _, err := scoped.ListTrades(ctx, TradeFilter{}); return err },
+		func() error { _, err := scoped.ListTradesPage(ctx, TradeFilter{Limit: 10}, PageOptions{}); return err },
+		func() error { _, err := scoped.ListFundingPayments(ctx, FundingPaymentFilter{}); return err },
+		func() error {
+			_, err := scoped.ListFundingPaymentsPage(ctx, FundingPaymentFilter{Limit: 10}, PageOptions{})
+			return err
+		},
+		func() error { _, err := scoped.GetPositions(ctx, PositionFilter{UserID: &userID}); return err },
+		func() error {
+			_, err := scoped.GetPositionsPage(ctx, PositionFilter{Limit: 10}, PageOptions{})
+			return err
+		},
+	}
+	for i, call := range calls {
+		if err := call(); err != nil {
+			t.Errorf("call %d failed: %v", i, err)
+		}
+	}
+
+	if len(queries) != len(calls) {
+		t.Fatalf("Expected %d requests, got %d", len(calls), len(queries))
+	}
+	for _, query := range queries[1:] {
+		if !strings.Contains(query, "exchange_account: { user_id: { _eq: $user_id } }") {
+			t.Errorf("Expected nested user scope in query:\n%s", query)
+		}
+	}
+}
+
+func TestScopedClient_RefusesUnscopedCalls(t *testing.T) {
+	calls := 0
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			calls++
+			return nil
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		userID string
+		filter TradeFilter
+	}{
+		{name: "empty user", userID: ""},
+		{name: "malformed user", userID: "not-a-uuid"},
+		{
+			name:   "filter for another user",
+			userID: "770e8400-e29b-41d4-a716-446655440000",
+			filter: TradeFilter{UserID: strPtr("880e8400-e29b-41d4-a716-446655440000")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scoped := client.ScopedClient(tt.userID)
+			if _, err := scoped.ListTrades(ctx, tt.filter); !errors.Is(err, ErrUnscoped) {
+				t.Errorf("ListTrades: expected ErrUnscoped, got %v", err)
+			}
+			if _, err := scoped.GetPositions(ctx, PositionFilter{UserID: tt.filter.UserID}); !errors.Is(err, ErrUnscoped) {
+				t.Errorf("GetPositions: expected ErrUnscoped, got %v", err)
+			}
+		})
+	}
+
+	if calls != 0 {
+		t.Errorf("Expected refused calls not to reach the server, got %d requests", calls)
+	}
+}
@@ -0,0 +1,524 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+)
+
+// tableModels maps each table to the model its rows decode into
+var tableModels = map[string]reflect.Type{
+	"exchanges":         reflect.TypeOf(Exchange{}),
+	"exchange_accounts": reflect.TypeOf(ExchangeAccount{}),
+	"trades":            reflect.TypeOf(Trade{}),
+	"funding_payments":  reflect.TypeOf(FundingPayment{}),
+	"orders":            reflect.TypeOf(Order{}),
+	"positions":         reflect.TypeOf(Position{}),
+	"position_trades":   reflect.TypeOf(PositionTrade{}),
+	"sync_runs":         reflect.TypeOf(SyncRun{}),
+	"dead_letters":      reflect.TypeOf(DeadLetter{}),
+	"asset_aliases":     reflect.TypeOf(AssetAlias{}),
+}
+
+// slimProjections lists, per operation, the model fields it intentionally leaves out
+// "*" allows any field to be missing, for lookups that only need a column or two
+var slimProjections = map[string][]string{
+	"ExistingTradeIDs":                {"*"}, // Only trade_id is compared
+	"GetLastProcessedTradeTimestamp":  {"*"}, // Only the newest allocated trade's timestamp is needed
+	"GetLatestFundingPaymentsByAsset": {"*"}, // Only the newest timestamp per asset is needed
+	"GetTradedPairs":                  {"*"}, // Only distinct asset pairs are needed
+	"GetAccountDataSummary":           {"*"}, // Bounds and counts only
+	"GetRealizedPnLBuckets":           {"*"}, // Only end_time and realized_pnl are bucketed
+	// Only maps identifiers to ids
+	"ResolveAccountIDs": {"user_id", "account_type", "account_type_metadata", "pnl_denomination", "enabled"},
+}
+
+// sharedOperations names the operation sent by methods that reuse another method's query
+var sharedOperations = map[string]string{
+	"ListTradesPage":          "ListTrades",
+	"ListFundingPaymentsPage": "ListFundingPayments",
+	"GetPositionsPage":        "GetPositions",
+	"GetPositionByID":         "GetPositionWithTrades",
+	"GetTradesByIDsStrict":    "GetTradesByIDs",
+}
+
+// queryCatalogEntry runs one Client method so the operations it sends can be captured
+type queryCatalogEntry struct {
+	method string
+	call   func(ctx context.Context, c *Client) error
+}
+
+// AllQueries exercises every Client method that reads or writes model rows
+func AllQueries() []queryCatalogEntry {
+	accountID := uuid.New()
+	id := accountID.String()
+	now := time.UnixMilli(1700000000000)
+	trade := &TradeInput{
+		BaseAsset: "BTC", QuoteAsset: "USDC", Side: "buy", Price: "1", Quantity: "1", Fee: "0",
+		Timestamp: now, TradeID: "t1", ExchangeAccountID: accountID,
+	}
+	payment := &FundingPaymentInput{
+		ExchangeAccountID: accountID, BaseAsset: "BTC", QuoteAsset: "USDC", Amount: "1",
+		Timestamp: now, PaymentID: "p1",
+	}
+	position := &Position{ID: uuid.New(), ExchangeAccountID: accountID, BaseAsset: "BTC", QuoteAsset: "USDC", StartTime: now, EndTime: &now}
+	account := &ExchangeAccountInput{ExchangeID: id, AccountIdentifier: "0x1234567890123456789012345678901234567890", AccountType: "main"}
+
+	ignore2 := func(_ interface{}, err error) error { return err }
+
+	return []queryCatalogEntry{
+		{"GetExchange", func(ctx context.Context, c *Client) error { return ignore2(c.GetExchange(ctx, id)) }},
+		{"ListExchanges", func(ctx context.Context, c *Client) error { return ignore2(c.ListExchanges(ctx, false)) }},
+		{"CreateExchange", func(ctx context.Context, c *Client) error {
+			return ignore2(c.CreateExchange(ctx, &ExchangeInput{Name: "x", DisplayName: "X"}))
+		}},
+		{"UpdateExchange", func(ctx context.Context, c *Client) error {
+			return ignore2(c.UpdateExchange(ctx, id, &ExchangeInput{Name: "x", DisplayName: "X"}))
+		}},
+		{"EnsureExchange", func(ctx context.Context, c *Client) error { return ignore2(c.EnsureExchange(ctx, "x", "X")) }},
+		{"GetAccount", func(ctx context.Context, c *Client) error { return ignore2(c.GetAccount(ctx, id)) }},
+		{"ListAccounts", func(ctx context.Context, c *Client) error { return ignore2(c.ListAccounts(ctx)) }},
+		{"ListAccountsFiltered", func(ctx context.Context, c *Client) error {
+			return ignore2(c.ListAccountsFiltered(ctx, AccountFilter{}))
+		}},
+		{"IterateAccounts", func(ctx context.Context, c *Client) error {
+			return c.IterateAccounts(ctx, 10, func([]*ExchangeAccount) error { return nil })
+		}},
+		{"ResolveAccountIDs", func(ctx context.Context, c *Client) error {
+			return ignore2(c.ResolveAccountIDs(ctx, []ExchangeIdentifier{{Exchange: "hyperliquid", Identifier: account.AccountIdentifier}}))
+		}},
+		{"CreateAccount", func(ctx context.Context, c *Client) error { return ignore2(c.CreateAccount(ctx, account)) }},
+		{"CreateAccounts", func(ctx context.Context, c *Client) error {
+			return ignore2(c.CreateAccounts(ctx, []*ExchangeAccountInput{account}))
+		}},
+		{"UpdateAccount", func(ctx context.Context, c *Client) error { return ignore2(c.UpdateAccount(ctx, id, account)) }},
+		{"PatchAccount", func(ctx context.Context, c *Client) error {
+			return ignore2(c.PatchAccount(ctx, id, AccountPatch{AccountTypeMetadata: json.RawMessage(`{"version": 1}`)}))
+		}},
+		{"GetAccountDataSummary", func(ctx context.Context, c *Client) error {
+			return ignore2(c.GetAccountDataSummary(ctx, accountID))
+		}},
+		{"GetTrade", func(ctx context.Context, c *Client) error { return ignore2(c.GetTrade(ctx, id)) }},
+		{"GetTradesByIDs", func(ctx context.Context, c *Client) error {
+			return ignore2(c.GetTradesByIDs(ctx, []uuid.UUID{accountID}))
+		}},
+		{"GetTradesByIDsStrict", func(ctx context.Context, c *Client) error {
+			_, err := c.GetTradesByIDsStrict(ctx, []uuid.UUID{accountID})
+			var missing *MissingIDsError
+			if errors.As(err, &missing) {
+				return nil
+			}
+			return err
+		}},
+		{"ListTrades", func(ctx context.Context, c *Client) error { return ignore2(c.ListTrades(ctx, TradeFilter{})) }},
+		{"ListTradesPage", func(ctx context.Context, c *Client) error {
+			return ignore2(c.ListTradesPage(ctx, TradeFilter{Limit: 10}, PageOptions{}))
+		}},
+		{"GetRecentTrades", func(ctx context.Context, c *Client) error { return ignore2(c.GetRecentTrades(ctx, 10)) }},
+		{"CreateTrade", func(ctx context.Context, c *Client) error { return ignore2(c.CreateTrade(ctx, trade)) }},
+		{"AddTrades", func(ctx context.Context, c *Client) error { return ignore2(c.AddTrades(ctx, []*TradeInput{trade})) }},
+		{"AddTradesCount", func(ctx context.Context, c *Client) error {
+			return ignore2(c.AddTradesCount(ctx, []*TradeInput{trade}))
+		}},
+		{"ExistingTradeIDs", func(ctx context.Context, c *Client) error {
+			return ignore2(c.ExistingTradeIDs(ctx, accountID, []string{"t1"}))
+		}},
+		{"FindUnallocatedTrades", func(ctx context.Context, c *Client) error {
+			return ignore2(c.FindUnallocatedTrades(ctx, accountID, nil, TimeRange{}))
+		}},
+		{"CountUnallocatedTrades", func(ctx context.Context, c *Client) error {
+			return ignore2(c.CountUnallocatedTrades(ctx, accountID, nil, TimeRange{}))
+		}},
+		{"UpdateTrade", func(ctx context.Context, c *Client) error { return ignore2(c.UpdateTrade(ctx, id, trade)) }},
+		{"LatestTrade", func(ctx context.Context, c *Client) error {
+			return ignore2(c.LatestTrade(ctx, []uuid.UUID{accountID}))
+		}},
+		{"GetActivity", func(ctx context.Context, c *Client) error {
+			return ignore2(c.GetActivity(ctx, accountID, now.Add(-time.Hour), now))
+		}},
+		{"GetTradedPairs", func(ctx context.Context, c *Client) error { return ignore2(c.GetTradedPairs(ctx, accountID)) }},
+		{"GetLatestFundingPayment", func(ctx context.Context, c *Client) error {
+			return ignore2(c.GetLatestFundingPayment(ctx, accountID))
+		}},
+		{"GetLatestFundingPayments", func(ctx context.Context, c *Client) error {
+			return ignore2(c.GetLatestFundingPayments(ctx, []uuid.UUID{accountID}))
+		}},
+		{"GetLatestFundingPaymentsByAsset", func(ctx context.Context, c *Client) error {
+			return ignore2(c.GetLatestFundingPaymentsByAsset(ctx, accountID))
+		}},
+		{"GetFundingForPositions", func(ctx context.Context, c *Client) error {
+			return ignore2(c.GetFundingForPositions(ctx, []*Position{position}))
+		}},
+		{"AddFundingPayments", func(ctx context.Context, c *Client) error {
+			return ignore2(c.AddFundingPayments(ctx, []*FundingPaymentInput{payment}))
+		}},
+		{"AddFundingPaymentsCount", func(ctx context.Context, c *Client) error {
+			return ignore2(c.AddFundingPaymentsCount(ctx, []*FundingPaymentInput{payment}))
+		}},
+		{"ListFundingPayments", func(ctx context.Context, c *Client) error {
+			return ignore2(c.ListFundingPayments(ctx, FundingPaymentFilter{}))
+		}},
+		{"ListFundingPaymentsPage", func(ctx context.Context, c *Client) error {
+			return ignore2(c.ListFundingPaymentsPage(ctx, FundingPaymentFilter{Limit: 10}, PageOptions{}))
+		}},
+		{"AddOrders", func(ctx context.Context, c *Client) error {
+			return ignore2(c.AddOrders(ctx, []*OrderInput{{
+				OrderID: "o1", BaseAsset: "BTC", QuoteAsset: "USDC", Side: "buy", Price: "1", Size: "1",
+				Status: "open", Timestamp: now, ExchangeAccountID: accountID,
+			}}))
+		}},
+		{"GetOrdersByAccount", func(ctx context.Context, c *Client) error {
+			return ignore2(c.GetOrdersByAccount(ctx, accountID, OrderFilter{}))
+		}},
+		{"CreateSyncRun", func(ctx context.Context, c *Client) error {
+			return ignore2(c.CreateSyncRun(ctx, &SyncRunInput{ExchangeAccountID: accountID, Kind: "trades", StartedAt: now}))
+		}},
+		{"ListSyncRuns", func(ctx context.Context, c *Client) error { return ignore2(c.ListSyncRuns(ctx, accountID, 10)) }},
+		{"AddDeadLetters", func(ctx context.Context, c *Client) error {
+			return ignore2(c.AddDeadLetters(ctx, []*DeadLetterInput{{
+				ExchangeAccountID: accountID, Kind: "trades", RowKey: "t1", Payload: []byte(`{}`), Error: "bad row", Attempts: 1,
+			}}))
+		}},
+		{"CreateAssetAlias", func(ctx context.Context, c *Client) error {
+			return ignore2(c.CreateAssetAlias(ctx, &AssetAliasInput{Exchange: "hyperliquid", Asset: "POL", Alias: "MATIC", EffectiveFrom: now}))
+		}},
+		{"ListAssetAliases", func(ctx context.Context, c *Client) error { return ignore2(c.ListAssetAliases(ctx, "hyperliquid")) }},
+		{"ListDeadLetters", func(ctx context.Context, c *Client) error { return ignore2(c.ListDeadLetters(ctx, accountID, true)) }},
+		{"ResolveDeadLetter", func(ctx context.Context, c *Client) error { return ignore2(c.ResolveDeadLetter(ctx, accountID)) }},
+		{"GetLastProcessedTradeTimestamp", func(ctx context.Context, c *Client) error {
+			return ignore2(c.GetLastProcessedTradeTimestamp(ctx, accountID, "BTC", "USDC"))
+		}},
+		{"CreatePosition", func(ctx context.Context, c *Client) error {
+			return ignore2(c.CreatePosition(ctx, &PositionInput{
+				ExchangeAccountID: accountID, BaseAsset: "BTC", QuoteAsset: "USDC", Side: "long", StartTime: now, EndTime: now,
+				EntryAvgPrice: "1", ExitAvgPrice: "1", TotalQuantity: "1", TotalFees: "0", RealizedPnL: "0",
+			}))
+		}},
+		{"CreatePositionTrades", func(ctx context.Context, c *Client) error {
+			return ignore2(c.CreatePositionTrades(ctx, []*PositionTradeInput{{
+				PositionID: position.ID, TradeID: uuid.New(), AllocationPercentage: "1", AllocatedQuantity: "1", AllocatedFees: "0",
+			}}))
+		}},
+		{"FindOrphanedPositionTrades", func(ctx context.Context, c *Client) error {
+			return ignore2(c.FindOrphanedPositionTrades(ctx))
+		}},
+		{"GetPositions", func(ctx context.Context, c *Client) error { return ignore2(c.GetPositions(ctx, PositionFilter{})) }},
+		{"GetPositionsPage", func(ctx context.Context, c *Client) error {
+			return ignore2(c.GetPositionsPage(ctx, PositionFilter{Limit: 10}, PageOptions{}))
+		}},
+		{"GetRealizedPnLBuckets", func(ctx context.Context, c *Client) error {
+			return ignore2(c.GetRealizedPnLBuckets(ctx, accountID, PositionFilter{}, 24*time.Hour))
+		}},
+		{"GetPositionByID", func(ctx context.Context, c *Client) error {
+			_, _, err := c.GetPositionByID(ctx, position.ID.String())
+			return err
+		}},
+		{"GetPositionDetail", func(ctx context.Context, c *Client) error {
+			_, _, _, err := c.GetPositionDetail(ctx, position.ID.String())
+			return err
+		}},
+	}
+}
+
+// selection is a field of a GraphQL selection set and its sub-selection
+type selection struct {
+	name     string
+	children []*selection
+}
+
+// selectionTokens splits a GraphQL document into names, punctuation and string literals
+func selectionTokens(query string) []string {
+	var tokens []string
+	for i := 0; i < len(query); {
+		r := rune(query[i])
+		switch {
+		case unicode.IsSpace(r) || r == ',':
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(query) && query[end] != '"' {
+				if query[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			tokens = append(tokens, query[i:end+1])
+			i = end + 1
+		case r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '.':
+			end := i
+			for end < len(query) && (query[end] == '_' || query[end] == '$' || query[end] == '-' || query[end] == '.' ||
+				unicode.IsLetter(rune(query[end])) || unicode.IsDigit(rune(query[end]))) {
+				end++
+			}
+			tokens = append(tokens, query[i:end])
+			i = end
+		default:
+			tokens = append(tokens, string(r))
+			i++
+		}
+	}
+	return tokens
+}
+
+// parseOperation returns the root fields of a single-operation GraphQL document
+func parseOperation(query string) ([]*selection, error) {
+	tokens := selectionTokens(query)
+	pos := 0
+
+	// Skip "query Name($vars...)" up to the operation's selection set
+	for pos < len(tokens) && tokens[pos] != "{" {
+		if tokens[pos] == "(" {
+			pos = skipBalanced(tokens, pos, "(", ")")
+			continue
+		}
+		pos++
+	}
+	fields, _, err := parseSelectionSet(tokens, pos)
+	return fields, err
+}
+
+// parseSelectionSet parses "{ field... }" starting at tokens[pos] and returns the position after it
+func parseSelectionSet(tokens []string, pos int) ([]*selection, int, error) {
+	if pos >= len(tokens) || tokens[pos] != "{" {
+		return nil, pos, fmt.Errorf("expected { at token %d", pos)
+	}
+	pos++
+
+	var fields []*selection
+	for pos < len(tokens) && tokens[pos] != "}" {
+		name := tokens[pos]
+		pos++
+		if pos < len(tokens) && tokens[pos] == ":" { // Alias: the field name follows
+			name = tokens[pos+1]
+			pos += 2
+		}
+		field := &selection{name: name}
+		if pos < len(tokens) && tokens[pos] == "(" {
+			pos = skipBalanced(tokens, pos, "(", ")")
+		}
+		if pos < len(tokens) && tokens[pos] == "{" {
+			children, next, err := parseSelectionSet(tokens, pos)
+			if err != nil {
+				return nil, pos, err
+			}
+			field.children, pos = children, next
+		}
+		fields = append(fields, field)
+	}
+	if pos >= len(tokens) {
+		return nil, pos, fmt.Errorf("unterminated selection set")
+	}
+	return fields, pos + 1, nil
+}
+
+// skipBalanced returns the position after the group opened at tokens[pos]
+func skipBalanced(tokens []string, pos int, open, close string) int {
+	depth := 0
+	for ; pos < len(tokens); pos++ {
+		switch tokens[pos] {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return pos + 1
+			}
+		}
+	}
+	return pos
+}
+
+// rootTable returns the table a root field reads or writes and the selection holding its rows
+// ok is false for fields that don't return rows (aggregates, affected_rows-only mutations)
+func rootTable(field *selection) (table string, rows []*selection, ok bool) {
+	name := field.name
+	switch {
+	case strings.HasSuffix(name, "_aggregate"):
+		return "", nil, false
+	case strings.HasPrefix(name, "insert_") && strings.HasSuffix(name, "_one"):
+		return strings.TrimSuffix(strings.TrimPrefix(name, "insert_"), "_one"), field.children, true
+	case strings.HasSuffix(name, "_by_pk"):
+		name = strings.TrimSuffix(name, "_by_pk")
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "update_"), "delete_")
+		return name, field.children, true
+	case strings.HasPrefix(name, "insert_"), strings.HasPrefix(name, "update_"), strings.HasPrefix(name, "delete_"):
+		for _, child := range field.children {
+			if child.name == "returning" {
+				return strings.SplitN(name, "_", 2)[1], child.children, true
+			}
+		}
+		return "", nil, false
+	}
+	return name, field.children, true
+}
+
+// modelFields returns the JSON field names a model declares
+func modelFields(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// missingFields reports, per table, the model fields an operation's query doesn't select
+func missingFields(opName, query string) (map[string][]string, error) {
+	roots, err := parseOperation(query)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool)
+	for _, field := range slimProjections[opName] {
+		allowed[field] = true
+	}
+	if allowed["*"] {
+		return nil, nil
+	}
+
+	missing := make(map[string][]string)
+	for _, root := range roots {
+		table, rows, ok := rootTable(root)
+		model, known := tableModels[table]
+		if !ok || !known {
+			continue
+		}
+		selected := make(map[string]bool, len(rows))
+		for _, field := range rows {
+			selected[field.name] = true
+		}
+		for _, field := range modelFields(model) {
+			if !selected[field] && !allowed[field] {
+				missing[table] = append(missing[table], field)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// captureQueries runs every catalog entry against a mock and returns the queries sent per operation
+func captureQueries(t *testing.T) map[string][]string {
+	t.Helper()
+
+	queries := make(map[string][]string)
+	mock := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			r := requestFromContext(ctx)
+			queries[r.opName] = append(queries[r.opName], r.query)
+			// Account writes look up the exchange and account type before sending
+			switch r.opName {
+			case "ListAccountTypes":
+				return json.Unmarshal([]byte(`{"exchange_account_types": [{"code": "main"}]}`), resp)
+			case "GetExchange":
+				return json.Unmarshal([]byte(`{"exchanges_by_pk": {"id": "x", "name": "hyperliquid", "display_name": "Hyperliquid"}}`), resp)
+			}
+			return nil
+		},
+	}
+	client := NewClientWithGraphQL(mock, ClientConfig{})
+
+	for _, entry := range AllQueries() {
+		_ = entry.call(context.Background(), client) // Empty responses may fail decoding; only the query matters
+		opName := entry.method
+		if shared, ok := sharedOperations[entry.method]; ok {
+			opName = shared
+		}
+		if len(queries[opName]) == 0 {
+			t.Errorf("%s was not sent; fix its catalog inputs", entry.method)
+		}
+	}
+	return queries
+}
+
+func TestAllQueries_SelectEveryModelField(t *testing.T) {
+	queries := captureQueries(t)
+
+	opNames := make([]string, 0, len(queries))
+	for opName := range queries {
+		opNames = append(opNames, opName)
+	}
+	sort.Strings(opNames)
+
+	for _, opName := range opNames {
+		for _, query := range queries[opName] {
+			missing, err := missingFields(opName, query)
+			if err != nil {
+				t.Errorf("%s: failed to parse query: %v\n%s", opName, err, query)
+				continue
+			}
+			for table, fields := range missing {
+				t.Errorf("%s selects %s without %s; select them or add the operation to slimProjections",
+					opName, table, strings.Join(fields, ", "))
+			}
+		}
+	}
+}
+
+func TestMissingFields_TradesCreatedAtRegression(t *testing.T) {
+	// ListTrades once shipped without created_at, leaving Trade.CreatedAt zero for every row
+	query := `
+		query ListTrades($limit: Int!) {
+			trades(order_by: [{ timestamp: desc }], limit: $limit, where: { side: { _in: ["buy", "sell"] } }) {
+				id
+				base_asset
+				quote_asset
+				side
+				price
+				quantity
+				timestamp
+				fee
+				order_id
+				trade_id
+				exchange_account_id
+				source
+				fee_asset
+				market_type
+				is_taker
+			}
+		}
+	`
+
+	missing, err := missingFields("ListTrades", query)
+	if err != nil {
+		t.Fatalf("missingFields failed: %v", err)
+	}
+	if got := missing["trades"]; len(got) != 1 || got[0] != "created_at" {
+		t.Errorf("Expected created_at to be reported missing from trades, got %v", missing)
+	}
+}
+
+func TestMissingFields_AliasesAndReturning(t *testing.T) {
+	query := `
+		mutation AddThings($objects: [trades_insert_input!]!) {
+			insert_trades(objects: $objects) {
+				affected_rows
+				returning { id }
+			}
+			p0: positions(where: { id: { _eq: "x" } }) { id }
+		}
+	`
+
+	missing, err := missingFields("AddThings", query)
+	if err != nil {
+		t.Fatalf("missingFields failed: %v", err)
+	}
+	if len(missing["trades"]) != len(modelFields(tableModels["trades"]))-1 {
+		t.Errorf("Expected every trade field but id to be missing, got %v", missing["trades"])
+	}
+	if len(missing["positions"]) == 0 {
+		t.Errorf("Expected the aliased positions field to be checked, got %v", missing)
+	}
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"reflect"
+	"time"
+)
+
+// slowQueryHistorySize is the number of slow queries retained for SlowQueries()
+const slowQueryHistorySize = 50
+
+// SlowQuery describes a single operation that exceeded ClientConfig.SlowQueryThreshold.
+// Only variable sizes are recorded, never variable values.
+type SlowQuery struct {
+	Operation     string         `json:"operation"`      // GraphQL operation name (e.g. "GetPositions")
+	Duration      time.Duration  `json:"duration"`       // Total time spent in execute
+	VariableSizes map[string]int `json:"variable_sizes"` // Array/object variable name -> element count
+	Succeeded     bool           `json:"succeeded"`      // Whether the operation eventually returned without error
+	At            time.Time      `json:"at"`             // When the operation finished
+}
+
+// SlowQueries returns the most recent slow queries (oldest first), up to slowQueryHistorySize.
+// Intended for debug endpoints; returns an empty slice when slow-query reporting is disabled.
+func (c *Client) SlowQueries() []SlowQuery {
+	c.slowMu.Lock()
+	defer c.slowMu.Unlock()
+
+	result := make([]SlowQuery, len(c.slowQueries))
+	copy(result, c.slowQueries)
+	return result
+}
+
+// observeLatency reports the operation as slow if it exceeded the configured threshold
+func (c *Client) observeLatency(req *request, duration time.Duration, err error) {
+	threshold := c.config.SlowQueryThreshold
+	if threshold <= 0 || duration <= threshold {
+		return
+	}
+
+	slow := SlowQuery{
+		Operation:     req.opName,
+		Duration:      duration,
+		VariableSizes: variableSizes(req.vars),
+		Succeeded:     err == nil,
+		At:            c.clock.Now(),
+	}
+
+	c.slowMu.Lock()
+	c.slowQueries = append(c.slowQueries, slow)
+	if len(c.slowQueries) > slowQueryHistorySize {
+		c.slowQueries = c.slowQueries[len(c.slowQueries)-slowQueryHistorySize:]
+	}
+	c.slowMu.Unlock()
+
+	c.logger.Warn("slow graphql operation",
+		"operation", slow.Operation,
+		"duration", slow.Duration,
+		"threshold", threshold,
+		"variable_sizes", slow.VariableSizes,
+		"succeeded", slow.Succeeded,
+	)
+
+	if c.config.SlowQueryHook != nil {
+		c.config.SlowQueryHook(slow)
+	}
+}
+
+// variableSizes returns the element count of every slice, array or map variable.
+// Scalar variables are omitted so no values ever leak into reports.
+func variableSizes(vars map[string]interface{}) map[string]int {
+	sizes := make(map[string]int)
+	for key, value := range vars {
+		v := reflect.ValueOf(value)
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			sizes[key] = v.Len()
+		}
+	}
+	return sizes
+}
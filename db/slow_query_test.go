@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/clock"
+)
+
+// delayingMock returns a mock GraphQL client that advances fake by delay before responding
+func delayingMock(fake *clock.Fake, delay time.Duration, respData map[string]interface{}, err error) *mockGraphQLClient {
+	return &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			fake.Advance(delay)
+			if err != nil {
+				return err
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+}
+
+func TestClient_SlowQuery_HookInvoked(t *testing.T) {
+	ctx := context.Background()
+	fake := clock.NewFake(time.Now())
+	mockClient := delayingMock(fake, 20*time.Millisecond, map[string]interface{}{"positions": []interface{}{}}, nil)
+
+	var hooked []SlowQuery
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:                "http://localhost:8080/v1/graphql",
+		AdminSecret:        "test-secret",
+		SlowQueryThreshold: 5 * time.Millisecond,
+		SlowQueryHook:      func(q SlowQuery) { hooked = append(hooked, q) },
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Clock:              fake,
+	})
+
+	filter := PositionFilter{ExchangeAccountIDs: []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}}
+	if _, err := client.GetPositions(ctx, filter); err != nil {
+		t.Fatalf("GetPositions failed: %v", err)
+	}
+
+	if len(hooked) != 1 {
+		t.Fatalf("Expected hook to be called once, got %d", len(hooked))
+	}
+	slow := hooked[0]
+	if slow.Operation != "GetPositions" {
+		t.Errorf("Expected operation 'GetPositions', got '%s'", slow.Operation)
+	}
+	if slow.Duration != 20*time.Millisecond {
+		t.Errorf("Expected duration 20ms, got %v", slow.Duration)
+	}
+	if !slow.Succeeded {
+		t.Error("Expected slow query to be marked as succeeded")
+	}
+	if slow.VariableSizes["exchange_account_ids"] != 3 {
+		t.Errorf("Expected exchange_account_ids size 3, got %d", slow.VariableSizes["exchange_account_ids"])
+	}
+
+	recorded := client.SlowQueries()
+	if len(recorded) != 1 || recorded[0].Operation != "GetPositions" {
+		t.Errorf("Expected SlowQueries to contain GetPositions, got %+v", recorded)
+	}
+}
+
+func TestClient_SlowQuery_FailureRecorded(t *testing.T) {
+	ctx := context.Background()
+	fake := clock.NewFake(time.Now())
+	mockClient := delayingMock(fake, 20*time.Millisecond, nil, errors.New("connection reset"))
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:                "http://localhost:8080/v1/graphql",
+		AdminSecret:        "test-secret",
+		SlowQueryThreshold: 5 * time.Millisecond,
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Clock:              fake,
+	})
+
+	if _, err := client.GetTrade(ctx, uuid.New().String()); err == nil {
+		t.Fatal("Expected error from GetTrade")
+	}
+
+	recorded := client.SlowQueries()
+	if len(recorded) != 1 {
+		t.Fatalf("Expected 1 slow query, got %d", len(recorded))
+	}
+	if recorded[0].Succeeded {
+		t.Error("Expected slow query to be marked as failed")
+	}
+	if _, ok := recorded[0].VariableSizes["id"]; ok {
+		t.Error("Scalar variables must not be included in variable sizes")
+	}
+}
+
+func TestClient_SlowQuery_BelowThreshold(t *testing.T) {
+	ctx := context.Background()
+	mockClient := delayingMock(clock.NewFake(time.Now()), 0, map[string]interface{}{"exchanges": []interface{}{}}, nil)
+
+	called := false
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:                "http://localhost:8080/v1/graphql",
+		AdminSecret:        "test-secret",
+		SlowQueryThreshold: time.Second,
+		SlowQueryHook:      func(SlowQuery) { called = true },
+	})
+
+	if _, err := client.ListExchanges(ctx, false); err != nil {
+		t.Fatalf("ListExchanges failed: %v", err)
+	}
+
+	if called {
+		t.Error("Hook should not be called for fast queries")
+	}
+	if len(client.SlowQueries()) != 0 {
+		t.Error("Expected no slow queries recorded")
+	}
+}
+
+func TestClient_SlowQuery_TotalAcrossRetries(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	attempts := 0
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			attempts++
+			fake.Advance(4 * time.Millisecond) // Each attempt stays under the threshold
+			if attempts < 3 {
+				return &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}
+			}
+			return json.Unmarshal([]byte(`{"exchanges": []}`), resp)
+		},
+	}
+
+	var hooked []SlowQuery
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		SlowQueryThreshold: 5 * time.Millisecond,
+		SlowQueryHook:      func(q SlowQuery) { hooked = append(hooked, q) },
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Clock:              fake,
+	}, WithRetry(3, time.Millisecond))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.ListExchanges(context.Background(), false)
+		done <- err
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(time.Millisecond)
+	fake.BlockUntil(1)
+	fake.Advance(2 * time.Millisecond)
+	if err := <-done; err != nil {
+		t.Fatalf("ListExchanges failed: %v", err)
+	}
+
+	if len(hooked) != 1 {
+		t.Fatalf("Expected one slow query for the whole call, got %d", len(hooked))
+	}
+	if hooked[0].Duration != 15*time.Millisecond {
+		t.Errorf("Expected the total 15ms including retries and backoff, got %v", hooked[0].Duration)
+	}
+	if !hooked[0].Succeeded {
+		t.Error("Expected the call that eventually succeeded to be marked as succeeded")
+	}
+}
@@ -0,0 +1,209 @@
+package db
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/zif-terminal/lib/models"
+)
+
+// requiredFields lists the JSON fields that must be present whenever a key model is decoded in
+// strict mode. A missing field here means the selection set drifted
+var requiredFields = map[reflect.Type][]string{
+	reflect.TypeOf(models.Exchange{}):        {"id", "name"},
+	reflect.TypeOf(models.ExchangeAccount{}): {"id", "account_identifier"},
+	reflect.TypeOf(models.Trade{}):           {"id", "exchange_account_id", "trade_id", "timestamp"},
+	reflect.TypeOf(models.FundingPayment{}):  {"id", "exchange_account_id", "payment_id", "timestamp"},
+	reflect.TypeOf(models.Position{}):        {"id", "exchange_account_id", "start_time", "end_time"},
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	rawMessageType      = reflect.TypeOf(json.RawMessage{})
+	interfaceType       = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// strictTypes caches the strict decoding type of each response type (see strictType)
+var strictTypes sync.Map
+
+// strictRequired maps the strict decoding type of each key model to its required fields
+var strictRequired sync.Map
+
+// checkStrict verifies that data contains no fields unknown to the response struct, using
+// json.Decoder.DisallowUnknownFields, and that key models carry their required fields. The
+// models' custom UnmarshalJSON methods decode with a plain json.Unmarshal, which would not pass
+// the setting on, so data is decoded into a copy of resp's type without those methods (see
+// strictType), checked for required fields and thrown away
+func checkStrict(data []byte, resp interface{}) error {
+	decoded := reflect.New(strictType(reflect.TypeOf(resp)))
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(decoded.Interface()); err != nil {
+		return err
+	}
+	return checkRequired(decoded, "data")
+}
+
+// checkRequired walks a value decoded into a strict type and reports the first key model missing
+// a required field. Leaf fields decode as json.RawMessage, which stays nil only when the field is
+// absent, so an explicit null (e.g. an open position's end_time) still counts as present
+func checkRequired(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return checkRequired(v.Elem(), path)
+	case reflect.Struct:
+		t := v.Type()
+		if required, ok := strictRequired.Load(t); ok {
+			for _, name := range required.([]string) {
+				if field, ok := strictField(t, name); ok && v.FieldByIndex(field.Index).IsZero() {
+					return fmt.Errorf("missing required field %q at %s", name, path)
+				}
+			}
+		}
+		for i := 0; i < t.NumField(); i++ {
+			name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+			if err := checkRequired(v.Field(i), path+"."+name); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		if v.Type() == rawMessageType {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := checkRequired(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, key := range keys {
+			if err := checkRequired(v.MapIndex(key), path+"."+key.String()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// strictField returns the field of a strict struct type decoding the JSON field name
+func strictField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Tag.Get("json") == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// strictType returns a type accepting the same JSON objects as t but without custom unmarshalers
+// Structs are rebuilt field by field with embedded fields flattened; values that are not objects
+// or lists become json.RawMessage, since only field names are checked and the normal decode
+// catches type mismatches
+func strictType(t reflect.Type) reflect.Type {
+	if cached, ok := strictTypes.Load(t); ok {
+		return cached.(reflect.Type)
+	}
+
+	var st reflect.Type
+	switch {
+	case t.Kind() == reflect.Pointer:
+		// Raw leaves stay unwrapped so a null leaves them non-nil (see checkRequired)
+		if st = strictType(t.Elem()); st != rawMessageType {
+			st = reflect.PointerTo(st)
+		}
+	case t.Kind() == reflect.Interface:
+		st = interfaceType
+	case t.Kind() == reflect.Struct && len(jsonFields(t)) > 0:
+		st = strictStruct(t)
+		if required, ok := requiredFields[t]; ok {
+			strictRequired.Store(st, required)
+		}
+	case customUnmarshaler(t):
+		st = rawMessageType
+	case t.Kind() == reflect.Slice, t.Kind() == reflect.Array:
+		st = reflect.SliceOf(strictType(t.Elem()))
+	case t.Kind() == reflect.Map:
+		st = reflect.MapOf(t.Key(), strictType(t.Elem()))
+	default:
+		st = rawMessageType
+	}
+
+	strictTypes.Store(t, st)
+	return st
+}
+
+// strictStruct builds the strict decoding type of a struct with JSON fields
+func strictStruct(t reflect.Type) reflect.Type {
+	fields := jsonFields(t)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	structFields := make([]reflect.StructField, len(names))
+	for i, name := range names {
+		structFields[i] = reflect.StructField{
+			Name: fmt.Sprintf("F%d", i),
+			Type: strictType(fields[name]),
+			Tag:  reflect.StructTag(fmt.Sprintf(`json:%q`, name)),
+		}
+	}
+	return reflect.StructOf(structFields)
+}
+
+// customUnmarshaler reports whether t decodes itself from JSON or from a JSON string
+func customUnmarshaler(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return t.Implements(jsonUnmarshalerType) || pt.Implements(jsonUnmarshalerType) ||
+		t.Implements(textUnmarshalerType) || pt.Implements(textUnmarshalerType)
+}
+
+// jsonFields returns the JSON field names a struct accepts, including promoted fields of embedded structs
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for k, v := range jsonFields(embedded) {
+					if _, exists := fields[k]; !exists {
+						fields[k] = v
+					}
+				}
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
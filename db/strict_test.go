@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestClient_StrictDecoding_UnknownField(t *testing.T) {
+	body := `{"data":{"exchanges":[{"id":"ex-1","name":"hyperliquid","display_name":"Hyperliquid","displayName":"renamed"}]}}`
+
+	client := NewClientWithGraphQL(rawResponse(body), ClientConfig{
+		URL:            "http://localhost:8080/v1/graphql",
+		AdminSecret:    "test-secret",
+		StrictDecoding: true,
+	})
+
+	_, err := client.ListExchanges(context.Background(), false)
+	if err == nil {
+		t.Fatal("Expected strict decoding error for unknown field")
+	}
+	if !strings.Contains(err.Error(), `"displayName"`) {
+		t.Errorf("Expected error to name the unexpected field, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "ListExchanges") {
+		t.Errorf("Expected error to name the operation, got: %v", err)
+	}
+}
+
+func TestClient_StrictDecoding_MissingRequiredField(t *testing.T) {
+	// trade_id missing from the trade selection
+	body := `{"data":{"trades_by_pk":{"id":"11111111-1111-1111-1111-111111111111","exchange_account_id":"22222222-2222-2222-2222-222222222222","timestamp":1609459200000}}}`
+
+	client := NewClientWithGraphQL(rawResponse(body), ClientConfig{
+		URL:            "http://localhost:8080/v1/graphql",
+		AdminSecret:    "test-secret",
+		StrictDecoding: true,
+	})
+
+	_, err := client.GetTrade(context.Background(), "11111111-1111-1111-1111-111111111111")
+	if err == nil {
+		t.Fatal("Expected strict decoding error for missing field")
+	}
+	if !strings.Contains(err.Error(), `"trade_id"`) {
+		t.Errorf("Expected error to name the missing field, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "GetTrade") {
+		t.Errorf("Expected error to name the operation, got: %v", err)
+	}
+}
+
+func TestClient_StrictDecoding_UnknownFieldInCustomUnmarshaler(t *testing.T) {
+	// Trade decodes itself through UnmarshalJSON, which must not hide the extra field
+	body := `{"data":{"trades_by_pk":{"id":"11111111-1111-1111-1111-111111111111","exchange_account_id":"22222222-2222-2222-2222-222222222222","trade_id":"t-1","timestamp":1609459200000,"price":"100.5","fee_currency":"USDC"}}}`
+
+	client := NewClientWithGraphQL(rawResponse(body), ClientConfig{StrictDecoding: true})
+
+	_, err := client.GetTrade(context.Background(), "11111111-1111-1111-1111-111111111111")
+	if err == nil {
+		t.Fatal("Expected strict decoding error for unknown field")
+	}
+	if !strings.Contains(err.Error(), `"fee_currency"`) {
+		t.Errorf("Expected error to name the unexpected field, got: %v", err)
+	}
+}
+
+func TestClient_StrictDecoding_NestedAndEmbedded(t *testing.T) {
+	// Nested exchange on accounts and embedded Position in GetPositionByID must both be accepted
+	accountBody := `{"data":{"exchange_accounts_by_pk":{"id":"acc-1","account_identifier":"0xabc","account_type":"main","account_type_metadata":null,"exchange":{"id":"ex-1","name":"hyperliquid","display_name":"Hyperliquid"}}}}`
+	client := NewClientWithGraphQL(rawResponse(accountBody), ClientConfig{StrictDecoding: true})
+	if _, err := client.GetAccount(context.Background(), "acc-1"); err != nil {
+		t.Fatalf("GetAccount failed in strict mode: %v", err)
+	}
+
+	positionBody := `{"data":{"positions_by_pk":{"id":"11111111-1111-1111-1111-111111111111","exchange_account_id":"22222222-2222-2222-2222-222222222222","start_time":1,"end_time":2,"position_trades":[]}}}`
+	client = NewClientWithGraphQL(rawResponse(positionBody), ClientConfig{StrictDecoding: true})
+	if _, _, err := client.GetPositionByID(context.Background(), "11111111-1111-1111-1111-111111111111"); err != nil {
+		t.Fatalf("GetPositionByID failed in strict mode: %v", err)
+	}
+}
+
+func TestClient_StrictDecoding_DisabledByDefault(t *testing.T) {
+	body := `{"data":{"exchanges":[{"id":"ex-1","name":"hyperliquid","display_name":"Hyperliquid","extra":true}]}}`
+
+	client := NewClientWithGraphQL(rawResponse(body), ClientConfig{})
+
+	exchanges, err := client.ListExchanges(context.Background(), false)
+	if err != nil {
+		t.Fatalf("ListExchanges failed: %v", err)
+	}
+	if len(exchanges) != 1 || exchanges[0].Name != "hyperliquid" {
+		t.Errorf("Expected one decoded exchange, got %+v", exchanges)
+	}
+}
+
+func TestClient_StrictDecoding_RequiredFieldMayBeNull(t *testing.T) {
+	// An open position's end_time is null but selected, which must pass; leaving it out must not
+	open := `{"data":{"positions":[{"id":"11111111-1111-1111-1111-111111111111","exchange_account_id":"22222222-2222-2222-2222-222222222222","start_time":1,"end_time":null}]}}`
+	client := NewClientWithGraphQL(rawResponse(open), ClientConfig{StrictDecoding: true})
+	if _, err := client.GetPositions(context.Background(), PositionFilter{}); err != nil {
+		t.Fatalf("GetPositions failed in strict mode: %v", err)
+	}
+
+	missing := `{"data":{"positions":[{"id":"11111111-1111-1111-1111-111111111111","exchange_account_id":"22222222-2222-2222-2222-222222222222","start_time":1}]}}`
+	client = NewClientWithGraphQL(rawResponse(missing), ClientConfig{StrictDecoding: true})
+	_, err := client.GetPositions(context.Background(), PositionFilter{})
+	if err == nil || !strings.Contains(err.Error(), `missing required field "end_time" at data.positions[0]`) {
+		t.Errorf("Expected a missing end_time error, got: %v", err)
+	}
+}
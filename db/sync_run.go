@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/models"
+)
+
+func init() {
+	registerOperations(map[string]Idempotency{
+		"CreateSyncRun": NotIdempotent, // Plain insert; a retried insert records the run twice
+	})
+}
+
+// SyncRun represents a sync run record (aliased from models package)
+type SyncRun = models.SyncRun
+
+// SyncRunInput represents sync run input for mutations (aliased from models package)
+type SyncRunInput = models.SyncRunInput
+
+// CreateSyncRun records the outcome of one ingestion step of an account sync
+func (c *Client) CreateSyncRun(ctx context.Context, input *SyncRunInput) (*SyncRun, error) {
+	if err := input.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to create sync run: %w", err)
+	}
+
+	query := `
+		mutation CreateSyncRun($object: sync_runs_insert_input!) {
+			insert_sync_runs_one(object: $object) {
+				id
+				exchange_account_id
+				kind
+				fetched
+				inserted
+				skipped
+				duration_ms
+				error
+				started_at
+				created_at
+			}
+		}
+	`
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"object": map[string]interface{}{
+			"exchange_account_id": uuidVar(input.ExchangeAccountID),
+			"kind":                input.Kind,
+			"fetched":             input.Fetched,
+			"inserted":            input.Inserted,
+			"skipped":             input.Skipped,
+			"duration_ms":         input.Duration.Milliseconds(),
+			"error":               input.Error,
+			"started_at":          input.StartedAt.UnixMilli(),
+		},
+	})
+
+	var resp struct {
+		InsertSyncRunsOne *SyncRun `json:"insert_sync_runs_one"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to create sync run: %w", err)
+	}
+
+	return resp.InsertSyncRunsOne, nil
+}
+
+// ListSyncRuns retrieves an account's most recent sync runs (newest first)
+func (c *Client) ListSyncRuns(ctx context.Context, exchangeAccountID uuid.UUID, limit int) ([]*SyncRun, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("failed to list sync runs: limit must be positive, got %d", limit)
+	}
+
+	query := `
+		query ListSyncRuns($exchange_account_id: uuid!, $limit: Int!) {
+			sync_runs(
+				where: { exchange_account_id: { _eq: $exchange_account_id } }
+				order_by: [{ started_at: desc }, { id: desc }]
+				limit: $limit
+			) {
+				id
+				exchange_account_id
+				kind
+				fetched
+				inserted
+				skipped
+				duration_ms
+				error
+				started_at
+				created_at
+			}
+		}
+	`
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"exchange_account_id": uuidVar(exchangeAccountID),
+		"limit":               limit,
+	})
+
+	var resp struct {
+		SyncRuns []*SyncRun `json:"sync_runs"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list sync runs: %w", err)
+	}
+
+	return resp.SyncRuns, nil
+}
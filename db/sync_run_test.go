@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+)
+
+func TestClient_CreateSyncRun(t *testing.T) {
+	accountID := uuid.New()
+	started := time.UnixMilli(1700000000000)
+	message := "failed to fetch trades: timeout"
+
+	var vars map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			vars = requestFromContext(ctx).vars
+			return json.Unmarshal([]byte(`{"insert_sync_runs_one": {
+				"id": "770e8400-e29b-41d4-a716-446655440000",
+				"exchange_account_id": "`+accountID.String()+`",
+				"kind": "trades", "fetched": 5, "inserted": 3, "skipped": 1,
+				"duration_ms": 1500, "error": "failed to fetch trades: timeout",
+				"started_at": 1700000000000, "created_at": "2023-11-14T22:13:21Z"
+			}}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	run, err := client.CreateSyncRun(context.Background(), &SyncRunInput{
+		ExchangeAccountID: accountID,
+		Kind:              "trades",
+		Fetched:           5,
+		Inserted:          3,
+		Skipped:           1,
+		Duration:          1500 * time.Millisecond,
+		Error:             &message,
+		StartedAt:         started,
+	})
+	if err != nil {
+		t.Fatalf("CreateSyncRun failed: %v", err)
+	}
+
+	object := vars["object"].(map[string]interface{})
+	if object["duration_ms"] != int64(1500) || object["started_at"] != started.UnixMilli() {
+		t.Errorf("Expected millisecond duration and start, got %v and %v", object["duration_ms"], object["started_at"])
+	}
+	if object["error"] != &message || object["exchange_account_id"] != accountID.String() {
+		t.Errorf("Unexpected object: %+v", object)
+	}
+
+	if run.Duration != 1500*time.Millisecond || !run.StartedAt.Equal(started) {
+		t.Errorf("Expected decoded duration and start, got %v and %v", run.Duration, run.StartedAt)
+	}
+	if run.Error == nil || *run.Error != message || run.Inserted != 3 {
+		t.Errorf("Unexpected decoded run: %+v", run)
+	}
+}
+
+func TestClient_CreateSyncRun_ValidatesInput(t *testing.T) {
+	calls := 0
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			calls++
+			return nil
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	_, err := client.CreateSyncRun(context.Background(), &SyncRunInput{Kind: "orders"})
+	if err == nil || !strings.Contains(err.Error(), "kind") {
+		t.Errorf("Expected validation error for kind, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected invalid input not to be sent, got %d calls", calls)
+	}
+}
+
+func TestClient_ListSyncRuns(t *testing.T) {
+	accountID := uuid.New()
+
+	var r *request
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			r = requestFromContext(ctx)
+			return nil
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	if _, err := client.ListSyncRuns(context.Background(), accountID, 20); err != nil {
+		t.Fatalf("ListSyncRuns failed: %v", err)
+	}
+	if r.vars["exchange_account_id"] != accountID.String() || r.vars["limit"] != 20 {
+		t.Errorf("Unexpected variables: %+v", r.vars)
+	}
+	if !strings.Contains(r.query, "order_by: [{ started_at: desc }, { id: desc }]") {
+		t.Errorf("Expected newest-first ordering in query:\n%s", r.query)
+	}
+
+	if _, err := client.ListSyncRuns(context.Background(), accountID, 0); err == nil {
+		t.Error("Expected error for non-positive limit")
+	}
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Default per-operation timeouts applied when ClientConfig leaves them unset
+const (
+	DefaultReadTimeout  = 10 * time.Second
+	DefaultWriteTimeout = 60 * time.Second
+)
+
+// NoTimeout disables the per-operation deadline when set as ClientConfig.ReadTimeout or
+// WriteTimeout (or passed to WithTimeout), so only the caller's ctx bounds the call
+const NoTimeout time.Duration = -1
+
+// timeoutContextKey is the context key under which WithTimeout stores a per-call override
+type timeoutContextKey struct{}
+
+// WithTimeout overrides the configured read/write timeout for operations run with the returned context
+// Zero or NoTimeout applies no operation deadline, so only the caller's ctx bounds the call
+func WithTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutContextKey{}, timeout)
+}
+
+// isMutation reports whether query is a GraphQL mutation (anything else is treated as a read)
+func isMutation(query string) bool {
+	return strings.HasPrefix(strings.TrimSpace(query), "mutation")
+}
+
+// operationTimeout returns the deadline budget for req: the per-call override if set,
+// otherwise ReadTimeout or WriteTimeout depending on the operation type
+func (c *Client) operationTimeout(ctx context.Context, req *request) time.Duration {
+	if timeout, ok := ctx.Value(timeoutContextKey{}).(time.Duration); ok {
+		return timeout
+	}
+
+	if isMutation(req.query) {
+		return resolveTimeout(c.config.WriteTimeout, DefaultWriteTimeout)
+	}
+	return resolveTimeout(c.config.ReadTimeout, DefaultReadTimeout)
+}
+
+// resolveTimeout applies the default for an unset (zero) timeout; NoTimeout (or any negative
+// value) disables the timeout
+func resolveTimeout(configured, fallback time.Duration) time.Duration {
+	switch {
+	case configured == 0:
+		return fallback
+	case configured < 0:
+		return 0
+	default:
+		return configured
+	}
+}
+
+// withOperationTimeout wraps ctx with the operation deadline for req
+// The caller's own deadline still wins if it is earlier
+func (c *Client) withOperationTimeout(ctx context.Context, req *request) (context.Context, context.CancelFunc) {
+	timeout := c.operationTimeout(ctx, req)
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, c.clock.Now().Add(timeout))
+}
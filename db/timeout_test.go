@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/clock"
+)
+
+// delayingGraphQLClient answers after delay unless the context expires first
+func delayingGraphQLClient(delay time.Duration) *mockGraphQLClient {
+	return &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			select {
+			case <-time.After(delay):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+func TestClient_OperationTimeouts(t *testing.T) {
+	client := NewClientWithGraphQL(delayingGraphQLClient(50*time.Millisecond), ClientConfig{
+		URL:          "http://localhost:8080/v1/graphql",
+		AdminSecret:  "test-secret",
+		ReadTimeout:  10 * time.Millisecond,
+		WriteTimeout: time.Second,
+	})
+
+	var resp struct{}
+	err := client.execute(context.Background(), client.graphqlRequest(`query GetThing { things { id } }`), &resp)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected read to hit ReadTimeout, got %v", err)
+	}
+
+	err = client.execute(context.Background(), client.graphqlRequest(`mutation AddThing { insert_things { affected_rows } }`), &resp)
+	if err != nil {
+		t.Errorf("Expected write to finish within WriteTimeout, got %v", err)
+	}
+}
+
+func TestClient_OperationTimeouts_Defaults(t *testing.T) {
+	var remaining time.Duration
+	mock := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				t.Fatal("Expected operation deadline")
+			}
+			remaining = time.Until(deadline)
+			return nil
+		},
+	}
+
+	client := NewClientWithGraphQL(mock, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	var resp struct{}
+	client.execute(context.Background(), client.graphqlRequest(`query GetThing { things { id } }`), &resp)
+	if remaining <= DefaultReadTimeout-time.Second || remaining > DefaultReadTimeout {
+		t.Errorf("Expected read deadline ~%s, got %s", DefaultReadTimeout, remaining)
+	}
+
+	client.execute(context.Background(), client.graphqlRequest(`mutation AddThing { insert_things { affected_rows } }`), &resp)
+	if remaining <= DefaultWriteTimeout-time.Second || remaining > DefaultWriteTimeout {
+		t.Errorf("Expected write deadline ~%s, got %s", DefaultWriteTimeout, remaining)
+	}
+}
+
+func TestClient_WithTimeout(t *testing.T) {
+	client := NewClientWithGraphQL(delayingGraphQLClient(50*time.Millisecond), ClientConfig{
+		URL:          "http://localhost:8080/v1/graphql",
+		AdminSecret:  "test-secret",
+		ReadTimeout:  10 * time.Millisecond,
+		WriteTimeout: 10 * time.Millisecond,
+	})
+
+	var resp struct{}
+	ctx := WithTimeout(context.Background(), time.Second)
+	if err := client.execute(ctx, client.graphqlRequest(`query GetThing { things { id } }`), &resp); err != nil {
+		t.Errorf("Expected per-call timeout to extend the read deadline, got %v", err)
+	}
+
+	ctx = WithTimeout(context.Background(), 0)
+	if err := client.execute(ctx, client.graphqlRequest(`mutation AddThing { insert_things { affected_rows } }`), &resp); err != nil {
+		t.Errorf("Expected zero timeout to inherit the caller ctx, got %v", err)
+	}
+}
+
+func TestClient_OperationTimeouts_FakeClock(t *testing.T) {
+	now := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var deadline time.Time
+	mock := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			var ok bool
+			if deadline, ok = ctx.Deadline(); !ok {
+				t.Fatal("Expected operation deadline")
+			}
+			return nil
+		},
+	}
+
+	client := NewClientWithGraphQL(mock, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	}, WithClock(clock.NewFake(now)))
+
+	var resp struct{}
+	client.execute(context.Background(), client.graphqlRequest(`query GetThing { things { id } }`), &resp)
+	if want := now.Add(DefaultReadTimeout); !deadline.Equal(want) {
+		t.Errorf("Expected read deadline %s, got %s", want, deadline)
+	}
+
+	client.execute(context.Background(), client.graphqlRequest(`mutation AddThing { insert_things { affected_rows } }`), &resp)
+	if want := now.Add(DefaultWriteTimeout); !deadline.Equal(want) {
+		t.Errorf("Expected write deadline %s, got %s", want, deadline)
+	}
+
+	client.execute(WithTimeout(context.Background(), time.Minute), client.graphqlRequest(`query GetThing { things { id } }`), &resp)
+	if want := now.Add(time.Minute); !deadline.Equal(want) {
+		t.Errorf("Expected per-call deadline %s, got %s", want, deadline)
+	}
+}
+
+func TestClient_OperationTimeouts_NoTimeout(t *testing.T) {
+	var hasDeadline bool
+	mock := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			_, hasDeadline = ctx.Deadline()
+			return nil
+		},
+	}
+
+	client := NewClientWithGraphQL(mock, ClientConfig{ReadTimeout: NoTimeout, WriteTimeout: NoTimeout})
+
+	var resp struct{}
+	client.execute(context.Background(), client.graphqlRequest(`query GetThing { things { id } }`), &resp)
+	if hasDeadline {
+		t.Error("Expected the read to inherit the caller ctx without a deadline")
+	}
+	client.execute(context.Background(), client.graphqlRequest(`mutation AddThing { insert_things { affected_rows } }`), &resp)
+	if hasDeadline {
+		t.Error("Expected the write to inherit the caller ctx without a deadline")
+	}
+
+	// The caller's own deadline is kept as is
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	want, _ := ctx.Deadline()
+	var got time.Time
+	mock.runFunc = func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+		got, _ = ctx.Deadline()
+		return nil
+	}
+	client.execute(ctx, client.graphqlRequest(`query GetThing { things { id } }`), &resp)
+	if !got.Equal(want) {
+		t.Errorf("Expected the caller deadline %s, got %s", want, got)
+	}
+
+	// NoTimeout also works per call
+	client = NewClientWithGraphQL(mock, ClientConfig{})
+	got = time.Time{}
+	client.execute(WithTimeout(context.Background(), NoTimeout), client.graphqlRequest(`query GetThing { things { id } }`), &resp)
+	if !got.IsZero() {
+		t.Errorf("Expected no deadline with WithTimeout(NoTimeout), got %s", got)
+	}
+}
@@ -8,12 +8,28 @@ import (
 	"github.com/zif-terminal/lib/models"
 )
 
+func init() {
+	registerOperations(map[string]Idempotency{
+		"CreateTrade":    NotIdempotent, // Plain insert
+		"UpdateTrade":    Idempotent,    // Update by primary key
+		"DeleteTrade":    Idempotent,    // Delete by primary key
+		"AddTrades":      Idempotent,    // Conflicting trade_ids are ignored
+		"AddTradesCount": Idempotent,    // Same insert as AddTrades
+	})
+}
+
 // Trade represents a trade model (aliased from models package)
 type Trade = models.Trade
 
 // TradeInput represents trade input for mutations (aliased from models package)
 type TradeInput = models.TradeInput
 
+// AssetPair identifies a market by base and quote asset (aliased from models package)
+type AssetPair = models.AssetPair
+
+// TimeRange is a half-open time window (aliased from models package)
+type TimeRange = models.TimeRange
+
 // TradeFilter represents filtering options for listing trades
 type TradeFilter = models.TradeFilter
 
@@ -33,6 +49,11 @@ func (c *Client) GetTrade(ctx context.Context, id string) (*Trade, error) {
 				order_id
 				trade_id
 				exchange_account_id
+				created_at
+				source
+				fee_asset
+				market_type
+				is_taker
 			}
 		}
 	`
@@ -58,50 +79,150 @@ func (c *Client) GetTrade(ctx context.Context, id string) (*Trade, error) {
 
 // ListTrades retrieves trades with optional filtering
 func (c *Client) ListTrades(ctx context.Context, filter TradeFilter) ([]*Trade, error) {
-	var query string
-	var vars map[string]interface{}
+	b := buildTradeWhere(filter)
+	query := listTradesQuery(b, paginationArgs(b, filter.Limit, filter.Offset), false)
 
-	// Build query based on whether we're filtering by account IDs
-	if len(filter.ExchangeAccountIDs) > 0 {
-		// Filter by specific account IDs
-		query = `
-			query ListTrades($exchange_account_ids: [uuid!]!) {
-				trades(
-					where: {
-						exchange_account_id: {
-							_in: $exchange_account_ids
-						}
-					}
-					order_by: { timestamp: desc }
-				) {
-					id
-					base_asset
-					quote_asset
-					side
-					price
-					quantity
-					timestamp
-					fee
-					order_id
-					trade_id
-					exchange_account_id
-				}
+	req := c.graphqlRequestWithVars(query, b.variables())
+
+	var resp struct {
+		Trades []*Trade `json:"trades"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list trades: %w", err)
+	}
+
+	return resp.Trades, nil
+}
+
+// ListTradesPage retrieves a single page of trades (newest first) using filter.Limit/filter.Offset
+// When opts.IncludeTotalCount is set, the matching row count is fetched in the same request
+func (c *Client) ListTradesPage(ctx context.Context, filter TradeFilter, opts PageOptions) (*Page[*Trade], error) {
+	b := buildTradeWhere(filter)
+	limit := filter.Limit
+	if limit > 0 {
+		limit++ // Look ahead one row to determine HasMore
+	}
+	query := listTradesQuery(b, paginationArgs(b, limit, filter.Offset), opts.IncludeTotalCount)
+
+	req := c.graphqlRequestWithVars(query, b.variables())
+
+	var resp struct {
+		Trades          []*Trade       `json:"trades"`
+		TradesAggregate aggregateCount `json:"trades_aggregate"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list trades page: %w", err)
+	}
+
+	return newPage(resp.Trades, filter.Limit, filter.Offset, resp.TradesAggregate.total(opts)), nil
+}
+
+// GetRecentTrades retrieves the limit most recent trades across all accounts, newest first
+func (c *Client) GetRecentTrades(ctx context.Context, limit int) ([]*Trade, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("failed to get recent trades: limit must be positive, got %d", limit)
+	}
+
+	query := `
+		query GetRecentTrades($limit: Int!) {
+			trades(
+				order_by: [{ timestamp: desc }, { id: desc }]
+				limit: $limit
+			) {
+				id
+				base_asset
+				quote_asset
+				side
+				price
+				quantity
+				timestamp
+				fee
+				order_id
+				trade_id
+				exchange_account_id
+				created_at
+				source
+				fee_asset
+				market_type
+				is_taker
 			}
-		`
+		}
+	`
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"limit": limit,
+	})
+
+	var resp struct {
+		Trades []*Trade `json:"trades"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get recent trades: %w", err)
+	}
+
+	return resp.Trades, nil
+}
+
+// buildTradeWhere translates a TradeFilter into where-clause conditions
+func buildTradeWhere(filter TradeFilter) *whereBuilder {
+	b := newWhereBuilder()
+
+	if len(filter.ExchangeAccountIDs) > 0 {
 		// Convert UUIDs to strings for GraphQL
 		accountIDs := make([]string, len(filter.ExchangeAccountIDs))
 		for i, id := range filter.ExchangeAccountIDs {
-			accountIDs[i] = id.String()
+			accountIDs[i] = uuidVar(id)
 		}
-		vars = map[string]interface{}{
-			"exchange_account_ids": accountIDs,
+		b.add("exchange_account_id", "_in", "exchange_account_ids", "[uuid!]!", accountIDs)
+	}
+
+	switch len(filter.ExcludeExchangeAccountIDs) {
+	case 0:
+	case 1:
+		b.add("exchange_account_id", "_neq", "exclude_exchange_account_id", "uuid!", uuidVar(filter.ExcludeExchangeAccountIDs[0]))
+	default:
+		accountIDs := make([]string, len(filter.ExcludeExchangeAccountIDs))
+		for i, id := range filter.ExcludeExchangeAccountIDs {
+			accountIDs[i] = uuidVar(id)
 		}
-	} else {
-		// No filter - get all trades
-		query = `
-			query ListTrades {
+		b.add("exchange_account_id", "_nin", "exclude_exchange_account_ids", "[uuid!]!", accountIDs)
+	}
+
+	switch len(filter.ExcludeAssets) {
+	case 0:
+	case 1:
+		b.add("base_asset", "_neq", "exclude_asset", "String!", filter.ExcludeAssets[0])
+	default:
+		b.add("base_asset", "_nin", "exclude_assets", "[String!]!", filter.ExcludeAssets)
+	}
+
+	if filter.Source != nil {
+		b.add("source", "_eq", "source", "String!", *filter.Source)
+	}
+
+	if filter.UserID != nil {
+		b.add("exchange_account.user_id", "_eq", "user_id", "uuid!", *filter.UserID)
+	}
+
+	return b
+}
+
+// listTradesQuery builds the ListTrades query, optionally including an aggregate count
+func listTradesQuery(b *whereBuilder, pagination string, withCount bool) string {
+	aggregate := ""
+	if withCount {
+		aggregate = aggregateSelection("trades_aggregate", b)
+	}
+
+	return fmt.Sprintf(`
+			query ListTrades%s {
 				trades(
-					order_by: { timestamp: desc }
+					%s
+					order_by: [{ timestamp: desc }, { id: desc }]
+					%s
 				) {
 					id
 					base_asset
@@ -114,26 +235,19 @@ func (c *Client) ListTrades(ctx context.Context, filter TradeFilter) ([]*Trade,
 					order_id
 					trade_id
 					exchange_account_id
-				}
+					created_at
+					source
+					fee_asset
+					market_type
+					is_taker
+				}%s
 			}
-		`
-		vars = map[string]interface{}{}
-	}
-
-	req := c.graphqlRequestWithVars(query, vars)
-
-	var resp struct {
-		Trades []*Trade `json:"trades"`
-	}
-
-	if err := c.execute(ctx, req, &resp); err != nil {
-		return nil, fmt.Errorf("failed to list trades: %w", err)
-	}
-
-	return resp.Trades, nil
+		`, b.declarations(), b.whereArg(), pagination, aggregate)
 }
 
 // CreateTrade creates a new trade
+// An empty OrderID is stored as NULL and an empty Fee as models.DefaultTradeFee
+// Returns a *DuplicateError if the account already has a trade with input.TradeID
 func (c *Client) CreateTrade(ctx context.Context, input *TradeInput) (*Trade, error) {
 	query := `
 		mutation CreateTrade(
@@ -144,9 +258,13 @@ func (c *Client) CreateTrade(ctx context.Context, input *TradeInput) (*Trade, er
 			$quantity: numeric!
 			$timestamp: bigint!
 			$fee: numeric!
-			$order_id: String!
+			$order_id: String
 			$trade_id: String!
 			$exchange_account_id: uuid!
+			$source: String
+			$fee_asset: String
+			$market_type: String
+			$is_taker: Boolean
 		) {
 			insert_trades_one(object: {
 				base_asset: $base_asset
@@ -159,6 +277,10 @@ func (c *Client) CreateTrade(ctx context.Context, input *TradeInput) (*Trade, er
 				order_id: $order_id
 				trade_id: $trade_id
 				exchange_account_id: $exchange_account_id
+				source: $source
+				fee_asset: $fee_asset
+				market_type: $market_type
+				is_taker: $is_taker
 			}) {
 				id
 				base_asset
@@ -171,6 +293,11 @@ func (c *Client) CreateTrade(ctx context.Context, input *TradeInput) (*Trade, er
 				order_id
 				trade_id
 				exchange_account_id
+				created_at
+				source
+				fee_asset
+				market_type
+				is_taker
 			}
 		}
 	`
@@ -182,10 +309,29 @@ func (c *Client) CreateTrade(ctx context.Context, input *TradeInput) (*Trade, er
 		"price":              input.Price,
 		"quantity":           input.Quantity,
 		"timestamp":          input.Timestamp.UnixMilli(),
-		"fee":                input.Fee,
-		"order_id":           input.OrderID,
+		"fee":                models.FeeOrDefault(input.Fee),
 		"trade_id":           input.TradeID,
-		"exchange_account_id": input.ExchangeAccountID.String(),
+		"exchange_account_id": uuidVar(input.ExchangeAccountID),
+		"is_taker":           input.IsTaker,
+	}
+	if input.OrderID != "" {
+		vars["order_id"] = input.OrderID
+	}
+	if input.Source != "" {
+		vars["source"] = input.Source
+	}
+	if input.FeeAsset != "" {
+		vars["fee_asset"] = input.FeeAsset
+	}
+	if input.MarketType != "" {
+		vars["market_type"] = input.MarketType
+	}
+
+	if err := normalizeSideField("trade", vars, models.NormalizeTradeSide); err != nil {
+		return nil, fmt.Errorf("failed to create trade: %w", err)
+	}
+	if err := encodeNumericFields("trade", vars, "price", "quantity", "fee"); err != nil {
+		return nil, fmt.Errorf("failed to create trade: %w", err)
 	}
 
 	req := c.graphqlRequestWithVars(query, vars)
@@ -195,6 +341,9 @@ func (c *Client) CreateTrade(ctx context.Context, input *TradeInput) (*Trade, er
 	}
 
 	if err := c.execute(ctx, req, &resp); err != nil {
+		if isUniqueViolation(err) {
+			err = &DuplicateError{Resource: "trade", Key: input.TradeID, Err: err}
+		}
 		return nil, fmt.Errorf("failed to create trade: %w", err)
 	}
 
@@ -206,6 +355,7 @@ func (c *Client) CreateTrade(ctx context.Context, input *TradeInput) (*Trade, er
 }
 
 // UpdateTrade updates an existing trade
+// An empty OrderID clears the stored order ID and an empty Fee stores models.DefaultTradeFee
 func (c *Client) UpdateTrade(ctx context.Context, id string, input *TradeInput) (*Trade, error) {
 	query := `
 		mutation UpdateTrade(
@@ -217,7 +367,7 @@ func (c *Client) UpdateTrade(ctx context.Context, id string, input *TradeInput)
 			$quantity: numeric!
 			$timestamp: bigint!
 			$fee: numeric!
-			$order_id: String!
+			$order_id: String
 			$trade_id: String!
 			$exchange_account_id: uuid!
 		) {
@@ -247,6 +397,11 @@ func (c *Client) UpdateTrade(ctx context.Context, id string, input *TradeInput)
 				order_id
 				trade_id
 				exchange_account_id
+				created_at
+				source
+				fee_asset
+				market_type
+				is_taker
 			}
 		}
 	`
@@ -259,10 +414,20 @@ func (c *Client) UpdateTrade(ctx context.Context, id string, input *TradeInput)
 		"price":              input.Price,
 		"quantity":           input.Quantity,
 		"timestamp":          input.Timestamp.UnixMilli(),
-		"fee":                input.Fee,
-		"order_id":           input.OrderID,
+		"fee":                models.FeeOrDefault(input.Fee),
+		"order_id":           nil, // An empty OrderID clears the column
 		"trade_id":           input.TradeID,
-		"exchange_account_id": input.ExchangeAccountID.String(),
+		"exchange_account_id": uuidVar(input.ExchangeAccountID),
+	}
+	if input.OrderID != "" {
+		vars["order_id"] = input.OrderID
+	}
+
+	if err := normalizeSideField("trade", vars, models.NormalizeTradeSide); err != nil {
+		return nil, fmt.Errorf("failed to update trade: %w", err)
+	}
+	if err := encodeNumericFields("trade", vars, "price", "quantity", "fee"); err != nil {
+		return nil, fmt.Errorf("failed to update trade: %w", err)
 	}
 
 	req := c.graphqlRequestWithVars(query, vars)
@@ -328,7 +493,7 @@ func (c *Client) LatestTrade(ctx context.Context, exchangeAccountIDs []uuid.UUID
 						_in: $exchange_account_ids
 					}
 				}
-				order_by: { timestamp: desc }
+				order_by: [{ timestamp: desc }, { id: desc }]
 			) {
 				id
 				base_asset
@@ -341,6 +506,11 @@ func (c *Client) LatestTrade(ctx context.Context, exchangeAccountIDs []uuid.UUID
 				order_id
 				trade_id
 				exchange_account_id
+				created_at
+				source
+				fee_asset
+				market_type
+				is_taker
 			}
 		}
 	`
@@ -348,7 +518,7 @@ func (c *Client) LatestTrade(ctx context.Context, exchangeAccountIDs []uuid.UUID
 	// Convert UUIDs to strings for GraphQL
 	accountIDs := make([]string, len(exchangeAccountIDs))
 	for i, id := range exchangeAccountIDs {
-		accountIDs[i] = id.String()
+		accountIDs[i] = uuidVar(id)
 	}
 
 	req := c.graphqlRequestWithVars(query, map[string]interface{}{
@@ -376,3 +546,506 @@ func (c *Client) LatestTrade(ctx context.Context, exchangeAccountIDs []uuid.UUID
 
 	return result, nil
 }
+
+// GetTradedPairs retrieves the distinct base/quote asset pairs an account has traded
+// Returns pairs as [base_asset, quote_asset], ordered by base then quote asset
+// With ClientConfig.AssetResolver, renamed base assets are reported once under their current symbol
+func (c *Client) GetTradedPairs(ctx context.Context, accountID uuid.UUID) ([][2]string, error) {
+	query := `
+		query GetTradedPairs($exchange_account_id: uuid!) {
+			trades(
+				where: {
+					exchange_account_id: {
+						_eq: $exchange_account_id
+					}
+				}
+				distinct_on: [base_asset, quote_asset]
+				order_by: [{ base_asset: asc }, { quote_asset: asc }]
+			) {
+				base_asset
+				quote_asset
+			}
+		}
+	`
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"exchange_account_id": uuidVar(accountID),
+	})
+
+	var resp struct {
+		Trades []struct {
+			BaseAsset  string `json:"base_asset"`
+			QuoteAsset string `json:"quote_asset"`
+		} `json:"trades"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get traded pairs: %w", err)
+	}
+
+	pairs := make([][2]string, 0, len(resp.Trades))
+	for _, trade := range resp.Trades {
+		pairs = append(pairs, [2]string{trade.BaseAsset, trade.QuoteAsset})
+	}
+
+	resolver, exchange, err := c.accountResolver(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get traded pairs: %w", err)
+	}
+	if resolver == nil {
+		return pairs, nil
+	}
+	return canonicalPairs(resolver, exchange, pairs), nil
+}
+
+// AddTrades adds one or many trades in a batch insert, split into several requests when the batch
+// exceeds ClientConfig.MaxRequestBytes. Empty OrderIDs are stored as NULL and empty Fees as
+// models.DefaultTradeFee
+// Trades that already exist for the account (same trade_id) are ignored, so re-syncing an
+// overlapping window is safe. Returns only the newly inserted trades
+// Rows are sorted into input order: a trade comes before another when its (exchange account,
+// trade_id) appears earlier in inputs, so callers may walk inputs and the result together by
+// skipping inputs that were not inserted
+func (c *Client) AddTrades(ctx context.Context, inputs []*TradeInput) ([]*Trade, error) {
+	if len(inputs) == 0 {
+		return []*Trade{}, nil
+	}
+
+	objects, err := tradeObjects(inputs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add trades: %w", err)
+	}
+
+	query := fmt.Sprintf(addTradesMutation, "AddTrades", `returning {
+					id
+					base_asset
+					quote_asset
+					side
+					price
+					quantity
+					timestamp
+					fee
+					order_id
+					trade_id
+					exchange_account_id
+					created_at
+					source
+					fee_asset
+					market_type
+					is_taker
+				}`)
+
+	inserted, err := insertChunked(c, query, objects, func(req *request) ([]*Trade, error) {
+		var resp struct {
+			InsertTrades struct {
+				Returning []*Trade `json:"returning"`
+			} `json:"insert_trades"`
+		}
+		if err := c.execute(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+		return resp.InsertTrades.Returning, nil
+	})
+	if err != nil {
+		return inserted, fmt.Errorf("failed to add trades: %w", err)
+	}
+
+	keys := make([]string, len(inputs))
+	for i, input := range inputs {
+		keys[i] = tradeInputKey(input.ExchangeAccountID, input.TradeID)
+	}
+	orderLikeInputs(inserted, keys, func(trade *Trade) string {
+		return tradeInputKey(trade.ExchangeAccountID, trade.TradeID)
+	})
+
+	return inserted, nil
+}
+
+// AddTradesCount inserts trades like AddTrades but asks Hasura only for the number of rows
+// inserted, for large batches whose rows the caller discards
+// On a *PartialInsertError the count covers the requests that were written
+func (c *Client) AddTradesCount(ctx context.Context, inputs []*TradeInput) (int, error) {
+	if len(inputs) == 0 {
+		return 0, nil
+	}
+
+	objects, err := tradeObjects(inputs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add trades: %w", err)
+	}
+
+	inserted, err := insertCount(ctx, c, fmt.Sprintf(addTradesMutation, "AddTradesCount", "affected_rows"), "trades", objects)
+	if err != nil {
+		return inserted, fmt.Errorf("failed to add trades: %w", err)
+	}
+	return inserted, nil
+}
+
+// addTradesMutation is the batch trade insert behind AddTrades and AddTradesCount, formatted with
+// the operation name and the selection
+const addTradesMutation = `
+		mutation %s($objects: [trades_insert_input!]!) {
+			insert_trades(
+				objects: $objects
+				on_conflict: { constraint: trades_exchange_account_id_trade_id_key, update_columns: [] }
+			) {
+				%s
+			}
+		}
+	`
+
+// tradeObjects converts trade inputs to insert objects
+func tradeObjects(inputs []*TradeInput) ([]map[string]interface{}, error) {
+	objects := make([]map[string]interface{}, len(inputs))
+	for i, input := range inputs {
+		objects[i] = map[string]interface{}{
+			"exchange_account_id": uuidVar(input.ExchangeAccountID),
+			"base_asset":          input.BaseAsset,
+			"quote_asset":         input.QuoteAsset,
+			"side":                input.Side,
+			"price":               input.Price,
+			"quantity":            input.Quantity,
+			"timestamp":           input.Timestamp.UnixMilli(),
+			"fee":                 models.FeeOrDefault(input.Fee),
+			"trade_id":            input.TradeID,
+		}
+		if input.OrderID != "" {
+			objects[i]["order_id"] = input.OrderID
+		}
+		if input.Source != "" {
+			objects[i]["source"] = input.Source
+		}
+		if input.FeeAsset != "" {
+			objects[i]["fee_asset"] = input.FeeAsset
+		}
+		if input.MarketType != "" {
+			objects[i]["market_type"] = input.MarketType
+		}
+		if input.IsTaker != nil {
+			objects[i]["is_taker"] = *input.IsTaker
+		}
+		if err := normalizeSideField("trade", objects[i], models.NormalizeTradeSide); err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+		if err := encodeNumericFields("trade", objects[i], "price", "quantity", "fee"); err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+	}
+	return objects, nil
+}
+
+// tradeInputKey identifies a trade by its unique (exchange_account_id, trade_id) pair
+func tradeInputKey(accountID uuid.UUID, tradeID string) string {
+	return accountID.String() + "/" + tradeID
+}
+
+// AddTradesResult reports the outcome of AddTradesIdempotent
+// Every input trade_id appears in exactly one of InsertedTradeIDs or ExistingTradeIDs
+type AddTradesResult struct {
+	Inserted         []*Trade // Rows written by this call
+	InsertedTradeIDs []string // Exchange trade IDs written by this call
+	ExistingTradeIDs []string // Exchange trade IDs that were already stored (or repeated in the batch)
+}
+
+// AddTradesIdempotent inserts trades like AddTrades and reports which trade_ids were newly inserted
+// versus already present. The unique (exchange_account_id, trade_id) constraint makes the insert
+// ignore rows that exist, so a batch retried after an ambiguous failure (e.g. a timeout where the
+// write may have landed) is a no-op for rows already written
+func (c *Client) AddTradesIdempotent(ctx context.Context, inputs []*TradeInput) (*AddTradesResult, error) {
+	inserted, err := c.AddTrades(ctx, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	type tradeKey struct {
+		accountID uuid.UUID
+		tradeID   string
+	}
+	written := make(map[tradeKey]bool, len(inserted))
+	for _, trade := range inserted {
+		written[tradeKey{trade.ExchangeAccountID, trade.TradeID}] = true
+	}
+
+	result := &AddTradesResult{
+		Inserted:         inserted,
+		InsertedTradeIDs: []string{},
+		ExistingTradeIDs: []string{},
+	}
+	for _, input := range inputs {
+		key := tradeKey{input.ExchangeAccountID, input.TradeID}
+		if written[key] {
+			result.InsertedTradeIDs = append(result.InsertedTradeIDs, input.TradeID)
+			delete(written, key) // A repeat later in the batch was ignored by the insert
+			continue
+		}
+		result.ExistingTradeIDs = append(result.ExistingTradeIDs, input.TradeID)
+	}
+
+	return result, nil
+}
+
+// ExistingTradeIDs reports which of the given exchange trade IDs are already stored for an account
+// Returns a set of the trade IDs that exist; IDs not in the set are new
+func (c *Client) ExistingTradeIDs(ctx context.Context, exchangeAccountID uuid.UUID, tradeIDs []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(tradeIDs) == 0 {
+		return existing, nil
+	}
+
+	query := `
+		query ExistingTradeIDs($exchange_account_id: uuid!, $trade_ids: [String!]!) {
+			trades(
+				where: {
+					exchange_account_id: { _eq: $exchange_account_id }
+					trade_id: { _in: $trade_ids }
+				}
+			) {
+				trade_id
+			}
+		}
+	`
+
+	req := c.graphqlRequestWithVars(query, map[string]interface{}{
+		"exchange_account_id": uuidVar(exchangeAccountID),
+		"trade_ids":           tradeIDs,
+	})
+
+	var resp struct {
+		Trades []struct {
+			TradeID string `json:"trade_id"`
+		} `json:"trades"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get existing trade IDs: %w", err)
+	}
+
+	for _, trade := range resp.Trades {
+		existing[trade.TradeID] = true
+	}
+
+	return existing, nil
+}
+
+// unallocatedTradesPageSize is the page size FindUnallocatedTrades uses internally
+var unallocatedTradesPageSize = 1000
+
+// FindUnallocatedTrades retrieves trades that are not linked to any position
+// pair (optional) restricts to one market and window restricts by trade timestamp
+// With ClientConfig.AssetResolver, trades stored under a former symbol of pair.Base match too,
+// and every returned trade carries the current symbol as its BaseAsset, so grouping by it keeps a
+// position together across a rename
+// Results are ordered oldest first and fetched in pages internally
+func (c *Client) FindUnallocatedTrades(
+	ctx context.Context,
+	exchangeAccountID uuid.UUID,
+	pair *AssetPair,
+	window TimeRange,
+) ([]*Trade, error) {
+	resolver, exchange, err := c.accountResolver(ctx, exchangeAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find unallocated trades: %w", err)
+	}
+	bases := baseSymbols(resolver, exchange, pair)
+	trades := make([]*Trade, 0)
+
+	for offset := 0; ; offset += unallocatedTradesPageSize {
+		b := buildUnallocatedTradesWhere(exchangeAccountID, pair, bases, window)
+		pagination := paginationArgs(b, unallocatedTradesPageSize, offset)
+
+		query := fmt.Sprintf(`
+			query FindUnallocatedTrades%s {
+				trades(
+					%s
+					order_by: [{ timestamp: asc }, { id: asc }]
+					%s
+				) {
+					id
+					base_asset
+					quote_asset
+					side
+					price
+					quantity
+					timestamp
+					fee
+					order_id
+					trade_id
+					exchange_account_id
+					created_at
+					source
+					fee_asset
+					market_type
+					is_taker
+				}
+			}
+		`, b.declarations(), b.whereArg(), pagination)
+
+		req := c.graphqlRequestWithVars(query, b.variables())
+
+		var resp struct {
+			Trades []*Trade `json:"trades"`
+		}
+
+		if err := c.execute(ctx, req, &resp); err != nil {
+			return nil, fmt.Errorf("failed to find unallocated trades: %w", err)
+		}
+
+		for _, trade := range resp.Trades {
+			trade.BaseAsset = resolver.Canonical(exchange, trade.BaseAsset)
+		}
+		trades = append(trades, resp.Trades...)
+		if len(resp.Trades) < unallocatedTradesPageSize {
+			return trades, nil
+		}
+	}
+}
+
+// CountUnallocatedTrades counts trades that are not linked to any position
+// Cheap counterpart to FindUnallocatedTrades for monitoring
+func (c *Client) CountUnallocatedTrades(
+	ctx context.Context,
+	exchangeAccountID uuid.UUID,
+	pair *AssetPair,
+	window TimeRange,
+) (int, error) {
+	resolver, exchange, err := c.accountResolver(ctx, exchangeAccountID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unallocated trades: %w", err)
+	}
+	b := buildUnallocatedTradesWhere(exchangeAccountID, pair, baseSymbols(resolver, exchange, pair), window)
+
+	query := fmt.Sprintf(`
+		query CountUnallocatedTrades%s {%s
+		}
+	`, b.declarations(), aggregateSelection("trades_aggregate", b))
+
+	req := c.graphqlRequestWithVars(query, b.variables())
+
+	var resp struct {
+		TradesAggregate aggregateCount `json:"trades_aggregate"`
+	}
+
+	if err := c.execute(ctx, req, &resp); err != nil {
+		return 0, fmt.Errorf("failed to count unallocated trades: %w", err)
+	}
+
+	if resp.TradesAggregate.Aggregate == nil {
+		return 0, nil
+	}
+	return resp.TradesAggregate.Aggregate.Count, nil
+}
+
+// buildUnallocatedTradesWhere builds the where clause shared by the unallocated trade queries
+// bases lists the symbols pair.Base is stored under when it has been renamed (see baseSymbols)
+func buildUnallocatedTradesWhere(exchangeAccountID uuid.UUID, pair *AssetPair, bases []string, window TimeRange) *whereBuilder {
+	b := newWhereBuilder()
+	b.add("exchange_account_id", "_eq", "exchange_account_id", "uuid!", uuidVar(exchangeAccountID))
+
+	if pair != nil {
+		if len(bases) > 1 {
+			b.add("base_asset", "_in", "base_assets", "[String!]!", bases)
+		} else {
+			b.add("base_asset", "_eq", "base_asset", "String!", pair.Base)
+		}
+		b.add("quote_asset", "_eq", "quote_asset", "String!", pair.Quote)
+	}
+	if !window.Start.IsZero() {
+		b.add("timestamp", "_gte", "start", "bigint!", window.Start.UnixMilli())
+	}
+	if !window.End.IsZero() {
+		b.add("timestamp", "_lt", "end", "bigint!", window.End.UnixMilli())
+	}
+
+	b.addRaw("_not", "position_trades: {}")
+
+	return b
+}
+
+// tradesByIDsChunkSize caps how many IDs GetTradesByIDs sends per request
+var tradesByIDsChunkSize = 1000
+
+// GetTradesByIDs retrieves trades by primary key, keyed by ID, for hydrating position allocations
+// IDs are deduplicated and queried tradesByIDsChunkSize at a time. IDs without a trade are left
+// out of the map; use GetTradesByIDsStrict to have them reported
+func (c *Client) GetTradesByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*Trade, error) {
+	result := make(map[uuid.UUID]*Trade, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, uuidVar(id))
+		}
+	}
+
+	query := `
+		query GetTradesByIDs($ids: [uuid!]!) {
+			trades(where: { id: { _in: $ids } }) {
+				id
+				base_asset
+				quote_asset
+				side
+				price
+				quantity
+				timestamp
+				fee
+				order_id
+				trade_id
+				exchange_account_id
+				created_at
+				source
+				fee_asset
+				market_type
+				is_taker
+			}
+		}
+	`
+
+	for start := 0; start < len(unique); start += tradesByIDsChunkSize {
+		chunk := unique[start:min(start+tradesByIDsChunkSize, len(unique))]
+		req := c.graphqlRequestWithVars(query, map[string]interface{}{
+			"ids": chunk,
+		})
+
+		var resp struct {
+			Trades []*Trade `json:"trades"`
+		}
+
+		if err := c.execute(ctx, req, &resp); err != nil {
+			return nil, fmt.Errorf("failed to get trades by IDs: %w", err)
+		}
+
+		for _, trade := range resp.Trades {
+			result[trade.ID] = trade
+		}
+	}
+
+	return result, nil
+}
+
+// GetTradesByIDsStrict is GetTradesByIDs that also fails when any ID has no trade
+// The error is then a *MissingIDsError listing those IDs in input order, and the trades that
+// were found are still returned
+func (c *Client) GetTradesByIDsStrict(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*Trade, error) {
+	trades, err := c.GetTradesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := &MissingIDsError{Resource: "trade"}
+	reported := make(map[uuid.UUID]bool)
+	for _, id := range ids {
+		if trades[id] == nil && !reported[id] {
+			reported[id] = true
+			missing.IDs = append(missing.IDs, id)
+		}
+	}
+	if len(missing.IDs) > 0 {
+		return trades, missing
+	}
+	return trades, nil
+}
@@ -3,6 +3,9 @@ package db
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -198,6 +201,71 @@ func TestClient_ListTrades_WithFilter(t *testing.T) {
 	}
 }
 
+func TestBuildTradeWhere_Exclusions(t *testing.T) {
+	include := uuid.New()
+	excludeA := uuid.New()
+	excludeB := uuid.New()
+
+	tests := []struct {
+		name      string
+		filter    models.TradeFilter
+		wantWhere string
+		wantDecls string
+	}{
+		{
+			name:      "single excluded account uses _neq",
+			filter:    models.TradeFilter{ExcludeExchangeAccountIDs: []uuid.UUID{excludeA}},
+			wantWhere: "{ exchange_account_id: { _neq: $exclude_exchange_account_id } }",
+			wantDecls: "($exclude_exchange_account_id: uuid!)",
+		},
+		{
+			name:      "multiple excluded accounts use _nin",
+			filter:    models.TradeFilter{ExcludeExchangeAccountIDs: []uuid.UUID{excludeA, excludeB}},
+			wantWhere: "{ exchange_account_id: { _nin: $exclude_exchange_account_ids } }",
+			wantDecls: "($exclude_exchange_account_ids: [uuid!]!)",
+		},
+		{
+			name: "exclusions merge with inclusive account filter",
+			filter: models.TradeFilter{
+				ExchangeAccountIDs:        []uuid.UUID{include},
+				ExcludeExchangeAccountIDs: []uuid.UUID{excludeA},
+			},
+			wantWhere: "{ exchange_account_id: { _in: $exchange_account_ids, _neq: $exclude_exchange_account_id } }",
+			wantDecls: "($exchange_account_ids: [uuid!]!, $exclude_exchange_account_id: uuid!)",
+		},
+		{
+			name:      "single excluded asset uses _neq",
+			filter:    models.TradeFilter{ExcludeAssets: []string{"BTC"}},
+			wantWhere: "{ base_asset: { _neq: $exclude_asset } }",
+			wantDecls: "($exclude_asset: String!)",
+		},
+		{
+			name:      "multiple excluded assets use _nin",
+			filter:    models.TradeFilter{ExcludeAssets: []string{"BTC", "ETH"}},
+			wantWhere: "{ base_asset: { _nin: $exclude_assets } }",
+			wantDecls: "($exclude_assets: [String!]!)",
+		},
+		{
+			name:      "user scope filters through the account relationship",
+			filter:    models.TradeFilter{UserID: strPtr("770e8400-e29b-41d4-a716-446655440000")},
+			wantWhere: "{ exchange_account: { user_id: { _eq: $user_id } } }",
+			wantDecls: "($user_id: uuid!)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := buildTradeWhere(tt.filter)
+			if got := b.where(); got != tt.wantWhere {
+				t.Errorf("where = %s, want %s", got, tt.wantWhere)
+			}
+			if got := b.declarations(); got != tt.wantDecls {
+				t.Errorf("declarations = %s, want %s", got, tt.wantDecls)
+			}
+		})
+	}
+}
+
 func TestClient_CreateTrade(t *testing.T) {
 	ctx := context.Background()
 	accountID := uuid.New()
@@ -450,3 +518,799 @@ func TestClient_LatestTrade_EmptyInput(t *testing.T) {
 		t.Errorf("Expected empty map, got %d entries", len(latestTrades))
 	}
 }
+
+func TestClient_GetTradedPairs(t *testing.T) {
+	ctx := context.Background()
+	accountID := uuid.New()
+
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			respData := map[string]interface{}{
+				"trades": []map[string]interface{}{
+					{"base_asset": "BTC", "quote_asset": "USDC"},
+					{"base_asset": "ETH", "quote_asset": "USDC"},
+					{"base_asset": "HYPE", "quote_asset": "USDC"},
+				},
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	pairs, err := client.GetTradedPairs(ctx, accountID)
+	if err != nil {
+		t.Fatalf("GetTradedPairs failed: %v", err)
+	}
+
+	expected := [][2]string{{"BTC", "USDC"}, {"ETH", "USDC"}, {"HYPE", "USDC"}}
+	if len(pairs) != len(expected) {
+		t.Fatalf("Expected %d pairs, got %d", len(expected), len(pairs))
+	}
+	for i, pair := range expected {
+		if pairs[i] != pair {
+			t.Errorf("Pair %d: expected %v, got %v", i, pair, pairs[i])
+		}
+	}
+}
+
+func TestClient_GetTradedPairs_NoTrades(t *testing.T) {
+	ctx := context.Background()
+
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			respData := map[string]interface{}{
+				"trades": []interface{}{},
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	pairs, err := client.GetTradedPairs(ctx, uuid.New())
+	if err != nil {
+		t.Fatalf("GetTradedPairs failed: %v", err)
+	}
+
+	if pairs == nil || len(pairs) != 0 {
+		t.Errorf("Expected empty non-nil slice, got %v", pairs)
+	}
+}
+
+func TestClient_AddTrades_IgnoresExisting(t *testing.T) {
+	ctx := context.Background()
+	accountID := uuid.New()
+
+	var query string
+	var objects []map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			objects = requestFromContext(ctx).vars["objects"].([]map[string]interface{})
+			respData := map[string]interface{}{
+				"insert_trades": map[string]interface{}{
+					"returning": []*models.Trade{{ID: uuid.New(), TradeID: "trade-1", ExchangeAccountID: accountID}},
+				},
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	inputs := []*TradeInput{
+		{TradeID: "trade-1", ExchangeAccountID: accountID, Side: "buy", Price: "100", Quantity: "1", Fee: "0", Timestamp: time.Now(), FeeAsset: "USDC"},
+		{TradeID: "trade-2", ExchangeAccountID: accountID, Side: "buy", Price: "100", Quantity: "1", Fee: "0", Timestamp: time.Now()},
+	}
+
+	trades, err := client.AddTrades(ctx, inputs)
+	if err != nil {
+		t.Fatalf("AddTrades failed: %v", err)
+	}
+
+	if !strings.Contains(query, "on_conflict: { constraint: trades_exchange_account_id_trade_id_key, update_columns: [] }") {
+		t.Errorf("Expected conflicting trades to be ignored, got: %s", query)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("Expected 2 objects, got %d", len(objects))
+	}
+	if objects[0]["fee_asset"] != "USDC" {
+		t.Errorf("Expected fee_asset to be sent when set, got %v", objects[0]["fee_asset"])
+	}
+	if _, ok := objects[1]["fee_asset"]; ok {
+		t.Error("Expected fee_asset to be omitted when empty")
+	}
+	if len(trades) != 1 {
+		t.Errorf("Expected only the newly inserted trade, got %d", len(trades))
+	}
+}
+
+func TestClient_AddTradesIdempotent_RetriedBatch(t *testing.T) {
+	ctx := context.Background()
+	accountID := uuid.New()
+
+	// stored emulates the trades table's unique (exchange_account_id, trade_id) constraint
+	stored := map[string]bool{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			var returning []*models.Trade
+			for _, object := range requestFromContext(ctx).vars["objects"].([]map[string]interface{}) {
+				tradeID := object["trade_id"].(string)
+				if stored[tradeID] {
+					continue
+				}
+				stored[tradeID] = true
+				returning = append(returning, &models.Trade{ID: uuid.New(), TradeID: tradeID, ExchangeAccountID: accountID})
+			}
+			respData := map[string]interface{}{
+				"insert_trades": map[string]interface{}{"returning": returning},
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	batch := make([]*TradeInput, 4)
+	for i := range batch {
+		batch[i] = &TradeInput{TradeID: fmt.Sprintf("trade-%d", i+1), ExchangeAccountID: accountID, Side: "buy", Price: "100", Quantity: "1", Fee: "0", Timestamp: time.Now()}
+	}
+
+	// The first half landed before the original attempt timed out
+	if _, err := client.AddTradesIdempotent(ctx, batch[:2]); err != nil {
+		t.Fatalf("AddTradesIdempotent failed: %v", err)
+	}
+
+	result, err := client.AddTradesIdempotent(ctx, batch)
+	if err != nil {
+		t.Fatalf("AddTradesIdempotent retry failed: %v", err)
+	}
+	if got := strings.Join(result.InsertedTradeIDs, ","); got != "trade-3,trade-4" {
+		t.Errorf("Expected trade-3,trade-4 inserted, got %s", got)
+	}
+	if got := strings.Join(result.ExistingTradeIDs, ","); got != "trade-1,trade-2" {
+		t.Errorf("Expected trade-1,trade-2 already present, got %s", got)
+	}
+	if len(result.Inserted) != 2 {
+		t.Errorf("Expected 2 inserted rows, got %d", len(result.Inserted))
+	}
+
+	// Retrying again is a no-op
+	result, err = client.AddTradesIdempotent(ctx, batch)
+	if err != nil {
+		t.Fatalf("AddTradesIdempotent second retry failed: %v", err)
+	}
+	if len(result.InsertedTradeIDs) != 0 || len(result.ExistingTradeIDs) != 4 {
+		t.Errorf("Expected every trade to already exist, got inserted=%v existing=%v", result.InsertedTradeIDs, result.ExistingTradeIDs)
+	}
+}
+
+func TestClient_CreateTrade_FeeAssetAndMarketTypeRoundTrip(t *testing.T) {
+	var query string
+	var vars map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query, vars = requestFromContext(ctx).query, requestFromContext(ctx).vars
+			return json.Unmarshal([]byte(`{"insert_trades_one": {"id": "`+uuid.NewString()+`", "trade_id": "trade-1",
+				"fee_asset": "HYPE", "market_type": "spot"}}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	trade, err := client.CreateTrade(context.Background(), &TradeInput{
+		TradeID: "trade-1", Side: "buy", Price: "1", Quantity: "1", Timestamp: time.Now(),
+		FeeAsset: "HYPE", MarketType: models.MarketTypeSpot,
+	})
+	if err != nil {
+		t.Fatalf("CreateTrade failed: %v", err)
+	}
+	if vars["fee_asset"] != "HYPE" || vars["market_type"] != models.MarketTypeSpot {
+		t.Errorf("Expected fee_asset and market_type to be sent, got %v", vars)
+	}
+	if !strings.Contains(query, "fee_asset: $fee_asset") || !strings.Contains(query, "market_type: $market_type") {
+		t.Errorf("Expected fee_asset and market_type to be written, got: %s", query)
+	}
+	if trade.FeeAsset != "HYPE" || trade.MarketType != models.MarketTypeSpot {
+		t.Errorf("Expected fee_asset and market_type to be read back, got %+v", trade)
+	}
+}
+
+func TestClient_AddTrades_LeavesEmptySourceUnset(t *testing.T) {
+	var objects []map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			objects = requestFromContext(ctx).vars["objects"].([]map[string]interface{})
+			return nil
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	inputs := []*TradeInput{
+		{TradeID: "trade-1", Side: "buy", Price: "1", Quantity: "1", Fee: "0", Timestamp: time.Now()},
+		{TradeID: "trade-2", Side: "buy", Price: "1", Quantity: "1", Fee: "0", Timestamp: time.Now(), Source: models.SourceManualImport},
+	}
+	if _, err := client.AddTrades(context.Background(), inputs); err != nil {
+		t.Fatalf("AddTrades failed: %v", err)
+	}
+
+	if source, ok := objects[0]["source"]; ok {
+		t.Errorf("Expected an empty source to be left to the database, got %v", source)
+	}
+	if objects[1]["source"] != models.SourceManualImport {
+		t.Errorf("Expected explicit source to be sent, got %v", objects[1]["source"])
+	}
+}
+
+func TestClient_ListTrades_SourceFilter(t *testing.T) {
+	var query string
+	var vars map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			vars = requestFromContext(ctx).vars
+			return json.Unmarshal([]byte(`{"trades": [{"trade_id": "trade-1", "source": "manual_import"}]}`), resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	source := models.SourceManualImport
+	trades, err := client.ListTrades(context.Background(), TradeFilter{Source: &source})
+	if err != nil {
+		t.Fatalf("ListTrades failed: %v", err)
+	}
+
+	if !strings.Contains(query, "source: { _eq: $source }") {
+		t.Errorf("Expected source condition, got: %s", query)
+	}
+	if vars["source"] != models.SourceManualImport {
+		t.Errorf("Expected source variable %q, got %v", models.SourceManualImport, vars["source"])
+	}
+	if len(trades) != 1 || trades[0].Source != models.SourceManualImport {
+		t.Errorf("Expected the trade's source to be decoded, got %+v", trades)
+	}
+}
+
+func TestClient_ExistingTradeIDs(t *testing.T) {
+	ctx := context.Background()
+	accountID := uuid.New()
+
+	var vars map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			vars = requestFromContext(ctx).vars
+			respData := map[string]interface{}{
+				"trades": []map[string]interface{}{{"trade_id": "trade-2"}},
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	existing, err := client.ExistingTradeIDs(ctx, accountID, []string{"trade-1", "trade-2"})
+	if err != nil {
+		t.Fatalf("ExistingTradeIDs failed: %v", err)
+	}
+
+	if vars["exchange_account_id"] != accountID.String() {
+		t.Errorf("Expected account ID variable %s, got %v", accountID, vars["exchange_account_id"])
+	}
+	if existing["trade-1"] || !existing["trade-2"] {
+		t.Errorf("Expected only trade-2 to exist, got %v", existing)
+	}
+}
+
+func TestClient_FindUnallocatedTrades_Paginates(t *testing.T) {
+	ctx := context.Background()
+	accountID := uuid.New()
+
+	defer func(size int) { unallocatedTradesPageSize = size }(unallocatedTradesPageSize)
+	unallocatedTradesPageSize = 2
+
+	var queries []string
+	var offsets []interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			r := requestFromContext(ctx)
+			queries = append(queries, r.query)
+			offsets = append(offsets, r.vars["offset"])
+
+			// Two full pages followed by a partial one
+			rows := 2
+			if len(queries) == 3 {
+				rows = 1
+			}
+			trades := make([]*models.Trade, rows)
+			for i := range trades {
+				trades[i] = &models.Trade{ID: uuid.New(), ExchangeAccountID: accountID, Timestamp: time.Now()}
+			}
+			data, _ := json.Marshal(map[string]interface{}{"trades": trades})
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	pair := &AssetPair{Base: "BTC", Quote: "USDC"}
+	window := TimeRange{Start: time.UnixMilli(1000), End: time.UnixMilli(2000)}
+
+	trades, err := client.FindUnallocatedTrades(ctx, accountID, pair, window)
+	if err != nil {
+		t.Fatalf("FindUnallocatedTrades failed: %v", err)
+	}
+
+	if len(trades) != 5 {
+		t.Errorf("Expected 5 trades across pages, got %d", len(trades))
+	}
+	if len(queries) != 3 {
+		t.Fatalf("Expected 3 page queries, got %d", len(queries))
+	}
+	if offsets[0] != nil || offsets[1] != 2 || offsets[2] != 4 {
+		t.Errorf("Expected offsets [none 2 4], got %v", offsets)
+	}
+
+	query := queries[0]
+	if !strings.Contains(query, "_not: { position_trades: {} }") {
+		t.Errorf("Expected _not position_trades relationship filter, got: %s", query)
+	}
+	if !strings.Contains(query, "timestamp: { _gte: $start, _lt: $end }") {
+		t.Errorf("Expected timestamp window filter, got: %s", query)
+	}
+	if !strings.Contains(query, "base_asset: { _eq: $base_asset }") {
+		t.Errorf("Expected asset pair filter, got: %s", query)
+	}
+}
+
+func TestClient_CountUnallocatedTrades(t *testing.T) {
+	ctx := context.Background()
+
+	var query string
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			respData := map[string]interface{}{
+				"trades_aggregate": map[string]interface{}{
+					"aggregate": map[string]interface{}{"count": 3},
+				},
+			}
+			data, _ := json.Marshal(respData)
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	count, err := client.CountUnallocatedTrades(ctx, uuid.New(), nil, TimeRange{})
+	if err != nil {
+		t.Fatalf("CountUnallocatedTrades failed: %v", err)
+	}
+
+	if count != 3 {
+		t.Errorf("Expected count 3, got %d", count)
+	}
+	if !strings.Contains(query, "_not: { position_trades: {} }") {
+		t.Errorf("Expected _not position_trades relationship filter, got: %s", query)
+	}
+	if strings.Contains(query, "timestamp") || strings.Contains(query, "base_asset") {
+		t.Errorf("Expected no window or pair filters, got: %s", query)
+	}
+}
+
+func TestClient_CreateTrade_DuplicateReturnsTypedError(t *testing.T) {
+	mockClient := rawResponse(`{
+		"errors": [{
+			"message": "Uniqueness violation. duplicate key value violates unique constraint \"trades_exchange_account_id_trade_id_key\"",
+			"extensions": {"path": "$.selectionSet.insert_trades_one.args.object", "code": "constraint-violation"}
+		}]
+	}`)
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	input := &TradeInput{
+		BaseAsset:         "BTC",
+		QuoteAsset:        "USDC",
+		Side:              "buy",
+		Price:             "50000",
+		Quantity:          "0.1",
+		Fee:               "1",
+		Timestamp:         time.Now(),
+		TradeID:           "trade-456",
+		ExchangeAccountID: uuid.New(),
+	}
+	_, err := client.CreateTrade(context.Background(), input)
+
+	var dupErr *DuplicateError
+	if !errors.As(err, &dupErr) {
+		t.Fatalf("Expected *DuplicateError, got %T: %v", err, err)
+	}
+	if dupErr.Resource != "trade" || dupErr.Key != "trade-456" {
+		t.Errorf("Expected duplicate trade trade-456, got %s %s", dupErr.Resource, dupErr.Key)
+	}
+	var gqlErr *GraphQLError
+	if !errors.As(err, &gqlErr) {
+		t.Error("Expected the GraphQL error to stay reachable through DuplicateError")
+	}
+}
+
+func TestClient_CreateTrade_ForeignKeyViolationIsNotDuplicate(t *testing.T) {
+	mockClient := rawResponse(`{
+		"errors": [{
+			"message": "Foreign key violation. insert or update on table \"trades\" violates foreign key constraint \"trades_exchange_account_id_fkey\"",
+			"extensions": {"code": "constraint-violation"}
+		}]
+	}`)
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	input := &TradeInput{
+		BaseAsset: "BTC", QuoteAsset: "USDC", Side: "buy", Price: "1", Quantity: "1", Fee: "0",
+		Timestamp: time.Now(), TradeID: "trade-1", ExchangeAccountID: uuid.New(),
+	}
+	_, err := client.CreateTrade(context.Background(), input)
+
+	var dupErr *DuplicateError
+	if err == nil || errors.As(err, &dupErr) {
+		t.Fatalf("Expected a non-duplicate error, got %v", err)
+	}
+}
+
+func TestClient_GetRecentTrades_AppliesLimit(t *testing.T) {
+	var query string
+	var vars map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			query = requestFromContext(ctx).query
+			vars = requestFromContext(ctx).vars
+			return json.Unmarshal([]byte(`{"trades": [{"id": "11111111-1111-1111-1111-111111111111", "timestamp": 1700000000000}]}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+
+	trades, err := client.GetRecentTrades(context.Background(), 25)
+	if err != nil {
+		t.Fatalf("GetRecentTrades failed: %v", err)
+	}
+	if len(trades) != 1 {
+		t.Errorf("Expected 1 trade, got %d", len(trades))
+	}
+
+	for _, want := range []string{"$limit: Int!", "limit: $limit", "order_by: [{ timestamp: desc }, { id: desc }]"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("Expected query to contain %q, got:\n%s", want, query)
+		}
+	}
+	if strings.Contains(query, "where:") {
+		t.Errorf("Expected no account filter, got:\n%s", query)
+	}
+	if vars["limit"] != 25 {
+		t.Errorf("Expected limit 25, got %v", vars["limit"])
+	}
+
+	if _, err := client.GetRecentTrades(context.Background(), 0); err == nil {
+		t.Error("Expected error for non-positive limit")
+	}
+}
+
+func TestClient_AddTrades_ReturnsInputOrder(t *testing.T) {
+	accountA, accountB := uuid.New(), uuid.New()
+	inputs := []*TradeInput{
+		{ExchangeAccountID: accountA, TradeID: "t-1", Side: "buy", Price: "100", Quantity: "1", Fee: "0", Timestamp: time.Now()},
+		{ExchangeAccountID: accountB, TradeID: "t-1", Side: "sell", Price: "101", Quantity: "2", Fee: "0", Timestamp: time.Now()},
+		{ExchangeAccountID: accountA, TradeID: "t-2", Side: "buy", Price: "102", Quantity: "3", Fee: "0", Timestamp: time.Now()},
+		{ExchangeAccountID: accountA, TradeID: "t-3", Side: "sell", Price: "103", Quantity: "4", Fee: "0", Timestamp: time.Now()},
+		{ExchangeAccountID: accountB, TradeID: "t-2", Side: "buy", Price: "104", Quantity: "5", Fee: "0", Timestamp: time.Now()},
+	}
+
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			objects := requestFromContext(ctx).vars["objects"].([]map[string]interface{})
+			// Return the rows shuffled, leaving out inputs[3] as if it already existed
+			var returning []map[string]interface{}
+			for _, i := range []int{4, 0, 2, 1} {
+				returning = append(returning, map[string]interface{}{
+					"id":                  uuid.New(),
+					"exchange_account_id": objects[i]["exchange_account_id"],
+					"trade_id":            objects[i]["trade_id"],
+					"price":               objects[i]["price"],
+				})
+			}
+			data, _ := json.Marshal(map[string]interface{}{
+				"insert_trades": map[string]interface{}{"returning": returning},
+			})
+			return json.Unmarshal(data, resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	trades, err := client.AddTrades(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("AddTrades failed: %v", err)
+	}
+
+	want := []*TradeInput{inputs[0], inputs[1], inputs[2], inputs[4]}
+	if len(trades) != len(want) {
+		t.Fatalf("Expected %d trades, got %d", len(want), len(trades))
+	}
+	for i, trade := range trades {
+		if trade.ExchangeAccountID != want[i].ExchangeAccountID || trade.TradeID != want[i].TradeID {
+			t.Errorf("Expected trade %d to be %s/%s, got %s/%s", i, want[i].ExchangeAccountID, want[i].TradeID, trade.ExchangeAccountID, trade.TradeID)
+		}
+		if trade.Price != want[i].Price {
+			t.Errorf("Expected trade %d to carry price %s, got %s", i, want[i].Price, trade.Price)
+		}
+	}
+}
+
+func TestClient_AddTrades_IsTaker(t *testing.T) {
+	accountID := uuid.New()
+	taker, maker := true, false
+
+	var objects []map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			objects = requestFromContext(ctx).vars["objects"].([]map[string]interface{})
+			return json.Unmarshal([]byte(`{"insert_trades": {"returning": [
+				{"id": "`+uuid.New().String()+`", "exchange_account_id": "`+accountID.String()+`", "trade_id": "t-1", "is_taker": true},
+				{"id": "`+uuid.New().String()+`", "exchange_account_id": "`+accountID.String()+`", "trade_id": "t-2", "is_taker": false},
+				{"id": "`+uuid.New().String()+`", "exchange_account_id": "`+accountID.String()+`", "trade_id": "t-3", "is_taker": null}
+			]}}`), resp)
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	inputs := []*TradeInput{
+		{ExchangeAccountID: accountID, TradeID: "t-1", Side: "buy", Price: "100", Quantity: "1", Fee: "0.05", Timestamp: time.Now(), IsTaker: &taker},
+		{ExchangeAccountID: accountID, TradeID: "t-2", Side: "buy", Price: "100", Quantity: "1", Fee: "-0.01", Timestamp: time.Now(), IsTaker: &maker},
+		{ExchangeAccountID: accountID, TradeID: "t-3", Side: "buy", Price: "100", Quantity: "1", Fee: "0", Timestamp: time.Now()},
+	}
+
+	trades, err := client.AddTrades(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("AddTrades failed: %v", err)
+	}
+
+	if objects[0]["is_taker"] != true || objects[1]["is_taker"] != false {
+		t.Errorf("Expected is_taker to be forwarded, got %v and %v", objects[0]["is_taker"], objects[1]["is_taker"])
+	}
+	if _, ok := objects[2]["is_taker"]; ok {
+		t.Error("Expected is_taker to be omitted when unknown")
+	}
+
+	if trades[0].IsTaker == nil || !*trades[0].IsTaker {
+		t.Errorf("Expected trade t-1 to be a taker fill, got %v", trades[0].IsTaker)
+	}
+	if trades[1].IsTaker == nil || *trades[1].IsTaker {
+		t.Errorf("Expected trade t-2 to be a maker fill, got %v", trades[1].IsTaker)
+	}
+	if trades[2].IsTaker != nil {
+		t.Errorf("Expected trade t-3 to be unknown, got %v", *trades[2].IsTaker)
+	}
+}
+
+func TestClient_Trades_WithoutOrderID(t *testing.T) {
+	accountID := uuid.New()
+	input := &TradeInput{
+		ExchangeAccountID: accountID, TradeID: "csv-1", BaseAsset: "BTC", QuoteAsset: "USDC",
+		Side: "buy", Price: "100", Quantity: "1", Timestamp: time.Now(), Source: models.SourceManualImport,
+	}
+
+	var queries []string
+	var vars []map[string]interface{}
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			inflight := requestFromContext(ctx)
+			queries = append(queries, inflight.query)
+			vars = append(vars, inflight.vars)
+			row := `{"id": "` + uuid.New().String() + `", "exchange_account_id": "` + accountID.String() + `", "trade_id": "csv-1", "fee": 0, "order_id": null}`
+			switch inflight.opName {
+			case "CreateTrade":
+				return json.Unmarshal([]byte(`{"insert_trades_one": `+row+`}`), resp)
+			case "UpdateTrade":
+				return json.Unmarshal([]byte(`{"update_trades_by_pk": `+row+`}`), resp)
+			default:
+				return json.Unmarshal([]byte(`{"insert_trades": {"returning": [`+row+`]}}`), resp)
+			}
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+	ctx := context.Background()
+
+	created, err := client.CreateTrade(ctx, input)
+	if err != nil {
+		t.Fatalf("CreateTrade failed: %v", err)
+	}
+	if !strings.Contains(queries[0], "$order_id: String\n") {
+		t.Errorf("Expected $order_id to be nullable, got: %s", queries[0])
+	}
+	if _, ok := vars[0]["order_id"]; ok {
+		t.Errorf("Expected order_id to be omitted, got %v", vars[0]["order_id"])
+	}
+	if vars[0]["fee"] != "0" {
+		t.Errorf("Expected the fee to default to 0, got %v", vars[0]["fee"])
+	}
+	if created.OrderID != "" || created.Fee != "0" {
+		t.Errorf("Expected no order ID and a zero fee, got %q and %q", created.OrderID, created.Fee)
+	}
+
+	if _, err := client.UpdateTrade(ctx, created.ID.String(), input); err != nil {
+		t.Fatalf("UpdateTrade failed: %v", err)
+	}
+	if value, ok := vars[1]["order_id"]; !ok || value != nil {
+		t.Errorf("Expected UpdateTrade to clear order_id, got %v", value)
+	}
+
+	if _, err := client.AddTrades(ctx, []*TradeInput{input}); err != nil {
+		t.Fatalf("AddTrades failed: %v", err)
+	}
+	object := vars[2]["objects"].([]map[string]interface{})[0]
+	if _, ok := object["order_id"]; ok {
+		t.Errorf("Expected order_id to be omitted from the insert, got %v", object["order_id"])
+	}
+	if object["fee"] != "0" {
+		t.Errorf("Expected the fee to default to 0, got %v", object["fee"])
+	}
+}
+
+// tradesByIDMock answers GetTradesByIDs with the requested IDs that are in stored, recording
+// the size of each request
+func tradesByIDMock(stored map[uuid.UUID]bool, sizes *[]int) *mockGraphQLClient {
+	return &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			ids := requestFromContext(ctx).vars["ids"].([]string)
+			*sizes = append(*sizes, len(ids))
+			var rows []map[string]interface{}
+			for _, id := range ids {
+				if stored[uuid.MustParse(id)] {
+					rows = append(rows, map[string]interface{}{"id": id, "trade_id": "t-" + id, "price": "1"})
+				}
+			}
+			data, _ := json.Marshal(map[string]interface{}{"trades": rows})
+			return json.Unmarshal(data, resp)
+		},
+	}
+}
+
+func TestClient_GetTradesByIDs_Chunks(t *testing.T) {
+	original := tradesByIDsChunkSize
+	tradesByIDsChunkSize = 2
+	defer func() { tradesByIDsChunkSize = original }()
+
+	stored := make(map[uuid.UUID]bool)
+	ids := make([]uuid.UUID, 5)
+	for i := range ids {
+		ids[i] = uuid.New()
+		stored[ids[i]] = true
+	}
+
+	tests := []struct {
+		name  string
+		ids   []uuid.UUID
+		sizes []int
+	}{
+		{"one under the chunk size", ids[:1], []int{1}},
+		{"exactly the chunk size", ids[:2], []int{2}},
+		{"a multiple of the chunk size", ids[:4], []int{2, 2}},
+		{"one over a multiple", ids, []int{2, 2, 1}},
+		{"duplicates counted once", append(ids[:2:2], ids[0], ids[1], ids[2]), []int{2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sizes []int
+			client := NewClientWithGraphQL(tradesByIDMock(stored, &sizes), ClientConfig{})
+
+			trades, err := client.GetTradesByIDs(context.Background(), tt.ids)
+			if err != nil {
+				t.Fatalf("GetTradesByIDs failed: %v", err)
+			}
+			if fmt.Sprint(sizes) != fmt.Sprint(tt.sizes) {
+				t.Errorf("Expected requests of %v IDs, got %v", tt.sizes, sizes)
+			}
+			for _, id := range tt.ids {
+				if trades[id] == nil || trades[id].TradeID != "t-"+id.String() {
+					t.Errorf("Expected trade %s in the result, got %+v", id, trades[id])
+				}
+			}
+		})
+	}
+}
+
+func TestClient_GetTradesByIDs_Missing(t *testing.T) {
+	found, gone := uuid.New(), uuid.New()
+	var sizes []int
+	client := NewClientWithGraphQL(tradesByIDMock(map[uuid.UUID]bool{found: true}, &sizes), ClientConfig{})
+	ctx := context.Background()
+
+	trades, err := client.GetTradesByIDs(ctx, []uuid.UUID{gone, found})
+	if err != nil {
+		t.Fatalf("Expected missing IDs to be ignored, got %v", err)
+	}
+	if len(trades) != 1 || trades[found] == nil {
+		t.Errorf("Expected only the stored trade, got %v", trades)
+	}
+
+	trades, err = client.GetTradesByIDsStrict(ctx, []uuid.UUID{gone, found, gone})
+	var missing *MissingIDsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected a MissingIDsError, got %v", err)
+	}
+	if len(missing.IDs) != 1 || missing.IDs[0] != gone || !strings.Contains(err.Error(), gone.String()) {
+		t.Errorf("Expected %s to be reported once, got %v", gone, err)
+	}
+	if trades[found] == nil {
+		t.Error("Expected the stored trade to be returned alongside the error")
+	}
+
+	if _, err := client.GetTradesByIDsStrict(ctx, []uuid.UUID{found}); err != nil {
+		t.Errorf("Expected no error when every trade exists, got %v", err)
+	}
+}
+
+func TestClient_GetTradesByIDs_Empty(t *testing.T) {
+	var sizes []int
+	client := NewClientWithGraphQL(tradesByIDMock(nil, &sizes), ClientConfig{})
+
+	for _, strict := range []bool{false, true} {
+		get := client.GetTradesByIDs
+		if strict {
+			get = client.GetTradesByIDsStrict
+		}
+		trades, err := get(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("Expected no error for empty input, got %v", err)
+		}
+		if trades == nil || len(trades) != 0 {
+			t.Errorf("Expected an empty non-nil map, got %v", trades)
+		}
+	}
+	if len(sizes) != 0 {
+		t.Errorf("Expected no request for empty input, got %d", len(sizes))
+	}
+}
@@ -0,0 +1,18 @@
+package db
+
+import "github.com/zif-terminal/lib/models"
+
+// normalizeSideField normalizes vars["side"] in place with normalize
+// An unrecognized side is reported as a *models.ValidationError for resource
+func normalizeSideField(resource string, vars map[string]interface{}, normalize func(string) (string, error)) error {
+	side, _ := vars["side"].(string)
+	normalized, err := normalize(side)
+	if err != nil {
+		return &models.ValidationError{
+			Resource: resource,
+			Fields:   []models.FieldError{{Field: "side", Message: err.Error()}},
+		}
+	}
+	vars["side"] = normalized
+	return nil
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/models"
+)
+
+func TestClient_CreateMethods_NormalizeSide(t *testing.T) {
+	ctx := context.Background()
+
+	var vars map[string]interface{}
+	calls := 0
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			calls++
+			vars = requestFromContext(ctx).vars
+			return nil
+		},
+	}
+
+	client := NewClientWithGraphQL(mockClient, ClientConfig{
+		URL:         "http://localhost:8080/v1/graphql",
+		AdminSecret: "test-secret",
+	})
+
+	trade := &TradeInput{Side: "BUY", Price: "1", Quantity: "1", Fee: "0", Timestamp: time.Now()}
+	_, _ = client.CreateTrade(ctx, trade)
+	if vars["side"] != "buy" {
+		t.Errorf("Expected trade side buy, got %v", vars["side"])
+	}
+
+	_, _ = client.UpdateTrade(ctx, uuid.New().String(), &TradeInput{Side: "s", Price: "1", Quantity: "1", Fee: "0", Timestamp: time.Now()})
+	if vars["side"] != "sell" {
+		t.Errorf("Expected trade side sell, got %v", vars["side"])
+	}
+
+	_, _ = client.CreatePosition(ctx, &PositionInput{
+		Side:          "SHORT",
+		EntryAvgPrice: "1",
+		ExitAvgPrice:  "1",
+		TotalQuantity: "1",
+		TotalFees:     "0",
+		RealizedPnL:   "0",
+	})
+	if vars["side"] != "short" {
+		t.Errorf("Expected position side short, got %v", vars["side"])
+	}
+
+	before := calls
+	trade.Side = "long"
+	_, err := client.CreateTrade(ctx, trade)
+	var verr *models.ValidationError
+	if !errors.As(err, &verr) || verr.Fields[0].Field != "side" {
+		t.Errorf("Expected side validation error for trade, got %v", err)
+	}
+	_, err = client.AddTrades(ctx, []*TradeInput{trade})
+	if !errors.As(err, &verr) || verr.Fields[0].Field != "side" {
+		t.Errorf("Expected side validation error for AddTrades, got %v", err)
+	}
+	_, err = client.CreatePosition(ctx, &PositionInput{Side: "buy"})
+	if !errors.As(err, &verr) || verr.Fields[0].Field != "side" {
+		t.Errorf("Expected side validation error for position, got %v", err)
+	}
+	if calls != before {
+		t.Errorf("Expected rejected inputs not to be sent, got %d extra calls", calls-before)
+	}
+}
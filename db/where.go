@@ -0,0 +1,218 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// whereFields is the allowlist of field paths where builders may filter on
+// Field names, operators and variable declarations are interpolated into the query text while
+// values always travel as variables, so only names vetted here can ever reach a query
+var whereFields = map[string]bool{
+	"id":                       true,
+	"exchange_account_id":      true,
+	"exchange_account.user_id": true,
+	"exchange":                 true,
+	"exchange.name":            true,
+	"account_type":             true,
+	"user_id":                  true,
+	"base_asset":               true,
+	"quote_asset":              true,
+	"side":                     true,
+	"source":                   true,
+	"status":                   true,
+	"timestamp":                true,
+	"start_time":               true,
+	"end_time":                 true,
+	"realized_pnl":             true,
+	"_not":                     true,
+}
+
+// whereOperators is the allowlist of comparison operators add accepts
+var whereOperators = map[string]bool{
+	"_eq": true, "_neq": true, "_in": true, "_nin": true,
+	"_gt": true, "_gte": true, "_lt": true, "_lte": true,
+}
+
+var (
+	// graphqlName matches a GraphQL name, as used for columns and variables
+	graphqlName = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+	// graphqlVarType matches a named, list or non-null variable type such as "[uuid!]!"
+	graphqlVarType = regexp.MustCompile(`^(\[[_A-Za-z][_0-9A-Za-z]*!?\]|[_A-Za-z][_0-9A-Za-z]*)!?$`)
+)
+
+// whereBuilder assembles a Hasura where clause together with the matching
+// variable declarations and variables. Conditions on the same field are merged
+// (e.g. start_time: { _gte: $a, _lte: $b }) and dotted paths render as nested
+// relationship filters (e.g. "exchange_account.user_id").
+type whereBuilder struct {
+	decls   []string
+	vars    map[string]interface{}
+	root    *whereNode
+	and     []string        // Conditions rendered as _and entries (see addAnd and addOrNull)
+	columns map[string]bool // Configured columns allowed on top of whereFields (see allowColumn)
+}
+
+// whereNode is a single field in the where tree: either a set of operators or nested fields
+type whereNode struct {
+	order    []string
+	children map[string]*whereNode
+	ops      []string
+}
+
+func newWhereNode() *whereNode {
+	return &whereNode{children: make(map[string]*whereNode)}
+}
+
+// newWhereBuilder creates an empty where builder
+func newWhereBuilder() *whereBuilder {
+	return &whereBuilder{
+		vars: make(map[string]interface{}),
+		root: newWhereNode(),
+	}
+}
+
+// add adds the condition `field: { op: $varName }` and declares $varName with varType
+// Panics if field or op is not allowlisted, since callers pass them as constants
+func (b *whereBuilder) add(field, op, varName, varType string, value interface{}) {
+	b.mustAllowField(field)
+	if !whereOperators[op] {
+		panic(fmt.Sprintf("where builder: operator %q is not allowlisted", op))
+	}
+	b.declare(varName, varType, value)
+	b.node(field).ops = append(b.node(field).ops, fmt.Sprintf("%s: $%s", op, varName))
+}
+
+// addAnd adds `field: { op: $varName }` as its own _and entry, so it never merges or collides with
+// other conditions on field (e.g. a second _gte)
+// Panics if field or op is not allowlisted, as add does
+func (b *whereBuilder) addAnd(field, op, varName, varType string, value interface{}) {
+	b.mustAllowField(field)
+	if !whereOperators[op] {
+		panic(fmt.Sprintf("where builder: operator %q is not allowlisted", op))
+	}
+	b.declare(varName, varType, value)
+	b.and = append(b.and, fieldCondition(field, fmt.Sprintf("{ %s: $%s }", op, varName)))
+}
+
+// addOrNull adds `_or: [{ field: { op: $varName } }, { field: { _is_null: true } }]` as its own
+// _and entry, matching rows where field satisfies op or is NULL (e.g. open positions' end_time)
+// Panics if field or op is not allowlisted, as add does
+func (b *whereBuilder) addOrNull(field, op, varName, varType string, value interface{}) {
+	b.mustAllowField(field)
+	if !whereOperators[op] {
+		panic(fmt.Sprintf("where builder: operator %q is not allowlisted", op))
+	}
+	b.declare(varName, varType, value)
+	b.and = append(b.and, fmt.Sprintf("{ _or: [%s, %s] }",
+		fieldCondition(field, fmt.Sprintf("{ %s: $%s }", op, varName)),
+		fieldCondition(field, "{ _is_null: true }")))
+}
+
+// fieldCondition wraps body in the object path of a dotted field, e.g. "{ a: { b: body } }"
+func fieldCondition(field, body string) string {
+	parts := strings.Split(field, ".")
+	for i := len(parts) - 1; i >= 0; i-- {
+		body = "{ " + parts[i] + ": " + body + " }"
+	}
+	return body
+}
+
+// addRaw adds a literal condition under field (e.g. "_not" with "position_trades: {}")
+// literal is interpolated as is, so it must be a constant and never carry caller input
+func (b *whereBuilder) addRaw(field, literal string) {
+	b.mustAllowField(field)
+	b.node(field).ops = append(b.node(field).ops, literal)
+}
+
+// declare declares a variable without adding a condition (e.g. for limit/offset)
+// Panics if varName or varType is not a valid GraphQL name or type
+func (b *whereBuilder) declare(varName, varType string, value interface{}) {
+	if !graphqlName.MatchString(varName) || !graphqlVarType.MatchString(varType) {
+		panic(fmt.Sprintf("where builder: invalid variable declaration $%s: %s", varName, varType))
+	}
+	b.decls = append(b.decls, fmt.Sprintf("$%s: %s", varName, varType))
+	b.vars[varName] = value
+}
+
+// allowColumn allows filtering on a column named by configuration rather than code
+// (e.g. ClientConfig.AccountEnabledColumn), which must be a plain GraphQL name
+func (b *whereBuilder) allowColumn(column string) error {
+	if !graphqlName.MatchString(column) {
+		return fmt.Errorf("invalid column name %q", column)
+	}
+	if b.columns == nil {
+		b.columns = make(map[string]bool)
+	}
+	b.columns[column] = true
+	return nil
+}
+
+// mustAllowField panics unless field is allowlisted in whereFields or by allowColumn
+func (b *whereBuilder) mustAllowField(field string) {
+	if !whereFields[field] && !b.columns[field] {
+		panic(fmt.Sprintf("where builder: field %q is not allowlisted", field))
+	}
+}
+
+// node returns the tree node for a dotted field path, creating it if needed
+func (b *whereBuilder) node(field string) *whereNode {
+	n := b.root
+	for _, part := range strings.Split(field, ".") {
+		child, ok := n.children[part]
+		if !ok {
+			child = newWhereNode()
+			n.children[part] = child
+			n.order = append(n.order, part)
+		}
+		n = child
+	}
+	return n
+}
+
+// empty reports whether no conditions have been added
+func (b *whereBuilder) empty() bool {
+	return len(b.root.order) == 0 && len(b.and) == 0
+}
+
+// declarations returns "($a: T!, $b: U!)" or "" if no variables were declared
+func (b *whereBuilder) declarations() string {
+	if len(b.decls) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(b.decls, ", ") + ")"
+}
+
+// where returns the body of the where argument, e.g. "{ side: { _eq: $side } }"
+func (b *whereBuilder) where() string {
+	if len(b.and) == 0 {
+		return b.root.render()
+	}
+	root := *b.root
+	root.ops = append(append([]string(nil), root.ops...), "_and: ["+strings.Join(b.and, ", ")+"]")
+	return root.render()
+}
+
+// whereArg returns "where: {...}" or "" when there are no conditions
+func (b *whereBuilder) whereArg() string {
+	if b.empty() {
+		return ""
+	}
+	return "where: " + b.where()
+}
+
+// render renders the node as a GraphQL input object
+func (n *whereNode) render() string {
+	parts := make([]string, 0, len(n.ops)+len(n.order))
+	parts = append(parts, n.ops...)
+	for _, key := range n.order {
+		parts = append(parts, key+": "+n.children[key].render())
+	}
+	return "{ " + strings.Join(parts, ", ") + " }"
+}
+
+// variables returns the collected variables
+func (b *whereBuilder) variables() map[string]interface{} {
+	return b.vars
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/machinebox/graphql"
+)
+
+// injection is a filter value that would close the where clause and select extra fields if interpolated
+const injection = `BTC" } }) { id account_identifier } evil: trades(where: {_not: {id: {_is_null: true}}}) { id #`
+
+func TestWhereBuilder_ValuesAreParameterized(t *testing.T) {
+	var requests []*request
+	mockClient := &mockGraphQLClient{
+		runFunc: func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			requests = append(requests, requestFromContext(ctx))
+			return json.Unmarshal([]byte(`{"positions": [], "exchange_accounts": []}`), resp)
+		},
+	}
+	client := NewClientWithGraphQL(mockClient, ClientConfig{})
+	ctx := context.Background()
+
+	if _, err := client.GetPositions(ctx, PositionFilter{BaseAsset: strPtr(injection), Side: strPtr(injection)}); err != nil {
+		t.Fatalf("GetPositions failed: %v", err)
+	}
+	if _, err := client.ListAccountsFiltered(ctx, AccountFilter{ExchangeNames: []string{injection}}); err != nil {
+		t.Fatalf("ListAccountsFiltered failed: %v", err)
+	}
+
+	for _, req := range requests {
+		if strings.Contains(req.query, "evil") || strings.Contains(req.query, `BTC"`) {
+			t.Errorf("%s: expected the filter value never to be interpolated, got:\n%s", req.opName, req.query)
+		}
+	}
+	if got := requests[0].vars["base_asset"]; got != injection {
+		t.Errorf("Expected base_asset to be sent as a variable, got %v", got)
+	}
+	if got := requests[0].vars["side"]; got != injection {
+		t.Errorf("Expected side to be sent as a variable, got %v", got)
+	}
+	if got, _ := requests[1].vars["exchange_names"].([]string); len(got) != 1 || got[0] != injection {
+		t.Errorf("Expected exchange_names to be sent as a variable, got %v", requests[1].vars["exchange_names"])
+	}
+}
+
+func TestWhereBuilder_RejectsUnlistedNames(t *testing.T) {
+	tests := []struct {
+		name string
+		add  func(b *whereBuilder)
+	}{
+		{"field", func(b *whereBuilder) { b.add("side: { _eq: \"long\" }, id", "_eq", "side", "String!", "long") }},
+		{"unknown column", func(b *whereBuilder) { b.add("api_key", "_eq", "api_key", "String!", "x") }},
+		{"operator", func(b *whereBuilder) { b.add("side", "_eq: \"long\", _neq", "side", "String!", "long") }},
+		{"variable name", func(b *whereBuilder) { b.add("side", "_eq", "side: String!, $x", "String!", "long") }},
+		{"variable type", func(b *whereBuilder) { b.declare("limit", "Int!) { id } #", 1) }},
+		{"raw field", func(b *whereBuilder) { b.addRaw("enabled", "_eq: true") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected the builder to panic")
+				}
+			}()
+			tt.add(newWhereBuilder())
+		})
+	}
+}
+
+func TestWhereBuilder_AllowColumn(t *testing.T) {
+	b := newWhereBuilder()
+	if err := b.allowColumn("is_active"); err != nil {
+		t.Fatalf("allowColumn failed: %v", err)
+	}
+	b.addRaw("is_active", "_eq: true")
+	if got, want := b.where(), "{ is_active: { _eq: true } }"; got != want {
+		t.Errorf("where = %s, want %s", got, want)
+	}
+
+	if err := b.allowColumn("enabled: { _eq: true } }) { id } #"); err == nil {
+		t.Error("Expected an invalid column name to be rejected")
+	}
+}
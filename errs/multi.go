@@ -0,0 +1,106 @@
+// Package errs provides error types shared across the library
+package errs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Multi collects independent failures from a batch operation (e.g. one per account or chunk)
+// Members keep the order they were appended in, and errors.Is/errors.As see every member
+// The zero value is ready to use
+type Multi struct {
+	errs []error
+}
+
+// Append adds errs in order, ignoring nils and flattening nested *Multi values
+func (m *Multi) Append(errs ...error) {
+	for _, err := range errs {
+		switch e := err.(type) {
+		case nil:
+		case *Multi:
+			if e != nil {
+				m.errs = append(m.errs, e.errs...)
+			}
+		default:
+			m.errs = append(m.errs, err)
+		}
+	}
+}
+
+// Len returns the number of collected errors
+func (m *Multi) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.errs)
+}
+
+// Errors returns a copy of the collected errors in append order
+func (m *Multi) Errors() []error {
+	if m == nil {
+		return nil
+	}
+	return append([]error(nil), m.errs...)
+}
+
+// ErrorOrNil returns m if it holds any error, nil otherwise
+// Use it when returning so a caller's err != nil check works
+func (m *Multi) ErrorOrNil() error {
+	if m.Len() == 0 {
+		return nil
+	}
+	return m
+}
+
+func (m *Multi) Error() string {
+	switch m.Len() {
+	case 0:
+		return "no errors"
+	case 1:
+		return m.errs[0].Error()
+	}
+	messages := make([]string, len(m.errs))
+	for i, err := range m.errs {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors occurred: %s", len(m.errs), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the members so errors.Is and errors.As traverse them
+func (m *Multi) Unwrap() []error {
+	return m.Errors()
+}
+
+// Filter returns a new Multi with the members for which keep returns true, in the same order
+func (m *Multi) Filter(keep func(error) bool) *Multi {
+	filtered := &Multi{}
+	for _, err := range m.Errors() {
+		if keep(err) {
+			filtered.errs = append(filtered.errs, err)
+		}
+	}
+	return filtered
+}
+
+// multiJSON is the JSON form of a Multi used in reports
+type multiJSON struct {
+	Count  int         `json:"count"`
+	Errors []errorJSON `json:"errors"`
+}
+
+// errorJSON is the JSON form of one member
+type errorJSON struct {
+	Message string `json:"message"`
+	Type    string `json:"type"` // Go type of the member, e.g. "*iface.RateLimitError"
+}
+
+// MarshalJSON encodes the members' messages and types in append order
+func (m *Multi) MarshalJSON() ([]byte, error) {
+	out := multiJSON{Count: m.Len(), Errors: make([]errorJSON, 0, m.Len())}
+	for _, err := range m.Errors() {
+		out.Errors = append(out.Errors, errorJSON{Message: err.Error(), Type: fmt.Sprintf("%T", err)})
+	}
+	return json.Marshal(out)
+}
@@ -0,0 +1,86 @@
+package errs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+type codeError struct {
+	Code int
+}
+
+func (e *codeError) Error() string { return fmt.Sprintf("code %d", e.Code) }
+
+func TestMulti_IsAs(t *testing.T) {
+	var m Multi
+	m.Append(nil, fmt.Errorf("account a: %w", io.EOF), nil)
+	m.Append(fmt.Errorf("account b: %w", &codeError{Code: 429}))
+
+	err := m.ErrorOrNil()
+	if err == nil {
+		t.Fatal("Expected non-nil error")
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Error("Expected errors.Is to find io.EOF in a member")
+	}
+	var code *codeError
+	if !errors.As(err, &code) || code.Code != 429 {
+		t.Errorf("Expected errors.As to find *codeError, got %v", code)
+	}
+	if got, want := err.Error(), "2 errors occurred: account a: EOF; account b: code 429"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestMulti_Empty(t *testing.T) {
+	var m Multi
+	m.Append(nil)
+	if err := m.ErrorOrNil(); err != nil {
+		t.Errorf("Expected nil for an empty Multi, got %v", err)
+	}
+
+	var nilMulti *Multi
+	if nilMulti.Len() != 0 || nilMulti.ErrorOrNil() != nil {
+		t.Error("Expected a nil *Multi to be empty")
+	}
+}
+
+func TestMulti_FlattenAndFilter(t *testing.T) {
+	inner := &Multi{}
+	inner.Append(errors.New("first"), &codeError{Code: 500})
+
+	var m Multi
+	m.Append(inner, errors.New("third"))
+	if m.Len() != 3 {
+		t.Fatalf("Expected nested Multi to be flattened into 3 errors, got %d", m.Len())
+	}
+
+	codes := m.Filter(func(err error) bool {
+		var code *codeError
+		return errors.As(err, &code)
+	})
+	if codes.Len() != 1 || codes.Errors()[0].Error() != "code 500" {
+		t.Errorf("Expected only the code error, got %v", codes.Errors())
+	}
+	if m.Len() != 3 {
+		t.Error("Expected Filter to leave the original unchanged")
+	}
+}
+
+func TestMulti_MarshalJSON(t *testing.T) {
+	var m Multi
+	m.Append(errors.New("boom"), &codeError{Code: 429})
+
+	data, err := json.Marshal(&m)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	want := `{"count":2,"errors":[{"message":"boom","type":"*errors.errorString"},{"message":"code 429","type":"*errs.codeError"}]}`
+	if string(data) != want {
+		t.Errorf("MarshalJSON() = %s, want %s", data, want)
+	}
+}
@@ -3,6 +3,7 @@ package exchange
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/zif-terminal/lib/exchange/hyperliquid"
 	"github.com/zif-terminal/lib/exchange/iface"
@@ -11,8 +12,53 @@ import (
 // ErrExchangeNotFound is returned when an exchange name is not recognized
 var ErrExchangeNotFound = errors.New("exchange not found")
 
+// ExchangeInfo describes a registered exchange (aliased from iface package)
+type ExchangeInfo = iface.ExchangeInfo
+
+// registration is a registry entry: the exchange's metadata and how to build its client
+type registration struct {
+	info      ExchangeInfo
+	newClient func() iface.ExchangeClient
+}
+
+// registry holds every supported exchange by name; it is only written during package init
+var registry = map[string]registration{}
+
+func init() {
+	mustRegister(ExchangeInfo{
+		Name:         "hyperliquid",
+		DisplayName:  "Hyperliquid",
+		IconSlug:     "hyperliquid",
+		DefaultQuote: "USDC",
+	}, func() iface.ExchangeClient { return hyperliquid.NewClient() })
+	// Add more exchanges here as they are implemented:
+	// mustRegister(ExchangeInfo{Name: "lighter", DisplayName: "Lighter", ...}, ...)
+	// mustRegister(ExchangeInfo{Name: "drift", DisplayName: "Drift", ...}, ...)
+}
+
+// register adds an exchange to the registry
+// Rejects invalid metadata (see ExchangeInfo.Validate) and duplicate names
+func register(info ExchangeInfo, newClient func() iface.ExchangeClient) error {
+	if err := info.Validate(); err != nil {
+		return err
+	}
+	if _, exists := registry[info.Name]; exists {
+		return fmt.Errorf("exchange %s is already registered", info.Name)
+	}
+	registry[info.Name] = registration{info: info, newClient: newClient}
+	return nil
+}
+
+// mustRegister is register for package init, where a bad entry is a programming error
+func mustRegister(info ExchangeInfo, newClient func() iface.ExchangeClient) {
+	if err := register(info, newClient); err != nil {
+		panic(err)
+	}
+}
+
 // GetClient returns an ExchangeClient for the given exchange name.
 // Returns ErrExchangeNotFound if the exchange name is not recognized.
+// It is safe to call concurrently, and the returned clients are safe for concurrent use.
 //
 // Example:
 //
@@ -22,25 +68,39 @@ var ErrExchangeNotFound = errors.New("exchange not found")
 //	}
 //	trades, err := client.FetchTrades(ctx, account, since)
 func GetClient(name string) (iface.ExchangeClient, error) {
-	switch name {
-	case "hyperliquid":
-		return hyperliquid.NewClient(), nil
-	// Add more exchanges here as they are implemented:
-	// case "lighter":
-	//     return lighter.NewClient(), nil
-	// case "drift":
-	//     return drift.NewClient(), nil
-	default:
+	entry, ok := registry[name]
+	if !ok {
 		return nil, fmt.Errorf("%w: %s", ErrExchangeNotFound, name)
 	}
+	return entry.newClient(), nil
+}
+
+// Info returns the display metadata of a registered exchange.
+// Returns ErrExchangeNotFound if the exchange name is not recognized.
+func Info(name string) (ExchangeInfo, error) {
+	entry, ok := registry[name]
+	if !ok {
+		return ExchangeInfo{}, fmt.Errorf("%w: %s", ErrExchangeNotFound, name)
+	}
+	return entry.info, nil
+}
+
+// ListInfo returns the metadata of every registered exchange, ordered by name.
+func ListInfo() []ExchangeInfo {
+	infos := make([]ExchangeInfo, 0, len(registry))
+	for _, entry := range registry {
+		infos = append(infos, entry.info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
 }
 
-// ListAvailableExchanges returns a list of all available exchange names.
+// ListAvailableExchanges returns a list of all available exchange names, ordered by name.
 func ListAvailableExchanges() []string {
-	return []string{
-		"hyperliquid",
-		// Add more exchanges here as they are implemented:
-		// "lighter",
-		// "drift",
+	infos := ListInfo()
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name
 	}
+	return names
 }
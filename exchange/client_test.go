@@ -2,6 +2,8 @@ package exchange
 
 import (
 	"errors"
+	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/zif-terminal/lib/exchange/iface"
@@ -74,3 +76,82 @@ func TestListAvailableExchanges(t *testing.T) {
 	}
 }
 
+// TestGetClient_Concurrent checks GetClient can be called from many goroutines; run with -race
+func TestGetClient_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client, err := GetClient("hyperliquid")
+			if err != nil {
+				t.Errorf("GetClient failed: %v", err)
+				return
+			}
+			if client.Name() != "hyperliquid" {
+				t.Errorf("Expected client name 'hyperliquid', got '%s'", client.Name())
+			}
+			_ = ListAvailableExchanges()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestInfo(t *testing.T) {
+	info, err := Info("hyperliquid")
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	if info.Name != "hyperliquid" || info.DisplayName != "Hyperliquid" || info.DefaultQuote != "USDC" {
+		t.Errorf("Unexpected hyperliquid info: %+v", info)
+	}
+
+	if _, err := Info("nonexistent"); !errors.Is(err, ErrExchangeNotFound) {
+		t.Errorf("Expected ErrExchangeNotFound, got: %v", err)
+	}
+}
+
+func TestListInfo_OrderedByName(t *testing.T) {
+	original := registry
+	defer func() { registry = original }()
+
+	registry = map[string]registration{}
+	for _, name := range []string{"lighter", "drift", "hyperliquid"} {
+		if err := register(ExchangeInfo{Name: name, DisplayName: name + " display"}, nil); err != nil {
+			t.Fatalf("register %s failed: %v", name, err)
+		}
+	}
+
+	var names []string
+	for _, info := range ListInfo() {
+		names = append(names, info.Name)
+	}
+	if want := []string{"drift", "hyperliquid", "lighter"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ListInfo order = %v, want %v", names, want)
+	}
+	if got := ListAvailableExchanges(); !reflect.DeepEqual(got, names) {
+		t.Errorf("ListAvailableExchanges = %v, want %v", got, names)
+	}
+}
+
+func TestRegister_RejectsInvalidInfo(t *testing.T) {
+	original := registry
+	defer func() { registry = original }()
+	registry = map[string]registration{}
+
+	if err := register(ExchangeInfo{Name: "lighter"}, nil); err == nil {
+		t.Error("Expected empty display name to be rejected")
+	}
+	if err := register(ExchangeInfo{DisplayName: "Lighter"}, nil); err == nil {
+		t.Error("Expected empty name to be rejected")
+	}
+	if err := register(ExchangeInfo{Name: "lighter", DisplayName: "Lighter"}, nil); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	if err := register(ExchangeInfo{Name: "lighter", DisplayName: "Lighter"}, nil); err == nil {
+		t.Error("Expected duplicate name to be rejected")
+	}
+	if len(registry) != 1 {
+		t.Errorf("Expected only the valid entry to be registered, got %d", len(registry))
+	}
+}
@@ -11,22 +11,159 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/clock"
 	"github.com/zif-terminal/lib/exchange/iface"
 	"github.com/zif-terminal/lib/models"
 )
 
+// defaultQuoteAsset is the quote asset Hyperliquid perps settle in when the coin has no explicit quote
+const defaultQuoteAsset = "USDC"
+
+// FillsEndpoint selects the /info request type FetchTrades uses to read fills
+type FillsEndpoint string
+
+const (
+	// FillsByTime pages forward through history with startTime (2000 fills per response,
+	// only the 10000 most recent fills are reachable). This is the default
+	FillsByTime FillsEndpoint = "userFillsByTime"
+	// FillsRecent makes a single request for the 2000 most recent fills
+	FillsRecent FillsEndpoint = "userFills"
+)
+
+// DefaultMaxPages is the hard cap on pages FetchTrades requests before failing
+// The API only reaches back 10000 fills, so a healthy fetch needs a handful of pages; the cap
+// only trips when a response keeps the pagination from advancing
+const DefaultMaxPages = 1000
+
 // Client implements iface.ExchangeClient for Hyperliquid
+// A Client is safe for concurrent use by multiple goroutines: options are fixed once NewClient
+// returns and per-fetch state (pagination, spot pairs) lives in the call. Callbacks such as the
+// WithRawCapture function may be invoked concurrently and must guard their own state
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+	options    iface.Options
+	rawCapture func(raw json.RawMessage) // Receives each raw fill before transformation (nil = off)
+
+	fillsEndpoint     FillsEndpoint // Request type FetchTrades sends (empty = FillsByTime)
+	aggregateByTime   bool          // Ask the API to merge partial fills of an order at the same time
+	skipRedundantSort bool          // Trust userFillsByTime ordering instead of re-sorting FetchTrades results
+	maxPages          int           // Hard cap on pages per FetchTrades call (0 = DefaultMaxPages)
+
+	maxAttempts       int           // Attempts per request on temporary errors (0 = default)
+	retryBackoff      time.Duration // Initial backoff between attempts (0 = default)
+	retryableStatuses map[int]bool  // HTTP statuses worth retrying (nil = defaultRetryableStatuses)
+
+	maintenanceDetector iface.MaintenanceDetector // Recognizes maintenance responses (nil = defaultMaintenanceDetector)
+
+	floatEpsilon float64 // Largest absolute error tolerated formatting float64 numerics (0 = DefaultFloatEpsilon, negative = unchecked)
+
+	clock clock.Clock // Source of time for retry backoff (nil = real clock)
+}
+
+// Option configures a Hyperliquid client
+type Option func(*Client)
+
+// WithExchangeOptions applies shared exchange options (e.g. iface.WithDefaultQuote)
+func WithExchangeOptions(opts ...iface.Option) Option {
+	return func(c *Client) {
+		c.options = iface.ApplyOptions(c.options, opts...)
+	}
+}
+
+// WithDefaultQuote sets the quote asset for coins without an explicit quote (default "USDC")
+// Any single-token coin, including ones this client does not recognize, is paired with it in
+// both trades and funding payments. Shorthand for WithExchangeOptions(iface.WithDefaultQuote(quote))
+func WithDefaultQuote(quote string) Option {
+	return WithExchangeOptions(iface.WithDefaultQuote(quote))
+}
+
+// WithRawCapture passes every raw fill to fn before it is transformed, so the exact payload
+// behind a transformFill error can be logged
+func WithRawCapture(fn func(raw json.RawMessage)) Option {
+	return func(c *Client) {
+		c.rawCapture = fn
+	}
+}
+
+// WithFillsEndpoint selects the endpoint FetchTrades reads fills from (default FillsByTime)
+func WithFillsEndpoint(endpoint FillsEndpoint) Option {
+	return func(c *Client) {
+		c.fillsEndpoint = endpoint
+	}
+}
+
+// WithAggregateByTime opts into Hyperliquid's server-side aggregation, which merges partial
+// fills of the same order executed at the same time into one fill
+func WithAggregateByTime(enabled bool) Option {
+	return func(c *Client) {
+		c.aggregateByTime = enabled
+	}
+}
+
+// WithSkipRedundantSort skips the final sort in FetchTrades and trusts userFillsByTime to return
+// fills oldest first, saving an O(n log n) pass on large histories
+// The tradeoff is that an out-of-order response from the API would be returned as is; sorting
+// stays on by default and is never skipped for FillsRecent, which returns newest first
+func WithSkipRedundantSort(skip bool) Option {
+	return func(c *Client) {
+		c.skipRedundantSort = skip
+	}
+}
+
+// WithMaxPages sets the hard cap on pages a single FetchTrades call may request (default
+// DefaultMaxPages). Exceeding it fails the fetch with iface.ErrTooManyPages; unlike the soft
+// iface.WithPageLimit it cannot be overridden at runtime
+func WithMaxPages(pages int) Option {
+	return func(c *Client) {
+		c.maxPages = pages
+	}
 }
 
 // NewClient creates a new Hyperliquid client
-func NewClient() *Client {
-	return &Client{
+func NewClient(opts ...Option) *Client {
+	c := &Client{
 		baseURL:    "https://api.hyperliquid.xyz",
 		httpClient: &http.Client{Timeout: 30 * time.Second},
+		options:    iface.Options{DefaultQuote: defaultQuoteAsset},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// pageCap returns the hard cap on pages per FetchTrades call
+func (c *Client) pageCap() int {
+	if c.maxPages <= 0 {
+		return DefaultMaxPages
+	}
+	return c.maxPages
+}
+
+// defaultQuote returns the configured default quote asset
+// Falls back to USDC so clients built without NewClient behave as before
+func (c *Client) defaultQuote() string {
+	if c.options.DefaultQuote == "" {
+		return defaultQuoteAsset
+	}
+	return c.options.DefaultQuote
+}
+
+// fillsRequest builds the request body for reading fills from the configured endpoint
+// startTime is only sent to FillsByTime, which is the only endpoint that pages by time
+func (c *Client) fillsRequest(endpoint FillsEndpoint, address string, startTime int64) map[string]interface{} {
+	requestBody := map[string]interface{}{
+		"type": string(endpoint),
+		"user": address,
+	}
+	if endpoint == FillsByTime {
+		requestBody["startTime"] = startTime
+	}
+	if c.aggregateByTime {
+		requestBody["aggregateByTime"] = true
 	}
+	return requestBody
 }
 
 // Name returns the exchange identifier
@@ -34,10 +171,17 @@ func (c *Client) Name() string {
 	return "hyperliquid"
 }
 
+// accountAddress returns the account's address in EIP-55 checksummed form
+// Returns an *iface.InvalidAccountError when the address is missing or malformed
+func (c *Client) accountAddress(account *models.ExchangeAccount) (string, error) {
+	return models.NormalizeAccountIdentifier(c.Name(), account.AccountIdentifier)
+}
+
 // FetchTrades fetches trades directly from Hyperliquid API
 // Transforms exchange response directly to []*models.TradeInput
 // Implements pagination to fetch all historical trades (API limits to 2000 per request)
-// Uses userFillsByTime endpoint which returns trades in chronological order (oldest first)
+// Uses userFillsByTime by default, which returns trades in chronological order (oldest first);
+// with WithFillsEndpoint(FillsRecent) a single userFills request is made instead
 func (c *Client) FetchTrades(
 	ctx context.Context,
 	account *models.ExchangeAccount,
@@ -55,9 +199,9 @@ func (c *Client) FetchTrades(
 	}
 
 	// Extract address from account identifier
-	address := account.AccountIdentifier
-	if address == "" {
-		return nil, fmt.Errorf("account identifier (address) is required")
+	address, err := c.accountAddress(account)
+	if err != nil {
+		return nil, err
 	}
 
 	// Hyperliquid API has a limit of 2000 trades per request
@@ -65,7 +209,13 @@ func (c *Client) FetchTrades(
 	// userFillsByTime returns trades in chronological order (oldest first)
 	const maxTradesPerRequest = 2000
 	allTrades := make([]*models.TradeInput, 0)
-	
+	spotPairs := &spotPairResolver{client: c}
+	pages := 0
+	endpoint := c.fillsEndpoint
+	if endpoint == "" {
+		endpoint = FillsByTime
+	}
+
 	// Determine initial startTime for pagination
 	// If since is zero, fetch all historical trades from the beginning
 	// Otherwise, fetch trades starting from the 'since' timestamp
@@ -76,6 +226,11 @@ func (c *Client) FetchTrades(
 		startTime = since.UnixMilli() // Fetch trades >= since
 	}
 
+	// Trade IDs already collected in the startTime millisecond
+	// Pages are requested from the newest millisecond seen rather than the one after it, because
+	// a page can end partway through a millisecond; fills seen before are skipped by trade ID
+	boundary := make(map[string]bool)
+
 	for {
 		// Check if ctx is cancelled before each request
 		if ctx.Err() != nil {
@@ -84,55 +239,18 @@ func (c *Client) FetchTrades(
 
 		// Build API request body
 		// Based on Hyperliquid API: POST /info with {"type": "userFillsByTime", "user": address, "startTime": startTime}
-		requestBody := map[string]interface{}{
-			"type":      "userFillsByTime",
-			"user":      address,
-			"startTime": startTime,
-		}
-
-		bodyBytes, err := json.Marshal(requestBody)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request: %w", err)
-		}
-
-		// Create HTTP request with context
-		req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/info", strings.NewReader(string(bodyBytes)))
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
-
-		req.Header.Set("Content-Type", "application/json")
+		requestBody := c.fillsRequest(endpoint, address, startTime)
 
-		// Make HTTP request
-		resp, err := c.httpClient.Do(req)
+		body, err := c.postInfo(ctx, requestBody, "trades")
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch trades: %w", err)
-		}
-
-		// Check for rate limit (HTTP 429)
-		if resp.StatusCode == http.StatusTooManyRequests {
-			resp.Body.Close()
-			retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
-			return nil, &iface.RateLimitError{
-				Exchange:   "hyperliquid",
-				Message:    "rate limit exceeded",
-				RetryAfter: retryAfter,
-			}
-		}
-
-		// Check for other HTTP errors
-		if resp.StatusCode != http.StatusOK {
-			resp.Body.Close()
-			return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, resp.Status)
+			return nil, err
 		}
 
 		// Parse response - API returns a direct array of fills, not wrapped in an object
-		var apiFills []hyperliquidFill
-		if err := json.NewDecoder(resp.Body).Decode(&apiFills); err != nil {
-			resp.Body.Close()
+		apiFills, err := c.decodeFills(body)
+		if err != nil {
 			return nil, fmt.Errorf("failed to decode response: %w", err)
 		}
-		resp.Body.Close()
 
 		// If no fills returned, we've reached the end
 		if len(apiFills) == 0 {
@@ -142,8 +260,14 @@ func (c *Client) FetchTrades(
 		// Transform to TradeInput and collect
 		batchTrades := make([]*models.TradeInput, 0, len(apiFills))
 		var newestTimestamp *time.Time
+		progressed := false
 
 		for _, apiFill := range apiFills {
+			// userFillsByTime returns spot fills alongside perp fills
+			if apiFill.Coin, err = spotPairs.resolve(ctx, apiFill); err != nil {
+				return nil, err
+			}
+
 			// Parse timestamp first
 			tradeTimestamp := parseTimestamp(apiFill.Time)
 			if tradeTimestamp.IsZero() {
@@ -160,12 +284,19 @@ func (c *Client) FetchTrades(
 				continue
 			}
 
-			tradeInput, err := transformFill(apiFill, accountUUID)
+			tradeInput, err := transformFill(apiFill, accountUUID, c.defaultQuote(), c.floatPrecisionEpsilon())
 			if err != nil {
 				// Return error instead of skipping - we're in dev phase and this should not happen
 				// Missing required fields (e.g., tid) indicate a problem that needs investigation
 				return nil, fmt.Errorf("failed to transform fill: %w | hash=%s | coin=%s | time=%v", err, apiFill.Hash, apiFill.Coin, apiFill.Time)
 			}
+
+			// Skip fills already collected from the previous page's last millisecond
+			if tradeTimestamp.UnixMilli() == startTime && boundary[tradeInput.TradeID] {
+				continue
+			}
+			progressed = true
+
 			batchTrades = append(batchTrades, tradeInput)
 		}
 
@@ -173,7 +304,8 @@ func (c *Client) FetchTrades(
 		allTrades = append(allTrades, batchTrades...)
 
 		// If we got fewer than maxTradesPerRequest, we've reached the end
-		if len(apiFills) < maxTradesPerRequest {
+		// userFills cannot page, so a single response is all there is
+		if len(apiFills) < maxTradesPerRequest || endpoint != FillsByTime {
 			break
 		}
 
@@ -182,38 +314,156 @@ func (c *Client) FetchTrades(
 			break
 		}
 
-		// Set startTime to the newest timestamp + 1ms for next pagination request
-		// This ensures we don't fetch the same trade again and continue forward
-		startTime = newestTimestamp.UnixMilli() + 1
+		// Consult the soft page limit before requesting another page
+		pages++
+		if err := c.options.CheckPageLimit(ctx, c.Name(), pages); err != nil {
+			return nil, err
+		}
+		// Fail fast if the pagination isn't converging rather than accumulating fills forever
+		if pages >= c.pageCap() {
+			return nil, fmt.Errorf("%w: %s fetch for %s requested %d pages without reaching the end (startTime=%d, %d fills collected); the API may not be advancing",
+				iface.ErrTooManyPages, c.Name(), address, pages, startTime, len(allTrades))
+		}
+
+		newestMillis := newestTimestamp.UnixMilli()
+		if !progressed {
+			// A full page of fills we already have: the API cannot page further into this
+			// millisecond, and skipping past it would drop the rest of its fills
+			return nil, fmt.Errorf("%w: %s fetch for %s got a full page of %d fills in millisecond %d, so some may be missing; userFillsByTime cannot page within a millisecond",
+				iface.ErrPageSaturated, c.Name(), address, maxTradesPerRequest, newestMillis)
+		}
+
+		// Re-request from the newest millisecond so fills after the page cut in it aren't skipped
+		if newestMillis != startTime {
+			clear(boundary)
+		}
+		for _, trade := range batchTrades {
+			if trade.Timestamp.UnixMilli() == newestMillis {
+				boundary[trade.TradeID] = true
+			}
+		}
+		startTime = newestMillis
 	}
 
-	// Trades are already sorted chronologically (oldest first) from userFillsByTime
-	// No need to sort again, but we verify for safety
-	sort.Slice(allTrades, func(i, j int) bool {
-		return allTrades[i].Timestamp.Before(allTrades[j].Timestamp)
-	})
+	c.sortTrades(endpoint, allTrades)
 
 	return allTrades, nil
 }
 
+// sortTrades puts FetchTrades results in chronological order
+// userFillsByTime already returns trades oldest first but userFills is newest first, so the
+// sort is skipped only when WithSkipRedundantSort is set and the endpoint is FillsByTime
+func (c *Client) sortTrades(endpoint FillsEndpoint, trades []*models.TradeInput) {
+	if c.skipRedundantSort && endpoint == FillsByTime {
+		return
+	}
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Timestamp.Before(trades[j].Timestamp)
+	})
+}
+
+// FetchRecentTrades fetches the account's most recent trades, newest first, capped at limit
+// Unlike FetchTrades, which walks the full history forward with userFillsByTime, this makes a
+// single userFills request; the API only returns the latest 2000 fills, so limit is capped there
+// Use FetchTrades for incremental syncing and FetchRecentTrades for "last N trades" views
+func (c *Client) FetchRecentTrades(
+	ctx context.Context,
+	account *models.ExchangeAccount,
+	limit int,
+) ([]*models.TradeInput, error) {
+	// Check if ctx is cancelled
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive, got %d", limit)
+	}
+
+	// Parse account ID to UUID
+	accountUUID, err := uuid.Parse(account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID: %w", err)
+	}
+
+	// Extract address from account identifier
+	address, err := c.accountAddress(account)
+	if err != nil {
+		return nil, err
+	}
+
+	// Based on Hyperliquid API: POST /info with {"type": "userFills", "user": address}
+	requestBody := c.fillsRequest(FillsRecent, address, 0)
+
+	body, err := c.postInfo(ctx, requestBody, "recent trades")
+	if err != nil {
+		return nil, err
+	}
+
+	apiFills, err := c.decodeFills(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	spotPairs := &spotPairResolver{client: c}
+	trades := make([]*models.TradeInput, 0, len(apiFills))
+	for _, apiFill := range apiFills {
+		if parseTimestamp(apiFill.Time).IsZero() {
+			continue // Skip invalid timestamps
+		}
+
+		if apiFill.Coin, err = spotPairs.resolve(ctx, apiFill); err != nil {
+			return nil, err
+		}
+
+		tradeInput, err := transformFill(apiFill, accountUUID, c.defaultQuote(), c.floatPrecisionEpsilon())
+		if err != nil {
+			return nil, fmt.Errorf("failed to transform fill: %w | hash=%s | coin=%s | time=%v", err, apiFill.Hash, apiFill.Coin, apiFill.Time)
+		}
+		trades = append(trades, tradeInput)
+	}
+
+	// userFills is documented newest-first, but sort explicitly so the order is guaranteed
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].Timestamp.After(trades[j].Timestamp)
+	})
+
+	if len(trades) > limit {
+		trades = trades[:limit]
+	}
+
+	return trades, nil
+}
+
 // transformFill converts Hyperliquid fill format to TradeInput
-func transformFill(apiFill hyperliquidFill, accountUUID uuid.UUID) (*models.TradeInput, error) {
+// A coin without a "-" or "/" separator is quoted in defaultQuote. Numeric fields that arrive as
+// float64 are rejected when formatting them would lose more than epsilon (see checkFloatPrecision)
+func transformFill(apiFill hyperliquidFill, accountUUID uuid.UUID, defaultQuote string, epsilon float64) (*models.TradeInput, error) {
 	// Normalize side: Hyperliquid uses "B" for buy, "S" for sell, or "A" for close
 	side := normalizeSide(apiFill.Side)
 
 	// Parse timestamp (Hyperliquid returns Unix timestamp in milliseconds)
 	timestamp := parseTimestamp(apiFill.Time)
 
-	// Convert numeric fields to strings
-	price := convertToString(apiFill.Px)
-	quantity := convertToString(apiFill.Sz)
-	fee := convertToString(apiFill.Fee)
+	// Numeric fields are json.Number, so the exchange's decimal text is kept as is
+	price := apiFill.Px.String()
+	quantity := apiFill.Sz.String()
+	fee := models.FeeOrDefault(apiFill.Fee.String())
 
 	// Extract base and quote assets from coin (e.g., "BTC" from "BTC-USDC" or just "BTC")
-	baseAsset, quoteAsset := parseAssetPair(apiFill.Coin)
+	baseAsset, quoteAsset := iface.SplitPair(apiFill.Coin, defaultQuote)
 
-	// Convert order ID to string
-	orderID := convertToString(apiFill.Oid)
+	// Spot pairs are written "BASE/QUOTE"; perp coins are bare names
+	marketType := models.MarketTypePerp
+	if strings.Contains(apiFill.Coin, "/") {
+		marketType = models.MarketTypeSpot
+	}
+
+	// Convert order ID to string (empty when the fill carries none)
+	orderID, err := numericString(apiFill.Oid, epsilon)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'oid' for fill with hash %s: %w", apiFill.Hash, err)
+	}
 
 	// Convert fill ID (tid) to string for trade ID - tid is unique per fill
 	// This ensures each fill has a unique trade_id, unlike Hash which can be shared across multiple fills
@@ -222,7 +472,10 @@ func transformFill(apiFill hyperliquidFill, accountUUID uuid.UUID) (*models.Trad
 		return nil, fmt.Errorf("missing required field 'tid' (fill ID) for fill with hash %s", apiFill.Hash)
 	}
 
-	tradeID := convertToString(apiFill.Tid)
+	tradeID, err := numericString(apiFill.Tid, epsilon)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'tid' (fill ID) for fill with hash %s: %w", apiFill.Hash, err)
+	}
 	if tradeID == "" || tradeID == "<nil>" {
 		return nil, fmt.Errorf("empty or invalid 'tid' (fill ID) for fill with hash %s, tid value: %v (type: %T)", apiFill.Hash, apiFill.Tid, apiFill.Tid)
 	}
@@ -245,9 +498,97 @@ func transformFill(apiFill hyperliquidFill, accountUUID uuid.UUID) (*models.Trad
 		Fee:              fee,
 		Timestamp:        timestamp,
 		ExchangeAccountID: accountUUID,
+		MarketType:       marketType,
+		IsTaker:          apiFill.Crossed,
 	}, nil
 }
 
+// decodeFills decodes a fills response, handing each raw fill to the capture callback first
+func (c *Client) decodeFills(body []byte) ([]hyperliquidFill, error) {
+	var rawFills []json.RawMessage
+	if err := json.Unmarshal(body, &rawFills); err != nil {
+		return nil, err
+	}
+
+	fills := make([]hyperliquidFill, len(rawFills))
+	for i, raw := range rawFills {
+		if c.rawCapture != nil {
+			c.rawCapture(raw)
+		}
+		if err := decodeNumbers(raw, &fills[i]); err != nil {
+			return nil, fmt.Errorf("fill %d: %w", i, err)
+		}
+	}
+
+	return fills, nil
+}
+
+// spotPairResolver resolves spot pairs referenced by index ("@107") to "BASE/QUOTE"
+// Spot metadata is loaded on the first spot fill and reused for the rest of the fetch
+type spotPairResolver struct {
+	client *Client
+	pairs  map[string]string
+}
+
+// resolve returns the fill's coin with index-referenced spot pairs replaced by "BASE/QUOTE"
+func (r *spotPairResolver) resolve(ctx context.Context, apiFill hyperliquidFill) (string, error) {
+	coin, err := r.resolveCoin(ctx, apiFill.Coin)
+	if err != nil {
+		return "", fmt.Errorf("%w for fill with hash %s", err, apiFill.Hash)
+	}
+	return coin, nil
+}
+
+// resolveCoin returns coin with an index-referenced spot pair replaced by "BASE/QUOTE"
+func (r *spotPairResolver) resolveCoin(ctx context.Context, coin string) (string, error) {
+	if !strings.HasPrefix(coin, "@") {
+		return coin, nil
+	}
+
+	if r.pairs == nil {
+		pairs, err := r.client.fetchSpotPairs(ctx)
+		if err != nil {
+			return "", err
+		}
+		r.pairs = pairs
+	}
+
+	pair, ok := r.pairs[coin]
+	if !ok {
+		return "", fmt.Errorf("unknown spot pair %s", coin)
+	}
+	return pair, nil
+}
+
+// fetchSpotPairs loads spot metadata and maps pair names (e.g., "@107") to "BASE/QUOTE"
+func (c *Client) fetchSpotPairs(ctx context.Context) (map[string]string, error) {
+	body, err := c.postInfo(ctx, map[string]interface{}{"type": "spotMeta"}, "spot metadata")
+	if err != nil {
+		return nil, err
+	}
+
+	var meta hyperliquidSpotMeta
+	if err := json.Unmarshal(body, &meta); err != nil {
+		return nil, fmt.Errorf("failed to decode spot metadata: %w", err)
+	}
+
+	tokens := make(map[int]string, len(meta.Tokens))
+	for _, token := range meta.Tokens {
+		tokens[token.Index] = token.Name
+	}
+
+	pairs := make(map[string]string, len(meta.Universe))
+	for _, pair := range meta.Universe {
+		base, quote := tokens[pair.Tokens[0]], tokens[pair.Tokens[1]]
+		if base == "" || quote == "" {
+			continue
+		}
+		pairs[pair.Name] = base + "/" + quote
+	}
+
+	return pairs, nil
+}
+
 // normalizeSide converts Hyperliquid side format to "buy" or "sell"
 // Hyperliquid uses: "B" (buy), "S" (sell), "A" (close/liquidation)
 func normalizeSide(side string) string {
@@ -272,6 +613,9 @@ func normalizeSide(side string) string {
 func parseTimestamp(ts interface{}) time.Time {
 	var t time.Time
 	switch v := ts.(type) {
+	case json.Number:
+		// Decoded with UseNumber; same handling as the string form
+		return parseTimestamp(v.String())
 	case float64:
 		// Unix timestamp in milliseconds
 		t = time.Unix(0, int64(v)*int64(time.Millisecond))
@@ -294,17 +638,6 @@ func parseTimestamp(ts interface{}) time.Time {
 	return t.UTC()
 }
 
-// parseAssetPair extracts base and quote assets from coin string
-// Hyperliquid format: "BTC-USDC" or "BTC"
-func parseAssetPair(coin string) (baseAsset, quoteAsset string) {
-	parts := strings.Split(coin, "-")
-	if len(parts) >= 2 {
-		return parts[0], parts[1]
-	}
-	// If no separator, assume USDC as quote (common for Hyperliquid)
-	return parts[0], "USDC"
-}
-
 // convertToString converts numeric values to string for precision
 func convertToString(v interface{}) string {
 	if v == nil {
@@ -313,6 +646,8 @@ func convertToString(v interface{}) string {
 	switch val := v.(type) {
 	case string:
 		return val
+	case json.Number:
+		return val.String()
 	case float64:
 		// Format with enough precision
 		return strconv.FormatFloat(val, 'f', -1, 64)
@@ -344,9 +679,9 @@ func (c *Client) FetchFundingPayments(
 	}
 
 	// Extract address from account identifier
-	address := account.AccountIdentifier
-	if address == "" {
-		return nil, fmt.Errorf("account identifier (address) is required")
+	address, err := c.accountAddress(account)
+	if err != nil {
+		return nil, err
 	}
 
 	// Build API request body
@@ -356,44 +691,14 @@ func (c *Client) FetchFundingPayments(
 		"user": address,
 	}
 
-	bodyBytes, err := json.Marshal(requestBody)
+	body, err := c.postInfo(ctx, requestBody, "funding payments")
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request with context
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/info", strings.NewReader(string(bodyBytes)))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	// Make HTTP request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch funding payments: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check for rate limit (HTTP 429)
-	if resp.StatusCode == http.StatusTooManyRequests {
-		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
-		return nil, &iface.RateLimitError{
-			Exchange:   "hyperliquid",
-			Message:    "rate limit exceeded",
-			RetryAfter: retryAfter,
-		}
-	}
-
-	// Check for other HTTP errors
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, resp.Status)
+		return nil, err
 	}
 
 	// Parse response - API returns a direct array of funding payments, not wrapped in an object
 	var apiPayments []hyperliquidFundingPayment
-	if err := json.NewDecoder(resp.Body).Decode(&apiPayments); err != nil {
+	if err := json.Unmarshal(body, &apiPayments); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -408,7 +713,7 @@ func (c *Client) FetchFundingPayments(
 			continue
 		}
 
-		paymentInput, err := transformFundingPayment(apiPayment, accountUUID)
+		paymentInput, err := transformFundingPayment(apiPayment, accountUUID, c.defaultQuote())
 		if err != nil {
 			// Return error instead of skipping - missing required fields indicate a problem
 			return nil, fmt.Errorf("failed to transform funding payment: %w | hash=%s | coin=%s | time=%v", err, apiPayment.Hash, apiPayment.Delta.Coin, apiPayment.Time)
@@ -424,8 +729,90 @@ func (c *Client) FetchFundingPayments(
 	return payments, nil
 }
 
+// FetchOrders fetches order history from Hyperliquid's historicalOrders endpoint
+// The API only returns the most recent 2000 orders; orders placed before since are dropped
+// Implements iface.OrderFetcher
+func (c *Client) FetchOrders(
+	ctx context.Context,
+	account *models.ExchangeAccount,
+	since time.Time,
+) ([]*models.OrderInput, error) {
+	// Check if ctx is cancelled
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// Parse account ID to UUID
+	accountUUID, err := uuid.Parse(account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID: %w", err)
+	}
+
+	// Extract address from account identifier
+	address, err := c.accountAddress(account)
+	if err != nil {
+		return nil, err
+	}
+
+	// Based on Hyperliquid API: POST /info with {"type": "historicalOrders", "user": address}
+	requestBody := map[string]interface{}{
+		"type": "historicalOrders",
+		"user": address,
+	}
+
+	body, err := c.postInfo(ctx, requestBody, "orders")
+	if err != nil {
+		return nil, err
+	}
+
+	var apiOrders []hyperliquidHistoricalOrder
+	if err := json.Unmarshal(body, &apiOrders); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	spotPairs := &spotPairResolver{client: c}
+	orders := make([]*models.OrderInput, 0, len(apiOrders))
+	for _, apiOrder := range apiOrders {
+		timestamp := parseTimestamp(apiOrder.Order.Timestamp)
+		if timestamp.IsZero() {
+			continue // Skip invalid timestamps
+		}
+
+		// Filter: only orders placed >= since
+		if !since.IsZero() && timestamp.Before(since) {
+			continue
+		}
+
+		coin, err := spotPairs.resolveCoin(ctx, apiOrder.Order.Coin)
+		if err != nil {
+			return nil, fmt.Errorf("%w for order %v", err, apiOrder.Order.Oid)
+		}
+		baseAsset, quoteAsset := iface.SplitPair(coin, c.defaultQuote())
+
+		orders = append(orders, &models.OrderInput{
+			OrderID:           convertToString(apiOrder.Order.Oid),
+			BaseAsset:         baseAsset,
+			QuoteAsset:        quoteAsset,
+			Side:              normalizeSide(apiOrder.Order.Side),
+			Price:             convertToString(apiOrder.Order.LimitPx),
+			Size:              convertToString(apiOrder.Order.OrigSz),
+			Status:            apiOrder.Status,
+			Timestamp:         timestamp,
+			ExchangeAccountID: accountUUID,
+		})
+	}
+
+	// Sort by timestamp (oldest first), matching FetchTrades and FetchFundingPayments
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].Timestamp.Before(orders[j].Timestamp)
+	})
+
+	return orders, nil
+}
+
 // transformFundingPayment converts Hyperliquid funding payment format to FundingPaymentInput
-func transformFundingPayment(apiPayment hyperliquidFundingPayment, accountUUID uuid.UUID) (*models.FundingPaymentInput, error) {
+// A coin without a "-" or "/" separator is quoted in defaultQuote
+func transformFundingPayment(apiPayment hyperliquidFundingPayment, accountUUID uuid.UUID, defaultQuote string) (*models.FundingPaymentInput, error) {
 	// Parse timestamp (Hyperliquid returns Unix timestamp in milliseconds)
 	timestamp := parseTimestamp(apiPayment.Time)
 	if timestamp.IsZero() {
@@ -436,7 +823,7 @@ func transformFundingPayment(apiPayment hyperliquidFundingPayment, accountUUID u
 	amount := convertToString(apiPayment.Delta.USDC)
 
 	// Extract base and quote assets from coin (e.g., "SOL" -> base="SOL", quote="USDC")
-	baseAsset, quoteAsset := parseAssetPair(apiPayment.Delta.Coin)
+	baseAsset, quoteAsset := iface.SplitPair(apiPayment.Delta.Coin, defaultQuote)
 	if baseAsset == "" {
 		return nil, fmt.Errorf("missing required field 'coin' (base asset)")
 	}
@@ -468,3 +855,24 @@ func parseRetryAfter(retryAfter string) time.Duration {
 	}
 	return time.Duration(seconds) * time.Second
 }
+
+// Probe checks that the Hyperliquid API is reachable by loading the perp metadata, which needs
+// no account and is small. Implements iface.Prober
+func (c *Client) Probe(ctx context.Context) error {
+	body, err := c.postInfo(ctx, map[string]interface{}{"type": "meta"}, "metadata")
+	if err != nil {
+		return err
+	}
+
+	var meta struct {
+		Universe []json.RawMessage `json:"universe"`
+	}
+	if err := json.Unmarshal(body, &meta); err != nil {
+		return fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	if len(meta.Universe) == 0 {
+		return fmt.Errorf("metadata lists no markets")
+	}
+
+	return nil
+}
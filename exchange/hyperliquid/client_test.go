@@ -3,8 +3,14 @@ package hyperliquid
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -118,6 +124,209 @@ func TestHyperliquidClient_FetchTrades_Success(t *testing.T) {
 	}
 }
 
+func TestHyperliquidClient_FetchTrades_IncludesSpotFills(t *testing.T) {
+	now := time.Now().UnixMilli()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch reqBody["type"] {
+		case "spotMeta":
+			w.Write([]byte(`{
+				"tokens": [{"name": "USDC", "index": 0}, {"name": "HYPE", "index": 150}],
+				"universe": [{"name": "@107", "tokens": [150, 0], "index": 107}]
+			}`))
+		case "userFillsByTime":
+			// Perp and spot fills come back interleaved from the same endpoint
+			response := []hyperliquidFill{
+				{Tid: 1, Oid: 10, Coin: "BTC", Side: "B", Px: "50000", Sz: "0.1", Fee: "1", Time: now - 3000},
+				{Tid: 2, Oid: 20, Coin: "@107", Side: "S", Px: "25.5", Sz: "10", Fee: "0.1", Time: now - 2000},
+				{Tid: 3, Oid: 30, Coin: "PURR/USDC", Side: "B", Px: "0.2", Sz: "100", Fee: "0.01", Time: now - 1000},
+			}
+			json.NewEncoder(w).Encode(response)
+		default:
+			t.Errorf("Unexpected request type %v", reqBody["type"])
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	account := &models.ExchangeAccount{
+		ID:                uuid.New().String(),
+		AccountIdentifier: "0x1234567890123456789012345678901234567890",
+	}
+
+	trades, err := client.FetchTrades(context.Background(), account, time.Time{})
+	if err != nil {
+		t.Fatalf("FetchTrades failed: %v", err)
+	}
+
+	if len(trades) != 3 {
+		t.Fatalf("Expected 3 trades, got %d", len(trades))
+	}
+
+	expected := []struct {
+		tradeID    string
+		base       string
+		quote      string
+		marketType string
+	}{
+		{"1", "BTC", "USDC", models.MarketTypePerp},
+		{"2", "HYPE", "USDC", models.MarketTypeSpot},
+		{"3", "PURR", "USDC", models.MarketTypeSpot},
+	}
+
+	for i, want := range expected {
+		got := trades[i]
+		if got.TradeID != want.tradeID || got.BaseAsset != want.base || got.QuoteAsset != want.quote || got.MarketType != want.marketType {
+			t.Errorf("Trade %d: expected %s %s/%s (%s), got %s %s/%s (%s)",
+				i, want.tradeID, want.base, want.quote, want.marketType,
+				got.TradeID, got.BaseAsset, got.QuoteAsset, got.MarketType)
+		}
+	}
+}
+
+func TestHyperliquidClient_FetchRecentTrades(t *testing.T) {
+	now := time.Now().UnixMilli()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		if reqBody["type"] != "userFills" {
+			t.Errorf("Expected type 'userFills', got '%v'", reqBody["type"])
+		}
+		if _, ok := reqBody["startTime"]; ok {
+			t.Error("Expected no 'startTime' parameter for recent fills")
+		}
+
+		response := []hyperliquidFill{
+			{Tid: 2, Oid: 20, Coin: "BTC", Side: "B", Px: "50000", Sz: "0.1", Fee: "1", Time: now - 2000},
+			{Tid: 3, Oid: 30, Coin: "ETH", Side: "S", Px: "3000", Sz: "1", Fee: "1", Time: now - 1000},
+			{Tid: 1, Oid: 10, Coin: "SOL", Side: "B", Px: "100", Sz: "5", Fee: "1", Time: now - 3000},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	account := &models.ExchangeAccount{
+		ID:                uuid.New().String(),
+		AccountIdentifier: "0x1234567890123456789012345678901234567890",
+	}
+
+	trades, err := client.FetchRecentTrades(context.Background(), account, 2)
+	if err != nil {
+		t.Fatalf("FetchRecentTrades failed: %v", err)
+	}
+
+	if len(trades) != 2 {
+		t.Fatalf("Expected 2 trades (capped by limit), got %d", len(trades))
+	}
+	if trades[0].TradeID != "3" || trades[1].TradeID != "2" {
+		t.Errorf("Expected newest-first trades [3 2], got [%s %s]", trades[0].TradeID, trades[1].TradeID)
+	}
+
+	if _, err := client.FetchRecentTrades(context.Background(), account, 0); err == nil {
+		t.Error("Expected error for non-positive limit")
+	}
+}
+
+func TestHyperliquidClient_FetchTrades_PageLimitAborts(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// Always return a full page so the client keeps paginating
+		base := time.Now().UnixMilli() - 1_000_000 + int64(requests)*3000
+		response := make([]hyperliquidFill, 2000)
+		for i := range response {
+			response[i] = hyperliquidFill{
+				Tid: requests*10000 + i, Oid: 1, Coin: "BTC", Side: "B",
+				Px: "1", Sz: "1", Fee: "0", Time: base + int64(i),
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	var consulted []int
+	client := NewClient(WithExchangeOptions(iface.WithPageLimit(2, func(ctx context.Context, exchange string, pages int) bool {
+		consulted = append(consulted, pages)
+		return false
+	})))
+	client.baseURL = server.URL
+
+	account := &models.ExchangeAccount{
+		ID:                uuid.New().String(),
+		AccountIdentifier: "0x1234567890123456789012345678901234567890",
+	}
+
+	_, err := client.FetchTrades(context.Background(), account, time.Time{})
+	if !errors.Is(err, iface.ErrFetchAborted) {
+		t.Fatalf("Expected ErrFetchAborted, got: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected fetch to stop after 2 pages, got %d requests", requests)
+	}
+	if len(consulted) != 1 || consulted[0] != 2 {
+		t.Errorf("Expected OnPageLimit to be consulted once at 2 pages, got %v", consulted)
+	}
+}
+
+func TestHyperliquidClient_FetchTrades_RawCapture(t *testing.T) {
+	now := time.Now().UnixMilli()
+	rawFills := []string{
+		fmt.Sprintf(`{"coin":"BTC","px":"50000","sz":"0.1","side":"B","time":%d,"hash":"0x1","tid":1,"oid":10,"fee":"1"}`, now-2000),
+		fmt.Sprintf(`{"coin":"ETH","px":"3000","sz":"1","side":"S","time":%d,"hash":"0x2","oid":20,"fee":"1"}`, now-1000), // missing tid
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[" + strings.Join(rawFills, ",") + "]"))
+	}))
+	defer server.Close()
+
+	var captured []string
+	client := NewClient(WithRawCapture(func(raw json.RawMessage) {
+		captured = append(captured, string(raw))
+	}))
+	client.baseURL = server.URL
+
+	account := &models.ExchangeAccount{
+		ID:                uuid.New().String(),
+		AccountIdentifier: "0x1234567890123456789012345678901234567890",
+	}
+
+	// The second fill fails to transform, but its payload must already have been captured
+	_, err := client.FetchTrades(context.Background(), account, time.Time{})
+	if err == nil {
+		t.Fatal("Expected transform error for fill without tid")
+	}
+
+	if len(captured) != len(rawFills) {
+		t.Fatalf("Expected %d captured fills, got %d", len(rawFills), len(captured))
+	}
+	for i, raw := range rawFills {
+		if captured[i] != raw {
+			t.Errorf("Captured fill %d = %s, want %s", i, captured[i], raw)
+		}
+	}
+}
+
 func TestHyperliquidClient_FetchTrades_RateLimit(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Retry-After", "60")
@@ -160,9 +369,9 @@ func TestHyperliquidClient_FetchTrades_RateLimit(t *testing.T) {
 }
 
 func TestHyperliquidClient_FetchTrades_ContextCancellation(t *testing.T) {
-	// Server that delays response
+	// Server that only answers once the request is abandoned
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(2 * time.Second)
+		<-r.Context().Done()
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode([]hyperliquidFill{})
 	}))
@@ -292,3 +501,441 @@ func TestHyperliquidClient_Contract(t *testing.T) {
 		}
 	})
 }
+
+func TestHyperliquidClient_FetchTrades_RequestBody(t *testing.T) {
+	const address = "0x1234567890123456789012345678901234567890"
+	since := time.UnixMilli(1700000000000)
+
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]interface{}
+	}{
+		{
+			name: "default userFillsByTime",
+			want: map[string]interface{}{"type": "userFillsByTime", "user": address, "startTime": float64(since.UnixMilli())},
+		},
+		{
+			name: "userFillsByTime aggregated",
+			opts: []Option{WithAggregateByTime(true)},
+			want: map[string]interface{}{"type": "userFillsByTime", "user": address, "startTime": float64(since.UnixMilli()), "aggregateByTime": true},
+		},
+		{
+			name: "userFills",
+			opts: []Option{WithFillsEndpoint(FillsRecent)},
+			want: map[string]interface{}{"type": "userFills", "user": address},
+		},
+		{
+			name: "userFills aggregated",
+			opts: []Option{WithFillsEndpoint(FillsRecent), WithAggregateByTime(true)},
+			want: map[string]interface{}{"type": "userFills", "user": address, "aggregateByTime": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var reqBody map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+					t.Fatalf("Failed to decode request: %v", err)
+				}
+				bodies = append(bodies, reqBody)
+				fmt.Fprintf(w, `[{"coin": "BTC", "px": "50000", "sz": "0.1", "side": "B", "time": %d, "hash": "0x1", "tid": 1, "oid": 1, "fee": "0.5"}]`, since.UnixMilli()+1)
+			}))
+			defer server.Close()
+
+			client := NewClient(tt.opts...)
+			client.baseURL = server.URL
+
+			account := &models.ExchangeAccount{ID: uuid.New().String(), AccountIdentifier: address}
+			trades, err := client.FetchTrades(context.Background(), account, since)
+			if err != nil {
+				t.Fatalf("FetchTrades failed: %v", err)
+			}
+
+			if len(bodies) != 1 {
+				t.Fatalf("Expected 1 request, got %d", len(bodies))
+			}
+			if !reflect.DeepEqual(bodies[0], tt.want) {
+				t.Errorf("Expected request body %v, got %v", tt.want, bodies[0])
+			}
+			if len(trades) != 1 {
+				t.Errorf("Expected 1 trade, got %d", len(trades))
+			}
+		})
+	}
+}
+
+// pagedFillsServer emulates userFillsByTime: fills at or after startTime, oldest first, at most 2000
+func pagedFillsServer(t *testing.T, fills []hyperliquidFill) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody struct {
+			StartTime int64 `json:"startTime"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		page := make([]hyperliquidFill, 0, 2000)
+		for _, fill := range fills {
+			if fill.Time.(int64) >= reqBody.StartTime && len(page) < 2000 {
+				page = append(page, fill)
+			}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+}
+
+func TestHyperliquidClient_FetchTrades_SameMillisecondPages(t *testing.T) {
+	const t0, t1, t2 = int64(1700000000000), int64(1700000000001), int64(1700000000002)
+
+	tests := []struct {
+		name   string
+		counts map[int64]int // Fills per millisecond
+		want   int
+	}{
+		{
+			name:   "page ends inside a millisecond",
+			counts: map[int64]int{t0: 500, t1: 1800, t2: 300},
+			want:   2600,
+		},
+		{
+			name:   "millisecond filling a page",
+			counts: map[int64]int{t0: 100, t1: 1999, t2: 300},
+			want:   2399,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fills []hyperliquidFill
+			tid := 0
+			for _, ms := range []int64{t0, t1, t2} {
+				for i := 0; i < tt.counts[ms]; i++ {
+					tid++
+					fills = append(fills, hyperliquidFill{
+						Coin: "BTC", Px: "50000", Sz: "0.01", Side: "B", Time: ms,
+						Hash: "0x1", Tid: tid, Oid: tid, Fee: "0.01",
+					})
+				}
+			}
+
+			server := pagedFillsServer(t, fills)
+			defer server.Close()
+
+			client := NewClient()
+			client.baseURL = server.URL
+
+			account := &models.ExchangeAccount{ID: uuid.New().String(), AccountIdentifier: "0x1234567890123456789012345678901234567890"}
+			trades, err := client.FetchTrades(context.Background(), account, time.Time{})
+			if err != nil {
+				t.Fatalf("FetchTrades failed: %v", err)
+			}
+
+			seen := make(map[string]bool, len(trades))
+			for _, trade := range trades {
+				if seen[trade.TradeID] {
+					t.Fatalf("Duplicate trade %s", trade.TradeID)
+				}
+				seen[trade.TradeID] = true
+			}
+			if len(trades) != tt.want {
+				t.Errorf("Expected %d trades, got %d", tt.want, len(trades))
+			}
+		})
+	}
+}
+
+func TestHyperliquidClient_FetchTrades_MillisecondLargerThanPage(t *testing.T) {
+	// The API cannot page past 2000 fills in one millisecond; the fetch must fail rather than drop fills
+	const t0, t1 = int64(1700000000000), int64(1700000000001)
+
+	var fills []hyperliquidFill
+	for tid := 1; tid <= 2100; tid++ {
+		ms := t1
+		if tid <= 100 {
+			ms = t0
+		}
+		fills = append(fills, hyperliquidFill{
+			Coin: "BTC", Px: "50000", Sz: "0.01", Side: "B", Time: ms,
+			Hash: "0x1", Tid: tid, Oid: tid, Fee: "0.01",
+		})
+	}
+
+	server := pagedFillsServer(t, fills)
+	defer server.Close()
+
+	client := NewClient()
+	client.baseURL = server.URL
+
+	account := &models.ExchangeAccount{ID: uuid.New().String(), AccountIdentifier: "0x1234567890123456789012345678901234567890"}
+	trades, err := client.FetchTrades(context.Background(), account, time.Time{})
+	if !errors.Is(err, iface.ErrPageSaturated) {
+		t.Fatalf("Expected ErrPageSaturated, got %v (%d trades)", err, len(trades))
+	}
+	if trades != nil {
+		t.Errorf("Expected no partial result, got %d trades", len(trades))
+	}
+}
+
+func TestHyperliquidClient_SortTrades(t *testing.T) {
+	base := time.UnixMilli(1700000000000)
+	newestFirst := func() []*models.TradeInput {
+		return []*models.TradeInput{
+			{TradeID: "2", Timestamp: base.Add(time.Second)},
+			{TradeID: "1", Timestamp: base},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		endpoint  FillsEndpoint
+		wantFirst string
+	}{
+		{name: "sorts by default", endpoint: FillsByTime, wantFirst: "1"},
+		{name: "skips for userFillsByTime", opts: []Option{WithSkipRedundantSort(true)}, endpoint: FillsByTime, wantFirst: "2"},
+		{name: "always sorts userFills", opts: []Option{WithSkipRedundantSort(true)}, endpoint: FillsRecent, wantFirst: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trades := newestFirst()
+			NewClient(tt.opts...).sortTrades(tt.endpoint, trades)
+			if trades[0].TradeID != tt.wantFirst {
+				t.Errorf("Expected trade %s first, got %s", tt.wantFirst, trades[0].TradeID)
+			}
+		})
+	}
+}
+
+func BenchmarkHyperliquidClient_SortTrades(b *testing.B) {
+	const n = 200000
+	base := time.UnixMilli(1700000000000)
+	trades := make([]*models.TradeInput, n)
+	for i := range trades {
+		trades[i] = &models.TradeInput{TradeID: strconv.Itoa(i), Timestamp: base.Add(time.Duration(i) * time.Millisecond)}
+	}
+
+	for _, bc := range []struct {
+		name string
+		skip bool
+	}{{"sort", false}, {"skip", true}} {
+		b.Run(bc.name, func(b *testing.B) {
+			client := NewClient(WithSkipRedundantSort(bc.skip))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				client.sortTrades(FillsByTime, trades)
+			}
+		})
+	}
+}
+
+func TestHyperliquidClient_InvalidAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no request for an invalid address")
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.baseURL = server.URL
+
+	account := &models.ExchangeAccount{ID: uuid.New().String(), AccountIdentifier: "0xinvalid"}
+	_, err := client.FetchTrades(context.Background(), account, time.Time{})
+
+	var invalid *iface.InvalidAccountError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Expected *iface.InvalidAccountError, got %v", err)
+	}
+}
+
+func TestHyperliquidClient_FetchTrades_DefaultQuote(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		coin      string
+		wantQuote string
+	}{
+		{name: "default", coin: "BTC", wantQuote: "USDC"},
+		{name: "overridden", opts: []Option{WithDefaultQuote("USD")}, coin: "BTC", wantQuote: "USD"},
+		{name: "unknown single token", opts: []Option{WithDefaultQuote("USDT")}, coin: "NEWCOIN", wantQuote: "USDT"},
+		{name: "explicit quote wins", opts: []Option{WithDefaultQuote("USD")}, coin: "ETH-USDT", wantQuote: "USDT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := pagedFillsServer(t, []hyperliquidFill{{
+				Coin: tt.coin, Px: "1", Sz: "1", Side: "B", Time: int64(1700000000000),
+				Hash: "0x1", Tid: 1, Oid: 1, Fee: "0",
+			}})
+			defer server.Close()
+
+			client := NewClient(tt.opts...)
+			client.baseURL = server.URL
+
+			account := &models.ExchangeAccount{ID: uuid.New().String(), AccountIdentifier: "0x1234567890123456789012345678901234567890"}
+			trades, err := client.FetchTrades(context.Background(), account, time.Time{})
+			if err != nil {
+				t.Fatalf("FetchTrades failed: %v", err)
+			}
+			if len(trades) != 1 {
+				t.Fatalf("Expected 1 trade, got %d", len(trades))
+			}
+			if trades[0].QuoteAsset != tt.wantQuote {
+				t.Errorf("Expected quote %s, got %s", tt.wantQuote, trades[0].QuoteAsset)
+			}
+		})
+	}
+}
+
+func TestHyperliquidClient_FetchTrades_MaxPagesStopsRunawayPagination(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// A pathological response: a full page of new fills at one timestamp whatever startTime is sent
+		response := make([]hyperliquidFill, 2000)
+		for i := range response {
+			response[i] = hyperliquidFill{
+				Tid: requests*2000 + i + 1, Oid: 1, Coin: "BTC", Side: "B",
+				Px: "1", Sz: "1", Fee: "0", Time: int64(1700000000000),
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithMaxPages(5))
+	client.baseURL = server.URL
+
+	account := &models.ExchangeAccount{
+		ID:                uuid.New().String(),
+		AccountIdentifier: "0x1234567890123456789012345678901234567890",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := client.FetchTrades(ctx, account, time.Time{})
+	if !errors.Is(err, iface.ErrTooManyPages) {
+		t.Fatalf("Expected ErrTooManyPages, got: %v", err)
+	}
+	if requests != 5 {
+		t.Errorf("Expected fetch to stop at the 5 page cap, got %d requests", requests)
+	}
+}
+
+func TestHyperliquidClient_PageCapDefault(t *testing.T) {
+	if got := NewClient().pageCap(); got != DefaultMaxPages {
+		t.Errorf("Expected default page cap %d, got %d", DefaultMaxPages, got)
+	}
+}
+
+// TestHyperliquidClient_FetchTrades_Concurrent shares one client across 50 goroutines, each
+// paging through fills and resolving spot pairs; run with -race to check the client holds no
+// unguarded shared state
+func TestHyperliquidClient_FetchTrades_Concurrent(t *testing.T) {
+	now := time.Now().UnixMilli()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch reqBody["type"] {
+		case "spotMeta":
+			w.Write([]byte(`{
+				"tokens": [{"name": "USDC", "index": 0}, {"name": "HYPE", "index": 150}],
+				"universe": [{"name": "@107", "tokens": [150, 0], "index": 107}]
+			}`))
+		case "userFillsByTime":
+			json.NewEncoder(w).Encode([]hyperliquidFill{
+				{Tid: 1, Oid: 10, Coin: "BTC", Side: "B", Px: "50000", Sz: "0.1", Fee: "1", Time: now - 2000},
+				{Tid: 2, Oid: 20, Coin: "@107", Side: "S", Px: "25.5", Sz: "10", Fee: "0.1", Time: now - 1000},
+			})
+		default:
+			t.Errorf("Unexpected request type %v", reqBody["type"])
+		}
+	}))
+	defer server.Close()
+
+	var captured sync.Map
+	client := NewClient(WithRawCapture(func(raw json.RawMessage) { captured.Store(string(raw), true) }))
+	client.baseURL = server.URL
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			account := &models.ExchangeAccount{
+				ID:                uuid.New().String(),
+				AccountIdentifier: "0x1234567890123456789012345678901234567890",
+			}
+			trades, err := client.FetchTrades(context.Background(), account, time.Time{})
+			if err != nil {
+				t.Errorf("FetchTrades failed: %v", err)
+				return
+			}
+			if len(trades) != 2 || trades[1].BaseAsset != "HYPE" {
+				t.Errorf("Expected BTC and HYPE trades, got %d trades", len(trades))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTransformFill_Crossed(t *testing.T) {
+	client := NewClient()
+	fills, err := client.decodeFills([]byte(`[
+		{"coin": "BTC", "px": "50000", "sz": "0.1", "side": "B", "time": 1700000000000, "hash": "0x1", "tid": 1, "oid": 10, "fee": "1", "crossed": true},
+		{"coin": "BTC", "px": "50000", "sz": "0.1", "side": "S", "time": 1700000000001, "hash": "0x2", "tid": 2, "oid": 11, "fee": "-0.2", "crossed": false},
+		{"coin": "BTC", "px": "50000", "sz": "0.1", "side": "B", "time": 1700000000002, "hash": "0x3", "tid": 3, "oid": 12, "fee": "1"}
+	]`))
+	if err != nil {
+		t.Fatalf("decodeFills failed: %v", err)
+	}
+
+	want := []*bool{boolPtr(true), boolPtr(false), nil}
+	for i, fill := range fills {
+		trade, err := transformFill(fill, uuid.New(), defaultQuoteAsset, DefaultFloatEpsilon)
+		if err != nil {
+			t.Fatalf("transformFill %d failed: %v", i, err)
+		}
+		switch {
+		case want[i] == nil && trade.IsTaker != nil:
+			t.Errorf("Fill %d: expected IsTaker to be unknown without crossed, got %v", i, *trade.IsTaker)
+		case want[i] != nil && (trade.IsTaker == nil || *trade.IsTaker != *want[i]):
+			t.Errorf("Fill %d: expected IsTaker %v, got %v", i, *want[i], trade.IsTaker)
+		}
+	}
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestTransformFill_WithoutOrderIDOrFee(t *testing.T) {
+	client := NewClient()
+	fills, err := client.decodeFills([]byte(`[
+		{"coin": "BTC", "px": "50000", "sz": "0.1", "side": "B", "time": 1700000000000, "hash": "0x1", "tid": 7}
+	]`))
+	if err != nil {
+		t.Fatalf("decodeFills failed: %v", err)
+	}
+
+	trade, err := transformFill(fills[0], uuid.New(), defaultQuoteAsset, DefaultFloatEpsilon)
+	if err != nil {
+		t.Fatalf("Expected a fill without oid or fee to be accepted, got %v", err)
+	}
+	if trade.OrderID != "" {
+		t.Errorf("Expected an empty order ID, got %q", trade.OrderID)
+	}
+	if trade.Fee != models.DefaultTradeFee {
+		t.Errorf("Expected fee %q, got %q", models.DefaultTradeFee, trade.Fee)
+	}
+	if trade.TradeID != "7" {
+		t.Errorf("Expected trade ID 7, got %s", trade.TradeID)
+	}
+}
@@ -169,9 +169,9 @@ func TestHyperliquidClient_FetchFundingPayments_RateLimit(t *testing.T) {
 }
 
 func TestHyperliquidClient_FetchFundingPayments_ContextCancellation(t *testing.T) {
-	// Server that delays response
+	// Server that only answers once the request is abandoned
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(2 * time.Second)
+		<-r.Context().Done()
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode([]hyperliquidFundingPayment{})
 	}))
@@ -418,3 +418,35 @@ func TestHyperliquidClient_FetchFundingPayments_AssetParsing(t *testing.T) {
 		t.Errorf("Expected quote asset 'USDT', got '%s'", payments[1].QuoteAsset)
 	}
 }
+
+func TestHyperliquidClient_FetchFundingPayments_ConfiguredDefaultQuote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := []hyperliquidFundingPayment{{Hash: "0x123", Time: time.Now().UnixMilli()}}
+		response[0].Delta.Type = "funding"
+		response[0].Delta.Coin = "BTC"
+		response[0].Delta.USDC = "1.0"
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithExchangeOptions(iface.WithDefaultQuote("USD")))
+	client.baseURL = server.URL
+
+	account := &models.ExchangeAccount{
+		ID:                uuid.New().String(),
+		AccountIdentifier: "0x1234567890123456789012345678901234567890",
+	}
+
+	payments, err := client.FetchFundingPayments(context.Background(), account, time.Time{})
+	if err != nil {
+		t.Fatalf("FetchFundingPayments failed: %v", err)
+	}
+
+	if len(payments) != 1 {
+		t.Fatalf("Expected 1 payment, got %d", len(payments))
+	}
+	if payments[0].QuoteAsset != "USD" {
+		t.Errorf("Expected configured quote asset 'USD', got '%s'", payments[0].QuoteAsset)
+	}
+}
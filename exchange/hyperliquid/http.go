@@ -0,0 +1,256 @@
+package hyperliquid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zif-terminal/lib/clock"
+	"github.com/zif-terminal/lib/exchange/iface"
+)
+
+// Default retry policy for temporary errors (see WithRetry)
+const (
+	defaultMaxAttempts  = 3
+	defaultRetryBackoff = 500 * time.Millisecond
+)
+
+// defaultRetryableStatuses are the HTTP statuses retried unless WithRetryableStatuses is used
+var defaultRetryableStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// bodyExcerptLength caps the response body quoted in a TemporaryError
+const bodyExcerptLength = 200
+
+// WithRetry sets how many attempts an /info request gets when the response is a temporary
+// error (e.g. an HTML challenge page) and the initial backoff, which doubles per attempt
+func WithRetry(maxAttempts int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts = maxAttempts
+		c.retryBackoff = backoff
+	}
+}
+
+// WithClock replaces the clock used for retry backoff and request timestamps, so tests can
+// advance time instead of sleeping
+func WithClock(clock clock.Clock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// WithRetryableStatuses sets the HTTP statuses that are retried per the retry policy
+// (default 429, 500, 502, 503 and 504); an empty list disables retrying on status codes
+// A 429 carrying Retry-After is never retried here, so the caller can honor the delay
+func WithRetryableStatuses(statuses []int) Option {
+	return func(c *Client) {
+		c.retryableStatuses = make(map[int]bool, len(statuses))
+		for _, status := range statuses {
+			c.retryableStatuses[status] = true
+		}
+	}
+}
+
+// WithMaintenanceDetector replaces the check that recognizes maintenance responses, which are
+// returned as *iface.MaintenanceError instead of being retried. The default matches upgrade and
+// maintenance notices in non-data responses; pass a detector returning false to disable detection
+func WithMaintenanceDetector(detector iface.MaintenanceDetector) Option {
+	return func(c *Client) {
+		c.maintenanceDetector = detector
+	}
+}
+
+// maintenancePhrases are lowercase fragments of the notices served while the API is down
+var maintenancePhrases = []string{
+	"currently upgrading",
+	"upgrade in progress",
+	"under maintenance",
+	"maintenance mode",
+	"scheduled maintenance",
+}
+
+// maintenanceSniffLength caps how much of a response body defaultMaintenanceDetector inspects
+const maintenanceSniffLength = 1024
+
+// defaultMaintenanceDetector matches maintenance notices at the start of a response body
+// Successful responses holding a JSON array are data (fills, funding), so they never match
+func defaultMaintenanceDetector(status int, body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	if status == http.StatusOK && len(trimmed) > 0 && trimmed[0] == '[' {
+		return false
+	}
+	if len(trimmed) > maintenanceSniffLength {
+		trimmed = trimmed[:maintenanceSniffLength]
+	}
+	text := strings.ToLower(string(trimmed))
+	for _, phrase := range maintenancePhrases {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMaintenance reports whether a response is a maintenance notice
+func (c *Client) isMaintenance(status int, body []byte) bool {
+	if c.maintenanceDetector != nil {
+		return c.maintenanceDetector(status, body)
+	}
+	return defaultMaintenanceDetector(status, body)
+}
+
+// isRetryableStatus reports whether responses with status are retried
+func (c *Client) isRetryableStatus(status int) bool {
+	if c.retryableStatuses == nil {
+		for _, retryable := range defaultRetryableStatuses {
+			if status == retryable {
+				return true
+			}
+		}
+		return false
+	}
+	return c.retryableStatuses[status]
+}
+
+// shouldRetry reports whether a failed /info attempt may be repeated
+func (c *Client) shouldRetry(err error) bool {
+	var rateLimit *iface.RateLimitError
+	if errors.As(err, &rateLimit) {
+		return rateLimit.RetryAfter == 0 && c.isRetryableStatus(http.StatusTooManyRequests)
+	}
+	return iface.IsTemporaryError(err)
+}
+
+// postInfo posts requestBody to the /info endpoint and returns the raw JSON response
+// Temporary errors and retryable statuses are retried per the retry policy; rate limits with
+// Retry-After and maintenance responses are returned as *iface.RateLimitError and
+// *iface.MaintenanceError without retrying so the caller can honor the wait
+func (c *Client) postInfo(ctx context.Context, requestBody map[string]interface{}, what string) ([]byte, error) {
+	bodyBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	maxAttempts := c.maxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	backoff := c.retryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		body, err := c.postInfoOnce(ctx, bodyBytes, what)
+		if err == nil || !c.shouldRetry(err) || attempt >= maxAttempts {
+			return body, err
+		}
+
+		if err := clock.Wait(ctx, clock.OrReal(c.clock), backoff); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+// postInfoOnce makes a single /info request and classifies the response
+func (c *Client) postInfoOnce(ctx context.Context, bodyBytes []byte, what string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/info", bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.options.ApplyHeaders(req.Header)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", what, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", what, err)
+	}
+
+	if c.isMaintenance(resp.StatusCode, body) {
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		if retryAfter <= 0 {
+			retryAfter = iface.DefaultMaintenanceRetryAfter
+		}
+		return nil, &iface.MaintenanceError{
+			Exchange:   "hyperliquid",
+			Message:    excerpt(body),
+			RetryAfter: retryAfter,
+		}
+	}
+
+	// Check for rate limit (HTTP 429)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &iface.RateLimitError{
+			Exchange:   "hyperliquid",
+			Message:    "rate limit exceeded",
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	// Check for other HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		if c.isRetryableStatus(resp.StatusCode) {
+			return nil, &iface.TemporaryError{
+				Exchange: "hyperliquid",
+				Message:  fmt.Sprintf("API returned status %d: %s", resp.StatusCode, resp.Status),
+			}
+		}
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	if err := checkJSONResponse(resp.Header.Get("Content-Type"), body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// checkJSONResponse rejects 200 responses that cannot be the API's JSON: empty bodies and
+// HTML pages (Cloudflare serves challenge pages with status 200 to some IP ranges)
+// Content types other than HTML are accepted, since plain-text JSON decodes fine
+func checkJSONResponse(contentType string, body []byte) error {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return &iface.TemporaryError{Exchange: "hyperliquid", Message: "empty response body"}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if strings.Contains(mediaType, "html") || trimmed[0] == '<' {
+		return &iface.TemporaryError{
+			Exchange: "hyperliquid",
+			Message:  fmt.Sprintf("unexpected non-JSON response (content type %q)", contentType),
+			Body:     excerpt(trimmed),
+		}
+	}
+
+	return nil
+}
+
+// excerpt returns the start of body with whitespace collapsed, for error messages
+func excerpt(body []byte) string {
+	text := strings.Join(strings.Fields(string(body)), " ")
+	if len(text) > bodyExcerptLength {
+		return text[:bodyExcerptLength] + "..."
+	}
+	return text
+}
@@ -0,0 +1,356 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/clock"
+	"github.com/zif-terminal/lib/exchange/iface"
+	"github.com/zif-terminal/lib/models"
+)
+
+const challengePage = `<!DOCTYPE html>
+<html><head><title>Just a moment...</title></head>
+<body>Checking your browser before accessing api.hyperliquid.xyz</body></html>`
+
+func testHTTPAccount() *models.ExchangeAccount {
+	return &models.ExchangeAccount{
+		ID:                uuid.New().String(),
+		AccountIdentifier: "0x1234567890123456789012345678901234567890",
+	}
+}
+
+func TestHyperliquidClient_HTMLResponseIsTemporary(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		w.Write([]byte(challengePage))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithRetry(3, time.Millisecond))
+	client.baseURL = server.URL
+
+	_, err := client.FetchFundingPayments(context.Background(), testHTTPAccount(), time.Time{})
+
+	var temporary *iface.TemporaryError
+	if !errors.As(err, &temporary) {
+		t.Fatalf("Expected *iface.TemporaryError, got %T: %v", err, err)
+	}
+	if !strings.HasPrefix(temporary.Body, "<!DOCTYPE html> <html><head><title>Just a moment...") {
+		t.Errorf("Expected trimmed body excerpt, got %q", temporary.Body)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestHyperliquidClient_RetriesTemporaryThenSucceeds(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		switch attempts {
+		case 1:
+			w.Write([]byte(challengePage)) // No content type: detected from the body
+		case 2:
+			w.WriteHeader(http.StatusOK) // Empty body
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithRetry(3, time.Millisecond))
+	client.baseURL = server.URL
+
+	trades, err := client.FetchTrades(context.Background(), testHTTPAccount(), time.Time{})
+	if err != nil {
+		t.Fatalf("FetchTrades failed: %v", err)
+	}
+	if len(trades) != 0 {
+		t.Errorf("Expected no trades, got %d", len(trades))
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestHyperliquidClient_EmptyResponseIsTemporary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithRetry(1, time.Millisecond))
+	client.baseURL = server.URL
+
+	_, err := client.FetchOrders(context.Background(), testHTTPAccount(), time.Time{})
+	if !iface.IsTemporaryError(err) {
+		t.Fatalf("Expected temporary error, got %v", err)
+	}
+}
+
+func TestHyperliquidClient_RequestHeaders(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []iface.Option
+		wantUserAgent string
+		wantExtra     map[string]string
+	}{
+		{
+			name:          "default user agent",
+			wantUserAgent: iface.DefaultUserAgent(),
+		},
+		{
+			name:          "custom user agent and extra headers",
+			opts:          []iface.Option{iface.WithUserAgent("my-app/1.0"), iface.WithExtraHeaders(map[string]string{"x-integrator-id": "zif"})},
+			wantUserAgent: "my-app/1.0",
+			wantExtra:     map[string]string{"X-Integrator-Id": "zif"},
+		},
+		{
+			name:          "extra headers cannot override content type",
+			opts:          []iface.Option{iface.WithExtraHeaders(map[string]string{"Content-Type": "text/plain"})},
+			wantUserAgent: iface.DefaultUserAgent(),
+			wantExtra:     map[string]string{"Content-Type": "application/json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Clone()
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`[]`))
+			}))
+			defer server.Close()
+
+			client := NewClient(WithExchangeOptions(tt.opts...))
+			client.baseURL = server.URL
+
+			if _, err := client.FetchFundingPayments(context.Background(), testHTTPAccount(), time.Time{}); err != nil {
+				t.Fatalf("FetchFundingPayments failed: %v", err)
+			}
+
+			if ua := got.Get("User-Agent"); ua != tt.wantUserAgent {
+				t.Errorf("Expected User-Agent %q, got %q", tt.wantUserAgent, ua)
+			}
+			for name, want := range tt.wantExtra {
+				if value := got.Get(name); value != want {
+					t.Errorf("Expected %s %q, got %q", name, want, value)
+				}
+			}
+			for _, name := range []string{"X-API-Key", "X-Signature", "Authorization"} {
+				if value := got.Get(name); value != "" {
+					t.Errorf("Expected no %s header by default, got %q", name, value)
+				}
+			}
+		})
+	}
+}
+
+func TestHyperliquidClient_WithRetryableStatuses(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		status       int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "custom code is retried", statuses: []int{418}, status: http.StatusTeapot, wantAttempts: 2},
+		{name: "default code no longer retried", statuses: []int{418}, status: http.StatusServiceUnavailable, wantAttempts: 1, wantErr: true},
+		{name: "default set retries 503", status: http.StatusServiceUnavailable, wantAttempts: 2},
+		{name: "default set does not retry 418", status: http.StatusTeapot, wantAttempts: 1, wantErr: true},
+		{name: "429 without Retry-After is retried", status: http.StatusTooManyRequests, wantAttempts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if attempts == 1 {
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Write([]byte(`[]`))
+			}))
+			defer server.Close()
+
+			opts := []Option{WithRetry(3, time.Millisecond)}
+			if tt.statuses != nil {
+				opts = append(opts, WithRetryableStatuses(tt.statuses))
+			}
+			client := NewClient(opts...)
+			client.baseURL = server.URL
+
+			_, err := client.FetchTrades(context.Background(), testHTTPAccount(), time.Time{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FetchTrades error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.wantAttempts, attempts)
+			}
+		})
+	}
+}
+
+func TestDefaultMaintenanceDetector(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   bool
+	}{
+		{"upgrade notice", http.StatusServiceUnavailable, "Exchange is currently upgrading, please try again later", true},
+		{"json notice", http.StatusOK, `{"error": "API under maintenance"}`, true},
+		{"html notice", http.StatusBadGateway, "<html><body><h1>Scheduled Maintenance</h1></body></html>", true},
+		{"plain 503", http.StatusServiceUnavailable, "Service Unavailable", false},
+		{"challenge page", http.StatusOK, challengePage, false},
+		{"fill data", http.StatusOK, `[{"coin": "BTC", "dir": "under maintenance"}]`, false},
+		{"empty", http.StatusOK, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultMaintenanceDetector(tt.status, []byte(tt.body)); got != tt.want {
+				t.Errorf("defaultMaintenanceDetector(%d, %q) = %v, want %v", tt.status, tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHyperliquidClient_MaintenanceIsNotRetried(t *testing.T) {
+	tests := []struct {
+		name           string
+		retryAfter     string
+		wantRetryAfter time.Duration
+	}{
+		{name: "default wait", wantRetryAfter: iface.DefaultMaintenanceRetryAfter},
+		{name: "retry-after header", retryAfter: "120", wantRetryAfter: 2 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte("Exchange is currently upgrading"))
+			}))
+			defer server.Close()
+
+			client := NewClient(WithRetry(3, time.Millisecond))
+			client.baseURL = server.URL
+
+			_, err := client.FetchFundingPayments(context.Background(), testHTTPAccount(), time.Time{})
+
+			var maintenance *iface.MaintenanceError
+			if !errors.As(err, &maintenance) {
+				t.Fatalf("Expected *iface.MaintenanceError, got %T: %v", err, err)
+			}
+			if maintenance.RetryAfter != tt.wantRetryAfter {
+				t.Errorf("Expected RetryAfter %v, got %v", tt.wantRetryAfter, maintenance.RetryAfter)
+			}
+			if attempts != 1 {
+				t.Errorf("Expected maintenance not to be retried, got %d attempts", attempts)
+			}
+		})
+	}
+}
+
+func TestHyperliquidClient_WithMaintenanceDetector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"status": "paused"}`))
+	}))
+	defer server.Close()
+
+	paused := func(status int, body []byte) bool {
+		return strings.Contains(string(body), `"paused"`)
+	}
+	client := NewClient(WithRetry(1, time.Millisecond), WithMaintenanceDetector(paused))
+	client.baseURL = server.URL
+
+	_, err := client.FetchFundingPayments(context.Background(), testHTTPAccount(), time.Time{})
+	if !iface.IsMaintenanceError(err) {
+		t.Fatalf("Expected the custom detector to report maintenance, got %v", err)
+	}
+}
+
+func TestHyperliquidClient_Probe(t *testing.T) {
+	var requestType string
+	universe := `{"universe": [{"name": "BTC", "szDecimals": 5}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		requestType, _ = body["type"].(string)
+		w.Write([]byte(universe))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.baseURL = server.URL
+
+	if err := iface.Probe(context.Background(), client); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if requestType != "meta" {
+		t.Errorf("Expected a meta request, got %q", requestType)
+	}
+
+	universe = `{"universe": []}`
+	if err := client.Probe(context.Background()); err == nil {
+		t.Error("Expected Probe to fail when no markets are listed")
+	}
+}
+
+func TestHyperliquidClient_RetryBackoffFollowsClock(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	fake := clock.NewFake(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := NewClient(WithRetry(3, time.Minute), WithClock(fake))
+	client.baseURL = server.URL
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.FetchFundingPayments(context.Background(), testHTTPAccount(), time.Time{})
+		done <- err
+	}()
+
+	// The backoff doubles: one minute after the first attempt, two after the second
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	fake.BlockUntil(1)
+	if got := attempts.Load(); got != 2 {
+		t.Fatalf("Expected a retry once the backoff elapsed, got %d attempts", got)
+	}
+	fake.Advance(2 * time.Minute)
+
+	if err := <-done; err != nil {
+		t.Fatalf("Expected the third attempt to succeed, got %v", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
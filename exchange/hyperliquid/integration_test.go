@@ -148,6 +148,10 @@ func TestHyperliquidClient_Integration_Contract(t *testing.T) {
 		},
 		ValidAccount:   validAccount,
 		InvalidAccount: invalidAccount,
+		DefaultQuote:   defaultQuoteAsset,
+		NewClientWithOptions: func(opts ...iface.Option) iface.ExchangeClient {
+			return NewClient(WithExchangeOptions(opts...))
+		},
 	}
 
 	// Run contract tests
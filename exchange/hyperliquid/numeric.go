@@ -0,0 +1,72 @@
+package hyperliquid
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// DefaultFloatEpsilon is the largest absolute error tolerated when a numeric field arrives as a
+// float64 rather than as a json.Number or string
+const DefaultFloatEpsilon = 1e-9
+
+// float64Digits is the number of significant decimal digits a float64 always preserves
+const float64Digits = 15
+
+// WithFloatEpsilon sets the largest absolute error tolerated when formatting a float64 numeric
+// field (default DefaultFloatEpsilon). A negative epsilon disables the check
+func WithFloatEpsilon(epsilon float64) Option {
+	return func(c *Client) {
+		c.floatEpsilon = epsilon
+	}
+}
+
+// floatPrecisionEpsilon returns the configured float epsilon, or DefaultFloatEpsilon
+func (c *Client) floatPrecisionEpsilon() float64 {
+	if c.floatEpsilon != 0 {
+		return c.floatEpsilon
+	}
+	return DefaultFloatEpsilon
+}
+
+// decodeNumbers decodes data into v keeping JSON numbers as json.Number, so their original
+// text survives in interface{} fields as well as json.Number ones
+func decodeNumbers(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// numericString converts a decoded numeric field to its decimal string
+// json.Number and string values pass through byte for byte. A float64 is formatted with
+// FormatFloat after checkFloatPrecision; other values fall back to convertToString
+func numericString(v interface{}, epsilon float64) (string, error) {
+	f, ok := v.(float64)
+	if !ok {
+		return convertToString(v), nil
+	}
+	if err := checkFloatPrecision(f, epsilon); err != nil {
+		return "", err
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), nil
+}
+
+// checkFloatPrecision rejects f when the digits it carries beyond float64's guaranteed precision
+// move it by more than epsilon. Such a value most likely came from a decimal with more significant
+// digits than a float64 holds, so its formatted form would not match what the exchange sent
+func checkFloatPrecision(f float64, epsilon float64) error {
+	if epsilon < 0 || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	reliable, err := strconv.ParseFloat(strconv.FormatFloat(f, 'g', float64Digits, 64), 64)
+	if err != nil {
+		return fmt.Errorf("failed to check float precision: %w", err)
+	}
+	if diff := math.Abs(f - reliable); diff > epsilon {
+		return fmt.Errorf("float %s exceeds float64 precision: digits past the %dth significant one account for %g (epsilon %g)",
+			strconv.FormatFloat(f, 'f', -1, 64), float64Digits, diff, epsilon)
+	}
+	return nil
+}
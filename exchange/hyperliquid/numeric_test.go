@@ -0,0 +1,123 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/models"
+)
+
+func TestClient_FetchTrades_NumericPassthrough(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UnixMilli()
+
+	// px and sz are JSON numbers with more significant digits than a float64 holds
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"coin": "kSHIB", "px": 0.000012345678901234567, "sz": 123456789012.345678, "side": "B",
+			"time": ` + strconv.FormatInt(now, 10) + `, "hash": "0x1", "tid": 900719925474099312, "oid": 42, "fee": 0.000001}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithFillsEndpoint(FillsRecent))
+	client.baseURL = server.URL
+
+	account := &models.ExchangeAccount{
+		ID:                uuid.New().String(),
+		AccountIdentifier: "0x1234567890123456789012345678901234567890",
+	}
+
+	trades, err := client.FetchTrades(ctx, account, time.Time{})
+	if err != nil {
+		t.Fatalf("FetchTrades failed: %v", err)
+	}
+	if len(trades) != 1 {
+		t.Fatalf("Expected 1 trade, got %d", len(trades))
+	}
+
+	trade := trades[0]
+	if trade.Quantity != "123456789012.345678" {
+		t.Errorf("Expected the 18-digit size byte for byte, got %s", trade.Quantity)
+	}
+	if trade.Price != "0.000012345678901234567" {
+		t.Errorf("Expected the price byte for byte, got %s", trade.Price)
+	}
+	if trade.Fee != "0.000001" {
+		t.Errorf("Expected fee 0.000001, got %s", trade.Fee)
+	}
+	if trade.TradeID != "900719925474099312" {
+		t.Errorf("Expected a tid beyond 2^53 to survive, got %s", trade.TradeID)
+	}
+	if trade.OrderID != "42" {
+		t.Errorf("Expected order ID 42, got %s", trade.OrderID)
+	}
+	if !trade.Timestamp.Equal(time.UnixMilli(now)) {
+		t.Errorf("Expected timestamp %d, got %v", now, trade.Timestamp)
+	}
+}
+
+func TestNumericString(t *testing.T) {
+	tenth, fifth := 0.1, 0.2 // Variables, so the sum is computed in float64
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		epsilon float64
+		want    string
+		wantErr bool
+	}{
+		{name: "nil", value: nil, want: ""},
+		{name: "string", value: "123456789012.345678", want: "123456789012.345678"},
+		{name: "json.Number", value: json.Number("123456789012.345678"), want: "123456789012.345678"},
+		{name: "exact float", value: 0.1, epsilon: DefaultFloatEpsilon, want: "0.1"},
+		{name: "large integral float", value: 1e12, epsilon: DefaultFloatEpsilon, want: "1000000000000"},
+		{name: "rounding noise", value: tenth + fifth, epsilon: DefaultFloatEpsilon, want: "0.30000000000000004"},
+		{name: "too many digits", value: 123456789012.345678, epsilon: DefaultFloatEpsilon, wantErr: true},
+		{name: "loose epsilon", value: 123456789012.345678, epsilon: 1e-3, want: "123456789012.34567"},
+		{name: "check disabled", value: 123456789012.345678, epsilon: -1, want: "123456789012.34567"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := numericString(tt.value, tt.epsilon)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "exceeds float64 precision") {
+					t.Fatalf("Expected a precision error, got %q, %v", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("numericString failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("numericString(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformFill_FloatPrecisionGuard(t *testing.T) {
+	fill := hyperliquidFill{
+		Coin: "BTC", Px: "50000", Sz: "0.1", Fee: "1", Side: "B",
+		Time: float64(1700000000000), Hash: "0x1", Tid: 123456789012.345678, Oid: float64(7),
+	}
+
+	if _, err := transformFill(fill, uuid.New(), defaultQuoteAsset, DefaultFloatEpsilon); err == nil {
+		t.Fatal("Expected the imprecise float tid to be rejected")
+	}
+
+	client := NewClient(WithFloatEpsilon(-1))
+	trade, err := transformFill(fill, uuid.New(), defaultQuoteAsset, client.floatPrecisionEpsilon())
+	if err != nil {
+		t.Fatalf("Expected a disabled guard to accept the fill, got %v", err)
+	}
+	if trade.OrderID != "7" {
+		t.Errorf("Expected order ID 7, got %s", trade.OrderID)
+	}
+}
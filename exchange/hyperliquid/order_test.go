@@ -0,0 +1,76 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/exchange/iface"
+	"github.com/zif-terminal/lib/models"
+)
+
+func TestClient_FetchOrders(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UnixMilli()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		switch reqBody["type"] {
+		case "historicalOrders":
+			// Newest first, as the API returns them; the oldest order predates since
+			w.Write([]byte(`[
+				{"order": {"coin": "@107", "side": "B", "limitPx": "25.1", "origSz": "4", "sz": "0", "oid": 303, "timestamp": ` + strconv.FormatInt(now-1000, 10) + `}, "status": "filled", "statusTimestamp": ` + strconv.FormatInt(now-900, 10) + `},
+				{"order": {"coin": "BTC", "side": "A", "limitPx": "65000.5", "origSz": "0.1", "sz": "0.1", "oid": 202, "timestamp": ` + strconv.FormatInt(now-5000, 10) + `}, "status": "canceled", "statusTimestamp": ` + strconv.FormatInt(now-4000, 10) + `},
+				{"order": {"coin": "ETH", "side": "B", "limitPx": "3000", "origSz": "1", "sz": "0", "oid": 101, "timestamp": ` + strconv.FormatInt(now-3600000, 10) + `}, "status": "filled", "statusTimestamp": ` + strconv.FormatInt(now-3600000, 10) + `}
+			]`))
+		case "spotMeta":
+			w.Write([]byte(`{"tokens": [{"name": "USDC", "index": 0}, {"name": "HYPE", "index": 150}], "universe": [{"name": "@107", "tokens": [150, 0], "index": 107}]}`))
+		default:
+			t.Errorf("Unexpected request type %v", reqBody["type"])
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.baseURL = server.URL
+
+	accountID := uuid.New()
+	account := &models.ExchangeAccount{
+		ID:                accountID.String(),
+		AccountIdentifier: "0x1234567890123456789012345678901234567890",
+	}
+
+	orders, err := iface.FetchOrders(ctx, client, account, time.UnixMilli(now-60000))
+	if err != nil {
+		t.Fatalf("FetchOrders failed: %v", err)
+	}
+
+	if len(orders) != 2 {
+		t.Fatalf("Expected 2 orders since cutoff, got %d", len(orders))
+	}
+
+	btc := orders[0]
+	if btc.OrderID != "202" || btc.BaseAsset != "BTC" || btc.QuoteAsset != "USDC" {
+		t.Errorf("Expected oldest order 202 BTC/USDC first, got %s %s/%s", btc.OrderID, btc.BaseAsset, btc.QuoteAsset)
+	}
+	if btc.Side != "sell" || btc.Price != "65000.5" || btc.Size != "0.1" || btc.Status != "canceled" {
+		t.Errorf("Unexpected BTC order fields: %+v", btc)
+	}
+	if btc.ExchangeAccountID != accountID {
+		t.Errorf("Expected ExchangeAccountID %s, got %s", accountID, btc.ExchangeAccountID)
+	}
+
+	spot := orders[1]
+	if spot.BaseAsset != "HYPE" || spot.QuoteAsset != "USDC" || spot.Side != "buy" || spot.Size != "4" {
+		t.Errorf("Unexpected spot order fields: %+v", spot)
+	}
+}
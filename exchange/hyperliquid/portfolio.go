@@ -0,0 +1,164 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/models"
+)
+
+// FetchPortfolioPnL fetches the account's PnL over the day, week and month windows from the
+// portfolio endpoint. Hyperliquid reports only total PnL per window, so Realized and Unrealized
+// are left empty
+// Implements iface.PortfolioPnLFetcher
+func (c *Client) FetchPortfolioPnL(ctx context.Context, account *models.ExchangeAccount) (*models.PortfolioPnL, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	accountUUID, periods, err := c.fetchPortfolio(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	pnl := &models.PortfolioPnL{ExchangeAccountID: accountUUID}
+	for _, window := range []struct {
+		name string
+		dest *models.PnLWindow
+	}{
+		{"day", &pnl.Day},
+		{"week", &pnl.Week},
+		{"month", &pnl.Month},
+	} {
+		period, ok := periods[window.name]
+		if !ok {
+			return nil, fmt.Errorf("portfolio response has no %s window", window.name)
+		}
+		total, asOf, err := windowPnL(period.PnLHistory)
+		if err != nil {
+			return nil, fmt.Errorf("portfolio %s window: %w", window.name, err)
+		}
+		window.dest.Total = total
+		if asOf.After(pnl.AsOf) {
+			pnl.AsOf = asOf
+		}
+	}
+
+	return pnl, nil
+}
+
+// portfolioHistoryWindows are the portfolio windows FetchPortfolioHistory merges, finest first
+// The perp* windows are skipped since they cover only the perp sub-account value
+var portfolioHistoryWindows = []string{"day", "week", "month", "allTime"}
+
+// FetchPortfolioHistory fetches the account value series of the portfolio endpoint as balance
+// snapshots, oldest first. The day/week/month/allTime windows overlap, so a timestamp reported by
+// several windows yields one snapshot, taken from the finest window reporting it
+// Implements iface.PortfolioHistoryFetcher
+func (c *Client) FetchPortfolioHistory(ctx context.Context, account *models.ExchangeAccount) ([]*models.BalanceSnapshotInput, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	accountUUID, periods, err := c.fetchPortfolio(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool)
+	snapshots := make([]*models.BalanceSnapshotInput, 0)
+	for _, name := range portfolioHistoryWindows {
+		for _, point := range periods[name].AccountValueHistory {
+			timestamp := parseTimestamp(point[0])
+			if seen[timestamp.UnixMilli()] {
+				continue
+			}
+			seen[timestamp.UnixMilli()] = true
+
+			value, err := models.ParseNumeric(convertToString(point[1]))
+			if err != nil {
+				return nil, fmt.Errorf("portfolio %s window: %w", name, err)
+			}
+			snapshots = append(snapshots, &models.BalanceSnapshotInput{
+				ExchangeAccountID: accountUUID,
+				Timestamp:         timestamp,
+				AccountValue:      models.FormatNumeric(value),
+			})
+		}
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Timestamp.Before(snapshots[j].Timestamp)
+	})
+
+	return snapshots, nil
+}
+
+// fetchPortfolio requests the portfolio endpoint for account and returns its windows keyed by name
+func (c *Client) fetchPortfolio(ctx context.Context, account *models.ExchangeAccount) (uuid.UUID, map[string]hyperliquidPortfolioPeriod, error) {
+	accountUUID, err := uuid.Parse(account.ID)
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("invalid account ID: %w", err)
+	}
+
+	address, err := c.accountAddress(account)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	// Based on Hyperliquid API: POST /info with {"type": "portfolio", "user": address}
+	requestBody := map[string]interface{}{
+		"type": "portfolio",
+		"user": address,
+	}
+
+	body, err := c.postInfo(ctx, requestBody, "portfolio")
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	var entries [][2]json.RawMessage
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return uuid.Nil, nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	periods := make(map[string]hyperliquidPortfolioPeriod, len(entries))
+	for _, entry := range entries {
+		var name string
+		var period hyperliquidPortfolioPeriod
+		if err := json.Unmarshal(entry[0], &name); err != nil {
+			return uuid.Nil, nil, fmt.Errorf("failed to decode portfolio window name: %w", err)
+		}
+		if err := json.Unmarshal(entry[1], &period); err != nil {
+			return uuid.Nil, nil, fmt.Errorf("failed to decode portfolio window %s: %w", name, err)
+		}
+		periods[name] = period
+	}
+
+	return accountUUID, periods, nil
+}
+
+// windowPnL returns the PnL accrued across a pnlHistory series (last minus first point) and the
+// time of the last point. An empty series means no activity: "0" and a zero time
+func windowPnL(history [][2]interface{}) (string, time.Time, error) {
+	if len(history) == 0 {
+		return "0", time.Time{}, nil
+	}
+
+	first, err := models.ParseNumeric(convertToString(history[0][1]))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	last, err := models.ParseNumeric(convertToString(history[len(history)-1][1]))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	total := new(big.Rat).Sub(last, first)
+	return models.FormatNumeric(total), parseTimestamp(history[len(history)-1][0]), nil
+}
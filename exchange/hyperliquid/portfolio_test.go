@@ -0,0 +1,145 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/exchange/iface"
+	"github.com/zif-terminal/lib/models"
+)
+
+// samplePortfolio is a trimmed portfolio response; values are strings as the API sends them
+const samplePortfolio = `[
+	["day", {
+		"accountValueHistory": [[1700000000000, "1000.0"], [1700086400000, "1012.5"]],
+		"pnlHistory": [[1700000000000, "0.0"], [1700043200000, "4.25"], [1700086400000, "12.5"]],
+		"vlm": "2500.0"
+	}],
+	["week", {
+		"accountValueHistory": [[1699481600000, "1050.0"], [1700086400000, "1012.5"]],
+		"pnlHistory": [[1699481600000, "0.0"], [1700086400000, "-37.5"]],
+		"vlm": "9000.0"
+	}],
+	["month", {
+		"accountValueHistory": [],
+		"pnlHistory": [],
+		"vlm": "0.0"
+	}],
+	["allTime", {
+		"accountValueHistory": [[1690000000000, "500.0"]],
+		"pnlHistory": [[1690000000000, "0.0"], [1700086400000, "512.5"]],
+		"vlm": "100000.0"
+	}],
+	["perpDay", {
+		"accountValueHistory": [],
+		"pnlHistory": [[1700000000000, "0.0"], [1700086400000, "10.0"]],
+		"vlm": "2000.0"
+	}]
+]`
+
+func TestHyperliquidClient_FetchPortfolioPnL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if reqBody["type"] != "portfolio" {
+			t.Errorf("Expected type portfolio, got %v", reqBody["type"])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(samplePortfolio))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.baseURL = server.URL
+
+	accountID := uuid.New()
+	account := &models.ExchangeAccount{ID: accountID.String(), AccountIdentifier: "0x1234567890123456789012345678901234567890"}
+
+	pnl, err := iface.FetchPortfolioPnL(context.Background(), client, account)
+	if err != nil {
+		t.Fatalf("FetchPortfolioPnL failed: %v", err)
+	}
+
+	if pnl.ExchangeAccountID != accountID {
+		t.Errorf("Expected account ID %s, got %s", accountID, pnl.ExchangeAccountID)
+	}
+	if pnl.Day.Total != "12.5" {
+		t.Errorf("Expected day total 12.5, got %q", pnl.Day.Total)
+	}
+	if pnl.Week.Total != "-37.5" {
+		t.Errorf("Expected week total -37.5, got %q", pnl.Week.Total)
+	}
+	if pnl.Month.Total != "0" {
+		t.Errorf("Expected month total 0 for an empty window, got %q", pnl.Month.Total)
+	}
+	if pnl.Day.Realized != "" || pnl.Day.Unrealized != "" {
+		t.Errorf("Expected no realized/unrealized split, got %+v", pnl.Day)
+	}
+	if want := time.UnixMilli(1700086400000).UTC(); !pnl.AsOf.Equal(want) {
+		t.Errorf("Expected AsOf %s, got %s", want, pnl.AsOf)
+	}
+}
+
+func TestHyperliquidClient_FetchPortfolioPnL_MissingWindow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[["day", {"pnlHistory": []}]]`))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.baseURL = server.URL
+
+	account := &models.ExchangeAccount{ID: uuid.New().String(), AccountIdentifier: "0x1234567890123456789012345678901234567890"}
+	if _, err := client.FetchPortfolioPnL(context.Background(), account); err == nil {
+		t.Fatal("Expected error for a response without week/month windows")
+	}
+}
+
+func TestHyperliquidClient_FetchPortfolioHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(samplePortfolio))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.baseURL = server.URL
+
+	accountID := uuid.New()
+	account := &models.ExchangeAccount{ID: accountID.String(), AccountIdentifier: "0x1234567890123456789012345678901234567890"}
+
+	snapshots, err := iface.FetchPortfolioHistory(context.Background(), client, account)
+	if err != nil {
+		t.Fatalf("FetchPortfolioHistory failed: %v", err)
+	}
+
+	// 1700086400000 is reported by both the day and week windows and must appear once
+	want := []struct {
+		millis int64
+		value  string
+	}{
+		{1690000000000, "500"},
+		{1699481600000, "1050"},
+		{1700000000000, "1000"},
+		{1700086400000, "1012.5"},
+	}
+	if len(snapshots) != len(want) {
+		t.Fatalf("Expected %d snapshots, got %d", len(want), len(snapshots))
+	}
+	for i, w := range want {
+		if snapshots[i].Timestamp.UnixMilli() != w.millis || snapshots[i].AccountValue != w.value {
+			t.Errorf("Snapshot %d: expected %d=%s, got %d=%s", i, w.millis, w.value, snapshots[i].Timestamp.UnixMilli(), snapshots[i].AccountValue)
+		}
+		if snapshots[i].ExchangeAccountID != accountID {
+			t.Errorf("Snapshot %d: expected account ID %s, got %s", i, accountID, snapshots[i].ExchangeAccountID)
+		}
+	}
+}
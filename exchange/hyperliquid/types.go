@@ -1,20 +1,23 @@
 package hyperliquid
 
+import "encoding/json"
+
 // hyperliquidFill represents a single fill from Hyperliquid API
 // The API returns userFills as a direct array, not wrapped in an object
 // Fields match the actual API response structure
 type hyperliquidFill struct {
 	Coin    string      `json:"coin"`     // Asset name (e.g., "BTC", "TNSR")
-	Px      interface{} `json:"px"`      // Price (number or string)
-	Sz      interface{} `json:"sz"`      // Size/Quantity (number or string)
+	Px      json.Number `json:"px"`      // Price (number or string, kept verbatim)
+	Sz      json.Number `json:"sz"`      // Size/Quantity (number or string, kept verbatim)
 	Side    string      `json:"side"`     // "B" (buy), "S" (sell), "A" (close)
 	Time    interface{} `json:"time"`    // Unix timestamp in milliseconds
 	Hash    string      `json:"hash"`     // Transaction hash
 	Tid     interface{} `json:"tid"`      // Fill ID (unique per fill, used as trade_id)
 	Oid     interface{} `json:"oid"`     // Order ID (number or string)
-	Fee     interface{} `json:"fee"`     // Fee (number or string)
+	Fee     json.Number `json:"fee"`     // Fee (number or string, kept verbatim)
+	Crossed *bool       `json:"crossed"` // True when the fill crossed the spread (taker); nil if absent
 	// Additional fields that may be present but not used:
-	// StartPosition, Dir, ClosedPnl, Crossed, FeeToken, TwapId
+	// StartPosition, Dir, ClosedPnl, FeeToken, TwapId
 }
 
 // hyperliquidFundingPayment represents a single funding payment from Hyperliquid API
@@ -32,3 +35,40 @@ type hyperliquidFundingPayment struct {
 		NSamples    interface{} `json:"nSamples"`    // Number of samples (not used)
 	} `json:"delta"`
 }
+
+// hyperliquidSpotMeta is the spotMeta response describing spot tokens and trading pairs
+// Spot fills reference pairs as "@<index>" (or by name for a few legacy pairs like "PURR/USDC")
+type hyperliquidSpotMeta struct {
+	Tokens []struct {
+		Name  string `json:"name"`  // Token symbol (e.g., "USDC", "HYPE")
+		Index int    `json:"index"` // Token index referenced by universe entries
+	} `json:"tokens"`
+	Universe []struct {
+		Name   string `json:"name"`   // Pair name as used in fills (e.g., "@107", "PURR/USDC")
+		Tokens [2]int `json:"tokens"` // [base token index, quote token index]
+		Index  int    `json:"index"`  // Pair index
+	} `json:"universe"`
+}
+
+// hyperliquidHistoricalOrder is a single entry of the historicalOrders response
+// The API returns the account's most recent orders (up to 2000) with their latest status
+type hyperliquidHistoricalOrder struct {
+	Order struct {
+		Coin      string      `json:"coin"`      // Asset name, or "@<index>" for spot pairs
+		Side      string      `json:"side"`      // "B" (buy) or "A" (ask/sell)
+		LimitPx   interface{} `json:"limitPx"`   // Limit price (number or string)
+		OrigSz    interface{} `json:"origSz"`    // Original size; "sz" is the remaining size
+		Oid       interface{} `json:"oid"`       // Order ID
+		Timestamp interface{} `json:"timestamp"` // Placement time in Unix milliseconds
+	} `json:"order"`
+	Status          string      `json:"status"`          // e.g. "open", "filled", "canceled", "rejected"
+	StatusTimestamp interface{} `json:"statusTimestamp"` // When the status last changed (Unix milliseconds)
+}
+
+// hyperliquidPortfolioPeriod is the data for one window of the portfolio response
+// The response is an array of [name, period] pairs, e.g. ["day", {...}], ["perpWeek", {...}]
+type hyperliquidPortfolioPeriod struct {
+	AccountValueHistory [][2]interface{} `json:"accountValueHistory"` // [Unix milliseconds, value]
+	PnLHistory          [][2]interface{} `json:"pnlHistory"`          // [Unix milliseconds, cumulative PnL since window start]
+	Vlm                 interface{}      `json:"vlm"`                 // Traded volume over the window
+}
@@ -2,6 +2,7 @@ package iface
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/zif-terminal/lib/models"
@@ -31,3 +32,85 @@ type ExchangeClient interface {
 		since time.Time,
 	) ([]*models.FundingPaymentInput, error)
 }
+
+// OrderFetcher is implemented by exchange clients that can fetch order history
+// It is optional: call FetchOrders rather than asserting the interface directly
+type OrderFetcher interface {
+	// FetchOrders fetches orders placed since a specific timestamp, sorted by timestamp (oldest first)
+	FetchOrders(
+		ctx context.Context,
+		account *models.ExchangeAccount,
+		since time.Time,
+	) ([]*models.OrderInput, error)
+}
+
+// FetchOrders fetches order history from client, or returns ErrNotSupported if the exchange has none
+func FetchOrders(
+	ctx context.Context,
+	client ExchangeClient,
+	account *models.ExchangeAccount,
+	since time.Time,
+) ([]*models.OrderInput, error) {
+	fetcher, ok := client.(OrderFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s order history: %w", client.Name(), ErrNotSupported)
+	}
+	return fetcher.FetchOrders(ctx, account, since)
+}
+
+// PortfolioPnLFetcher is implemented by exchange clients that report account-level PnL
+// It is optional: call FetchPortfolioPnL rather than asserting the interface directly
+type PortfolioPnLFetcher interface {
+	// FetchPortfolioPnL fetches the account's PnL over the standard day/week/month windows
+	FetchPortfolioPnL(ctx context.Context, account *models.ExchangeAccount) (*models.PortfolioPnL, error)
+}
+
+// FetchPortfolioPnL fetches account PnL from client, or returns ErrNotSupported if the exchange has none
+func FetchPortfolioPnL(
+	ctx context.Context,
+	client ExchangeClient,
+	account *models.ExchangeAccount,
+) (*models.PortfolioPnL, error) {
+	fetcher, ok := client.(PortfolioPnLFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s portfolio PnL: %w", client.Name(), ErrNotSupported)
+	}
+	return fetcher.FetchPortfolioPnL(ctx, account)
+}
+
+// PortfolioHistoryFetcher is implemented by exchange clients that report historical account value
+// It is optional: call FetchPortfolioHistory rather than asserting the interface directly
+type PortfolioHistoryFetcher interface {
+	// FetchPortfolioHistory fetches the account's value history as snapshots, oldest first
+	FetchPortfolioHistory(ctx context.Context, account *models.ExchangeAccount) ([]*models.BalanceSnapshotInput, error)
+}
+
+// FetchPortfolioHistory fetches account value history from client, or returns ErrNotSupported if the exchange has none
+func FetchPortfolioHistory(
+	ctx context.Context,
+	client ExchangeClient,
+	account *models.ExchangeAccount,
+) ([]*models.BalanceSnapshotInput, error) {
+	fetcher, ok := client.(PortfolioHistoryFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s portfolio history: %w", client.Name(), ErrNotSupported)
+	}
+	return fetcher.FetchPortfolioHistory(ctx, account)
+}
+
+// Prober is implemented by exchange clients that can cheaply check the exchange is reachable
+// It is optional: call Probe rather than asserting the interface directly
+type Prober interface {
+	// Probe makes a lightweight unauthenticated request (e.g. market metadata) and reports
+	// whether the exchange answered with a usable response
+	Probe(ctx context.Context) error
+}
+
+// Probe checks that client's exchange is reachable, or returns ErrNotSupported if it has no probe
+func Probe(ctx context.Context, client ExchangeClient) error {
+	prober, ok := client.(Prober)
+	if !ok {
+		return fmt.Errorf("%s probe: %w", client.Name(), ErrNotSupported)
+	}
+	return prober.Probe(ctx)
+}
@@ -0,0 +1,45 @@
+package iface
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zif-terminal/lib/models"
+)
+
+// noOrdersClient is an exchange client without order history
+type noOrdersClient struct {
+	ExchangeClient
+}
+
+func (noOrdersClient) Name() string { return "no-orders" }
+
+func TestFetchOrders_NotSupported(t *testing.T) {
+	_, err := FetchOrders(context.Background(), noOrdersClient{}, &models.ExchangeAccount{}, time.Time{})
+	if !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("Expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestFetchPortfolioPnL_NotSupported(t *testing.T) {
+	_, err := FetchPortfolioPnL(context.Background(), noOrdersClient{}, &models.ExchangeAccount{})
+	if !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("Expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestFetchPortfolioHistory_NotSupported(t *testing.T) {
+	_, err := FetchPortfolioHistory(context.Background(), noOrdersClient{}, &models.ExchangeAccount{})
+	if !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("Expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestProbe_NotSupported(t *testing.T) {
+	err := Probe(context.Background(), noOrdersClient{})
+	if !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("Expected ErrNotSupported, got %v", err)
+	}
+}
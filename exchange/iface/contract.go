@@ -16,6 +16,11 @@ type ExchangeClientContract struct {
 	ValidAccount *models.ExchangeAccount
 	// InvalidAccount is optional - if nil, invalid account tests are skipped
 	InvalidAccount *models.ExchangeAccount
+	// DefaultQuote is the quote asset NewClient assumes when a symbol has no explicit quote
+	DefaultQuote string
+	// NewClientWithOptions is optional - if nil, default quote tests are skipped
+	// It must build the same client as NewClient with the given shared options applied
+	NewClientWithOptions func(opts ...Option) ExchangeClient
 }
 
 // RunExchangeClientContractTests runs all contract tests
@@ -253,9 +258,77 @@ func RunExchangeClientContractTests(t *testing.T, contract ExchangeClientContrac
 			t.Errorf("Filtered payments (%d) should not exceed all payments (%d)", len(filteredPayments), len(allPayments))
 		}
 	})
+
+	t.Run("FetchOrders_Optional", func(t *testing.T) {
+		client := contract.NewClient()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		orders, err := FetchOrders(ctx, client, contract.ValidAccount, time.Time{})
+		if errors.Is(err, ErrNotSupported) {
+			t.Skip("Skipping order history test: not supported by exchange")
+		}
+		if err != nil {
+			t.Fatalf("FetchOrders with valid account should not error: %v", err)
+		}
+
+		for i, order := range orders {
+			validateOrderInput(t, order)
+			if i > 0 && order.Timestamp.Before(orders[i-1].Timestamp) {
+				t.Error("Orders must be sorted by timestamp (oldest first)")
+			}
+		}
+	})
+
+	if contract.NewClientWithOptions != nil && contract.DefaultQuote != "" {
+		t.Run("FetchTrades_DefaultQuote", func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+			defer cancel()
+
+			// Compare trades from the default client with a client using a sentinel default quote:
+			// trades that fell back to the default must switch, explicitly quoted trades must not
+			const sentinelQuote = "CONTRACTQ"
+			trades, err := contract.NewClient().FetchTrades(ctx, contract.ValidAccount, time.Time{})
+			if err != nil {
+				t.Skip("Skipping default quote test due to error:", err)
+			}
+			configured, err := contract.NewClientWithOptions(WithDefaultQuote(sentinelQuote)).FetchTrades(ctx, contract.ValidAccount, time.Time{})
+			if err != nil {
+				t.Fatalf("FetchTrades with configured default quote failed: %v", err)
+			}
+
+			quotes := make(map[string]string, len(configured))
+			for _, trade := range configured {
+				quotes[trade.TradeID] = trade.QuoteAsset
+			}
+
+			for _, trade := range trades {
+				got, ok := quotes[trade.TradeID]
+				if !ok {
+					continue
+				}
+				if got == "" {
+					t.Errorf("Trade %s: QuoteAsset must be non-empty", trade.TradeID)
+					continue
+				}
+				// A symbol may spell out the default quote explicitly, so either value is valid there
+				if trade.QuoteAsset == contract.DefaultQuote {
+					if got != sentinelQuote && got != contract.DefaultQuote {
+						t.Errorf("Trade %s: expected quote asset %q, got %q", trade.TradeID, sentinelQuote, got)
+					}
+					continue
+				}
+				if got != trade.QuoteAsset {
+					t.Errorf("Trade %s: explicit quote asset %q changed to %q", trade.TradeID, trade.QuoteAsset, got)
+				}
+			}
+		})
+	}
 }
 
 // validateTradeInput validates TradeInput structure
+// OrderID is optional, since some venues report none; Fee is required, with models.DefaultTradeFee
+// standing in when the exchange reports no fee
 func validateTradeInput(t *testing.T, trade *models.TradeInput) {
 	if trade.TradeID == "" {
 		t.Error("TradeInput.TradeID must be non-empty")
@@ -276,7 +349,7 @@ func validateTradeInput(t *testing.T, trade *models.TradeInput) {
 		t.Error("TradeInput.Quantity must be non-empty")
 	}
 	if trade.Fee == "" {
-		t.Error("TradeInput.Fee must be non-empty")
+		t.Error("TradeInput.Fee must be non-empty (use models.DefaultTradeFee when the exchange reports none)")
 	}
 	if trade.Timestamp.IsZero() {
 		t.Error("TradeInput.Timestamp must be non-zero")
@@ -301,3 +374,22 @@ func validateFundingPaymentInput(t *testing.T, payment *models.FundingPaymentInp
 		t.Error("FundingPaymentInput.Timestamp must be non-zero")
 	}
 }
+
+// validateOrderInput validates OrderInput structure
+func validateOrderInput(t *testing.T, order *models.OrderInput) {
+	if order.OrderID == "" {
+		t.Error("OrderInput.OrderID must be non-empty")
+	}
+	if order.Side != "buy" && order.Side != "sell" {
+		t.Errorf("OrderInput.Side must be 'buy' or 'sell', got: %s", order.Side)
+	}
+	if order.BaseAsset == "" {
+		t.Error("OrderInput.BaseAsset must be non-empty")
+	}
+	if order.Status == "" {
+		t.Error("OrderInput.Status must be non-empty")
+	}
+	if order.Timestamp.IsZero() {
+		t.Error("OrderInput.Timestamp must be non-zero")
+	}
+}
@@ -1,8 +1,11 @@
 package iface
 
 import (
+	"errors"
 	"fmt"
 	"time"
+
+	"github.com/zif-terminal/lib/models"
 )
 
 // RateLimitError indicates the exchange API rate limit was exceeded
@@ -25,3 +28,67 @@ func IsRateLimitError(err error) bool {
 	_, ok := err.(*RateLimitError)
 	return ok
 }
+
+// DefaultMaintenanceRetryAfter is the wait a MaintenanceError suggests when the exchange doesn't say
+// how long the maintenance will last
+const DefaultMaintenanceRetryAfter = 5 * time.Minute
+
+// MaintenanceError indicates the exchange is down for maintenance or an upgrade
+// Callers should treat it like a long rate limit and wait RetryAfter before trying again
+type MaintenanceError struct {
+	Exchange   string
+	Message    string
+	RetryAfter time.Duration // Always set; DefaultMaintenanceRetryAfter unless the exchange says otherwise
+}
+
+func (e *MaintenanceError) Error() string {
+	return fmt.Sprintf("%s is under maintenance: %s (retry after %v)", e.Exchange, e.Message, e.RetryAfter)
+}
+
+// IsMaintenanceError checks if an error is (or wraps) a MaintenanceError
+func IsMaintenanceError(err error) bool {
+	var maintenance *MaintenanceError
+	return errors.As(err, &maintenance)
+}
+
+// MaintenanceDetector reports whether a response with the given HTTP status and body means the
+// exchange is down for maintenance. Exchange clients ship a default and accept overrides
+type MaintenanceDetector func(status int, body []byte) bool
+
+// ErrFetchAborted is returned when a fetch is stopped because it exceeded a configured soft limit
+var ErrFetchAborted = errors.New("fetch aborted")
+
+// ErrTooManyPages is returned when a paginated fetch exceeds its hard page cap, which usually
+// means the exchange keeps returning the same page
+var ErrTooManyPages = errors.New("too many pages")
+
+// ErrPageSaturated is returned when more rows share one timestamp than fit in a page, so a
+// time-paginated fetch cannot return them all
+var ErrPageSaturated = errors.New("page saturated by one timestamp")
+
+// ErrNotSupported is returned when an exchange does not offer the requested data (e.g. order history)
+var ErrNotSupported = errors.New("not supported by exchange")
+
+// TemporaryError indicates a transient exchange failure worth retrying, such as a CDN
+// challenge page or an empty body served with status 200
+type TemporaryError struct {
+	Exchange string
+	Message  string
+	Body     string // Trimmed excerpt of the response body (optional)
+}
+
+func (e *TemporaryError) Error() string {
+	if e.Body != "" {
+		return fmt.Sprintf("temporary error from %s: %s: %q", e.Exchange, e.Message, e.Body)
+	}
+	return fmt.Sprintf("temporary error from %s: %s", e.Exchange, e.Message)
+}
+
+// IsTemporaryError checks if an error is (or wraps) a TemporaryError
+func IsTemporaryError(err error) bool {
+	var temporary *TemporaryError
+	return errors.As(err, &temporary)
+}
+
+// InvalidAccountError indicates a malformed account identifier (aliased from models package)
+type InvalidAccountError = models.InvalidAccountError
@@ -0,0 +1,67 @@
+package iface
+
+import (
+	"context"
+	"time"
+
+	"github.com/zif-terminal/lib/models"
+)
+
+// FundingFetchOptions controls an incremental funding fetch with per-asset cursors
+type FundingFetchOptions struct {
+	// Since bounds assets without a cursor: their payments at or after Since are returned (zero = all)
+	Since time.Time
+	// AssetCursors maps base asset -> newest stored payment timestamp; only payments strictly
+	// after an asset's cursor are returned (e.g. from db.GetLatestFundingPaymentsByAsset)
+	AssetCursors map[string]time.Time
+}
+
+// FetchFundingPaymentsByAsset fetches funding payments once, from the earliest cursor, and filters
+// them per asset so a lagging asset doesn't cause known payments of other assets to be returned
+// The returned cursors hold the newest timestamp per asset across opts.AssetCursors and the result
+func FetchFundingPaymentsByAsset(
+	ctx context.Context,
+	client ExchangeClient,
+	account *models.ExchangeAccount,
+	opts FundingFetchOptions,
+) ([]*models.FundingPaymentInput, map[string]time.Time, error) {
+	payments, err := client.FetchFundingPayments(ctx, account, fundingFetchStart(opts))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cursors := make(map[string]time.Time, len(opts.AssetCursors))
+	for asset, cursor := range opts.AssetCursors {
+		cursors[asset] = cursor
+	}
+
+	fresh := make([]*models.FundingPaymentInput, 0, len(payments))
+	for _, payment := range payments {
+		if cursor, ok := opts.AssetCursors[payment.BaseAsset]; ok {
+			if !payment.Timestamp.After(cursor) {
+				continue
+			}
+		} else if payment.Timestamp.Before(opts.Since) {
+			continue
+		}
+
+		fresh = append(fresh, payment)
+		if payment.Timestamp.After(cursors[payment.BaseAsset]) {
+			cursors[payment.BaseAsset] = payment.Timestamp
+		}
+	}
+
+	return fresh, cursors, nil
+}
+
+// fundingFetchStart returns the earliest timestamp any asset needs
+// Assets without a cursor need everything from Since, so it is always a candidate
+func fundingFetchStart(opts FundingFetchOptions) time.Time {
+	start := opts.Since
+	for _, cursor := range opts.AssetCursors {
+		if cursor.Before(start) {
+			start = cursor
+		}
+	}
+	return start
+}
@@ -0,0 +1,77 @@
+package iface
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zif-terminal/lib/models"
+)
+
+// fundingClient returns fixed funding payments and records the since it was called with
+type fundingClient struct {
+	noOrdersClient
+	payments []*models.FundingPaymentInput
+	since    time.Time
+}
+
+func (f *fundingClient) FetchFundingPayments(ctx context.Context, account *models.ExchangeAccount, since time.Time) ([]*models.FundingPaymentInput, error) {
+	f.since = since
+	var result []*models.FundingPaymentInput
+	for _, payment := range f.payments {
+		if !payment.Timestamp.Before(since) {
+			result = append(result, payment)
+		}
+	}
+	return result, nil
+}
+
+func TestFetchFundingPaymentsByAsset(t *testing.T) {
+	at := func(sec int64) time.Time { return time.Unix(sec, 0) }
+	payment := func(asset string, sec int64) *models.FundingPaymentInput {
+		return &models.FundingPaymentInput{BaseAsset: asset, Timestamp: at(sec), PaymentID: asset}
+	}
+
+	client := &fundingClient{payments: []*models.FundingPaymentInput{
+		payment("BTC", 100), payment("ETH", 100), payment("SOL", 100),
+		payment("BTC", 200), payment("ETH", 200), payment("SOL", 200),
+		payment("BTC", 300), payment("ETH", 300), payment("SOL", 300),
+	}}
+
+	payments, cursors, err := FetchFundingPaymentsByAsset(context.Background(), client, &models.ExchangeAccount{}, FundingFetchOptions{
+		Since: at(150),
+		AssetCursors: map[string]time.Time{
+			"BTC":  at(300), // Up to date
+			"ETH":  at(100), // Lagging
+			"DOGE": at(50),  // No new payments
+		},
+	})
+	if err != nil {
+		t.Fatalf("FetchFundingPaymentsByAsset failed: %v", err)
+	}
+
+	if !client.since.Equal(at(50)) {
+		t.Errorf("Expected a single fetch from the earliest cursor, got since %v", client.since)
+	}
+
+	got := make(map[string][]int64)
+	for _, p := range payments {
+		got[p.BaseAsset] = append(got[p.BaseAsset], p.Timestamp.Unix())
+	}
+	if len(got["BTC"]) != 0 {
+		t.Errorf("Expected no BTC payments at or before its cursor, got %v", got["BTC"])
+	}
+	if len(got["ETH"]) != 2 || got["ETH"][0] != 200 {
+		t.Errorf("Expected ETH payments after its cursor [200 300], got %v", got["ETH"])
+	}
+	if len(got["SOL"]) != 2 || got["SOL"][0] != 200 {
+		t.Errorf("Expected SOL payments from Since [200 300], got %v", got["SOL"])
+	}
+
+	want := map[string]time.Time{"BTC": at(300), "ETH": at(300), "SOL": at(300), "DOGE": at(50)}
+	for asset, ts := range want {
+		if !cursors[asset].Equal(ts) {
+			t.Errorf("Expected %s cursor %v, got %v", asset, ts, cursors[asset])
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package iface
+
+import (
+	"net/http"
+	"runtime/debug"
+	"sync"
+)
+
+// modulePath is this library's module path, used to find its version in the build info
+const modulePath = "github.com/zif-terminal/lib"
+
+var (
+	userAgentOnce sync.Once
+	userAgent     string
+)
+
+// DefaultUserAgent returns "zif-terminal-lib/<version>", where version is the module version
+// recorded in the binary's build info ("devel" when unavailable, e.g. in tests)
+func DefaultUserAgent() string {
+	userAgentOnce.Do(func() {
+		userAgent = "zif-terminal-lib/" + moduleVersion()
+	})
+	return userAgent
+}
+
+// moduleVersion looks up this library's version in the running binary's build info
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	if info.Main.Path == modulePath && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != modulePath {
+			continue
+		}
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			return dep.Replace.Version
+		}
+		if dep.Version != "" {
+			return dep.Version
+		}
+	}
+	return "devel"
+}
+
+// WithUserAgent overrides the User-Agent sent on every request
+func WithUserAgent(ua string) Option {
+	return func(o *Options) {
+		o.UserAgent = ua
+	}
+}
+
+// WithExtraHeaders adds headers to every request (e.g. an integrator ID the exchange asks for)
+// Repeated calls accumulate; setting the same header again replaces the earlier value
+func WithExtraHeaders(headers map[string]string) Option {
+	return func(o *Options) {
+		if o.ExtraHeaders == nil {
+			o.ExtraHeaders = make(http.Header, len(headers))
+		}
+		for name, value := range headers {
+			o.ExtraHeaders.Set(name, value)
+		}
+	}
+}
+
+// ApplyHeaders sets the User-Agent and extra headers on h
+// Clients call it before setting their own headers so those cannot be overridden
+// Nothing credential-related is added here; authentication stays with each exchange client
+func (o Options) ApplyHeaders(h http.Header) {
+	ua := o.UserAgent
+	if ua == "" {
+		ua = DefaultUserAgent()
+	}
+	h.Set("User-Agent", ua)
+
+	for name, values := range o.ExtraHeaders {
+		h[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+	}
+}
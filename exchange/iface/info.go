@@ -0,0 +1,6 @@
+package iface
+
+import "github.com/zif-terminal/lib/models"
+
+// ExchangeInfo describes a registered exchange for display and seeding (aliased from models package)
+type ExchangeInfo = models.ExchangeInfo
@@ -0,0 +1,83 @@
+package iface
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Options holds configuration shared by all exchange clients
+// Each client starts from its own defaults and applies caller-provided Option values on top
+type Options struct {
+	// DefaultQuote is the quote asset assumed when an exchange symbol carries no explicit quote
+	// (e.g. Hyperliquid "BTC" settles in USDC, Drift/Lighter in USD, Binance in USDT)
+	DefaultQuote string
+
+	// PageLimit is the number of pages a paginated fetch may request before OnPageLimit is
+	// consulted (0 = unlimited), guarding against accidentally pulling years of history
+	PageLimit int
+	// OnPageLimit is called once when a fetch is about to exceed PageLimit
+	// Returning false aborts the fetch with ErrFetchAborted; when nil a warning is logged and the fetch continues
+	OnPageLimit func(ctx context.Context, exchange string, pages int) bool
+
+	// UserAgent identifies this library to the exchange (empty = DefaultUserAgent())
+	UserAgent string
+	// ExtraHeaders are added to every outgoing request; client-managed headers such as
+	// Content-Type and authentication headers take precedence
+	ExtraHeaders http.Header
+}
+
+// Option configures Options
+type Option func(*Options)
+
+// WithDefaultQuote overrides the quote asset used for symbols without an explicit quote
+func WithDefaultQuote(quote string) Option {
+	return func(o *Options) {
+		o.DefaultQuote = quote
+	}
+}
+
+// WithPageLimit sets a soft page limit and the function consulted when it is exceeded
+func WithPageLimit(pages int, onLimit func(ctx context.Context, exchange string, pages int) bool) Option {
+	return func(o *Options) {
+		o.PageLimit = pages
+		o.OnPageLimit = onLimit
+	}
+}
+
+// CheckPageLimit reports whether a fetch that has already requested pages pages may continue
+// It consults OnPageLimit (or logs a warning) exactly when the limit is reached
+func (o Options) CheckPageLimit(ctx context.Context, exchange string, pages int) error {
+	if o.PageLimit <= 0 || pages != o.PageLimit {
+		return nil
+	}
+	if o.OnPageLimit == nil {
+		slog.Default().Warn("large fetch exceeds page limit", "exchange", exchange, "pages", pages)
+		return nil
+	}
+	if !o.OnPageLimit(ctx, exchange, pages) {
+		return fmt.Errorf("%w: %s fetch stopped after %d pages", ErrFetchAborted, exchange, pages)
+	}
+	return nil
+}
+
+// ApplyOptions applies opts on top of defaults and returns the result
+func ApplyOptions(defaults Options, opts ...Option) Options {
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&defaults)
+		}
+	}
+	return defaults
+}
+
+// SplitPair extracts base and quote assets from an exchange symbol
+// Accepts "BASE-QUOTE" and "BASE/QUOTE"; a bare "BASE" gets defaultQuote
+func SplitPair(coin, defaultQuote string) (baseAsset, quoteAsset string) {
+	if i := strings.IndexAny(coin, "-/"); i >= 0 {
+		return coin[:i], coin[i+1:]
+	}
+	return coin, defaultQuote
+}
@@ -0,0 +1,71 @@
+package iface
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSplitPair(t *testing.T) {
+	tests := []struct {
+		coin         string
+		defaultQuote string
+		wantBase     string
+		wantQuote    string
+	}{
+		{"BTC", "USDC", "BTC", "USDC"},
+		{"BTC", "USD", "BTC", "USD"},
+		{"ETH-USDT", "USDC", "ETH", "USDT"},
+		{"SOL/USD", "USDC", "SOL", "USD"},
+		{"", "USDC", "", "USDC"},
+	}
+
+	for _, tt := range tests {
+		base, quote := SplitPair(tt.coin, tt.defaultQuote)
+		if base != tt.wantBase || quote != tt.wantQuote {
+			t.Errorf("SplitPair(%q, %q) = (%q, %q), want (%q, %q)",
+				tt.coin, tt.defaultQuote, base, quote, tt.wantBase, tt.wantQuote)
+		}
+	}
+}
+
+func TestApplyOptions(t *testing.T) {
+	opts := ApplyOptions(Options{DefaultQuote: "USDC"})
+	if opts.DefaultQuote != "USDC" {
+		t.Errorf("Expected defaults to be kept, got %q", opts.DefaultQuote)
+	}
+
+	opts = ApplyOptions(Options{DefaultQuote: "USDC"}, WithDefaultQuote("USD"))
+	if opts.DefaultQuote != "USD" {
+		t.Errorf("Expected WithDefaultQuote to override default, got %q", opts.DefaultQuote)
+	}
+}
+
+func TestOptions_ApplyHeaders(t *testing.T) {
+	if ua := DefaultUserAgent(); !strings.HasPrefix(ua, "zif-terminal-lib/") || ua == "zif-terminal-lib/" {
+		t.Errorf("Expected zif-terminal-lib/<version>, got %q", ua)
+	}
+
+	h := http.Header{}
+	ApplyOptions(Options{}).ApplyHeaders(h)
+	if got := h.Get("User-Agent"); got != DefaultUserAgent() {
+		t.Errorf("Expected default User-Agent, got %q", got)
+	}
+
+	h = http.Header{}
+	opts := ApplyOptions(Options{},
+		WithUserAgent("my-app/1.0"),
+		WithExtraHeaders(map[string]string{"X-Integrator": "a"}),
+		WithExtraHeaders(map[string]string{"x-integrator": "b", "X-Trace": "t"}),
+	)
+	opts.ApplyHeaders(h)
+	if got := h.Get("User-Agent"); got != "my-app/1.0" {
+		t.Errorf("Expected custom User-Agent, got %q", got)
+	}
+	if got := h.Values("X-Integrator"); len(got) != 1 || got[0] != "b" {
+		t.Errorf("Expected later X-Integrator to replace earlier, got %v", got)
+	}
+	if got := h.Get("X-Trace"); got != "t" {
+		t.Errorf("Expected X-Trace t, got %q", got)
+	}
+}
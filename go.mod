@@ -5,9 +5,11 @@ go 1.21
 require (
 	github.com/google/uuid v1.6.0
 	github.com/machinebox/graphql v0.2.2
+	golang.org/x/crypto v0.18.0
 )
 
 require (
 	github.com/matryer/is v1.4.1 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
+	golang.org/x/sys v0.16.0 // indirect
 )
@@ -0,0 +1,154 @@
+// Package health summarizes whether a service's dependencies are reachable, for /healthz endpoints
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/zif-terminal/lib/clock"
+	"github.com/zif-terminal/lib/db"
+	"github.com/zif-terminal/lib/exchange/iface"
+)
+
+// Status classifies a dependency or a whole service
+type Status string
+
+const (
+	// StatusUp means the dependency answered within the slow threshold
+	StatusUp Status = "up"
+	// StatusDegraded means the dependency answered, but slower than the slow threshold; for a
+	// Report it means the database is up but at least one exchange is not
+	StatusDegraded Status = "degraded"
+	// StatusDown means the dependency failed or timed out; for a Report it means the database is down
+	StatusDown Status = "down"
+	// StatusUnknown means the exchange client has no probe (see iface.Prober); it does not affect the Report
+	StatusUnknown Status = "unknown"
+)
+
+// Dependency kinds
+const (
+	KindDatabase = "database"
+	KindExchange = "exchange"
+)
+
+// Default per-dependency limits used by Check
+const (
+	DefaultTimeout       = 5 * time.Second
+	DefaultSlowThreshold = 2 * time.Second
+)
+
+// DependencyStatus is the outcome of checking one dependency
+type DependencyStatus struct {
+	Name      string        `json:"name"` // "database" or the exchange name
+	Kind      string        `json:"kind"` // KindDatabase or KindExchange
+	Status    Status        `json:"status"`
+	Latency   time.Duration `json:"-"`
+	LatencyMs int64         `json:"latency_ms"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Report summarizes a service's dependencies
+// Status is down when the database is down, degraded when the database is reachable but any
+// dependency is down or degraded, and up otherwise
+type Report struct {
+	Status       Status             `json:"status"`
+	CheckedAt    time.Time          `json:"checked_at"`
+	Dependencies []DependencyStatus `json:"dependencies"` // Database first, then exchanges in the order given
+}
+
+// Options sets the limits applied to each dependency
+type Options struct {
+	Timeout       time.Duration // Deadline for each probe (0 = DefaultTimeout)
+	SlowThreshold time.Duration // Probes slower than this are degraded (0 = DefaultSlowThreshold)
+	Clock         clock.Clock   // Stamps the report and times probes (nil = real clock)
+}
+
+// Check probes the database and every exchange concurrently with DefaultTimeout each
+// See CheckWithOptions
+func Check(ctx context.Context, database db.DBClient, exchanges []iface.ExchangeClient) (*Report, error) {
+	return CheckWithOptions(ctx, database, exchanges, Options{})
+}
+
+// CheckWithOptions runs database.Ping and iface.Probe for each exchange concurrently, each under
+// its own timeout. Dependency failures are reported in the Report, not as an error; the error is
+// ctx.Err() when ctx ends before the checks finish (the Report is still returned)
+func CheckWithOptions(ctx context.Context, database db.DBClient, exchanges []iface.ExchangeClient, opts Options) (*Report, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.SlowThreshold <= 0 {
+		opts.SlowThreshold = DefaultSlowThreshold
+	}
+	opts.Clock = clock.OrReal(opts.Clock)
+
+	report := &Report{
+		CheckedAt:    opts.Clock.Now().UTC(),
+		Dependencies: make([]DependencyStatus, len(exchanges)+1),
+	}
+
+	var wg sync.WaitGroup
+	run := func(i int, name, kind string, probe func(context.Context) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Dependencies[i] = checkDependency(ctx, name, kind, probe, opts)
+		}()
+	}
+
+	run(0, KindDatabase, KindDatabase, database.Ping)
+	for i, ex := range exchanges {
+		ex := ex
+		run(i+1, ex.Name(), KindExchange, func(ctx context.Context) error {
+			return iface.Probe(ctx, ex)
+		})
+	}
+	wg.Wait()
+
+	report.Status = classify(report.Dependencies)
+	return report, ctx.Err()
+}
+
+// checkDependency runs probe under the timeout and classifies the outcome
+func checkDependency(ctx context.Context, name, kind string, probe func(context.Context) error, opts Options) DependencyStatus {
+	ctx, cancel := context.WithDeadline(ctx, opts.Clock.Now().Add(opts.Timeout))
+	defer cancel()
+
+	start := opts.Clock.Now()
+	err := probe(ctx)
+	latency := opts.Clock.Now().Sub(start)
+
+	status := DependencyStatus{
+		Name:      name,
+		Kind:      kind,
+		Latency:   latency,
+		LatencyMs: latency.Milliseconds(),
+	}
+	switch {
+	case errors.Is(err, iface.ErrNotSupported):
+		status.Status = StatusUnknown
+	case err != nil:
+		status.Status = StatusDown
+		status.Error = err.Error()
+	case latency > opts.SlowThreshold:
+		status.Status = StatusDegraded
+	default:
+		status.Status = StatusUp
+	}
+	return status
+}
+
+// classify derives the Report status from the dependency statuses
+func classify(dependencies []DependencyStatus) Status {
+	overall := StatusUp
+	for _, dep := range dependencies {
+		switch {
+		case dep.Kind == KindDatabase && dep.Status == StatusDown:
+			return StatusDown
+		case dep.Status == StatusDown || dep.Status == StatusDegraded:
+			overall = StatusDegraded
+		}
+	}
+	return overall
+}
@@ -0,0 +1,184 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zif-terminal/lib/clock"
+	"github.com/zif-terminal/lib/db"
+	"github.com/zif-terminal/lib/exchange/iface"
+)
+
+// fakeDB is a DBClient whose Ping returns err after delay
+type fakeDB struct {
+	db.DBClient
+	delay time.Duration
+	err   error
+}
+
+func (f *fakeDB) Ping(ctx context.Context) error {
+	return wait(ctx, f.delay, f.err)
+}
+
+// fakeExchange is an exchange client whose Probe returns err after delay
+type fakeExchange struct {
+	iface.ExchangeClient
+	name  string
+	delay time.Duration
+	err   error
+}
+
+func (f *fakeExchange) Name() string { return f.name }
+
+func (f *fakeExchange) Probe(ctx context.Context) error {
+	return wait(ctx, f.delay, f.err)
+}
+
+// noProbeExchange is an exchange client without a probe
+type noProbeExchange struct {
+	iface.ExchangeClient
+}
+
+func (noProbeExchange) Name() string { return "no-probe" }
+
+// wait returns err after delay, or ctx.Err() if ctx ends first
+func wait(ctx context.Context, delay time.Duration, err error) error {
+	select {
+	case <-time.After(delay):
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestCheck_HealthyDatabaseFailingExchange(t *testing.T) {
+	exchanges := []iface.ExchangeClient{
+		&fakeExchange{name: "hyperliquid", err: errors.New("connection refused")},
+	}
+
+	report, err := Check(context.Background(), &fakeDB{}, exchanges)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if report.Status != StatusDegraded {
+		t.Errorf("Expected degraded, got %s", report.Status)
+	}
+	if len(report.Dependencies) != 2 {
+		t.Fatalf("Expected 2 dependencies, got %d", len(report.Dependencies))
+	}
+
+	database := report.Dependencies[0]
+	if database.Name != KindDatabase || database.Kind != KindDatabase || database.Status != StatusUp || database.Error != "" {
+		t.Errorf("Expected a healthy database, got %+v", database)
+	}
+	exchange := report.Dependencies[1]
+	if exchange.Name != "hyperliquid" || exchange.Kind != KindExchange || exchange.Status != StatusDown {
+		t.Errorf("Expected hyperliquid down, got %+v", exchange)
+	}
+	if exchange.Error != "connection refused" {
+		t.Errorf("Expected the probe error, got %q", exchange.Error)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Failed to encode report: %v", err)
+	}
+	for _, want := range []string{`"status":"degraded"`, `"latency_ms":`, `"error":"connection refused"`, `"name":"database"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %s in %s", want, data)
+		}
+	}
+}
+
+func TestCheck_DatabaseDown(t *testing.T) {
+	exchanges := []iface.ExchangeClient{&fakeExchange{name: "hyperliquid"}}
+
+	report, err := Check(context.Background(), &fakeDB{err: errors.New("401 unauthorized")}, exchanges)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if report.Status != StatusDown {
+		t.Errorf("Expected down when the database is down, got %s", report.Status)
+	}
+	if report.Dependencies[1].Status != StatusUp {
+		t.Errorf("Expected hyperliquid up, got %+v", report.Dependencies[1])
+	}
+}
+
+func TestCheckWithOptions_TimeoutsAndSlowProbes(t *testing.T) {
+	exchanges := []iface.ExchangeClient{
+		&fakeExchange{name: "hanging", delay: time.Minute},
+		&fakeExchange{name: "slow", delay: 30 * time.Millisecond},
+		noProbeExchange{},
+	}
+
+	start := time.Now()
+	report, err := CheckWithOptions(context.Background(), &fakeDB{}, exchanges, Options{
+		Timeout:       100 * time.Millisecond,
+		SlowThreshold: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("CheckWithOptions failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the hanging probe to be cut off by its timeout, took %v", elapsed)
+	}
+
+	want := map[string]Status{KindDatabase: StatusUp, "hanging": StatusDown, "slow": StatusDegraded, "no-probe": StatusUnknown}
+	for _, dep := range report.Dependencies {
+		if dep.Status != want[dep.Name] {
+			t.Errorf("Expected %s to be %s, got %s (%s)", dep.Name, want[dep.Name], dep.Status, dep.Error)
+		}
+	}
+	if !strings.Contains(report.Dependencies[1].Error, context.DeadlineExceeded.Error()) {
+		t.Errorf("Expected a deadline error for the hanging probe, got %q", report.Dependencies[1].Error)
+	}
+	if report.Status != StatusDegraded {
+		t.Errorf("Expected degraded, got %s", report.Status)
+	}
+}
+
+func TestCheck_AllUp(t *testing.T) {
+	report, err := Check(context.Background(), &fakeDB{}, []iface.ExchangeClient{noProbeExchange{}})
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if report.Status != StatusUp {
+		t.Errorf("Expected up when no dependency failed, got %s", report.Status)
+	}
+}
+
+// clockedDB is a DBClient whose Ping advances a fake clock by took
+type clockedDB struct {
+	db.DBClient
+	clk  *clock.Fake
+	took time.Duration
+}
+
+func (c *clockedDB) Ping(ctx context.Context) error {
+	c.clk.Advance(c.took)
+	return nil
+}
+
+func TestCheckWithOptions_FakeClock(t *testing.T) {
+	now := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(now)
+
+	report, err := CheckWithOptions(context.Background(), &clockedDB{clk: clk, took: 3 * time.Second}, nil, Options{Clock: clk})
+	if err != nil {
+		t.Fatalf("CheckWithOptions failed: %v", err)
+	}
+	if !report.CheckedAt.Equal(now) {
+		t.Errorf("Expected CheckedAt %s, got %s", now, report.CheckedAt)
+	}
+	database := report.Dependencies[0]
+	if database.Latency != 3*time.Second || database.Status != StatusDegraded {
+		t.Errorf("Expected a degraded 3s probe timed by the clock, got %+v", database)
+	}
+}
@@ -1,7 +1,10 @@
 package models
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"strings"
 )
 
 // AccountType represents an account type in the database
@@ -10,6 +13,16 @@ type AccountType struct {
 	Code string `json:"code" db:"code"`
 }
 
+// Account type codes seeded into exchange_account_types
+const (
+	AccountTypeMain       = "main"
+	AccountTypeSubAccount = "sub_account"
+	AccountTypeVault      = "vault"
+)
+
+// AccountTypes lists every canonical account type code
+var AccountTypes = []string{AccountTypeMain, AccountTypeSubAccount, AccountTypeVault}
+
 // ExchangeAccount represents a user's account on an exchange in the database
 // Uses Hasura relationship to fetch nested Exchange object
 type ExchangeAccount struct {
@@ -19,6 +32,32 @@ type ExchangeAccount struct {
 	AccountIdentifier   string          `json:"account_identifier" db:"account_identifier"`
 	AccountType         string          `json:"account_type" db:"account_type"` // "main", "sub_account", "vault" - FK to exchange_account_types.code
 	AccountTypeMetadata json.RawMessage `json:"account_type_metadata" db:"account_type_metadata"` // JSONB
+	PnLDenomination     *string         `json:"pnl_denomination" db:"pnl_denomination"` // "USDC", "USDT" or "USD" (nil = not set)
+	Enabled             bool            `json:"enabled" db:"enabled"` // false = syncing paused (see db.Client.SetAccountEnabled)
+}
+
+// UnmarshalJSON custom unmarshaler to handle null JSONB metadata
+// A null account_type_metadata leaves AccountTypeMetadata nil, as when it is absent, rather than
+// the literal null bytes; the nested exchange is decoded as usual
+func (a *ExchangeAccount) UnmarshalJSON(data []byte) error {
+	type Alias ExchangeAccount
+	aux := &struct {
+		AccountTypeMetadata json.RawMessage `json:"account_type_metadata"` // JSONB, null when unset
+		*Alias
+	}{
+		Alias: (*Alias)(a),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	a.AccountTypeMetadata = nil
+	if trimmed := bytes.TrimSpace(aux.AccountTypeMetadata); len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null")) {
+		a.AccountTypeMetadata = aux.AccountTypeMetadata
+	}
+
+	return nil
 }
 
 // ExchangeAccountInput is used for GraphQL mutations
@@ -28,4 +67,43 @@ type ExchangeAccountInput struct {
 	AccountIdentifier   string          `json:"account_identifier"`
 	AccountType         string          `json:"account_type"` // Uses code string ('main', 'sub_account', 'vault')
 	AccountTypeMetadata json.RawMessage `json:"account_type_metadata,omitempty"`
+	PnLDenomination     *string         `json:"pnl_denomination,omitempty"` // nil = not set
+}
+
+// AccountFilter represents filtering options for listing exchange accounts
+// Empty fields do not filter; non-empty fields are ANDed together
+type AccountFilter struct {
+	ExchangeNames []string // Exchange names (e.g. "hyperliquid"), matched through the exchange relationship
+	AccountTypes  []string // Account type codes ("main", "sub_account", "vault")
+	ActiveOnly    bool     // Only accounts that are enabled for syncing
+	UserIDs       []string // Owning users
+	UserID        *string  // Single owning user (see db.ScopedClient)
+}
+
+// PnLDenominations are the currencies PnL can be displayed in
+var PnLDenominations = []string{"USDC", "USDT", "USD"}
+
+// DefaultPnLDenomination is used for accounts without a PnL denomination preference
+const DefaultPnLDenomination = "USDC"
+
+// NormalizePnLDenomination returns denom upper-cased if it is one of PnLDenominations
+func NormalizePnLDenomination(denom string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(denom))
+	for _, allowed := range PnLDenominations {
+		if normalized == allowed {
+			return normalized, nil
+		}
+	}
+	return "", fmt.Errorf("invalid PnL denomination %q: must be one of %s", denom, strings.Join(PnLDenominations, ", "))
+}
+
+// ExchangeIdentifier names an account by exchange name (e.g. "hyperliquid") and account identifier
+type ExchangeIdentifier struct {
+	Exchange   string
+	Identifier string
+}
+
+// AccountPatch lists the exchange account columns to change; nil fields are left as they are
+type AccountPatch struct {
+	AccountTypeMetadata json.RawMessage // JSONB; see MigrateAccountMetadata for the versioned shapes
 }
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// InvalidAccountError indicates an account identifier that is malformed for its exchange
+// Returned before the identifier is stored or sent so the caller sees the real cause
+type InvalidAccountError struct {
+	Exchange   string
+	Identifier string
+	Reason     string
+}
+
+func (e *InvalidAccountError) Error() string {
+	return fmt.Sprintf("invalid %s account identifier %q: %s", e.Exchange, e.Identifier, e.Reason)
+}
+
+// Address formats used by exchanges, keyed by exchange name
+var (
+	evmExchanges    = map[string]bool{"hyperliquid": true}
+	solanaExchanges = map[string]bool{"drift": true}
+)
+
+// NormalizeAccountIdentifier validates identifier for exchangeName and returns its canonical form
+// EVM addresses (hyperliquid) are returned EIP-55 checksummed; single-case input is accepted and
+// mixed-case input must already carry a valid checksum. Solana addresses (drift) must be base58
+// encoded 32-byte public keys. Other exchanges only have surrounding whitespace trimmed
+// Returns an *InvalidAccountError on failure
+func NormalizeAccountIdentifier(exchangeName, identifier string) (string, error) {
+	trimmed := strings.TrimSpace(identifier)
+	invalid := func(reason string) error {
+		return &InvalidAccountError{Exchange: exchangeName, Identifier: identifier, Reason: reason}
+	}
+	if trimmed == "" {
+		return "", invalid("must not be empty")
+	}
+
+	exchange := strings.ToLower(exchangeName)
+	switch {
+	case evmExchanges[exchange]:
+		return normalizeEVMAddress(trimmed, invalid)
+	case solanaExchanges[exchange]:
+		key, ok := decodeBase58(trimmed)
+		if !ok {
+			return "", invalid("must be base58 encoded")
+		}
+		if len(key) != 32 {
+			return "", invalid(fmt.Sprintf("must decode to 32 bytes, got %d", len(key)))
+		}
+		return trimmed, nil
+	default:
+		return trimmed, nil
+	}
+}
+
+// IdentifierIgnoresCase reports whether exchangeName's account identifiers compare case-insensitively
+// (EVM addresses), so rows stored before checksumming, in lower case, still match
+func IdentifierIgnoresCase(exchangeName string) bool {
+	return evmExchanges[strings.ToLower(exchangeName)]
+}
+
+// normalizeEVMAddress validates a 0x-prefixed 20-byte hex address and returns its EIP-55 form
+func normalizeEVMAddress(address string, invalid func(reason string) error) (string, error) {
+	if !strings.HasPrefix(address, "0x") && !strings.HasPrefix(address, "0X") {
+		return "", invalid("must start with 0x")
+	}
+	digits := address[2:]
+	if len(digits) != 40 {
+		return "", invalid(fmt.Sprintf("must have 40 hex digits, got %d", len(digits)))
+	}
+	if _, err := hex.DecodeString(digits); err != nil {
+		return "", invalid("must be hexadecimal")
+	}
+
+	checksummed := checksumAddress(digits)
+	lower, upper := strings.ToLower(digits), strings.ToUpper(digits)
+	if digits != lower && digits != upper && "0x"+digits != checksummed {
+		return "", invalid("mixed-case address has an invalid EIP-55 checksum")
+	}
+	return checksummed, nil
+}
+
+// checksumAddress returns the EIP-55 encoding of 40 hex digits: a letter is uppercased when the
+// matching nibble of keccak256(lowercase hex) is 8 or more
+func checksumAddress(digits string) string {
+	lower := strings.ToLower(digits)
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(lower))
+	hash := h.Sum(nil)
+
+	out := make([]byte, 0, 42)
+	out = append(out, '0', 'x')
+	for i := 0; i < len(lower); i++ {
+		ch := lower[i]
+		nibble := hash[i/2] >> 4
+		if i%2 == 1 {
+			nibble = hash[i/2] & 0x0f
+		}
+		if ch >= 'a' && nibble >= 8 {
+			ch -= 'a' - 'A'
+		}
+		out = append(out, ch)
+	}
+	return string(out)
+}
+
+// base58Alphabet is the Bitcoin/Solana base58 alphabet
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// decodeBase58 decodes s, keeping one zero byte per leading '1'
+func decodeBase58(s string) ([]byte, bool) {
+	var leadingZeros int
+	for leadingZeros < len(s) && s[leadingZeros] == '1' {
+		leadingZeros++
+	}
+
+	// Big-endian base-256 accumulator
+	var out []byte
+	for i := leadingZeros; i < len(s); i++ {
+		carry := strings.IndexByte(base58Alphabet, s[i])
+		if carry < 0 {
+			return nil, false
+		}
+		for j := len(out) - 1; j >= 0; j-- {
+			carry += int(out[j]) * 58
+			out[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			out = append([]byte{byte(carry)}, out...)
+			carry >>= 8
+		}
+	}
+
+	return append(make([]byte, leadingZeros), out...), true
+}
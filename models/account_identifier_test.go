@@ -0,0 +1,62 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeAccountIdentifier(t *testing.T) {
+	tests := []struct {
+		name       string
+		exchange   string
+		identifier string
+		want       string
+		wantErr    bool
+	}{
+		// EIP-55 reference vectors
+		{name: "valid checksum", exchange: "hyperliquid", identifier: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", want: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{name: "valid checksum 2", exchange: "hyperliquid", identifier: "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", want: "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"},
+		{name: "lowercase normalized", exchange: "hyperliquid", identifier: "0xdbf03b407c01e7cd3cbea99509d93f8dddc8c6fb", want: "0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB"},
+		{name: "uppercase normalized", exchange: "Hyperliquid", identifier: " 0xD1220A0CF47C7B9BE7A2E6BA89F429762E7B9ADB ", want: "0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb"},
+		{name: "digits only", exchange: "hyperliquid", identifier: "0x1234567890123456789012345678901234567890", want: "0x1234567890123456789012345678901234567890"},
+		{name: "bad checksum", exchange: "hyperliquid", identifier: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", wantErr: true},
+		{name: "too short", exchange: "hyperliquid", identifier: "0x123", wantErr: true},
+		{name: "not hex", exchange: "hyperliquid", identifier: "0xzz34567890123456789012345678901234567890", wantErr: true},
+		{name: "missing prefix", exchange: "hyperliquid", identifier: "1234567890123456789012345678901234567890", wantErr: true},
+		{name: "empty", exchange: "hyperliquid", identifier: "  ", wantErr: true},
+
+		{name: "solana address", exchange: "drift", identifier: "11111111111111111111111111111111", want: "11111111111111111111111111111111"},
+		{name: "solana key", exchange: "drift", identifier: "dRiftyHA39MWEi3m9aunc5MzRF1JYuBsbn6VPcn33UH", want: "dRiftyHA39MWEi3m9aunc5MzRF1JYuBsbn6VPcn33UH"},
+		{name: "solana invalid alphabet", exchange: "drift", identifier: "0OIl1111111111111111111111111111", wantErr: true},
+		{name: "solana wrong length", exchange: "drift", identifier: "3yZe7d", wantErr: true},
+
+		{name: "unknown exchange trimmed", exchange: "lighter", identifier: " 42 ", want: "42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeAccountIdentifier(tt.exchange, tt.identifier)
+			if tt.wantErr {
+				var invalid *InvalidAccountError
+				if !errors.As(err, &invalid) {
+					t.Fatalf("Expected *InvalidAccountError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeAccountIdentifier failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeAccountIdentifier() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentifierIgnoresCase(t *testing.T) {
+	for exchange, want := range map[string]bool{"hyperliquid": true, "Hyperliquid": true, "drift": false, "lighter": false} {
+		if got := IdentifierIgnoresCase(exchange); got != want {
+			t.Errorf("IdentifierIgnoresCase(%q) = %v, want %v", exchange, got, want)
+		}
+	}
+}
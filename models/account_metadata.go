@@ -0,0 +1,83 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// CurrentAccountMetadataVersion is the account_type_metadata wire format written by this version
+// of the library. Rows without a "version" field are version 0
+const CurrentAccountMetadataVersion = 1
+
+// VaultMetadata is the account_type_metadata of vault accounts
+type VaultMetadata struct {
+	Version int    `json:"version"`
+	Address string `json:"address,omitempty"` // Vault address
+}
+
+// SubAccountMetadata is the account_type_metadata of sub-accounts
+type SubAccountMetadata struct {
+	Version int `json:"version"`
+	Index   int `json:"index"` // Sub-account index under the master account
+}
+
+// metadataMigration upgrades decoded metadata fields by one version in place
+type metadataMigration func(fields map[string]json.RawMessage) error
+
+// accountMetadataMigrations lists, per account type, the step upgrading version i to i+1 at index i
+// Account types without typed metadata (e.g. main) are not listed and are never migrated
+var accountMetadataMigrations = map[string][]metadataMigration{
+	AccountTypeVault:      {stampVersionOnly},
+	AccountTypeSubAccount: {stampVersionOnly},
+}
+
+// stampVersionOnly is the v0 to v1 step: v1 has the v0 fields plus the version stamp, which
+// MigrateAccountMetadata adds once every step has run
+func stampVersionOnly(map[string]json.RawMessage) error {
+	return nil
+}
+
+// MigrateAccountMetadata upgrades raw account_type_metadata of the given account type to
+// CurrentAccountMetadataVersion. changed is false when raw is already current, empty, or belongs
+// to an account type without typed metadata; raw is then returned as is
+// Fields a migration step does not know are kept. Metadata from a newer version is an error
+func MigrateAccountMetadata(raw json.RawMessage, accountType string) (json.RawMessage, bool, error) {
+	steps, ok := accountMetadataMigrations[accountType]
+	trimmed := bytes.TrimSpace(raw)
+	if !ok || len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return raw, false, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, false, fmt.Errorf("failed to decode %s metadata: %w", accountType, err)
+	}
+
+	version := 0
+	if encoded, ok := fields["version"]; ok {
+		if err := json.Unmarshal(encoded, &version); err != nil {
+			return nil, false, fmt.Errorf("invalid %s metadata version %s: %w", accountType, encoded, err)
+		}
+	}
+	switch {
+	case version == CurrentAccountMetadataVersion:
+		return raw, false, nil
+	case version > CurrentAccountMetadataVersion || version < 0:
+		return nil, false, fmt.Errorf("unsupported %s metadata version %d (current is %d)", accountType, version, CurrentAccountMetadataVersion)
+	}
+
+	for v := version; v < CurrentAccountMetadataVersion; v++ {
+		if err := steps[v](fields); err != nil {
+			return nil, false, fmt.Errorf("failed to migrate %s metadata from version %d: %w", accountType, v, err)
+		}
+	}
+	fields["version"] = json.RawMessage(strconv.Itoa(CurrentAccountMetadataVersion))
+
+	migrated, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode %s metadata: %w", accountType, err)
+	}
+	return migrated, true, nil
+}
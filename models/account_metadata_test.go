@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMigrateAccountMetadata_V0ToV1(t *testing.T) {
+	tests := []struct {
+		name        string
+		accountType string
+		raw         string
+		check       func(t *testing.T, migrated json.RawMessage)
+	}{
+		{
+			name:        "vault",
+			accountType: AccountTypeVault,
+			raw:         `{"address": "0xdfc24b077bc1425ad1dea75bcb6f8158e10df303"}`,
+			check: func(t *testing.T, migrated json.RawMessage) {
+				var meta VaultMetadata
+				if err := json.Unmarshal(migrated, &meta); err != nil {
+					t.Fatalf("Failed to decode migrated metadata: %v", err)
+				}
+				if meta.Version != 1 || meta.Address != "0xdfc24b077bc1425ad1dea75bcb6f8158e10df303" {
+					t.Errorf("Expected v1 vault metadata with the address kept, got %+v", meta)
+				}
+			},
+		},
+		{
+			name:        "sub-account",
+			accountType: AccountTypeSubAccount,
+			raw:         `{"index": 3, "label": "hedge"}`,
+			check: func(t *testing.T, migrated json.RawMessage) {
+				var meta SubAccountMetadata
+				if err := json.Unmarshal(migrated, &meta); err != nil {
+					t.Fatalf("Failed to decode migrated metadata: %v", err)
+				}
+				if meta.Version != 1 || meta.Index != 3 {
+					t.Errorf("Expected v1 sub-account metadata with the index kept, got %+v", meta)
+				}
+				if !strings.Contains(string(migrated), `"label":"hedge"`) {
+					t.Errorf("Expected unknown fields to be kept, got %s", migrated)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrated, changed, err := MigrateAccountMetadata(json.RawMessage(tt.raw), tt.accountType)
+			if err != nil {
+				t.Fatalf("MigrateAccountMetadata failed: %v", err)
+			}
+			if !changed {
+				t.Fatal("Expected v0 metadata to change")
+			}
+			tt.check(t, migrated)
+
+			// Migrating again is a no-op
+			again, changed, err := MigrateAccountMetadata(migrated, tt.accountType)
+			if err != nil || changed || string(again) != string(migrated) {
+				t.Errorf("Expected current metadata to be left alone, got %s, %v, %v", again, changed, err)
+			}
+		})
+	}
+}
+
+func TestMigrateAccountMetadata_Unchanged(t *testing.T) {
+	tests := []struct {
+		name        string
+		accountType string
+		raw         json.RawMessage
+	}{
+		{name: "main account", accountType: AccountTypeMain, raw: json.RawMessage(`{"key": "value"}`)},
+		{name: "null", accountType: AccountTypeVault, raw: json.RawMessage(`null`)},
+		{name: "empty", accountType: AccountTypeSubAccount, raw: nil},
+		{name: "current", accountType: AccountTypeVault, raw: json.RawMessage(`{"version": 1, "address": "0xabc"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrated, changed, err := MigrateAccountMetadata(tt.raw, tt.accountType)
+			if err != nil {
+				t.Fatalf("MigrateAccountMetadata failed: %v", err)
+			}
+			if changed || string(migrated) != string(tt.raw) {
+				t.Errorf("Expected %s to be returned unchanged, got %s (changed=%v)", tt.raw, migrated, changed)
+			}
+		})
+	}
+}
+
+func TestMigrateAccountMetadata_Invalid(t *testing.T) {
+	for _, raw := range []string{`{"version": 2}`, `{"version": "one"}`, `[1, 2]`} {
+		if _, _, err := MigrateAccountMetadata(json.RawMessage(raw), AccountTypeVault); err == nil {
+			t.Errorf("Expected an error for %s", raw)
+		}
+	}
+}
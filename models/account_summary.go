@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccountDataSummary describes what is stored for one exchange account
+// Timestamps are nil and counts zero when the corresponding table has no rows
+type AccountDataSummary struct {
+	ExchangeAccountID   uuid.UUID  `json:"exchange_account_id"`
+	TradeCount          int        `json:"trade_count"`
+	FirstTradeAt        *time.Time `json:"first_trade_at"`
+	LastTradeAt         *time.Time `json:"last_trade_at"`
+	FundingPaymentCount int        `json:"funding_payment_count"`
+	FirstFundingAt      *time.Time `json:"first_funding_at"`
+	LastFundingAt       *time.Time `json:"last_funding_at"`
+	OpenPositionCount   int        `json:"open_position_count"`
+	ClosedPositionCount int        `json:"closed_position_count"`
+	// LastSyncCursor is the timestamp of the newest trade already processed into positions
+	LastSyncCursor *time.Time `json:"last_sync_cursor"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExchangeAccount_UnmarshalJSON_NestedExchange(t *testing.T) {
+	data := []byte(`{
+		"id": "660e8400-e29b-41d4-a716-446655440000",
+		"user_id": "770e8400-e29b-41d4-a716-446655440000",
+		"exchange": {"id": "550e8400-e29b-41d4-a716-446655440000", "name": "hyperliquid", "display_name": "Hyperliquid"},
+		"account_identifier": "0x1234567890123456789012345678901234567890",
+		"account_type": "sub_account",
+		"account_type_metadata": {"parent_address": "0xabc"},
+		"pnl_denomination": "USDT",
+		"enabled": true
+	}`)
+
+	var account ExchangeAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		t.Fatalf("UnmarshalJSON failed: %v", err)
+	}
+
+	if account.Exchange == nil {
+		t.Fatal("Expected nested exchange, got nil")
+	}
+	if account.Exchange.Name != "hyperliquid" || account.Exchange.DisplayName != "Hyperliquid" {
+		t.Errorf("Expected exchange hyperliquid/Hyperliquid, got %s/%s", account.Exchange.Name, account.Exchange.DisplayName)
+	}
+	if account.ID != "660e8400-e29b-41d4-a716-446655440000" || account.AccountType != AccountTypeSubAccount {
+		t.Errorf("Expected id and account type to be decoded, got %q and %q", account.ID, account.AccountType)
+	}
+	if string(account.AccountTypeMetadata) != `{"parent_address": "0xabc"}` {
+		t.Errorf("Expected metadata to be kept as is, got %s", account.AccountTypeMetadata)
+	}
+	if account.PnLDenomination == nil || *account.PnLDenomination != "USDT" {
+		t.Errorf("Expected pnl denomination USDT, got %v", account.PnLDenomination)
+	}
+	if !account.Enabled {
+		t.Error("Expected account to be enabled")
+	}
+}
+
+func TestExchangeAccount_UnmarshalJSON_NullMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "null", data: `{"id": "a", "exchange": null, "account_type_metadata": null}`},
+		{name: "absent", data: `{"id": "a"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := ExchangeAccount{AccountTypeMetadata: json.RawMessage(`{"stale": true}`)}
+			if err := json.Unmarshal([]byte(tt.data), &account); err != nil {
+				t.Fatalf("UnmarshalJSON failed: %v", err)
+			}
+			if account.AccountTypeMetadata != nil {
+				t.Errorf("Expected nil metadata, got %q", account.AccountTypeMetadata)
+			}
+			if account.Exchange != nil {
+				t.Errorf("Expected nil exchange, got %+v", account.Exchange)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Activity is an account's trades and funding payments within one time window, read together so
+// both reflect the same moment
+type Activity struct {
+	ExchangeAccountID uuid.UUID
+	Since             time.Time         // Inclusive start of the window
+	Until             time.Time         // Exclusive end of the window
+	Trades            []*Trade          // Oldest first
+	FundingPayments   []*FundingPayment // Oldest first
+}
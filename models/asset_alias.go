@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AssetAlias records that an exchange renamed an asset, e.g. MATIC to POL on Hyperliquid
+// Trades stored under Alias and under Asset belong to the same asset
+// Matches the 'asset_aliases' table schema
+type AssetAlias struct {
+	ID            uuid.UUID `json:"id"`
+	Exchange      string    `json:"exchange"`       // Exchange name, e.g. "hyperliquid"
+	Asset         string    `json:"asset"`          // Current symbol, e.g. "POL"
+	Alias         string    `json:"alias"`          // Former symbol, e.g. "MATIC"
+	EffectiveFrom time.Time `json:"effective_from"` // When the exchange started using Asset
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// UnmarshalJSON custom unmarshaler to handle BIGINT timestamps (Unix milliseconds)
+func (a *AssetAlias) UnmarshalJSON(data []byte) error {
+	type Alias AssetAlias
+	aux := &struct {
+		EffectiveFrom interface{} `json:"effective_from"` // Unix milliseconds (number or string)
+		CreatedAt     interface{} `json:"created_at"`     // timestamptz string or Unix milliseconds
+		*Alias
+	}{
+		Alias: (*Alias)(a),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.EffectiveFrom != nil {
+		effectiveFrom, err := parseFlexibleTime(aux.EffectiveFrom)
+		if err != nil {
+			return fmt.Errorf("failed to parse effective_from: %w", err)
+		}
+		a.EffectiveFrom = effectiveFrom
+	}
+
+	if aux.CreatedAt != nil {
+		createdAt, err := parseFlexibleTime(aux.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to parse created_at: %w", err)
+		}
+		a.CreatedAt = createdAt
+	}
+
+	return nil
+}
+
+// AssetAliasInput represents an asset alias to record
+// Used for GraphQL mutations
+type AssetAliasInput struct {
+	Exchange      string
+	Asset         string
+	Alias         string
+	EffectiveFrom time.Time
+}
+
+// Validate checks that the input has everything CreateAssetAlias needs
+// Returns a *ValidationError listing every invalid field
+func (in *AssetAliasInput) Validate() error {
+	verr := &ValidationError{Resource: "asset alias"}
+
+	if in.Exchange == "" {
+		verr.add("exchange", "must not be empty")
+	}
+	if in.Asset == "" {
+		verr.add("asset", "must not be empty")
+	}
+	if in.Alias == "" {
+		verr.add("alias", "must not be empty")
+	}
+	if in.Asset != "" && in.Asset == in.Alias {
+		verr.add("alias", "must differ from asset")
+	}
+	if in.EffectiveFrom.IsZero() {
+		verr.add("effective_from", "must not be zero")
+	}
+
+	return verr.errOrNil()
+}
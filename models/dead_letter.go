@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeadLetter records a fetched row that repeatedly failed to persist, so the rest of its batch
+// could be stored without it
+// Matches the 'dead_letters' table schema
+type DeadLetter struct {
+	ID                uuid.UUID       `json:"id"`
+	ExchangeAccountID uuid.UUID       `json:"exchange_account_id"`
+	Kind              string          `json:"kind"`     // SyncRunKindTrades or SyncRunKindFundingPayments
+	RowKey            string          `json:"row_key"`  // Exchange trade ID or payment ID of the row
+	Payload           json.RawMessage `json:"payload"`  // The input row as JSON (jsonb)
+	Error             string          `json:"error"`    // Error of the last failed attempt
+	Attempts          int             `json:"attempts"` // Failed attempts before the row was set aside
+	ResolvedAt        *time.Time      `json:"resolved_at"`
+	CreatedAt         time.Time       `json:"created_at"`
+}
+
+// UnmarshalJSON custom unmarshaler to handle BIGINT timestamps (Unix milliseconds)
+func (d *DeadLetter) UnmarshalJSON(data []byte) error {
+	type Alias DeadLetter
+	aux := &struct {
+		ResolvedAt interface{} `json:"resolved_at"` // Unix milliseconds (number or string), null while unresolved
+		CreatedAt  interface{} `json:"created_at"`  // timestamptz string or Unix milliseconds
+		*Alias
+	}{
+		Alias: (*Alias)(d),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	d.ResolvedAt = nil
+	if aux.ResolvedAt != nil {
+		resolvedAt, err := parseFlexibleTime(aux.ResolvedAt)
+		if err != nil {
+			return fmt.Errorf("failed to parse resolved_at: %w", err)
+		}
+		d.ResolvedAt = &resolvedAt
+	}
+
+	if aux.CreatedAt != nil {
+		createdAt, err := parseFlexibleTime(aux.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to parse created_at: %w", err)
+		}
+		d.CreatedAt = createdAt
+	}
+
+	return nil
+}
+
+// DeadLetterInput represents a dead letter to record
+// Used for GraphQL mutations
+type DeadLetterInput struct {
+	ExchangeAccountID uuid.UUID
+	Kind              string
+	RowKey            string
+	Payload           json.RawMessage
+	Error             string
+	Attempts          int
+}
+
+// Validate checks that the input has everything AddDeadLetters needs
+// Returns a *ValidationError listing every invalid field
+func (in *DeadLetterInput) Validate() error {
+	verr := &ValidationError{Resource: "dead letter"}
+
+	if in.ExchangeAccountID == uuid.Nil {
+		verr.add("exchange_account_id", "must not be empty")
+	}
+	if in.Kind != SyncRunKindTrades && in.Kind != SyncRunKindFundingPayments {
+		verr.add("kind", fmt.Sprintf("must be %q or %q, got %q", SyncRunKindTrades, SyncRunKindFundingPayments, in.Kind))
+	}
+	if in.RowKey == "" {
+		verr.add("row_key", "must not be empty")
+	}
+	if !json.Valid(in.Payload) {
+		verr.add("payload", "must be valid JSON")
+	}
+	if in.Attempts < 1 {
+		verr.add("attempts", "must be at least 1")
+	}
+
+	return verr.errOrNil()
+}
@@ -1,5 +1,7 @@
 package models
 
+import "fmt"
+
 // Exchange represents a supported exchange in the database
 // Matches the 'exchanges' table schema
 type Exchange struct {
@@ -13,3 +15,23 @@ type ExchangeInput struct {
 	Name        string `json:"name"`
 	DisplayName string `json:"display_name"`
 }
+
+// ExchangeInfo describes a registered exchange for display and seeding
+type ExchangeInfo struct {
+	Name            string `json:"name"`             // Registry key, e.g. "hyperliquid"
+	DisplayName     string `json:"display_name"`     // Name shown to users, e.g. "Hyperliquid"
+	IconSlug        string `json:"icon_slug"`        // Icon asset name used by the UI
+	DefaultQuote    string `json:"default_quote"`    // Quote asset for symbols without an explicit quote
+	SupportsTestnet bool   `json:"supports_testnet"` // Whether the client can target the exchange's testnet
+}
+
+// Validate checks that info can be registered
+func (info ExchangeInfo) Validate() error {
+	if info.Name == "" {
+		return fmt.Errorf("invalid exchange info: name is required")
+	}
+	if info.DisplayName == "" {
+		return fmt.Errorf("invalid exchange info for %s: display name is required", info.Name)
+	}
+	return nil
+}
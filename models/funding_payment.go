@@ -3,6 +3,8 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +20,8 @@ type FundingPayment struct {
 	Amount            string    `json:"amount"` // Using string for precision (NUMERIC in DB), signed: positive = received, negative = paid
 	Timestamp         time.Time `json:"timestamp"`
 	PaymentID         string    `json:"payment_id"`
+	CreatedAt         time.Time `json:"created_at"` // When the row was ingested (zero if not selected)
+	Source            string    `json:"source"`     // SourceExchangeSync, SourceManualImport or SourceBackfill
 }
 
 // UnmarshalJSON custom unmarshaler to handle BIGINT timestamp (Unix milliseconds) and NUMERIC as numbers
@@ -26,6 +30,7 @@ func (f *FundingPayment) UnmarshalJSON(data []byte) error {
 	aux := &struct {
 		Timestamp interface{} `json:"timestamp"` // Can be number (Unix milliseconds) or string
 		Amount    interface{} `json:"amount"`   // Can be string or number
+		CreatedAt interface{} `json:"created_at"` // timestamptz string or Unix milliseconds
 		*Alias
 	}{
 		Alias: (*Alias)(f),
@@ -35,29 +40,13 @@ func (f *FundingPayment) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	// Parse timestamp (BIGINT Unix milliseconds from PostgreSQL)
+	// Parse timestamp (BIGINT Unix milliseconds, or a timestamptz string on mixed schemas)
 	if aux.Timestamp != nil {
-		var unixMillis int64
-		switch v := aux.Timestamp.(type) {
-		case float64:
-			// JSON numbers come as float64
-			unixMillis = int64(v)
-		case int64:
-			unixMillis = v
-		case int:
-			unixMillis = int64(v)
-		case string:
-			// Try parsing as number string first
-			var err error
-			unixMillis, err = parseInt64(v)
-			if err != nil {
-				return fmt.Errorf("failed to parse timestamp: %w", err)
-			}
-		default:
-			return fmt.Errorf("unexpected timestamp type: %T", aux.Timestamp)
+		timestamp, err := parseFlexibleTime(aux.Timestamp)
+		if err != nil {
+			return fmt.Errorf("failed to parse timestamp: %w", err)
 		}
-		// Convert Unix milliseconds to time.Time
-		f.Timestamp = time.Unix(0, unixMillis*int64(time.Millisecond)).UTC()
+		f.Timestamp = timestamp
 	}
 
 	// Convert NUMERIC field (can be number or string) to string
@@ -65,6 +54,15 @@ func (f *FundingPayment) UnmarshalJSON(data []byte) error {
 		f.Amount = convertToString(aux.Amount)
 	}
 
+	// Parse created_at (timestamptz string or Unix milliseconds)
+	if aux.CreatedAt != nil {
+		createdAt, err := parseFlexibleTime(aux.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to parse created_at: %w", err)
+		}
+		f.CreatedAt = createdAt
+	}
+
 	return nil
 }
 
@@ -77,4 +75,98 @@ type FundingPaymentInput struct {
 	Amount            string    `json:"amount"`
 	Timestamp         time.Time `json:"timestamp"`
 	PaymentID         string    `json:"payment_id"`
+	Source            string    `json:"source,omitempty"` // How the row was obtained (empty = not recorded)
+}
+
+// Validate checks that the input has everything AddFundingPayments needs
+// Returns a *ValidationError listing every invalid field
+func (in *FundingPaymentInput) Validate() error {
+	verr := &ValidationError{Resource: "funding payment"}
+
+	if strings.TrimSpace(in.PaymentID) == "" {
+		verr.add("payment_id", "must not be empty")
+	}
+	if strings.TrimSpace(in.BaseAsset) == "" {
+		verr.add("base_asset", "must not be empty")
+	}
+	if strings.TrimSpace(in.QuoteAsset) == "" {
+		verr.add("quote_asset", "must not be empty")
+	}
+	if _, ok := parseDecimal(in.Amount); !ok {
+		verr.add("amount", fmt.Sprintf("must be a decimal number, got %q", in.Amount))
+	}
+	if in.Timestamp.IsZero() {
+		verr.add("timestamp", "must not be zero")
+	}
+
+	return verr.errOrNil()
+}
+
+// FundingPaymentFilter represents filtering options for listing funding payments
+type FundingPaymentFilter struct {
+	ExchangeAccountIDs []uuid.UUID // Empty slice = all accounts, non-empty = filter by these IDs
+	BaseAsset          *string
+	QuoteAsset         *string
+	TimestampGte       *time.Time
+	TimestampLte       *time.Time
+	UserID             *string // Only payments on accounts owned by this user
+	Limit              int     // Maximum number of rows to return (0 = no limit)
+	Offset             int     // Number of rows to skip (used with Limit for paging)
+}
+
+// fundingPaymentKey identifies a funding event independently of its derived payment ID
+type fundingPaymentKey struct {
+	accountID  uuid.UUID
+	baseAsset  string
+	quoteAsset string
+	timestamp  int64  // Unix milliseconds, the precision stored in the database
+	amount     string // Canonical decimal so "1.50" and "1.5" match
+}
+
+// DedupeFundingPayments collapses payments with the same account, assets, timestamp and amount,
+// keeping the entry with the smallest PaymentID so the result does not depend on input order
+// The result is sorted by timestamp, then account, assets and PaymentID
+func DedupeFundingPayments(payments []*FundingPaymentInput) []*FundingPaymentInput {
+	kept := make(map[fundingPaymentKey]*FundingPaymentInput, len(payments))
+	for _, payment := range payments {
+		if payment == nil {
+			continue
+		}
+		amount := payment.Amount
+		if r, ok := parseDecimal(amount); ok {
+			amount = FormatNumeric(r)
+		}
+		key := fundingPaymentKey{
+			accountID:  payment.ExchangeAccountID,
+			baseAsset:  payment.BaseAsset,
+			quoteAsset: payment.QuoteAsset,
+			timestamp:  payment.Timestamp.UnixMilli(),
+			amount:     amount,
+		}
+		if existing, ok := kept[key]; !ok || payment.PaymentID < existing.PaymentID {
+			kept[key] = payment
+		}
+	}
+
+	result := make([]*FundingPaymentInput, 0, len(kept))
+	for _, payment := range kept {
+		result = append(result, payment)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		if a.ExchangeAccountID != b.ExchangeAccountID {
+			return a.ExchangeAccountID.String() < b.ExchangeAccountID.String()
+		}
+		if a.BaseAsset != b.BaseAsset {
+			return a.BaseAsset < b.BaseAsset
+		}
+		if a.QuoteAsset != b.QuoteAsset {
+			return a.QuoteAsset < b.QuoteAsset
+		}
+		return a.PaymentID < b.PaymentID
+	})
+	return result
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -206,3 +207,114 @@ func TestFundingPayment_UnmarshalJSON_InvalidTimestamp(t *testing.T) {
 		t.Fatal("Expected error for invalid timestamp")
 	}
 }
+
+func TestFundingPaymentInput_Validate(t *testing.T) {
+	valid := func() *FundingPaymentInput {
+		return &FundingPaymentInput{
+			ExchangeAccountID: uuid.New(),
+			BaseAsset:         "BTC",
+			QuoteAsset:        "USDC",
+			Amount:            "-10.5",
+			Timestamp:         time.Unix(1609459200, 0),
+			PaymentID:         "1609459200000_BTC",
+		}
+	}
+
+	tests := []struct {
+		name          string
+		mutate        func(in *FundingPaymentInput)
+		invalidFields []string
+	}{
+		{name: "valid", mutate: func(in *FundingPaymentInput) {}},
+		{name: "exponent amount", mutate: func(in *FundingPaymentInput) { in.Amount = "1e-7" }},
+		{name: "empty payment id", mutate: func(in *FundingPaymentInput) { in.PaymentID = " " }, invalidFields: []string{"payment_id"}},
+		{name: "empty base asset", mutate: func(in *FundingPaymentInput) { in.BaseAsset = "" }, invalidFields: []string{"base_asset"}},
+		{name: "empty quote asset", mutate: func(in *FundingPaymentInput) { in.QuoteAsset = "" }, invalidFields: []string{"quote_asset"}},
+		{name: "unparseable amount", mutate: func(in *FundingPaymentInput) { in.Amount = "ten" }, invalidFields: []string{"amount"}},
+		{name: "fraction amount", mutate: func(in *FundingPaymentInput) { in.Amount = "1/3" }, invalidFields: []string{"amount"}},
+		{name: "empty amount", mutate: func(in *FundingPaymentInput) { in.Amount = "" }, invalidFields: []string{"amount"}},
+		{name: "zero timestamp", mutate: func(in *FundingPaymentInput) { in.Timestamp = time.Time{} }, invalidFields: []string{"timestamp"}},
+		{
+			name: "multiple fields",
+			mutate: func(in *FundingPaymentInput) {
+				in.PaymentID = ""
+				in.Amount = "abc"
+			},
+			invalidFields: []string{"payment_id", "amount"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := valid()
+			tt.mutate(in)
+			err := in.Validate()
+
+			if len(tt.invalidFields) == 0 {
+				if err != nil {
+					t.Fatalf("Expected no error, got: %v", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected *ValidationError, got: %v", err)
+			}
+			if len(verr.Fields) != len(tt.invalidFields) {
+				t.Fatalf("Expected %d invalid fields, got %+v", len(tt.invalidFields), verr.Fields)
+			}
+			for i, field := range tt.invalidFields {
+				if verr.Fields[i].Field != field {
+					t.Errorf("Expected invalid field %s, got %s", field, verr.Fields[i].Field)
+				}
+			}
+		})
+	}
+}
+
+func TestDedupeFundingPayments(t *testing.T) {
+	account := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	payment := func(id, asset, amount string, ts time.Time) *FundingPaymentInput {
+		return &FundingPaymentInput{
+			ExchangeAccountID: account, BaseAsset: asset, QuoteAsset: "USDC",
+			Amount: amount, Timestamp: ts, PaymentID: id,
+		}
+	}
+
+	inputs := []*FundingPaymentInput{
+		payment("b-later", "ETH", "-1", t0.Add(time.Hour)),
+		payment("0xhash-BTC", "BTC", "1.50", t0),
+		payment("0xhash-BTC-2", "BTC", "1.5", t0),                    // Same event, different derived ID and formatting
+		payment("0xhash-BTC-exact", "BTC", "1.50", t0),               // Exact duplicate of the event
+		payment("near-amount", "BTC", "1.500001", t0),                // Different amount: kept
+		payment("near-time", "BTC", "1.5", t0.Add(time.Millisecond)), // 1ms later: kept
+		payment("other-asset", "ETH", "1.5", t0),                     // Different asset: kept
+		nil,
+	}
+
+	got := DedupeFundingPayments(inputs)
+
+	wantIDs := []string{"0xhash-BTC", "near-amount", "other-asset", "near-time", "b-later"}
+	if len(got) != len(wantIDs) {
+		t.Fatalf("Expected %d payments, got %d", len(wantIDs), len(got))
+	}
+	for i, id := range wantIDs {
+		if got[i].PaymentID != id {
+			t.Errorf("Payment %d: expected %s, got %s", i, id, got[i].PaymentID)
+		}
+	}
+
+	// Input order must not change the result
+	reversed := make([]*FundingPaymentInput, len(inputs))
+	for i, p := range inputs {
+		reversed[len(inputs)-1-i] = p
+	}
+	again := DedupeFundingPayments(reversed)
+	for i := range got {
+		if again[i] != got[i] {
+			t.Errorf("Payment %d differs after reordering input: %s vs %s", i, again[i].PaymentID, got[i].PaymentID)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package models
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// NumericEqual reports whether two NUMERIC strings represent the same decimal value
+// Formatting differences are ignored ("10.50" == "10.5", "1e2" == "100")
+// Returns an error if either value is not a valid decimal
+func NumericEqual(a, b string) (bool, error) {
+	x, ok := parseDecimal(a)
+	if !ok {
+		return false, fmt.Errorf("invalid numeric value %q", a)
+	}
+	y, ok := parseDecimal(b)
+	if !ok {
+		return false, fmt.Errorf("invalid numeric value %q", b)
+	}
+	return x.Cmp(y) == 0, nil
+}
+
+// ParseNumeric parses a NUMERIC string into an exact rational value for decimal-safe arithmetic
+func ParseNumeric(s string) (*big.Rat, error) {
+	r, ok := parseDecimal(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid numeric value %q", s)
+	}
+	return r, nil
+}
+
+// numericScale is the number of fractional digits FormatNumeric keeps for non-terminating values
+const numericScale = 18
+
+// FormatNumeric formats r as a plain NUMERIC string without trailing zeros ("10.5", "-3", "0.001")
+// Values that don't terminate in decimal are rounded to 18 fractional digits
+func FormatNumeric(r *big.Rat) string {
+	return formatScaled(r, numericScale)
+}
+
+// WeightedAvgPrice returns the quantity-weighted average price of the trades on side ("buy" or
+// "sell"), as a NUMERIC string. Trades on the other side are ignored
+// Returns an error if trades is empty, no trade is on side, or a side, price or quantity is invalid
+func WeightedAvgPrice(trades []*Trade, side string) (string, error) {
+	if len(trades) == 0 {
+		return "", fmt.Errorf("cannot average price: no trades")
+	}
+	side, err := NormalizeTradeSide(side)
+	if err != nil {
+		return "", fmt.Errorf("cannot average price: %w", err)
+	}
+
+	notional, quantity := new(big.Rat), new(big.Rat)
+	for _, trade := range trades {
+		tradeSide, err := NormalizeTradeSide(trade.Side)
+		if err != nil {
+			return "", fmt.Errorf("cannot average price: trade %s side %w", trade.ID, err)
+		}
+		if tradeSide != side {
+			continue
+		}
+		price, err := ParseNumeric(trade.Price)
+		if err != nil {
+			return "", fmt.Errorf("cannot average price: trade %s price: %w", trade.ID, err)
+		}
+		qty, err := ParseNumeric(trade.Quantity)
+		if err != nil {
+			return "", fmt.Errorf("cannot average price: trade %s quantity: %w", trade.ID, err)
+		}
+		notional.Add(notional, new(big.Rat).Mul(price, qty))
+		quantity.Add(quantity, qty)
+	}
+
+	if quantity.Sign() == 0 {
+		return "", fmt.Errorf("cannot average price: no %s quantity", side)
+	}
+	return FormatNumeric(notional.Quo(notional, quantity)), nil
+}
+
+// Decimal is a NUMERIC value in string form ("10.5", "-3"), as held by model fields like Trade.Price
+type Decimal = string
+
+// WeightedAvg returns the average of values weighted by weights (e.g. fill prices by size)
+// Returns an error if the slices are empty or differ in length, a value is invalid, a weight is
+// negative, or the weights sum to zero
+func WeightedAvg(values, weights []Decimal) (Decimal, error) {
+	if len(values) == 0 {
+		return "", fmt.Errorf("cannot average: no values")
+	}
+	if len(values) != len(weights) {
+		return "", fmt.Errorf("cannot average: %d values but %d weights", len(values), len(weights))
+	}
+
+	total, weightSum := new(big.Rat), new(big.Rat)
+	for i := range values {
+		value, err := ParseNumeric(values[i])
+		if err != nil {
+			return "", fmt.Errorf("cannot average: value %d: %w", i, err)
+		}
+		weight, err := parseWeight(weights[i])
+		if err != nil {
+			return "", fmt.Errorf("cannot average: weight %d: %w", i, err)
+		}
+		total.Add(total, new(big.Rat).Mul(value, weight))
+		weightSum.Add(weightSum, weight)
+	}
+
+	if weightSum.Sign() == 0 {
+		return "", fmt.Errorf("cannot average: weights sum to zero")
+	}
+	return FormatNumeric(total.Quo(total, weightSum)), nil
+}
+
+// AllocateProRata splits total into parts proportional to weights (e.g. a fee across fills)
+// Parts are truncated to 18 fractional digits (or total's own precision, if finer) and the
+// rounding remainder goes to the part with the largest weight, the first one on ties, so the
+// parts always sum exactly to total
+// Returns an error if weights is empty, total or a weight is invalid, a weight is negative, or
+// the weights sum to zero
+func AllocateProRata(total Decimal, weights []Decimal) ([]Decimal, error) {
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("cannot allocate: no weights")
+	}
+	amount, err := ParseNumeric(total)
+	if err != nil {
+		return nil, fmt.Errorf("cannot allocate: total: %w", err)
+	}
+
+	parsed := make([]*big.Rat, len(weights))
+	weightSum := new(big.Rat)
+	largest := 0
+	for i, w := range weights {
+		weight, err := parseWeight(w)
+		if err != nil {
+			return nil, fmt.Errorf("cannot allocate: weight %d: %w", i, err)
+		}
+		parsed[i] = weight
+		weightSum.Add(weightSum, weight)
+		if weight.Cmp(parsed[largest]) > 0 {
+			largest = i
+		}
+	}
+	if weightSum.Sign() == 0 {
+		return nil, fmt.Errorf("cannot allocate: weights sum to zero")
+	}
+
+	scale := max(numericScale, fractionDigits(amount))
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+
+	// Work in integer units of 10^-scale: each share is truncated toward zero
+	units := new(big.Int).Quo(new(big.Int).Mul(amount.Num(), unit), amount.Denom())
+	shares := make([]*big.Int, len(parsed))
+	remainder := new(big.Int).Set(units)
+	for i, weight := range parsed {
+		share := new(big.Rat).Mul(new(big.Rat).SetInt(units), weight)
+		share.Quo(share, weightSum)
+		shares[i] = new(big.Int).Quo(share.Num(), share.Denom())
+		remainder.Sub(remainder, shares[i])
+	}
+	shares[largest].Add(shares[largest], remainder)
+
+	parts := make([]Decimal, len(shares))
+	for i, share := range shares {
+		parts[i] = formatScaled(new(big.Rat).SetFrac(share, unit), scale)
+	}
+	return parts, nil
+}
+
+// parseWeight parses a non-negative NUMERIC weight
+func parseWeight(s string) (*big.Rat, error) {
+	weight, err := ParseNumeric(s)
+	if err != nil {
+		return nil, err
+	}
+	if weight.Sign() < 0 {
+		return nil, fmt.Errorf("negative weight %q", s)
+	}
+	return weight, nil
+}
+
+// fractionDigits returns the number of fractional digits needed to write r exactly
+// r must terminate in decimal, which every value parsed from a NUMERIC string does
+func fractionDigits(r *big.Rat) int {
+	denom := new(big.Int).Set(r.Denom())
+	twos := int(denom.TrailingZeroBits())
+	denom.Rsh(denom, uint(twos))
+
+	fives := 0
+	five, rem := big.NewInt(5), new(big.Int)
+	for denom.Cmp(big.NewInt(1)) > 0 {
+		quo, _ := new(big.Int).QuoRem(denom, five, rem)
+		if rem.Sign() != 0 {
+			break
+		}
+		denom = quo
+		fives++
+	}
+	return max(twos, fives)
+}
+
+// formatScaled formats r without trailing zeros, rounded to scale fractional digits
+func formatScaled(r *big.Rat, scale int) string {
+	s := r.FloatString(scale)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,291 @@
+package models
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestNumericEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"10.50", "10.5", true},
+		{"10", "10.000", true},
+		{"-0.0", "0", true},
+		{"+3", "3", true},
+		{".5", "0.5", true},
+		{"1e2", "100", true},
+		{"0.1", "0.10000000000000000001", false},
+		{"10.5", "10.05", false},
+		{"-1", "1", false},
+	}
+
+	for _, tt := range tests {
+		got, err := NumericEqual(tt.a, tt.b)
+		if err != nil {
+			t.Errorf("NumericEqual(%q, %q) returned error: %v", tt.a, tt.b, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NumericEqual(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNumericEqual_Invalid(t *testing.T) {
+	for _, pair := range [][2]string{{"abc", "1"}, {"1", ""}, {"1.2.3", "1"}} {
+		if _, err := NumericEqual(pair[0], pair[1]); err == nil {
+			t.Errorf("NumericEqual(%q, %q) expected error", pair[0], pair[1])
+		}
+	}
+}
+
+func TestFormatNumeric(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"10.50", "10.5"},
+		{"-3", "-3"},
+		{"0.001", "0.001"},
+		{"1e2", "100"},
+		{"-0.0", "0"},
+	}
+
+	for _, tt := range tests {
+		r, err := ParseNumeric(tt.in)
+		if err != nil {
+			t.Fatalf("ParseNumeric(%q) failed: %v", tt.in, err)
+		}
+		if got := FormatNumeric(r); got != tt.want {
+			t.Errorf("FormatNumeric(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := ParseNumeric("abc"); err == nil {
+		t.Error("Expected error for invalid numeric value")
+	}
+}
+
+func TestWeightedAvgPrice(t *testing.T) {
+	trades := []*Trade{
+		{Side: "buy", Price: "100", Quantity: "1"},
+		{Side: "buy", Price: "110", Quantity: "3"},
+		{Side: "B", Price: "90.5", Quantity: "0.5"}, // Partial fill, exchange-style side
+		{Side: "sell", Price: "200", Quantity: "10"},
+	}
+
+	tests := []struct {
+		side string
+		want string
+	}{
+		// (100*1 + 110*3 + 90.5*0.5) / 4.5 = 475.25 / 4.5
+		{"buy", "105.611111111111111111"},
+		{"sell", "200"},
+	}
+	for _, tt := range tests {
+		got, err := WeightedAvgPrice(trades, tt.side)
+		if err != nil {
+			t.Fatalf("WeightedAvgPrice(%s) failed: %v", tt.side, err)
+		}
+		if got != tt.want {
+			t.Errorf("WeightedAvgPrice(%s) = %s, want %s", tt.side, got, tt.want)
+		}
+	}
+
+	// Exact decimal math: float64 would give 0.30000000000000004 here
+	exact, err := WeightedAvgPrice([]*Trade{
+		{Side: "buy", Price: "0.1", Quantity: "1"},
+		{Side: "buy", Price: "0.5", Quantity: "1"},
+	}, "buy")
+	if err != nil || exact != "0.3" {
+		t.Errorf("WeightedAvgPrice = %s, %v, want 0.3", exact, err)
+	}
+}
+
+func TestWeightedAvgPrice_Errors(t *testing.T) {
+	tests := map[string]struct {
+		trades []*Trade
+		side   string
+	}{
+		"empty input":       {nil, "buy"},
+		"no trades on side": {[]*Trade{{Side: "sell", Price: "1", Quantity: "1"}}, "buy"},
+		"zero quantity":     {[]*Trade{{Side: "buy", Price: "1", Quantity: "0"}}, "buy"},
+		"invalid side arg":  {[]*Trade{{Side: "buy", Price: "1", Quantity: "1"}}, "long"},
+		"invalid price":     {[]*Trade{{Side: "buy", Price: "n/a", Quantity: "1"}}, "buy"},
+		"invalid quantity":  {[]*Trade{{Side: "buy", Price: "1", Quantity: ""}}, "buy"},
+		"invalid side":      {[]*Trade{{Side: "x", Price: "1", Quantity: "1"}}, "buy"},
+	}
+	for name, tt := range tests {
+		if got, err := WeightedAvgPrice(tt.trades, tt.side); err == nil {
+			t.Errorf("%s: expected error, got %s", name, got)
+		}
+	}
+}
+
+func TestWeightedAvg(t *testing.T) {
+	tests := []struct {
+		values, weights []Decimal
+		want            Decimal
+	}{
+		{[]Decimal{"100", "110"}, []Decimal{"1", "3"}, "107.5"},
+		{[]Decimal{"0.1", "0.2"}, []Decimal{"1", "1"}, "0.15"},
+		{[]Decimal{"5"}, []Decimal{"1e-8"}, "5"},
+		{[]Decimal{"1", "2", "3"}, []Decimal{"0", "1", "0"}, "2"},
+		{[]Decimal{"1", "2"}, []Decimal{"1", "2"}, "1.666666666666666667"},
+	}
+	for _, tt := range tests {
+		got, err := WeightedAvg(tt.values, tt.weights)
+		if err != nil || got != tt.want {
+			t.Errorf("WeightedAvg(%v, %v) = %s, %v, want %s", tt.values, tt.weights, got, err, tt.want)
+		}
+	}
+}
+
+func TestWeightedAvg_Errors(t *testing.T) {
+	tests := map[string]struct {
+		values, weights []Decimal
+	}{
+		"empty":           {nil, nil},
+		"length mismatch": {[]Decimal{"1", "2"}, []Decimal{"1"}},
+		"invalid value":   {[]Decimal{"x"}, []Decimal{"1"}},
+		"invalid weight":  {[]Decimal{"1"}, []Decimal{""}},
+		"negative weight": {[]Decimal{"1", "2"}, []Decimal{"2", "-1"}},
+		"zero weights":    {[]Decimal{"1", "2"}, []Decimal{"0", "0"}},
+	}
+	for name, tt := range tests {
+		if got, err := WeightedAvg(tt.values, tt.weights); err == nil {
+			t.Errorf("%s: expected error, got %s", name, got)
+		}
+	}
+}
+
+func TestAllocateProRata(t *testing.T) {
+	tests := []struct {
+		total   Decimal
+		weights []Decimal
+		want    []Decimal
+	}{
+		{"10", []Decimal{"1", "1"}, []Decimal{"5", "5"}},
+		{"1", []Decimal{"1", "1", "1"}, []Decimal{"0.333333333333333334", "0.333333333333333333", "0.333333333333333333"}},
+		{"1", []Decimal{"1", "2"}, []Decimal{"0.333333333333333333", "0.666666666666666667"}},
+		{"-0.03", []Decimal{"1", "1", "1"}, []Decimal{"-0.01", "-0.01", "-0.01"}},
+		{"7", []Decimal{"0", "3", "0"}, []Decimal{"0", "7", "0"}},
+		{"1e-20", []Decimal{"1", "2"}, []Decimal{"0", "0.00000000000000000001"}},
+		{"0", []Decimal{"1", "2"}, []Decimal{"0", "0"}},
+	}
+	for _, tt := range tests {
+		got, err := AllocateProRata(tt.total, tt.weights)
+		if err != nil {
+			t.Errorf("AllocateProRata(%s, %v) failed: %v", tt.total, tt.weights, err)
+			continue
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("AllocateProRata(%s, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+		}
+	}
+}
+
+func TestAllocateProRata_Errors(t *testing.T) {
+	tests := map[string]struct {
+		total   Decimal
+		weights []Decimal
+	}{
+		"no weights":      {"1", nil},
+		"invalid total":   {"abc", []Decimal{"1"}},
+		"invalid weight":  {"1", []Decimal{"1", "1,5"}},
+		"negative weight": {"1", []Decimal{"2", "-1"}},
+		"zero weights":    {"1", []Decimal{"0", "0"}},
+	}
+	for name, tt := range tests {
+		if got, err := AllocateProRata(tt.total, tt.weights); err == nil {
+			t.Errorf("%s: expected error, got %v", name, got)
+		}
+	}
+}
+
+// randomDecimal returns a random decimal with up to 8 integer and 24 fractional digits
+func randomDecimal(rng *rand.Rand, signed bool) Decimal {
+	fraction := fmt.Sprintf("%012d%012d", rng.Int63n(1e12), rng.Int63n(1e12))[:rng.Intn(25)]
+	s := fmt.Sprintf("%d.%s", rng.Int63n(1e8), fraction)
+	if signed && rng.Intn(2) == 0 {
+		return "-" + s
+	}
+	return s
+}
+
+func TestAllocateProRata_SumsExactly(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 2000; i++ {
+		total := randomDecimal(rng, true)
+		weights := make([]Decimal, 1+rng.Intn(12))
+		for j := range weights {
+			weights[j] = randomDecimal(rng, false)
+			if rng.Intn(5) == 0 {
+				weights[j] = "0"
+			}
+		}
+		weights[rng.Intn(len(weights))] = "1" // At least one positive weight
+
+		parts, err := AllocateProRata(total, weights)
+		if err != nil {
+			t.Fatalf("AllocateProRata(%s, %v) failed: %v", total, weights, err)
+		}
+
+		want, _ := ParseNumeric(total)
+		sum := new(big.Rat)
+		for j, part := range parts {
+			value, err := ParseNumeric(part)
+			if err != nil {
+				t.Fatalf("part %d is not a valid NUMERIC: %q", j, part)
+			}
+			if value.Sign()*want.Sign() < 0 {
+				t.Fatalf("AllocateProRata(%s, %v): part %s has the wrong sign", total, weights, part)
+			}
+			if weights[j] == "0" && value.Sign() != 0 {
+				t.Fatalf("AllocateProRata(%s, %v): zero weight got %s", total, weights, part)
+			}
+			sum.Add(sum, value)
+		}
+		if sum.Cmp(want) != 0 {
+			t.Fatalf("AllocateProRata(%s, %v) = %v, sums to %s", total, weights, parts, FormatNumeric(sum))
+		}
+	}
+}
+
+func TestWeightedAvg_WithinRange(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 1000; i++ {
+		n := 1 + rng.Intn(10)
+		values, weights := make([]Decimal, n), make([]Decimal, n)
+		lowest, highest := (*big.Rat)(nil), (*big.Rat)(nil)
+		for j := range values {
+			values[j] = randomDecimal(rng, true)
+			weights[j] = randomDecimal(rng, false)
+			value, _ := ParseNumeric(values[j])
+			if lowest == nil || value.Cmp(lowest) < 0 {
+				lowest = value
+			}
+			if highest == nil || value.Cmp(highest) > 0 {
+				highest = value
+			}
+		}
+		weights[0] = "1"
+
+		avg, err := WeightedAvg(values, weights)
+		if err != nil {
+			t.Fatalf("WeightedAvg(%v, %v) failed: %v", values, weights, err)
+		}
+		// The result is rounded to 18 digits, so allow that much outside the inputs' range
+		got, _ := ParseNumeric(avg)
+		tolerance := big.NewRat(1, 1e18)
+		if got.Cmp(new(big.Rat).Sub(lowest, tolerance)) < 0 || got.Cmp(new(big.Rat).Add(highest, tolerance)) > 0 {
+			t.Fatalf("WeightedAvg(%v, %v) = %s, outside [%s, %s]", values, weights, avg, FormatNumeric(lowest), FormatNumeric(highest))
+		}
+	}
+}
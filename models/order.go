@@ -0,0 +1,120 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Order represents an order record in the database
+// Matches the 'orders' table schema
+type Order struct {
+	ID                uuid.UUID `json:"id"`
+	OrderID           string    `json:"order_id"`
+	BaseAsset         string    `json:"base_asset"`
+	QuoteAsset        string    `json:"quote_asset"`
+	Side              string    `json:"side"`   // "buy" or "sell"
+	Price             string    `json:"price"`  // Limit price, string for precision (NUMERIC in DB)
+	Size              string    `json:"size"`   // Original order size, string for precision (NUMERIC in DB)
+	Status            string    `json:"status"` // Exchange-reported status (e.g. "open", "filled", "canceled")
+	Timestamp         time.Time `json:"timestamp"`
+	ExchangeAccountID uuid.UUID `json:"exchange_account_id"`
+	CreatedAt         time.Time `json:"created_at"` // When the row was ingested (zero if not selected)
+}
+
+// UnmarshalJSON custom unmarshaler to handle BIGINT timestamp (Unix milliseconds) and NUMERIC as numbers
+func (o *Order) UnmarshalJSON(data []byte) error {
+	type Alias Order
+	aux := &struct {
+		Timestamp interface{} `json:"timestamp"`  // Unix milliseconds (number or string)
+		Price     interface{} `json:"price"`      // Can be string or number
+		Size      interface{} `json:"size"`       // Can be string or number
+		CreatedAt interface{} `json:"created_at"` // timestamptz string or Unix milliseconds
+		*Alias
+	}{
+		Alias: (*Alias)(o),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.Timestamp != nil {
+		timestamp, err := parseFlexibleTime(aux.Timestamp)
+		if err != nil {
+			return fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		o.Timestamp = timestamp
+	}
+
+	if aux.Price != nil {
+		o.Price = convertToString(aux.Price)
+	}
+	if aux.Size != nil {
+		o.Size = convertToString(aux.Size)
+	}
+
+	if aux.CreatedAt != nil {
+		createdAt, err := parseFlexibleTime(aux.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to parse created_at: %w", err)
+		}
+		o.CreatedAt = createdAt
+	}
+
+	return nil
+}
+
+// OrderInput represents an order fetched from an exchange's order history
+// Used for GraphQL mutations
+type OrderInput struct {
+	OrderID           string    `json:"order_id"`
+	BaseAsset         string    `json:"base_asset"`
+	QuoteAsset        string    `json:"quote_asset"`
+	Side              string    `json:"side"`
+	Price             string    `json:"price"`
+	Size              string    `json:"size"`
+	Status            string    `json:"status"`
+	Timestamp         time.Time `json:"timestamp"`
+	ExchangeAccountID uuid.UUID `json:"exchange_account_id"`
+}
+
+// Validate checks that the input has everything AddOrders needs
+// Returns a *ValidationError listing every invalid field
+func (in *OrderInput) Validate() error {
+	verr := &ValidationError{Resource: "order"}
+
+	if strings.TrimSpace(in.OrderID) == "" {
+		verr.add("order_id", "must not be empty")
+	}
+	if strings.TrimSpace(in.BaseAsset) == "" {
+		verr.add("base_asset", "must not be empty")
+	}
+	if in.Side != "buy" && in.Side != "sell" {
+		verr.add("side", fmt.Sprintf("must be \"buy\" or \"sell\", got %q", in.Side))
+	}
+	if _, ok := parseDecimal(in.Price); !ok {
+		verr.add("price", fmt.Sprintf("must be a decimal number, got %q", in.Price))
+	}
+	if _, ok := parseDecimal(in.Size); !ok {
+		verr.add("size", fmt.Sprintf("must be a decimal number, got %q", in.Size))
+	}
+	if in.Timestamp.IsZero() {
+		verr.add("timestamp", "must not be zero")
+	}
+
+	return verr.errOrNil()
+}
+
+// OrderFilter represents filtering options for listing an account's orders
+type OrderFilter struct {
+	BaseAsset    *string
+	Statuses     []string // Empty slice = any status
+	TimestampGte *time.Time
+	TimestampLte *time.Time
+	Limit        int // Maximum number of rows to return (0 = no limit)
+	Offset       int // Number of rows to skip (used with Limit for paging)
+}
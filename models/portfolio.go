@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PnLWindow is profit and loss over one reporting window, as NUMERIC strings
+type PnLWindow struct {
+	Realized   string `json:"realized,omitempty"`   // Empty when the exchange reports only the total
+	Unrealized string `json:"unrealized,omitempty"` // Empty when the exchange reports only the total
+	Total      string `json:"total"`
+}
+
+// PortfolioPnL is exchange-reported account PnL over the standard day/week/month windows
+type PortfolioPnL struct {
+	ExchangeAccountID uuid.UUID `json:"exchange_account_id"`
+	Day               PnLWindow `json:"day"`
+	Week              PnLWindow `json:"week"`
+	Month             PnLWindow `json:"month"`
+	AsOf              time.Time `json:"as_of"` // Time of the newest data point the exchange reported
+}
+
+// BalanceSnapshotInput is an exchange-reported total account value at a point in time
+type BalanceSnapshotInput struct {
+	ExchangeAccountID uuid.UUID `json:"exchange_account_id"`
+	Timestamp         time.Time `json:"timestamp"`
+	AccountValue      string    `json:"account_value"` // NUMERIC string in the account's quote currency
+}
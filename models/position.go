@@ -8,21 +8,21 @@ import (
 	"github.com/google/uuid"
 )
 
-// Position represents a closed position record in the database
+// Position represents a position record in the database; EndTime is nil while it is open
 // Matches the 'positions' table schema
 type Position struct {
-	ID                uuid.UUID `json:"id"`
-	ExchangeAccountID uuid.UUID `json:"exchange_account_id"`
-	BaseAsset         string    `json:"base_asset"`
-	QuoteAsset        string    `json:"quote_asset"`
-	Side              string    `json:"side"` // "long" or "short"
-	StartTime         time.Time `json:"start_time"`
-	EndTime           time.Time `json:"end_time"`
-	EntryAvgPrice     string    `json:"entry_avg_price"` // NUMERIC as string
-	ExitAvgPrice      string    `json:"exit_avg_price"`  // NUMERIC as string
-	TotalQuantity     string    `json:"total_quantity"`  // NUMERIC as string
-	TotalFees         string    `json:"total_fees"`      // NUMERIC as string
-	RealizedPnL       string    `json:"realized_pnl"`    // NUMERIC as string
+	ID                uuid.UUID  `json:"id"`
+	ExchangeAccountID uuid.UUID  `json:"exchange_account_id"`
+	BaseAsset         string     `json:"base_asset"`
+	QuoteAsset        string     `json:"quote_asset"`
+	Side              string     `json:"side"` // "long" or "short"
+	StartTime         time.Time  `json:"start_time"`
+	EndTime           *time.Time `json:"end_time"`        // nil while the position is open
+	EntryAvgPrice     string     `json:"entry_avg_price"` // NUMERIC as string
+	ExitAvgPrice      string     `json:"exit_avg_price"`  // NUMERIC as string
+	TotalQuantity     string     `json:"total_quantity"`  // NUMERIC as string
+	TotalFees         string     `json:"total_fees"`      // NUMERIC as string
+	RealizedPnL       string     `json:"realized_pnl"`    // NUMERIC as string
 }
 
 // UnmarshalJSON custom unmarshaler to handle BIGINT timestamps and NUMERIC fields
@@ -45,22 +45,22 @@ func (p *Position) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	// Parse start_time (BIGINT Unix milliseconds)
+	// Parse start_time (BIGINT Unix milliseconds or timestamptz string)
 	if aux.StartTime != nil {
-		ts, err := parseTimestamp(aux.StartTime)
+		ts, err := parseFlexibleTime(aux.StartTime)
 		if err != nil {
 			return fmt.Errorf("failed to parse start_time: %w", err)
 		}
 		p.StartTime = ts
 	}
 
-	// Parse end_time (BIGINT Unix milliseconds)
+	// Parse end_time (BIGINT Unix milliseconds or timestamptz string)
 	if aux.EndTime != nil {
-		ts, err := parseTimestamp(aux.EndTime)
+		ts, err := parseFlexibleTime(aux.EndTime)
 		if err != nil {
 			return fmt.Errorf("failed to parse end_time: %w", err)
 		}
-		p.EndTime = ts
+		p.EndTime = &ts
 	}
 
 	// Convert NUMERIC fields to string
@@ -83,28 +83,6 @@ func (p *Position) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// parseTimestamp parses a timestamp from various formats (BIGINT Unix milliseconds)
-func parseTimestamp(v interface{}) (time.Time, error) {
-	var unixMillis int64
-	switch val := v.(type) {
-	case float64:
-		unixMillis = int64(val)
-	case int64:
-		unixMillis = val
-	case int:
-		unixMillis = int64(val)
-	case string:
-		var err error
-		unixMillis, err = parseInt64(val)
-		if err != nil {
-			return time.Time{}, fmt.Errorf("failed to parse timestamp string: %w", err)
-		}
-	default:
-		return time.Time{}, fmt.Errorf("unexpected timestamp type: %T", v)
-	}
-	return time.Unix(0, unixMillis*int64(time.Millisecond)).UTC(), nil
-}
-
 // PositionInput represents input for creating a position
 type PositionInput struct {
 	ExchangeAccountID uuid.UUID `json:"exchange_account_id"`
@@ -178,4 +156,17 @@ type PositionFilter struct {
 	StartTimeLte       *time.Time
 	EndTimeGte         *time.Time
 	EndTimeLte         *time.Time
+	Overlaps           *TimeRange // Positions open at any point in the window: start_time <= End and (end_time >= Start or still open)
+	RealizedPnLLte     *string    // Realized PnL at most this decimal (e.g. "-100" for losses of 100 or more)
+	RealizedPnLGte     *string    // Realized PnL at least this decimal
+	UserID             *string    // Only positions on accounts owned by this user
+	Limit              int        // Maximum number of rows to return (0 = no limit)
+	Offset             int        // Number of rows to skip (used with Limit for paging)
+}
+
+// PnLBucket is the realized PnL of the positions closed within one time bucket
+type PnLBucket struct {
+	Start       time.Time `json:"start"`        // Inclusive start of the bucket (UTC)
+	RealizedPnL string    `json:"realized_pnl"` // Sum of realized_pnl, NUMERIC as string
+	Positions   int       `json:"positions"`    // Number of positions summed
 }
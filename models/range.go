@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// AssetPair identifies a market by base and quote asset (e.g. BTC/USDC)
+type AssetPair struct {
+	Base  string
+	Quote string
+}
+
+// TimeRange is a half-open time window [Start, End)
+// A zero Start or End leaves that side unbounded
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Trade and position sides as stored in the database
+const (
+	SideBuy   = "buy"
+	SideSell  = "sell"
+	SideLong  = "long"
+	SideShort = "short"
+)
+
+// NormalizeTradeSide returns SideBuy or SideSell for a case-insensitive "buy"/"b" or "sell"/"s"
+func NormalizeTradeSide(side string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(side)) {
+	case "buy", "b":
+		return SideBuy, nil
+	case "sell", "s":
+		return SideSell, nil
+	default:
+		return "", fmt.Errorf("must be %q or %q, got %q", SideBuy, SideSell, side)
+	}
+}
+
+// NormalizePositionSide returns SideLong or SideShort for a case-insensitive "long" or "short"
+func NormalizePositionSide(side string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(side)) {
+	case "long":
+		return SideLong, nil
+	case "short":
+		return SideShort, nil
+	default:
+		return "", fmt.Errorf("must be %q or %q, got %q", SideLong, SideShort, side)
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+import "testing"
+
+func TestNormalizeTradeSide(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "buy", want: SideBuy},
+		{in: "BUY", want: SideBuy},
+		{in: " b ", want: SideBuy},
+		{in: "Sell", want: SideSell},
+		{in: "S", want: SideSell},
+		{in: "", wantErr: true},
+		{in: "long", wantErr: true},
+		{in: "bid", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeTradeSide(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("NormalizeTradeSide(%q) = %q, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeTradeSide(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizePositionSide(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "long", want: SideLong},
+		{in: " SHORT", want: SideShort},
+		{in: "Long", want: SideLong},
+		{in: "buy", wantErr: true},
+		{in: "l", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizePositionSide(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("NormalizePositionSide(%q) = %q, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizePositionSide(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package models
+
+// Sources record how a trade or funding payment row entered the database
+const (
+	SourceExchangeSync = "exchange_sync" // Fetched from the exchange API by the sync helpers
+	SourceManualImport = "manual_import" // Uploaded by a user (e.g. CSV import)
+	SourceBackfill     = "backfill"      // Written by a one-off historical backfill
+)
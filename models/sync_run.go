@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sync run kinds, one per ingested table
+const (
+	SyncRunKindTrades          = "trades"
+	SyncRunKindFundingPayments = "funding_payments"
+)
+
+// SyncRun records the outcome of one ingestion step of an account sync
+// Matches the 'sync_runs' table schema
+type SyncRun struct {
+	ID                uuid.UUID     `json:"id"`
+	ExchangeAccountID uuid.UUID     `json:"exchange_account_id"`
+	Kind              string        `json:"kind"`     // SyncRunKindTrades or SyncRunKindFundingPayments
+	Fetched           int           `json:"fetched"`  // Rows returned by the exchange
+	Inserted          int           `json:"inserted"` // Rows newly stored
+	Skipped           int           `json:"skipped"`  // Rows dropped before storing (e.g. enricher failures)
+	Duration          time.Duration `json:"-"`        // Stored as duration_ms
+	Error             *string       `json:"error"`    // nil if the step succeeded
+	StartedAt         time.Time     `json:"started_at"`
+	CreatedAt         time.Time     `json:"created_at"`
+}
+
+// UnmarshalJSON custom unmarshaler to handle BIGINT timestamps (Unix milliseconds) and duration_ms
+func (r *SyncRun) UnmarshalJSON(data []byte) error {
+	type Alias SyncRun
+	aux := &struct {
+		DurationMs int64       `json:"duration_ms"`
+		StartedAt  interface{} `json:"started_at"` // Unix milliseconds (number or string)
+		CreatedAt  interface{} `json:"created_at"` // timestamptz string or Unix milliseconds
+		*Alias
+	}{
+		Alias: (*Alias)(r),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	r.Duration = time.Duration(aux.DurationMs) * time.Millisecond
+
+	if aux.StartedAt != nil {
+		startedAt, err := parseFlexibleTime(aux.StartedAt)
+		if err != nil {
+			return fmt.Errorf("failed to parse started_at: %w", err)
+		}
+		r.StartedAt = startedAt
+	}
+
+	if aux.CreatedAt != nil {
+		createdAt, err := parseFlexibleTime(aux.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to parse created_at: %w", err)
+		}
+		r.CreatedAt = createdAt
+	}
+
+	return nil
+}
+
+// SyncRunInput represents a sync run to record
+// Used for GraphQL mutations
+type SyncRunInput struct {
+	ExchangeAccountID uuid.UUID
+	Kind              string
+	Fetched           int
+	Inserted          int
+	Skipped           int
+	Duration          time.Duration
+	Error             *string
+	StartedAt         time.Time
+}
+
+// Validate checks that the input has everything CreateSyncRun needs
+// Returns a *ValidationError listing every invalid field
+func (in *SyncRunInput) Validate() error {
+	verr := &ValidationError{Resource: "sync run"}
+
+	if in.ExchangeAccountID == uuid.Nil {
+		verr.add("exchange_account_id", "must not be empty")
+	}
+	if in.Kind != SyncRunKindTrades && in.Kind != SyncRunKindFundingPayments {
+		verr.add("kind", fmt.Sprintf("must be %q or %q, got %q", SyncRunKindTrades, SyncRunKindFundingPayments, in.Kind))
+	}
+	if in.Fetched < 0 || in.Inserted < 0 || in.Skipped < 0 {
+		verr.add("counts", "must not be negative")
+	}
+	if in.Duration < 0 {
+		verr.add("duration", "must not be negative")
+	}
+	if in.StartedAt.IsZero() {
+		verr.add("started_at", "must not be zero")
+	}
+
+	return verr.errOrNil()
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// timestamptzLayouts are the textual formats Hasura/PostgreSQL use for timestamptz values
+// RFC3339Nano also accepts plain RFC3339 since its fractional seconds are optional
+var timestamptzLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+}
+
+// parseFlexibleTime parses a value that is either epoch milliseconds (number or numeric string)
+// or a timestamptz string, returning the time in UTC
+// Every model time column decodes through it, so BIGINT and timestamptz schemas both work
+func parseFlexibleTime(v interface{}) (time.Time, error) {
+	switch val := v.(type) {
+	case float64:
+		return time.UnixMilli(int64(val)).UTC(), nil
+	case int64:
+		return time.UnixMilli(val).UTC(), nil
+	case int:
+		return time.UnixMilli(int64(val)).UTC(), nil
+	case string:
+		if ms, err := strconv.ParseInt(val, 10, 64); err == nil {
+			return time.UnixMilli(ms).UTC(), nil
+		}
+		for _, layout := range timestamptzLayouts {
+			if t, err := time.Parse(layout, val); err == nil {
+				return t.UTC(), nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("unrecognized time format: %q", val)
+	default:
+		return time.Time{}, fmt.Errorf("unexpected time type: %T", v)
+	}
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCreatedAt_WireFormats(t *testing.T) {
+	want := time.Date(2024, 3, 1, 12, 30, 45, 123000000, time.UTC)
+
+	tests := []struct {
+		name      string
+		createdAt string
+	}{
+		{"timestamptz", `"2024-03-01T12:30:45.123+00:00"`},
+		{"timestamptz with offset", `"2024-03-01T14:30:45.123+02:00"`},
+		{"postgres text", `"2024-03-01 12:30:45.123+00"`},
+		{"epoch millis number", `1709296245123`},
+		{"epoch millis string", `"1709296245123"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var trade Trade
+			if err := json.Unmarshal([]byte(`{"timestamp": 1709296200000, "created_at": `+tt.createdAt+`}`), &trade); err != nil {
+				t.Fatalf("Trade unmarshal failed: %v", err)
+			}
+			if !trade.CreatedAt.Equal(want) {
+				t.Errorf("Trade.CreatedAt = %v, want %v", trade.CreatedAt, want)
+			}
+
+			var payment FundingPayment
+			if err := json.Unmarshal([]byte(`{"timestamp": 1709296200000, "created_at": `+tt.createdAt+`}`), &payment); err != nil {
+				t.Fatalf("FundingPayment unmarshal failed: %v", err)
+			}
+			if !payment.CreatedAt.Equal(want) {
+				t.Errorf("FundingPayment.CreatedAt = %v, want %v", payment.CreatedAt, want)
+			}
+		})
+	}
+}
+
+func TestCreatedAt_Missing(t *testing.T) {
+	var trade Trade
+	if err := json.Unmarshal([]byte(`{"timestamp": 1709296200000}`), &trade); err != nil {
+		t.Fatalf("Trade unmarshal failed: %v", err)
+	}
+	if !trade.CreatedAt.IsZero() {
+		t.Errorf("Expected zero CreatedAt when not selected, got %v", trade.CreatedAt)
+	}
+
+	if err := json.Unmarshal([]byte(`{"created_at": "yesterday"}`), &trade); err == nil {
+		t.Error("Expected error for unrecognized created_at format")
+	}
+}
+
+func TestTimestampColumns_RFC3339AndMillis(t *testing.T) {
+	want := time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"epoch millis number", `1709296245000`},
+		{"epoch millis string", `"1709296245000"`},
+		{"RFC3339", `"2024-03-01T12:30:45Z"`},
+		{"RFC3339 with offset", `"2024-03-01T07:30:45-05:00"`},
+		{"RFC3339Nano", `"2024-03-01T12:30:45.000000000Z"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var trade Trade
+			if err := json.Unmarshal([]byte(`{"timestamp": `+tt.value+`}`), &trade); err != nil {
+				t.Fatalf("Trade unmarshal failed: %v", err)
+			}
+			if !trade.Timestamp.Equal(want) {
+				t.Errorf("Trade.Timestamp = %v, want %v", trade.Timestamp, want)
+			}
+
+			var payment FundingPayment
+			if err := json.Unmarshal([]byte(`{"timestamp": `+tt.value+`}`), &payment); err != nil {
+				t.Fatalf("FundingPayment unmarshal failed: %v", err)
+			}
+			if !payment.Timestamp.Equal(want) {
+				t.Errorf("FundingPayment.Timestamp = %v, want %v", payment.Timestamp, want)
+			}
+
+			var order Order
+			if err := json.Unmarshal([]byte(`{"timestamp": `+tt.value+`}`), &order); err != nil {
+				t.Fatalf("Order unmarshal failed: %v", err)
+			}
+			if !order.Timestamp.Equal(want) {
+				t.Errorf("Order.Timestamp = %v, want %v", order.Timestamp, want)
+			}
+
+			var position Position
+			if err := json.Unmarshal([]byte(`{"start_time": `+tt.value+`, "end_time": `+tt.value+`}`), &position); err != nil {
+				t.Fatalf("Position unmarshal failed: %v", err)
+			}
+			if !position.StartTime.Equal(want) || position.EndTime == nil || !position.EndTime.Equal(want) {
+				t.Errorf("Position times = %v/%v, want %v", position.StartTime, position.EndTime, want)
+			}
+		})
+	}
+}
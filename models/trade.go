@@ -20,9 +20,14 @@ type Trade struct {
 	Quantity          string    `json:"quantity"` // Using string for precision (NUMERIC in DB)
 	Timestamp         time.Time `json:"timestamp"`
 	Fee               string    `json:"fee"` // Using string for precision (NUMERIC in DB)
-	OrderID           string    `json:"order_id"`
+	OrderID           string    `json:"order_id"` // Empty when the venue reports no order ID (NULL in DB)
 	TradeID           string    `json:"trade_id"`
 	ExchangeAccountID uuid.UUID `json:"exchange_account_id"`
+	CreatedAt         time.Time `json:"created_at"` // When the row was ingested (zero if not selected)
+	FeeAsset          string    `json:"fee_asset"`   // Asset the fee was charged in (empty = unknown)
+	MarketType        string    `json:"market_type"` // MarketTypePerp or MarketTypeSpot (empty = unknown)
+	Source            string    `json:"source"`     // SourceExchangeSync, SourceManualImport or SourceBackfill
+	IsTaker           *bool     `json:"is_taker"`   // True for taker fills, false for maker fills (nil = unknown)
 }
 
 // UnmarshalJSON custom unmarshaler to handle BIGINT timestamp (Unix milliseconds) and NUMERIC as numbers
@@ -33,6 +38,7 @@ func (t *Trade) UnmarshalJSON(data []byte) error {
 		Price     interface{} `json:"price"`     // Can be string or number
 		Quantity  interface{} `json:"quantity"`  // Can be string or number
 		Fee       interface{} `json:"fee"`       // Can be string or number
+		CreatedAt interface{} `json:"created_at"` // timestamptz string or Unix milliseconds
 		*Alias
 	}{
 		Alias: (*Alias)(t),
@@ -42,29 +48,13 @@ func (t *Trade) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	// Parse timestamp (BIGINT Unix milliseconds from PostgreSQL)
+	// Parse timestamp (BIGINT Unix milliseconds, or a timestamptz string on mixed schemas)
 	if aux.Timestamp != nil {
-		var unixMillis int64
-		switch v := aux.Timestamp.(type) {
-		case float64:
-			// JSON numbers come as float64
-			unixMillis = int64(v)
-		case int64:
-			unixMillis = v
-		case int:
-			unixMillis = int64(v)
-		case string:
-			// Try parsing as number string first
-			var err error
-			unixMillis, err = parseInt64(v)
-			if err != nil {
-				return fmt.Errorf("failed to parse timestamp: %w", err)
-			}
-		default:
-			return fmt.Errorf("unexpected timestamp type: %T", aux.Timestamp)
+		timestamp, err := parseFlexibleTime(aux.Timestamp)
+		if err != nil {
+			return fmt.Errorf("failed to parse timestamp: %w", err)
 		}
-		// Convert Unix milliseconds to time.Time
-		t.Timestamp = time.Unix(0, unixMillis*int64(time.Millisecond)).UTC()
+		t.Timestamp = timestamp
 	}
 
 	// Convert NUMERIC fields (can be number or string) to string
@@ -78,6 +68,15 @@ func (t *Trade) UnmarshalJSON(data []byte) error {
 		t.Fee = convertToString(aux.Fee)
 	}
 
+	// Parse created_at (timestamptz string or Unix milliseconds)
+	if aux.CreatedAt != nil {
+		createdAt, err := parseFlexibleTime(aux.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to parse created_at: %w", err)
+		}
+		t.CreatedAt = createdAt
+	}
+
 	return nil
 }
 
@@ -98,13 +97,6 @@ func convertToString(v interface{}) string {
 	}
 }
 
-// parseInt64 parses a string to int64
-func parseInt64(s string) (int64, error) {
-	var result int64
-	_, err := fmt.Sscanf(s, "%d", &result)
-	return result, err
-}
-
 // TradeInput represents input for creating/updating a trade
 // Used for GraphQL mutations
 type TradeInput struct {
@@ -114,13 +106,42 @@ type TradeInput struct {
 	Price             string    `json:"price"`
 	Quantity          string    `json:"quantity"`
 	Timestamp         time.Time `json:"timestamp"`
-	Fee               string    `json:"fee"`
-	OrderID           string    `json:"order_id"`
+	Fee               string    `json:"fee"` // Empty = DefaultTradeFee
+	OrderID           string    `json:"order_id"` // Optional: some venues and imports have no order ID
 	TradeID           string    `json:"trade_id"`
 	ExchangeAccountID uuid.UUID `json:"exchange_account_id"`
+	FeeAsset          string    `json:"fee_asset,omitempty"` // Asset the fee was charged in (empty = unknown)
+	MarketType        string    `json:"market_type,omitempty"` // MarketTypePerp or MarketTypeSpot (empty = unknown)
+	Source            string    `json:"source,omitempty"` // How the row was obtained (empty = not recorded)
+	IsTaker           *bool     `json:"is_taker,omitempty"` // True for taker fills, false for maker fills (nil = unknown)
 }
 
+// Market types a trade can belong to
+const (
+	MarketTypePerp = "perp"
+	MarketTypeSpot = "spot"
+)
+
 // TradeFilter represents filtering options for listing trades
+// Exclusions are ANDed with the inclusive filters, so an ID listed in both
+// ExchangeAccountIDs and ExcludeExchangeAccountIDs is excluded
 type TradeFilter struct {
-	ExchangeAccountIDs []uuid.UUID // Empty slice = all accounts, non-empty = filter by these IDs
+	ExchangeAccountIDs        []uuid.UUID // Empty slice = all accounts, non-empty = filter by these IDs
+	ExcludeExchangeAccountIDs []uuid.UUID // Accounts to leave out (e.g. "all accounts except these")
+	ExcludeAssets             []string    // Base assets to leave out
+	Source                    *string     // Only trades from this source (e.g. SourceManualImport)
+	UserID                    *string     // Only trades on accounts owned by this user
+	Limit                     int         // Maximum number of rows to return (0 = no limit)
+	Offset                    int         // Number of rows to skip (used with Limit for paging)
+}
+
+// DefaultTradeFee is stored for trades whose exchange reports no fee
+const DefaultTradeFee = "0"
+
+// FeeOrDefault returns fee, or DefaultTradeFee when it is empty
+func FeeOrDefault(fee string) string {
+	if fee == "" {
+		return DefaultTradeFee
+	}
+	return fee
 }
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// FieldError describes a single invalid field of an input struct
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid field found while validating an input
+type ValidationError struct {
+	Resource string       `json:"resource"` // e.g. "funding payment"
+	Fields   []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + ": " + f.Message
+	}
+	return "invalid " + e.Resource + " input: " + strings.Join(parts, "; ")
+}
+
+// add records an invalid field
+func (e *ValidationError) add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// errOrNil returns the error if any field was invalid, nil otherwise
+func (e *ValidationError) errOrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// decimalPattern matches plain decimal strings as accepted by PostgreSQL NUMERIC (e.g. "-10.5", "0.001", "3")
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// parseDecimal parses a NUMERIC string into an exact rational value
+func parseDecimal(s string) (*big.Rat, bool) {
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return nil, false
+	}
+	r, ok := new(big.Rat).SetString(s)
+	return r, ok
+}
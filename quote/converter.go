@@ -0,0 +1,20 @@
+// Package quote values asset amounts in a single reporting currency
+package quote
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// ErrNoPrice is returned when a Converter has no price for an asset at the requested time
+var ErrNoPrice = errors.New("no price available")
+
+// Converter values asset amounts in one reporting currency (e.g. USD)
+// Implementations return amount unchanged for the reporting currency itself
+type Converter interface {
+	// Value returns the value of amount units of asset at time at
+	// Returns an error wrapping ErrNoPrice when no price is known
+	Value(ctx context.Context, asset string, amount *big.Rat, at time.Time) (*big.Rat, error)
+}
@@ -0,0 +1,16 @@
+package report
+
+import "github.com/zif-terminal/lib/models"
+
+// PnLDenomination returns the currency the account's PnL should be converted to for display
+// Falls back to models.DefaultPnLDenomination when the account has no valid preference
+func PnLDenomination(account *models.ExchangeAccount) string {
+	if account == nil || account.PnLDenomination == nil {
+		return models.DefaultPnLDenomination
+	}
+	denom, err := models.NormalizePnLDenomination(*account.PnLDenomination)
+	if err != nil {
+		return models.DefaultPnLDenomination
+	}
+	return denom
+}
@@ -0,0 +1,31 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/zif-terminal/lib/models"
+)
+
+func TestPnLDenomination(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name    string
+		account *models.ExchangeAccount
+		want    string
+	}{
+		{name: "unset", account: &models.ExchangeAccount{}, want: "USDC"},
+		{name: "set", account: &models.ExchangeAccount{PnLDenomination: str("USDT")}, want: "USDT"},
+		{name: "lower case", account: &models.ExchangeAccount{PnLDenomination: str("usd")}, want: "USD"},
+		{name: "invalid stored value", account: &models.ExchangeAccount{PnLDenomination: str("EUR")}, want: "USDC"},
+		{name: "nil account", account: nil, want: "USDC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PnLDenomination(tt.account); got != tt.want {
+				t.Errorf("PnLDenomination() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
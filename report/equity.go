@@ -0,0 +1,188 @@
+// Package report derives account-level views (equity, PnL) from stored trades and funding
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/db"
+	"github.com/zif-terminal/lib/models"
+	"github.com/zif-terminal/lib/quote"
+)
+
+// EquityPoint is the reconstructed account equity at one point in time
+type EquityPoint struct {
+	Time          time.Time
+	Equity        string   // NUMERIC string in the converter's reporting currency; empty when Gap is set
+	Gap           bool     // Some held asset had no price at Time, so Equity is unknown
+	MissingAssets []string // Assets without a price when Gap is set
+}
+
+// balanceEvent changes asset balances at a point in time
+type balanceEvent struct {
+	at      time.Time
+	changes map[string]*big.Rat // asset -> signed delta
+	funding bool                // From a funding payment rather than a trade
+}
+
+// EquityCurve reconstructs account equity from trades and funding payments, emitting one point per
+// interval from the interval containing the first event through the one containing the last
+// Balances start at zero and there is no transfer data yet, so equity is the net result of trading
+// and funding rather than the account's absolute balance. Open exposure is valued via priceSource
+func EquityCurve(
+	ctx context.Context,
+	client db.DBClient,
+	accountID uuid.UUID,
+	priceSource quote.Converter,
+	interval time.Duration,
+) ([]EquityPoint, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval must be positive, got %s", interval)
+	}
+
+	events, err := loadBalanceEvents(ctx, client, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return []EquityPoint{}, nil
+	}
+
+	start := events[0].at.Truncate(interval)
+	last := events[len(events)-1].at
+
+	balances := make(map[string]*big.Rat)
+	points := make([]EquityPoint, 0)
+	next := 0
+	for at := start; ; at = at.Add(interval) {
+		for next < len(events) && !events[next].at.After(at) {
+			addChanges(balances, events[next].changes)
+			next++
+		}
+
+		point, err := valueBalances(ctx, priceSource, balances, at)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+
+		if !at.Before(last) {
+			break
+		}
+	}
+
+	return points, nil
+}
+
+// loadBalanceEvents reads the account's trades and funding payments as balance changes, oldest first
+func loadBalanceEvents(ctx context.Context, client db.DBClient, accountID uuid.UUID) ([]balanceEvent, error) {
+	trades, err := client.ListTrades(ctx, db.TradeFilter{ExchangeAccountIDs: []uuid.UUID{accountID}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trades: %w", err)
+	}
+	payments, err := client.ListFundingPayments(ctx, db.FundingPaymentFilter{ExchangeAccountIDs: []uuid.UUID{accountID}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load funding payments: %w", err)
+	}
+
+	events := make([]balanceEvent, 0, len(trades)+len(payments))
+	for _, trade := range trades {
+		event, err := tradeEvent(trade)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	for _, payment := range payments {
+		amount, err := models.ParseNumeric(payment.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("funding payment %s: %w", payment.PaymentID, err)
+		}
+		events = append(events, balanceEvent{
+			at:      payment.Timestamp,
+			changes: map[string]*big.Rat{payment.QuoteAsset: amount},
+			funding: true,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].at.Before(events[j].at)
+	})
+	return events, nil
+}
+
+// tradeEvent converts a trade into base and quote balance changes
+// The fee is charged in the quote asset
+func tradeEvent(trade *models.Trade) (balanceEvent, error) {
+	price, err := models.ParseNumeric(trade.Price)
+	if err != nil {
+		return balanceEvent{}, fmt.Errorf("trade %s price: %w", trade.TradeID, err)
+	}
+	quantity, err := models.ParseNumeric(trade.Quantity)
+	if err != nil {
+		return balanceEvent{}, fmt.Errorf("trade %s quantity: %w", trade.TradeID, err)
+	}
+	fee := new(big.Rat)
+	if trade.Fee != "" {
+		if fee, err = models.ParseNumeric(trade.Fee); err != nil {
+			return balanceEvent{}, fmt.Errorf("trade %s fee: %w", trade.TradeID, err)
+		}
+	}
+
+	notional := new(big.Rat).Mul(price, quantity)
+	base := new(big.Rat).Set(quantity)
+	quoteDelta := new(big.Rat).Neg(notional)
+	if trade.Side == "sell" {
+		base.Neg(base)
+		quoteDelta.Set(notional)
+	}
+	quoteDelta.Sub(quoteDelta, fee)
+
+	changes := map[string]*big.Rat{trade.BaseAsset: base}
+	if existing, ok := changes[trade.QuoteAsset]; ok {
+		existing.Add(existing, quoteDelta)
+	} else {
+		changes[trade.QuoteAsset] = quoteDelta
+	}
+	return balanceEvent{at: trade.Timestamp, changes: changes}, nil
+}
+
+// valueBalances values every non-zero balance at time at
+// Assets without a price mark the point as a gap instead of counting as zero
+func valueBalances(ctx context.Context, priceSource quote.Converter, balances map[string]*big.Rat, at time.Time) (EquityPoint, error) {
+	assets := make([]string, 0, len(balances))
+	for asset := range balances {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+
+	point := EquityPoint{Time: at}
+	total := new(big.Rat)
+	for _, asset := range assets {
+		amount := balances[asset]
+		if amount.Sign() == 0 {
+			continue
+		}
+
+		value, err := priceSource.Value(ctx, asset, amount, at)
+		if errors.Is(err, quote.ErrNoPrice) {
+			point.Gap = true
+			point.MissingAssets = append(point.MissingAssets, asset)
+			continue
+		}
+		if err != nil {
+			return EquityPoint{}, fmt.Errorf("failed to value %s at %s: %w", asset, at, err)
+		}
+		total.Add(total, value)
+	}
+
+	if !point.Gap {
+		point.Equity = models.FormatNumeric(total)
+	}
+	return point, nil
+}
@@ -0,0 +1,121 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/db"
+	"github.com/zif-terminal/lib/models"
+	"github.com/zif-terminal/lib/quote"
+)
+
+// fakeDB serves fixed trades and funding payments; other DBClient methods are not used
+type fakeDB struct {
+	db.DBClient
+	trades   []*models.Trade
+	payments []*models.FundingPayment
+}
+
+func (f *fakeDB) ListTrades(ctx context.Context, filter db.TradeFilter) ([]*db.Trade, error) {
+	return f.trades, nil
+}
+
+func (f *fakeDB) ListFundingPayments(ctx context.Context, filter db.FundingPaymentFilter) ([]*db.FundingPayment, error) {
+	return f.payments, nil
+}
+
+// fakeConverter values USDC 1:1 and other assets from a price table keyed by asset and time
+type fakeConverter struct {
+	prices map[string]map[time.Time]string
+}
+
+func (f *fakeConverter) Value(ctx context.Context, asset string, amount *big.Rat, at time.Time) (*big.Rat, error) {
+	if asset == "USDC" {
+		return new(big.Rat).Set(amount), nil
+	}
+	price, ok := f.prices[asset][at]
+	if !ok {
+		return nil, fmt.Errorf("%s at %s: %w", asset, at, quote.ErrNoPrice)
+	}
+	r, _ := new(big.Rat).SetString(price)
+	return r.Mul(r, amount), nil
+}
+
+func TestEquityCurve(t *testing.T) {
+	accountID := uuid.New()
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	client := &fakeDB{
+		trades: []*models.Trade{
+			// Newest first, as ListTrades returns them
+			{TradeID: "2", BaseAsset: "BTC", QuoteAsset: "USDC", Side: "sell", Price: "110", Quantity: "1", Fee: "1", Timestamp: t0.Add(135 * time.Minute)},
+			{TradeID: "1", BaseAsset: "BTC", QuoteAsset: "USDC", Side: "buy", Price: "100", Quantity: "1", Fee: "1", Timestamp: t0.Add(10 * time.Minute)},
+		},
+		payments: []*models.FundingPayment{
+			{PaymentID: "f1", BaseAsset: "BTC", QuoteAsset: "USDC", Amount: "-2.5", Timestamp: t0.Add(90 * time.Minute)},
+		},
+	}
+	converter := &fakeConverter{prices: map[string]map[time.Time]string{
+		"BTC": {t0.Add(time.Hour): "105"}, // No BTC price at 02:00
+	}}
+
+	points, err := EquityCurve(context.Background(), client, accountID, converter, time.Hour)
+	if err != nil {
+		t.Fatalf("EquityCurve failed: %v", err)
+	}
+
+	// 00:00 nothing held yet
+	// 01:00 1 BTC @105 - 101 USDC = 4
+	// 02:00 1 BTC unpriced -> gap
+	// 03:00 BTC sold: -101 - 2.5 + 109 = 5.5 USDC
+	want := []EquityPoint{
+		{Time: t0, Equity: "0"},
+		{Time: t0.Add(time.Hour), Equity: "4"},
+		{Time: t0.Add(2 * time.Hour), Gap: true, MissingAssets: []string{"BTC"}},
+		{Time: t0.Add(3 * time.Hour), Equity: "5.5"},
+	}
+	if !reflect.DeepEqual(points, want) {
+		t.Errorf("EquityCurve() = %+v, want %+v", points, want)
+	}
+}
+
+func TestEquityCurve_NoEvents(t *testing.T) {
+	points, err := EquityCurve(context.Background(), &fakeDB{}, uuid.New(), &fakeConverter{}, time.Hour)
+	if err != nil {
+		t.Fatalf("EquityCurve failed: %v", err)
+	}
+	if len(points) != 0 {
+		t.Errorf("Expected no points, got %d", len(points))
+	}
+}
+
+func TestEquityCurve_ConverterError(t *testing.T) {
+	client := &fakeDB{trades: []*models.Trade{
+		{TradeID: "1", BaseAsset: "BTC", QuoteAsset: "USDC", Side: "buy", Price: "100", Quantity: "1", Fee: "0", Timestamp: time.Unix(0, 0).UTC()},
+	}}
+	failing := converterFunc(func(ctx context.Context, asset string, amount *big.Rat, at time.Time) (*big.Rat, error) {
+		return nil, errors.New("price feed down")
+	})
+
+	if _, err := EquityCurve(context.Background(), client, uuid.New(), failing, time.Hour); err == nil {
+		t.Fatal("Expected converter error to be returned, got nil")
+	}
+}
+
+func TestEquityCurve_InvalidInterval(t *testing.T) {
+	if _, err := EquityCurve(context.Background(), &fakeDB{}, uuid.New(), &fakeConverter{}, 0); err == nil {
+		t.Fatal("Expected error for zero interval, got nil")
+	}
+}
+
+type converterFunc func(ctx context.Context, asset string, amount *big.Rat, at time.Time) (*big.Rat, error)
+
+func (f converterFunc) Value(ctx context.Context, asset string, amount *big.Rat, at time.Time) (*big.Rat, error) {
+	return f(ctx, asset, amount, at)
+}
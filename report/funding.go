@@ -0,0 +1,27 @@
+package report
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/zif-terminal/lib/models"
+)
+
+// SumFundingInWindow returns the signed sum of funding payment amounts with start <= timestamp < end
+// The window is half-open so adjacent windows never count a payment twice
+// Returns "0" when no payment falls in the window
+func SumFundingInWindow(payments []*models.FundingPayment, start, end time.Time) (string, error) {
+	total := new(big.Rat)
+	for _, payment := range payments {
+		if payment == nil || payment.Timestamp.Before(start) || !payment.Timestamp.Before(end) {
+			continue
+		}
+		amount, err := models.ParseNumeric(payment.Amount)
+		if err != nil {
+			return "", fmt.Errorf("funding payment %s: %w", payment.PaymentID, err)
+		}
+		total.Add(total, amount)
+	}
+	return models.FormatNumeric(total), nil
+}
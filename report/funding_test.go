@@ -0,0 +1,54 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zif-terminal/lib/models"
+)
+
+func TestSumFundingInWindow(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	payments := []*models.FundingPayment{
+		{PaymentID: "before", Amount: "100", Timestamp: start.Add(-time.Millisecond)},
+		{PaymentID: "at-start", Amount: "1.5", Timestamp: start},
+		{PaymentID: "inside", Amount: "-0.75", Timestamp: start.Add(time.Hour)},
+		{PaymentID: "last-ms", Amount: "0.000000000000000001", Timestamp: end.Add(-time.Millisecond)},
+		{PaymentID: "at-end", Amount: "200", Timestamp: end},
+	}
+
+	tests := []struct {
+		name       string
+		payments   []*models.FundingPayment
+		start, end time.Time
+		want       string
+	}{
+		{name: "start inclusive, end exclusive", payments: payments, start: start, end: end, want: "0.750000000000000001"},
+		{name: "only negatives", payments: payments[2:3], start: start, end: end, want: "-0.75"},
+		{name: "empty window", payments: payments, start: end, end: end, want: "0"},
+		{name: "no payments", payments: nil, start: start, end: end, want: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SumFundingInWindow(tt.payments, tt.start, tt.end)
+			if err != nil {
+				t.Fatalf("SumFundingInWindow failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SumFundingInWindow() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSumFundingInWindow_InvalidAmount(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	payments := []*models.FundingPayment{{PaymentID: "bad", Amount: "abc", Timestamp: start}}
+
+	if _, err := SumFundingInWindow(payments, start, start.Add(time.Hour)); err == nil {
+		t.Fatal("Expected error for invalid amount, got nil")
+	}
+}
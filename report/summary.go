@@ -0,0 +1,99 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/db"
+	"github.com/zif-terminal/lib/models"
+	"github.com/zif-terminal/lib/quote"
+)
+
+// AccountSummary is an account's net result from trading and funding up to a point in time,
+// valued in the account's PnL denomination
+type AccountSummary struct {
+	ExchangeAccountID uuid.UUID
+	Denomination      string // Currency of NetPnL and Funding (see PnLDenomination)
+	At                time.Time
+	NetPnL            string   // Net result of trades and funding (NUMERIC); empty when Gap is set
+	Funding           string   // Net funding received (NUMERIC); empty when Gap is set
+	Gap               bool     // Some asset had no price at At, so the values are unknown
+	MissingAssets     []string // Assets without a price when Gap is set
+}
+
+// Summarize values the account's trades and funding payments up to at in its PnL denomination
+// converters holds a quote.Converter per denomination; the one for PnLDenomination(account) is used
+func Summarize(
+	ctx context.Context,
+	client db.DBClient,
+	account *models.ExchangeAccount,
+	converters map[string]quote.Converter,
+	at time.Time,
+) (*AccountSummary, error) {
+	accountID, err := uuid.Parse(account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID: %w", err)
+	}
+	denom := PnLDenomination(account)
+	converter, ok := converters[denom]
+	if !ok {
+		return nil, fmt.Errorf("no converter for PnL denomination %s", denom)
+	}
+
+	events, err := loadBalanceEvents(ctx, client, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[string]*big.Rat)
+	funding := make(map[string]*big.Rat)
+	for _, event := range events {
+		if event.at.After(at) {
+			break
+		}
+		addChanges(balances, event.changes)
+		if event.funding {
+			addChanges(funding, event.changes)
+		}
+	}
+
+	net, err := valueBalances(ctx, converter, balances, at)
+	if err != nil {
+		return nil, err
+	}
+	fundingValue, err := valueBalances(ctx, converter, funding, at)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &AccountSummary{ExchangeAccountID: accountID, Denomination: denom, At: at}
+	if net.Gap || fundingValue.Gap {
+		summary.Gap = true
+		seen := make(map[string]bool)
+		for _, asset := range append(net.MissingAssets, fundingValue.MissingAssets...) {
+			if !seen[asset] {
+				seen[asset] = true
+				summary.MissingAssets = append(summary.MissingAssets, asset)
+			}
+		}
+		sort.Strings(summary.MissingAssets)
+		return summary, nil
+	}
+	summary.NetPnL = net.Equity
+	summary.Funding = fundingValue.Equity
+	return summary, nil
+}
+
+// addChanges adds signed asset deltas to balances
+func addChanges(balances map[string]*big.Rat, changes map[string]*big.Rat) {
+	for asset, delta := range changes {
+		if balances[asset] == nil {
+			balances[asset] = new(big.Rat)
+		}
+		balances[asset].Add(balances[asset], delta)
+	}
+}
@@ -0,0 +1,84 @@
+package report
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/models"
+	"github.com/zif-terminal/lib/quote"
+)
+
+func TestSummarize_UsesAccountDenomination(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := &fakeDB{
+		trades: []*models.Trade{
+			{TradeID: "2", BaseAsset: "BTC", QuoteAsset: "USDC", Side: "sell", Price: "110", Quantity: "1", Fee: "1", Timestamp: t0.Add(2 * time.Hour)},
+			{TradeID: "1", BaseAsset: "BTC", QuoteAsset: "USDC", Side: "buy", Price: "100", Quantity: "1", Fee: "1", Timestamp: t0},
+		},
+		payments: []*models.FundingPayment{
+			{PaymentID: "f1", BaseAsset: "BTC", QuoteAsset: "USDC", Amount: "-2", Timestamp: t0.Add(time.Hour)},
+		},
+	}
+	// USDT converter: 1 USDC = 2 USDT, to tell the converters apart
+	usdt := converterFunc(func(ctx context.Context, asset string, amount *big.Rat, at time.Time) (*big.Rat, error) {
+		return new(big.Rat).Mul(amount, big.NewRat(2, 1)), nil
+	})
+	converters := map[string]quote.Converter{"USDC": &fakeConverter{}, "USDT": usdt}
+
+	tests := []struct {
+		name        string
+		denom       *string
+		wantDenom   string
+		wantNet     string
+		wantFunding string
+	}{
+		{name: "unset uses the default", denom: nil, wantDenom: "USDC", wantNet: "6", wantFunding: "-2"},
+		{name: "preference", denom: strPtr("usdt"), wantDenom: "USDT", wantNet: "12", wantFunding: "-4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := &models.ExchangeAccount{ID: uuid.New().String(), PnLDenomination: tt.denom}
+			summary, err := Summarize(context.Background(), client, account, converters, t0.Add(3*time.Hour))
+			if err != nil {
+				t.Fatalf("Summarize failed: %v", err)
+			}
+			if summary.Denomination != tt.wantDenom || summary.NetPnL != tt.wantNet || summary.Funding != tt.wantFunding {
+				t.Errorf("Summarize() = %+v, want %s net %s funding %s", summary, tt.wantDenom, tt.wantNet, tt.wantFunding)
+			}
+		})
+	}
+}
+
+func TestSummarize_Gap(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := &fakeDB{trades: []*models.Trade{
+		{TradeID: "1", BaseAsset: "BTC", QuoteAsset: "USDC", Side: "buy", Price: "100", Quantity: "1", Fee: "0", Timestamp: t0},
+	}}
+	converters := map[string]quote.Converter{"USDC": &fakeConverter{}}
+
+	summary, err := Summarize(context.Background(), client, &models.ExchangeAccount{ID: uuid.New().String()}, converters, t0)
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+	if !summary.Gap || summary.NetPnL != "" || !reflect.DeepEqual(summary.MissingAssets, []string{"BTC"}) {
+		t.Errorf("Expected a gap for unpriced BTC, got %+v", summary)
+	}
+}
+
+func TestSummarize_MissingConverter(t *testing.T) {
+	account := &models.ExchangeAccount{ID: uuid.New().String(), PnLDenomination: strPtr("USD")}
+	converters := map[string]quote.Converter{"USDC": &fakeConverter{}}
+
+	if _, err := Summarize(context.Background(), &fakeDB{}, account, converters, time.Now()); err == nil {
+		t.Fatal("Expected an error without a USD converter, got nil")
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -14,7 +14,7 @@ if ! command -v go &> /dev/null; then
     exit 1
 fi
 
-# Run all tests
-go test -v ./...
+# Run all tests with the race detector so concurrency tests catch unguarded shared state
+go test -race -v ./...
 
 echo "Tests completed!"
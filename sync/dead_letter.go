@@ -0,0 +1,157 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/db"
+	"github.com/zif-terminal/lib/models"
+)
+
+// DeadLetterRecorder is implemented by stores that can set aside rows that repeatedly fail to persist
+// When the Store passed to Account implements it and Options.DeadLetterAfter is set, a failing
+// batch is split until the rows at fault are found; those are recorded and the rest stored
+// *db.Client satisfies it
+type DeadLetterRecorder interface {
+	AddDeadLetters(ctx context.Context, inputs []*models.DeadLetterInput) ([]*models.DeadLetter, error)
+}
+
+// failedRow is a row that kept failing to persist on its own
+type failedRow[T any] struct {
+	row      T
+	attempts int
+	err      error // Error of the last attempt
+}
+
+// storeRows inserts rows with insert and returns the inserted rows and the number of rows dead-lettered
+// Without dead-lettering (Options.DeadLetterAfter unset or a store that is not a DeadLetterRecorder)
+// this is a single insert and any failure fails the batch
+func storeRows[T, R any](
+	ctx context.Context,
+	store Store,
+	opts Options,
+	accountID uuid.UUID,
+	kind string,
+	rows []T,
+	key func(T) string,
+	insert func(ctx context.Context, rows []T) ([]R, error),
+) ([]R, int, error) {
+	recorder, ok := store.(DeadLetterRecorder)
+	if !ok || opts.DeadLetterAfter <= 0 {
+		inserted, err := insert(ctx, rows)
+		return inserted, 0, err
+	}
+
+	inserted, failed, err := insertIsolating(ctx, rows, opts.DeadLetterAfter, insert)
+	if err != nil || len(failed) == 0 {
+		return inserted, 0, err
+	}
+
+	inputs := make([]*models.DeadLetterInput, len(failed))
+	for i, f := range failed {
+		payload, err := json.Marshal(f.row)
+		if err != nil {
+			return inserted, 0, fmt.Errorf("failed to encode dead letter %s: %w", key(f.row), err)
+		}
+		inputs[i] = &models.DeadLetterInput{
+			ExchangeAccountID: accountID,
+			Kind:              kind,
+			RowKey:            key(f.row),
+			Payload:           payload,
+			Error:             f.err.Error(),
+			Attempts:          f.attempts,
+		}
+		logger(opts).Warn("dead-lettered row that failed to persist",
+			"account_id", accountID,
+			"kind", kind,
+			"row_key", inputs[i].RowKey,
+			"attempts", f.attempts,
+			"error", f.err,
+		)
+	}
+	if _, err := recorder.AddDeadLetters(ctx, inputs); err != nil {
+		return inserted, 0, fmt.Errorf("failed to record dead letters: %w", err)
+	}
+
+	return inserted, len(failed), nil
+}
+
+// insertIsolating inserts rows, splitting a failing batch in halves until the rows at fault are isolated
+// A row failing on its own is tried up to attempts times in total before it is returned as failed.
+// Any other error (see rowError) stops the insert so the run fails and can be retried; rows
+// inserted by then stay written
+func insertIsolating[T, R any](
+	ctx context.Context,
+	rows []T,
+	attempts int,
+	insert func(ctx context.Context, rows []T) ([]R, error),
+) ([]R, []failedRow[T], error) {
+	if len(rows) == 0 {
+		return nil, nil, nil
+	}
+
+	inserted, err := insert(ctx, rows)
+	if err == nil {
+		return inserted, nil, nil
+	}
+	// A batch split into several requests may have been partly written; go on from the first row not stored
+	var partial *db.PartialInsertError
+	if errors.As(err, &partial) && partial.Committed > 0 && partial.Committed < len(rows) && rowError(err) {
+		rest, failed, err := insertIsolating(ctx, rows[partial.Committed:], attempts, insert)
+		return append(inserted, rest...), failed, err
+	}
+	if !rowError(err) {
+		return inserted, nil, err
+	}
+
+	if len(rows) == 1 {
+		tries := 1
+		for ; tries < attempts; tries++ {
+			if inserted, err = insert(ctx, rows); err == nil {
+				return inserted, nil, nil
+			}
+			if !rowError(err) {
+				return nil, nil, err
+			}
+		}
+		return nil, []failedRow[T]{{row: rows[0], attempts: tries, err: err}}, nil
+	}
+
+	mid := len(rows) / 2
+	left, leftFailed, err := insertIsolating(ctx, rows[:mid], attempts, insert)
+	if err != nil {
+		return left, nil, err
+	}
+	right, rightFailed, err := insertIsolating(ctx, rows[mid:], attempts, insert)
+	if err != nil {
+		return append(left, right...), nil, err
+	}
+	return append(left, right...), append(leftFailed, rightFailed...), nil
+}
+
+// rowErrorCodes are the Hasura error codes caused by the data sent rather than by the server,
+// the schema or the connection
+var rowErrorCodes = map[string]bool{
+	"constraint-violation": true, // e.g. a check constraint or a foreign key the row breaks
+	"data-exception":       true, // e.g. a value that doesn't parse as NUMERIC
+}
+
+// rowError reports whether err was caused by the rows sent: a *db.GraphQLError whose errors all
+// carry a row error code. Outages, HTTP errors, schema or permission errors and timeouts would
+// fail any row, so dead-lettering on them would set aside good data
+func rowError(err error) bool {
+	var gqlErr *db.GraphQLError
+	if !errors.As(err, &gqlErr) || len(gqlErr.Errors) == 0 {
+		return false
+	}
+	for _, detail := range gqlErr.Errors {
+		code, _ := detail.Extensions["code"].(string)
+		if !rowErrorCodes[code] {
+			return false
+		}
+	}
+	return true
+}
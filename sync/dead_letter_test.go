@@ -0,0 +1,238 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zif-terminal/lib/db"
+	"github.com/zif-terminal/lib/models"
+)
+
+// poisonStore is a fakeStore that rejects every batch containing a poison row and records dead letters
+type poisonStore struct {
+	*fakeStore
+	poison      map[string]bool // Trade and payment IDs the store rejects
+	err         error           // Returned for rejected batches (default: a Hasura NUMERIC parse error)
+	attempts    map[string]int  // Rejected attempts per poison row
+	deadLetters []*models.DeadLetterInput
+}
+
+func (p *poisonStore) reject(key string) error {
+	if p.attempts == nil {
+		p.attempts = make(map[string]int)
+	}
+	p.attempts[key]++
+	if p.err != nil {
+		return p.err
+	}
+	return &db.GraphQLError{Operation: "AddTrades", Errors: []db.GraphQLErrorDetail{{
+		Message:    fmt.Sprintf("invalid input syntax for type numeric: %q", key),
+		Extensions: map[string]interface{}{"code": "data-exception"},
+	}}}
+}
+
+func (p *poisonStore) AddTrades(ctx context.Context, inputs []*models.TradeInput) ([]*models.Trade, error) {
+	for _, input := range inputs {
+		if p.poison[input.TradeID] {
+			return nil, p.reject(input.TradeID)
+		}
+	}
+	return p.fakeStore.AddTrades(ctx, inputs)
+}
+
+func (p *poisonStore) AddFundingPayments(ctx context.Context, inputs []*models.FundingPaymentInput) ([]*models.FundingPayment, error) {
+	for _, input := range inputs {
+		if p.poison[input.PaymentID] {
+			return nil, p.reject(input.PaymentID)
+		}
+	}
+	return p.fakeStore.AddFundingPayments(ctx, inputs)
+}
+
+func (p *poisonStore) AddDeadLetters(ctx context.Context, inputs []*models.DeadLetterInput) ([]*models.DeadLetter, error) {
+	letters := make([]*models.DeadLetter, len(inputs))
+	for i, input := range inputs {
+		if err := input.Validate(); err != nil {
+			return nil, err
+		}
+		letters[i] = &models.DeadLetter{ExchangeAccountID: input.ExchangeAccountID, Kind: input.Kind, RowKey: input.RowKey}
+	}
+	p.deadLetters = append(p.deadLetters, inputs...)
+	return letters, nil
+}
+
+func TestAccount_DeadLettersPoisonRow(t *testing.T) {
+	ids := make([]string, 50)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("t%d", i)
+	}
+	ex := &fakeExchange{
+		trades: testTrades(ids...),
+		payments: []*models.FundingPaymentInput{
+			{PaymentID: "p1", Timestamp: time.Unix(1, 0)},
+			{PaymentID: "p2", Timestamp: time.Unix(2, 0)},
+		},
+	}
+	store := &poisonStore{fakeStore: &fakeStore{}, poison: map[string]bool{"t17": true, "p2": true}}
+	account := testAccount()
+
+	var logs bytes.Buffer
+	report, err := Account(context.Background(), ex, store, account, Options{
+		DeadLetterAfter: 3,
+		Logger:          slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+	if err != nil {
+		t.Fatalf("Account failed: %v", err)
+	}
+	if strings.Count(logs.String(), "dead-lettered row") != 2 {
+		t.Errorf("Expected a warning per dead letter on the configured logger, got: %s", logs.String())
+	}
+
+	if len(store.trades) != 49 || report.TradesInserted != 49 || report.TradesDeadLettered != 1 {
+		t.Errorf("Expected 49 trades stored and 1 dead-lettered, got %d stored and %+v", len(store.trades), report)
+	}
+	for _, trade := range store.trades {
+		if trade.TradeID == "t17" {
+			t.Error("Expected the poison trade not to be stored")
+		}
+	}
+	if len(store.payments) != 1 || report.FundingInserted != 1 || report.FundingDeadLettered != 1 {
+		t.Errorf("Expected 1 payment stored and 1 dead-lettered, got %d stored and %+v", len(store.payments), report)
+	}
+
+	if len(store.deadLetters) != 2 {
+		t.Fatalf("Expected 2 dead letters, got %d", len(store.deadLetters))
+	}
+	trade := store.deadLetters[0]
+	if trade.Kind != models.SyncRunKindTrades || trade.RowKey != "t17" || trade.ExchangeAccountID.String() != account.ID {
+		t.Errorf("Unexpected trade dead letter: %+v", trade)
+	}
+	if trade.Attempts != 3 || trade.Error != `graphql: invalid input syntax for type numeric: "t17"` {
+		t.Errorf("Expected 3 attempts and the last error, got %d and %q", trade.Attempts, trade.Error)
+	}
+	var payload models.TradeInput
+	if err := json.Unmarshal(trade.Payload, &payload); err != nil || payload.TradeID != "t17" || payload.BaseAsset != "BTC" {
+		t.Errorf("Expected the trade input as payload, got %s (%v)", trade.Payload, err)
+	}
+	if payment := store.deadLetters[1]; payment.Kind != models.SyncRunKindFundingPayments || payment.RowKey != "p2" {
+		t.Errorf("Unexpected payment dead letter: %+v", payment)
+	}
+}
+
+func TestAccount_DeadLetteringNeedsOptIn(t *testing.T) {
+	ex := &fakeExchange{trades: testTrades("t1", "t2")}
+	store := &poisonStore{fakeStore: &fakeStore{}, poison: map[string]bool{"t2": true}}
+
+	report, err := Account(context.Background(), ex, store, testAccount(), Options{})
+	if err == nil {
+		t.Fatal("Expected the poison row to fail the batch without DeadLetterAfter")
+	}
+	if len(store.trades) != 0 || len(store.deadLetters) != 0 || report.TradesDeadLettered != 0 {
+		t.Errorf("Expected nothing stored or dead-lettered, got %d trades and %d dead letters", len(store.trades), len(store.deadLetters))
+	}
+}
+
+func TestAccount_ConnectionErrorsAreNotDeadLettered(t *testing.T) {
+	ex := &fakeExchange{trades: testTrades("t1", "t2", "t3")}
+	store := &poisonStore{
+		fakeStore: &fakeStore{},
+		poison:    map[string]bool{"t2": true},
+		err:       &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")},
+	}
+
+	_, err := Account(context.Background(), ex, store, testAccount(), Options{DeadLetterAfter: 3})
+
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		t.Fatalf("Expected the connection error to fail the sync, got %v", err)
+	}
+	if len(store.deadLetters) != 0 || store.attempts["t2"] != 1 {
+		t.Errorf("Expected no isolation or dead letters, got %d attempts and %d dead letters", store.attempts["t2"], len(store.deadLetters))
+	}
+}
+
+func TestAccount_ServerErrorsAreNotDeadLettered(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "http status", err: &db.HTTPStatusError{StatusCode: 503, Body: "upstream unavailable"}},
+		{name: "schema error", err: &db.GraphQLError{Errors: []db.GraphQLErrorDetail{{
+			Message:    "field 'fee_asset' not found in type: 'trades_insert_input'",
+			Extensions: map[string]interface{}{"code": "validation-failed"},
+		}}}},
+		{name: "permission error", err: &db.GraphQLError{Errors: []db.GraphQLErrorDetail{{
+			Message:    "check constraint of an insert permission has failed",
+			Extensions: map[string]interface{}{"code": "permission-error"},
+		}}}},
+		{name: "plain error", err: errors.New("unexpected end of JSON input")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := &fakeExchange{trades: testTrades("t1", "t2", "t3")}
+			store := &poisonStore{fakeStore: &fakeStore{}, poison: map[string]bool{"t2": true}, err: tt.err}
+
+			_, err := Account(context.Background(), ex, store, testAccount(), Options{DeadLetterAfter: 3})
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected the server error to fail the sync, got %v", err)
+			}
+			if len(store.deadLetters) != 0 || store.attempts["t2"] != 1 {
+				t.Errorf("Expected no isolation or dead letters, got %d attempts and %d dead letters", store.attempts["t2"], len(store.deadLetters))
+			}
+		})
+	}
+}
+
+// chunkingStore is a poisonStore that writes batches in requests of chunk rows, like db.Client
+// does for large batches, so a poison row fails its request after earlier ones were written
+type chunkingStore struct {
+	*poisonStore
+	chunk int
+}
+
+func (c *chunkingStore) AddTrades(ctx context.Context, inputs []*models.TradeInput) ([]*models.Trade, error) {
+	var stored []*models.Trade
+	for start := 0; start < len(inputs); start += c.chunk {
+		end := min(start+c.chunk, len(inputs))
+		rows, err := c.poisonStore.AddTrades(ctx, inputs[start:end])
+		if err != nil {
+			if start == 0 {
+				return nil, err
+			}
+			return stored, &db.PartialInsertError{Operation: "AddTrades", Committed: start, Total: len(inputs), Err: err}
+		}
+		stored = append(stored, rows...)
+	}
+	return stored, nil
+}
+
+func TestAccount_DeadLetteringResumesAfterPartialInsert(t *testing.T) {
+	ids := make([]string, 10)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("t%d", i)
+	}
+	ex := &fakeExchange{trades: testTrades(ids...)}
+	store := &chunkingStore{poisonStore: &poisonStore{fakeStore: &fakeStore{}, poison: map[string]bool{"t7": true}}, chunk: 4}
+
+	report, err := Account(context.Background(), ex, store, testAccount(), Options{DeadLetterAfter: 2})
+	if err != nil {
+		t.Fatalf("Account failed: %v", err)
+	}
+
+	if len(store.trades) != 9 || report.TradesInserted != 9 || report.TradesDeadLettered != 1 {
+		t.Errorf("Expected 9 trades stored once and 1 dead-lettered, got %d stored and %+v", len(store.trades), report)
+	}
+	if len(store.deadLetters) != 1 || store.deadLetters[0].RowKey != "t7" {
+		t.Errorf("Expected only t7 dead-lettered, got %+v", store.deadLetters)
+	}
+}
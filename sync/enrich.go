@@ -0,0 +1,62 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zif-terminal/lib/models"
+)
+
+// Enricher attaches computed fields to a trade after it is fetched and before it is stored
+// (e.g. USD notional, strategy tags derived from order ID prefixes)
+type Enricher func(ctx context.Context, trade *models.TradeInput) error
+
+// EnrichErrorPolicy decides how an enricher error is handled
+type EnrichErrorPolicy int
+
+const (
+	// FailBatch aborts the sync and stores none of the fetched trades
+	FailBatch EnrichErrorPolicy = iota
+	// SkipRow drops the failing trade, records the error in the Report and continues
+	SkipRow
+)
+
+// DefaultFeeAsset fills FeeAsset with the trade's quote asset when the exchange did not report one
+func DefaultFeeAsset(ctx context.Context, trade *models.TradeInput) error {
+	if trade.FeeAsset == "" {
+		trade.FeeAsset = trade.QuoteAsset
+	}
+	return nil
+}
+
+// enrichTrades runs every enricher in order on each trade, applying the configured error policy
+func enrichTrades(ctx context.Context, trades []*models.TradeInput, opts Options, report *Report) ([]*models.TradeInput, error) {
+	if len(opts.Enrichers) == 0 {
+		return trades, nil
+	}
+
+	kept := make([]*models.TradeInput, 0, len(trades))
+	for _, trade := range trades {
+		if err := enrichTrade(ctx, trade, opts.Enrichers); err != nil {
+			if opts.EnrichErrorPolicy == SkipRow {
+				report.TradesSkipped++
+				report.EnrichErrors = append(report.EnrichErrors, err)
+				continue
+			}
+			return nil, err
+		}
+		kept = append(kept, trade)
+	}
+
+	return kept, nil
+}
+
+// enrichTrade runs enrichers on a single trade, stopping at the first error
+func enrichTrade(ctx context.Context, trade *models.TradeInput, enrichers []Enricher) error {
+	for i, enrich := range enrichers {
+		if err := enrich(ctx, trade); err != nil {
+			return fmt.Errorf("enricher %d failed for trade %s: %w", i, trade.TradeID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrFetchNotConfirmed is returned in guarded mode when Confirm declines a large fetch
+// Nothing from the fetch is stored
+var ErrFetchNotConfirmed = errors.New("large fetch not confirmed")
+
+// SoftLimits flags suspiciously large fetches (e.g. a zero since against a production account)
+// Zero thresholds disable the checks, keeping the default behavior unchanged
+type SoftLimits struct {
+	MaxTrades          int // Warn when a fetch returns more trades than this
+	MaxFundingPayments int // Warn when a fetch returns more funding payments than this
+
+	// OnExceeded is called for every exceeded threshold; when nil a warning is logged
+	OnExceeded func(ctx context.Context, warning LimitWarning)
+
+	// Guarded pauses after a threshold is exceeded and stores the data only if Confirm returns true
+	Guarded bool
+	// Confirm decides whether a guarded sync continues; a nil Confirm declines
+	Confirm func(ctx context.Context, warning LimitWarning) bool
+}
+
+// LimitWarning describes a fetch that exceeded a soft limit
+type LimitWarning struct {
+	AccountID uuid.UUID
+	Kind      string // "trades" or "funding_payments"
+	Count     int
+	Threshold int
+	Since     time.Time
+}
+
+// check reports an exceeded threshold and, in guarded mode, asks for confirmation
+func (l SoftLimits) check(ctx context.Context, warning LimitWarning) error {
+	if warning.Threshold <= 0 || warning.Count <= warning.Threshold {
+		return nil
+	}
+
+	if l.OnExceeded != nil {
+		l.OnExceeded(ctx, warning)
+	} else {
+		slog.Default().Warn("large fetch exceeds soft limit",
+			"account_id", warning.AccountID,
+			"kind", warning.Kind,
+			"count", warning.Count,
+			"threshold", warning.Threshold,
+			"since", warning.Since,
+		)
+	}
+
+	if l.Guarded && (l.Confirm == nil || !l.Confirm(ctx, warning)) {
+		return fmt.Errorf("%w: %d %s exceeds limit of %d", ErrFetchNotConfirmed, warning.Count, warning.Kind, warning.Threshold)
+	}
+
+	return nil
+}
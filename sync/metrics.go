@@ -0,0 +1,129 @@
+package sync
+
+import (
+	"errors"
+	"math"
+
+	"github.com/zif-terminal/lib/clock"
+	"github.com/zif-terminal/lib/exchange/iface"
+	"github.com/zif-terminal/lib/models"
+)
+
+// Metric names registered by NewMetrics
+const (
+	// MetricSyncDuration is a histogram of per-account sync duration in seconds, labelled by exchange
+	MetricSyncDuration = "sync_account_duration_seconds"
+	// MetricTradesInserted counts trades inserted, labelled by exchange
+	MetricTradesInserted = "sync_trades_inserted_total"
+	// MetricFundingInserted counts funding payments inserted, labelled by exchange
+	MetricFundingInserted = "sync_funding_payments_inserted_total"
+	// MetricAccountsSyncing is a gauge of accounts currently syncing, labelled by exchange
+	MetricAccountsSyncing = "sync_accounts_in_progress"
+	// MetricRateLimitHits counts exchange rate-limit errors, labelled by exchange
+	MetricRateLimitHits = "sync_rate_limit_hits_total"
+	// MetricTradeFreshness is a gauge of seconds since the account's latest stored trade,
+	// labelled by exchange and account_id
+	MetricTradeFreshness = "sync_trade_freshness_seconds"
+)
+
+// Label names used by the sync metrics
+const (
+	LabelExchange  = "exchange"
+	LabelAccountID = "account_id"
+)
+
+// Counter is a monotonically increasing metric
+type Counter interface {
+	Add(delta float64, labelValues ...string)
+}
+
+// Gauge is a metric that can go up and down
+type Gauge interface {
+	Set(value float64, labelValues ...string)
+	Add(delta float64, labelValues ...string)
+}
+
+// Histogram records observations into buckets
+type Histogram interface {
+	Observe(value float64, labelValues ...string)
+}
+
+// Registerer creates metrics in a metrics backend (e.g. a thin adapter over a Prometheus registry)
+// Label values are passed in the order the label names were registered
+type Registerer interface {
+	Counter(name, help string, labelNames ...string) Counter
+	Gauge(name, help string, labelNames ...string) Gauge
+	Histogram(name, help string, buckets []float64, labelNames ...string) Histogram
+}
+
+// DurationBuckets are the exponential histogram buckets for MetricSyncDuration (0.1s to ~7min)
+var DurationBuckets = ExponentialBuckets(0.1, 2, 13)
+
+// ExponentialBuckets returns count buckets starting at start, each factor times the previous
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start * math.Pow(factor, float64(i))
+	}
+	return buckets
+}
+
+// Metrics instruments sync runs; create it once with NewMetrics and share it via Options.Metrics
+// A nil *Metrics records nothing
+type Metrics struct {
+	duration        Histogram
+	tradesInserted  Counter
+	fundingInserted Counter
+	syncing         Gauge
+	rateLimitHits   Counter
+	freshness       Gauge
+}
+
+// NewMetrics registers the sync metrics with reg
+func NewMetrics(reg Registerer) *Metrics {
+	return &Metrics{
+		duration:        reg.Histogram(MetricSyncDuration, "Duration of a single account sync in seconds", DurationBuckets, LabelExchange),
+		tradesInserted:  reg.Counter(MetricTradesInserted, "Trades inserted by sync", LabelExchange),
+		fundingInserted: reg.Counter(MetricFundingInserted, "Funding payments inserted by sync", LabelExchange),
+		syncing:         reg.Gauge(MetricAccountsSyncing, "Accounts currently syncing", LabelExchange),
+		rateLimitHits:   reg.Counter(MetricRateLimitHits, "Exchange rate-limit errors hit during sync", LabelExchange),
+		freshness:       reg.Gauge(MetricTradeFreshness, "Seconds since the latest stored trade", LabelExchange, LabelAccountID),
+	}
+}
+
+// start marks an account sync as in progress and returns a function that records its end,
+// timing it with clk
+func (m *Metrics) start(exchange string, clk clock.Clock) func() {
+	if m == nil {
+		return func() {}
+	}
+	began := clk.Now()
+	m.syncing.Add(1, exchange)
+	return func() {
+		m.syncing.Add(-1, exchange)
+		m.duration.Observe(clk.Now().Sub(began).Seconds(), exchange)
+	}
+}
+
+// observeReport records inserted counts and trade freshness (as of clk) once an account sync finishes
+func (m *Metrics) observeReport(exchange string, account *models.ExchangeAccount, report *Report, clk clock.Clock) {
+	if m == nil || report == nil {
+		return
+	}
+	m.tradesInserted.Add(float64(report.TradesInserted), exchange)
+	m.fundingInserted.Add(float64(report.FundingInserted), exchange)
+	if !report.LatestTradeAt.IsZero() {
+		m.freshness.Set(clk.Now().Sub(report.LatestTradeAt).Seconds(), exchange, account.ID)
+	}
+}
+
+// observeError counts rate-limit errors returned by the exchange
+func (m *Metrics) observeError(exchange string, err error) {
+	if m == nil {
+		return
+	}
+	var rateLimit *iface.RateLimitError
+	if errors.As(err, &rateLimit) {
+		m.rateLimitHits.Add(1, exchange)
+	}
+}
@@ -0,0 +1,146 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"strings"
+	stdsync "sync"
+	"testing"
+	"time"
+
+	"github.com/zif-terminal/lib/clock"
+	"github.com/zif-terminal/lib/exchange/iface"
+	"github.com/zif-terminal/lib/models"
+)
+
+// memoryRegistry is a Registerer keeping metric values in memory, keyed by name and label values
+type memoryRegistry struct {
+	mu           stdsync.Mutex
+	values       map[string]float64
+	observations map[string][]float64
+	maxValues    map[string]float64 // Highest value each gauge reached
+}
+
+func newMemoryRegistry() *memoryRegistry {
+	return &memoryRegistry{
+		values:       make(map[string]float64),
+		observations: make(map[string][]float64),
+		maxValues:    make(map[string]float64),
+	}
+}
+
+func metricKey(name string, labelValues []string) string {
+	return name + "{" + strings.Join(labelValues, ",") + "}"
+}
+
+type memoryMetric struct {
+	registry *memoryRegistry
+	name     string
+}
+
+func (m memoryMetric) Add(delta float64, labelValues ...string) {
+	m.registry.mu.Lock()
+	defer m.registry.mu.Unlock()
+	key := metricKey(m.name, labelValues)
+	m.registry.values[key] += delta
+	m.registry.maxValues[key] = max(m.registry.maxValues[key], m.registry.values[key])
+}
+
+func (m memoryMetric) Set(value float64, labelValues ...string) {
+	m.registry.mu.Lock()
+	defer m.registry.mu.Unlock()
+	m.registry.values[metricKey(m.name, labelValues)] = value
+}
+
+func (m memoryMetric) Observe(value float64, labelValues ...string) {
+	m.registry.mu.Lock()
+	defer m.registry.mu.Unlock()
+	key := metricKey(m.name, labelValues)
+	m.registry.observations[key] = append(m.registry.observations[key], value)
+}
+
+func (r *memoryRegistry) Counter(name, help string, labelNames ...string) Counter {
+	return memoryMetric{registry: r, name: name}
+}
+
+func (r *memoryRegistry) Gauge(name, help string, labelNames ...string) Gauge {
+	return memoryMetric{registry: r, name: name}
+}
+
+func (r *memoryRegistry) Histogram(name, help string, buckets []float64, labelNames ...string) Histogram {
+	return memoryMetric{registry: r, name: name}
+}
+
+// rateLimitedExchange fails every trade fetch with a rate-limit error
+type rateLimitedExchange struct {
+	fakeExchange
+}
+
+func (r *rateLimitedExchange) FetchTrades(ctx context.Context, account *models.ExchangeAccount, since time.Time) ([]*models.TradeInput, error) {
+	return nil, &iface.RateLimitError{Exchange: "fake", Message: "slow down"}
+}
+
+func TestAccount_RecordsMetrics(t *testing.T) {
+	registry := newMemoryRegistry()
+	metrics := NewMetrics(registry)
+	clk := clock.NewFake(time.Unix(1000, 0))
+
+	ex := &fakeExchange{
+		trades:   testTrades("t1", "t2", "t3"),
+		payments: []*models.FundingPaymentInput{{PaymentID: "p1", Timestamp: time.Unix(5, 0)}},
+	}
+	first, second := testAccount(), testAccount()
+
+	for _, account := range []*models.ExchangeAccount{first, second} {
+		if _, err := Account(context.Background(), ex, &fakeStore{}, account, Options{Metrics: metrics, Clock: clk}); err != nil {
+			t.Fatalf("Account failed: %v", err)
+		}
+	}
+
+	if got := registry.values[metricKey(MetricTradesInserted, []string{"fake"})]; got != 6 {
+		t.Errorf("Expected 6 trades inserted, got %v", got)
+	}
+	if got := registry.values[metricKey(MetricFundingInserted, []string{"fake"})]; got != 2 {
+		t.Errorf("Expected 2 funding payments inserted, got %v", got)
+	}
+	if got := registry.values[metricKey(MetricAccountsSyncing, []string{"fake"})]; got != 0 {
+		t.Errorf("Expected no accounts syncing after the run, got %v", got)
+	}
+	if got := registry.maxValues[metricKey(MetricAccountsSyncing, []string{"fake"})]; got != 1 {
+		t.Errorf("Expected one account syncing at a time, got peak %v", got)
+	}
+	if got := len(registry.observations[metricKey(MetricSyncDuration, []string{"fake"})]); got != 2 {
+		t.Errorf("Expected 2 duration observations, got %d", got)
+	}
+
+	// testTrades stamps the newest trade at t=2s
+	for _, account := range []*models.ExchangeAccount{first, second} {
+		if got := registry.values[metricKey(MetricTradeFreshness, []string{"fake", account.ID})]; got != 998 {
+			t.Errorf("Account %s: expected freshness 998s, got %v", account.ID, got)
+		}
+	}
+}
+
+func TestAccount_CountsRateLimitHits(t *testing.T) {
+	registry := newMemoryRegistry()
+
+	_, err := Account(context.Background(), &rateLimitedExchange{}, &fakeStore{}, testAccount(), Options{Metrics: NewMetrics(registry)})
+	var rateLimit *iface.RateLimitError
+	if !errors.As(err, &rateLimit) {
+		t.Fatalf("Expected rate-limit error, got %v", err)
+	}
+
+	if got := registry.values[metricKey(MetricRateLimitHits, []string{"fake"})]; got != 1 {
+		t.Errorf("Expected 1 rate-limit hit, got %v", got)
+	}
+}
+
+func TestExponentialBuckets(t *testing.T) {
+	buckets := ExponentialBuckets(1, 2, 4)
+	want := []float64{1, 2, 4, 8}
+	for i := range want {
+		if buckets[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, buckets)
+		}
+	}
+}
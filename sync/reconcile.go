@@ -0,0 +1,108 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/clock"
+	"github.com/zif-terminal/lib/db"
+	"github.com/zif-terminal/lib/exchange/iface"
+	"github.com/zif-terminal/lib/models"
+)
+
+// FundingReconcileReport compares an account's funding payments on the exchange with the stored rows
+type FundingReconcileReport struct {
+	AccountID     uuid.UUID
+	Since         time.Time // Start of the compared window
+	Until         time.Time // End of the compared window (when the comparison started)
+	ExchangeCount int       // Payments the exchange reported in the window
+	StoredCount   int       // Payments stored in the window
+
+	Missing []*models.FundingPaymentInput // Reported by the exchange but not stored
+	Extra   []*models.FundingPayment      // Stored but not reported by the exchange
+}
+
+// InSync reports whether the exchange and the database agree on the window
+func (r *FundingReconcileReport) InSync() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0
+}
+
+// ReconcileFunding compares the funding payments the exchange reports for account since the given
+// time with the rows stored in client, matching them by payment_id. Nothing is written: missing
+// payments can be inserted with a normal sync, and extra rows need a human look
+// The window ends at the current time
+func ReconcileFunding(
+	ctx context.Context,
+	ex iface.ExchangeClient,
+	client *db.Client,
+	account *models.ExchangeAccount,
+	since time.Time,
+) (*FundingReconcileReport, error) {
+	return ReconcileFundingWithOptions(ctx, ex, client, account, since, Options{})
+}
+
+// ReconcileFundingWithOptions is ReconcileFunding with options; the window ends at opts.Clock's
+// current time. Options other than Clock are ignored
+func ReconcileFundingWithOptions(
+	ctx context.Context,
+	ex iface.ExchangeClient,
+	client *db.Client,
+	account *models.ExchangeAccount,
+	since time.Time,
+	opts Options,
+) (*FundingReconcileReport, error) {
+	accountID, err := uuid.Parse(account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID: %w", err)
+	}
+
+	// Payments made while the comparison runs would show up on one side only, so cap the window
+	until := clock.OrReal(opts.Clock).Now()
+	report := &FundingReconcileReport{AccountID: accountID, Since: since, Until: until}
+
+	fetched, err := ex.FetchFundingPayments(ctx, account, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch funding payments: %w", err)
+	}
+
+	filter := db.FundingPaymentFilter{
+		ExchangeAccountIDs: []uuid.UUID{accountID},
+		TimestampLte:       &until,
+	}
+	if !since.IsZero() {
+		filter.TimestampGte = &since
+	}
+	stored, err := client.ListFundingPayments(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list funding payments: %w", err)
+	}
+	report.StoredCount = len(stored)
+
+	var inWindow []*models.FundingPaymentInput
+	reported := make(map[string]bool, len(fetched))
+	for _, payment := range dedupeFundingPayments(fetched) {
+		if payment.Timestamp.Before(since) || payment.Timestamp.After(until) {
+			continue
+		}
+		inWindow = append(inWindow, payment)
+		reported[payment.PaymentID] = true
+	}
+	report.ExchangeCount = len(inWindow)
+
+	storedIDs := make(map[string]bool, len(stored))
+	for _, payment := range stored {
+		storedIDs[payment.PaymentID] = true
+		if !reported[payment.PaymentID] {
+			report.Extra = append(report.Extra, payment)
+		}
+	}
+	for _, payment := range inWindow {
+		if !storedIDs[payment.PaymentID] {
+			report.Missing = append(report.Missing, payment)
+		}
+	}
+
+	return report, nil
+}
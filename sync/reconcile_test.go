@@ -0,0 +1,113 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/machinebox/graphql"
+	"github.com/zif-terminal/lib/clock"
+	"github.com/zif-terminal/lib/db"
+	"github.com/zif-terminal/lib/models"
+)
+
+// cannedGraphQL answers every request with the same response data and counts requests
+type cannedGraphQL struct {
+	data  string
+	calls int
+}
+
+func (c *cannedGraphQL) Run(ctx context.Context, req *graphql.Request, resp interface{}) error {
+	c.calls++
+	return json.Unmarshal([]byte(c.data), resp)
+}
+
+// storedPaymentsJSON renders funding_payments rows for the given payment IDs
+func storedPaymentsJSON(accountID string, timestamps map[string]time.Time) string {
+	rows := make([]string, 0, len(timestamps))
+	for paymentID, timestamp := range timestamps {
+		rows = append(rows, fmt.Sprintf(
+			`{"id": %q, "exchange_account_id": %q, "base_asset": "BTC", "quote_asset": "USDC", "amount": "1", "timestamp": %d, "payment_id": %q}`,
+			uuid.New().String(), accountID, timestamp.UnixMilli(), paymentID,
+		))
+	}
+	return `{"funding_payments": [` + strings.Join(rows, ",") + `]}`
+}
+
+func TestReconcileFunding(t *testing.T) {
+	account := testAccount()
+	since := time.Now().Add(-24 * time.Hour)
+
+	ex := &fakeExchange{payments: []*models.FundingPaymentInput{
+		{PaymentID: "p1", Timestamp: since.Add(time.Hour)},
+		{PaymentID: "p2", Timestamp: since.Add(2 * time.Hour)},
+		{PaymentID: "p2", Timestamp: since.Add(2 * time.Hour)}, // Repeated by the exchange
+		{PaymentID: "p3", Timestamp: since.Add(3 * time.Hour)},
+	}}
+	graphqlClient := &cannedGraphQL{data: storedPaymentsJSON(account.ID, map[string]time.Time{
+		"p1":    since.Add(time.Hour),
+		"p3":    since.Add(3 * time.Hour),
+		"stale": since.Add(4 * time.Hour),
+	})}
+	client := db.NewClientWithGraphQL(graphqlClient, db.ClientConfig{})
+
+	report, err := ReconcileFunding(context.Background(), ex, client, account, since)
+	if err != nil {
+		t.Fatalf("ReconcileFunding failed: %v", err)
+	}
+
+	if report.InSync() {
+		t.Error("Expected differences to be reported")
+	}
+	if report.ExchangeCount != 3 || report.StoredCount != 3 {
+		t.Errorf("Expected 3 payments on each side, got %d reported and %d stored", report.ExchangeCount, report.StoredCount)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].PaymentID != "p2" {
+		t.Errorf("Expected p2 to be missing, got %+v", report.Missing)
+	}
+	if len(report.Extra) != 1 || report.Extra[0].PaymentID != "stale" {
+		t.Errorf("Expected stale to be extra, got %+v", report.Extra)
+	}
+	if graphqlClient.calls != 1 {
+		t.Errorf("Expected a single read, got %d requests", graphqlClient.calls)
+	}
+}
+
+func TestReconcileFunding_InSync(t *testing.T) {
+	account := testAccount()
+	since := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(since.Add(30 * time.Minute))
+
+	ex := &fakeExchange{payments: []*models.FundingPaymentInput{
+		{PaymentID: "old", Timestamp: since.Add(-time.Hour)}, // Before the window
+		{PaymentID: "p1", Timestamp: since.Add(time.Minute)},
+		{PaymentID: "late", Timestamp: since.Add(45 * time.Minute)}, // After the clock's now
+	}}
+	client := db.NewClientWithGraphQL(&cannedGraphQL{
+		data: storedPaymentsJSON(account.ID, map[string]time.Time{"p1": since.Add(time.Minute)}),
+	}, db.ClientConfig{})
+
+	report, err := ReconcileFundingWithOptions(context.Background(), ex, client, account, since, Options{Clock: clk})
+	if err != nil {
+		t.Fatalf("ReconcileFunding failed: %v", err)
+	}
+	if !report.InSync() || report.ExchangeCount != 1 {
+		t.Errorf("Expected the window to be in sync, got %+v", report)
+	}
+	if !report.Until.Equal(clk.Now()) {
+		t.Errorf("Expected the window to end at the clock's now %s, got %s", clk.Now(), report.Until)
+	}
+}
+
+func TestReconcileFunding_InvalidAccount(t *testing.T) {
+	client := db.NewClientWithGraphQL(&cannedGraphQL{data: `{}`}, db.ClientConfig{})
+
+	_, err := ReconcileFunding(context.Background(), &fakeExchange{}, client, &models.ExchangeAccount{ID: "nope"}, time.Time{})
+	if err == nil {
+		t.Fatal("Expected an error for an invalid account ID")
+	}
+}
@@ -0,0 +1,62 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zif-terminal/lib/clock"
+	"github.com/zif-terminal/lib/errs"
+	"github.com/zif-terminal/lib/exchange/iface"
+	"github.com/zif-terminal/lib/models"
+)
+
+// RunAll syncs each account in order with Account; a failing account does not stop the others
+// Reports are returned in account order (nil for accounts that could not start). The error is an
+// *errs.Multi with one member per failed account, in account order, or nil when all succeeded
+// Cancelling ctx stops before the next account and adds ctx.Err() to the errors
+// When an account fails because the exchange is under maintenance or asked for a Retry-After
+// wait, RunAll pauses that long before the next account instead of hammering the exchange
+func RunAll(
+	ctx context.Context,
+	ex iface.ExchangeClient,
+	store Store,
+	accounts []*models.ExchangeAccount,
+	opts Options,
+) ([]*Report, error) {
+	reports := make([]*Report, len(accounts))
+	failures := &errs.Multi{}
+
+	for i, account := range accounts {
+		if err := ctx.Err(); err != nil {
+			failures.Append(err)
+			break
+		}
+
+		report, err := Account(ctx, ex, store, account, opts)
+		reports[i] = report
+		if err != nil {
+			failures.Append(fmt.Errorf("account %s: %w", account.ID, err))
+			if wait := backoffAfter(err); wait > 0 && i < len(accounts)-1 {
+				clock.Wait(ctx, clock.OrReal(opts.Clock), wait)
+			}
+		}
+	}
+
+	return reports, failures.ErrorOrNil()
+}
+
+// backoffAfter returns how long the exchange asked callers to wait after err (0 = no wait)
+// Maintenance windows are treated like a long rate limit
+func backoffAfter(err error) time.Duration {
+	var maintenance *iface.MaintenanceError
+	if errors.As(err, &maintenance) {
+		return maintenance.RetryAfter
+	}
+	var rateLimit *iface.RateLimitError
+	if errors.As(err, &rateLimit) {
+		return rateLimit.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,157 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zif-terminal/lib/clock"
+	"github.com/zif-terminal/lib/errs"
+	"github.com/zif-terminal/lib/exchange/iface"
+	"github.com/zif-terminal/lib/models"
+)
+
+// limitedAccountExchange fails FetchTrades for one account identifier
+type limitedAccountExchange struct {
+	fakeExchange
+	limited string
+	err     error // Returned for the limited account (nil = a RateLimitError without Retry-After)
+}
+
+func (r *limitedAccountExchange) FetchTrades(ctx context.Context, account *models.ExchangeAccount, since time.Time) ([]*models.TradeInput, error) {
+	if account.AccountIdentifier == r.limited {
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, &iface.RateLimitError{Exchange: "fake", Message: "slow down"}
+	}
+	return r.fakeExchange.FetchTrades(ctx, account, since)
+}
+
+func TestRunAll_CollectsFailures(t *testing.T) {
+	ex := &limitedAccountExchange{fakeExchange: fakeExchange{trades: testTrades("t1")}, limited: "0xlimited"}
+	store := &fakeStore{}
+
+	limited := testAccount()
+	limited.AccountIdentifier = "0xlimited"
+	accounts := []*models.ExchangeAccount{
+		testAccount(),
+		{ID: "not-a-uuid"},
+		limited,
+		testAccount(),
+	}
+
+	reports, err := RunAll(context.Background(), ex, store, accounts, Options{})
+
+	var multi *errs.Multi
+	if !errors.As(err, &multi) {
+		t.Fatalf("Expected *errs.Multi, got %T: %v", err, err)
+	}
+	if multi.Len() != 2 {
+		t.Fatalf("Expected 2 failures, got %d: %v", multi.Len(), err)
+	}
+	var rateLimit *iface.RateLimitError
+	if !errors.As(err, &rateLimit) {
+		t.Error("Expected errors.As to find the rate limit error")
+	}
+
+	if len(reports) != 4 {
+		t.Fatalf("Expected a report slot per account, got %d", len(reports))
+	}
+	if reports[1] != nil {
+		t.Error("Expected no report for the invalid account")
+	}
+	if reports[0].TradesInserted != 1 || reports[3].TradesInserted != 1 {
+		t.Error("Expected the accounts around the failures to be synced")
+	}
+}
+
+func TestRunAll_NoFailures(t *testing.T) {
+	ex := &fakeExchange{trades: testTrades("t1")}
+
+	_, err := RunAll(context.Background(), ex, &fakeStore{}, []*models.ExchangeAccount{testAccount(), testAccount()}, Options{})
+	if err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+}
+
+func TestRunAll_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reports, err := RunAll(ctx, &fakeExchange{}, &fakeStore{}, []*models.ExchangeAccount{testAccount()}, Options{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if reports[0] != nil {
+		t.Error("Expected no account to be synced after cancellation")
+	}
+}
+
+func TestRunAll_BacksOffDuringMaintenance(t *testing.T) {
+	wait := time.Hour
+	ex := &limitedAccountExchange{
+		fakeExchange: fakeExchange{trades: testTrades("t1")},
+		limited:      "0xlimited",
+		err:          &iface.MaintenanceError{Exchange: "fake", Message: "upgrading", RetryAfter: wait},
+	}
+
+	limited := testAccount()
+	limited.AccountIdentifier = "0xlimited"
+
+	fake := clock.NewFake(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+	type result struct {
+		reports []*Report
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		reports, err := RunAll(context.Background(), ex, &fakeStore{}, []*models.ExchangeAccount{limited, testAccount()}, Options{Clock: fake})
+		done <- result{reports, err}
+	}()
+
+	fake.BlockUntil(1)
+	fake.Advance(wait - time.Second)
+	select {
+	case <-done:
+		t.Fatal("Expected RunAll to wait out the maintenance window before the next account")
+	default:
+	}
+	fake.Advance(time.Second)
+
+	res := <-done
+	if !iface.IsMaintenanceError(res.err) {
+		t.Fatalf("Expected a maintenance error, got %v", res.err)
+	}
+	if res.reports[1] == nil || res.reports[1].TradesInserted != 1 {
+		t.Error("Expected the next account to be synced after the wait")
+	}
+}
+
+func TestRunAll_MaintenanceWaitStopsOnCancel(t *testing.T) {
+	ex := &limitedAccountExchange{
+		fakeExchange: fakeExchange{trades: testTrades("t1")},
+		limited:      "0xlimited",
+		err:          &iface.MaintenanceError{Exchange: "fake", Message: "upgrading", RetryAfter: time.Hour},
+	}
+
+	limited := testAccount()
+	limited.AccountIdentifier = "0xlimited"
+
+	fake := clock.NewFake(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		fake.BlockUntil(1)
+		cancel()
+	}()
+
+	reports, err := RunAll(ctx, ex, &fakeStore{}, []*models.ExchangeAccount{limited, testAccount()}, Options{Clock: fake})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the wait to end with the context, got %v", err)
+	}
+	if reports[1] != nil {
+		t.Error("Expected no account to be synced after cancellation")
+	}
+}
@@ -0,0 +1,110 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/db"
+	"github.com/zif-terminal/lib/exchange/iface"
+	"github.com/zif-terminal/lib/models"
+)
+
+// Sink persists fetched exchange data
+// Implementations return the number of rows actually written
+type Sink interface {
+	WriteTrades(ctx context.Context, trades []*models.TradeInput) (int, error)
+	WriteFundingPayments(ctx context.Context, payments []*models.FundingPaymentInput) (int, error)
+}
+
+// DBSink is a Sink backed by the database client
+type DBSink struct {
+	client *db.Client
+}
+
+// NewDBSink creates a Sink that writes through the database client
+func NewDBSink(client *db.Client) *DBSink {
+	return &DBSink{client: client}
+}
+
+// WriteTrades inserts trades, ignoring ones that are already stored
+// Only the inserted count is requested, since the rows themselves are discarded
+func (s *DBSink) WriteTrades(ctx context.Context, trades []*models.TradeInput) (int, error) {
+	return s.client.AddTradesCount(ctx, trades)
+}
+
+// WriteFundingPayments inserts funding payments, ignoring ones that are already stored
+// Only the inserted count is requested, since the rows themselves are discarded
+func (s *DBSink) WriteFundingPayments(ctx context.Context, payments []*models.FundingPaymentInput) (int, error) {
+	return s.client.AddFundingPaymentsCount(ctx, payments)
+}
+
+// RunSync fetches trades and funding payments since the given time and writes them to sink
+// Rows repeated within the fetched data (same trade or payment ID) are written once
+func RunSync(
+	ctx context.Context,
+	ex iface.ExchangeClient,
+	sink Sink,
+	account *models.ExchangeAccount,
+	since time.Time,
+) (*Report, error) {
+	accountID, err := uuid.Parse(account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID: %w", err)
+	}
+
+	report := &Report{AccountID: accountID}
+
+	trades, err := ex.FetchTrades(ctx, account, since)
+	if err != nil {
+		return report, fmt.Errorf("failed to fetch trades: %w", err)
+	}
+	report.TradesFetched = len(trades)
+	defaultTradeSource(trades)
+
+	if report.TradesInserted, err = sink.WriteTrades(ctx, dedupeTrades(trades)); err != nil {
+		return report, fmt.Errorf("failed to write trades: %w", err)
+	}
+
+	payments, err := ex.FetchFundingPayments(ctx, account, since)
+	if err != nil {
+		return report, fmt.Errorf("failed to fetch funding payments: %w", err)
+	}
+	report.FundingFetched = len(payments)
+	defaultFundingSource(payments)
+
+	if report.FundingInserted, err = sink.WriteFundingPayments(ctx, dedupeFundingPayments(payments)); err != nil {
+		return report, fmt.Errorf("failed to write funding payments: %w", err)
+	}
+
+	return report, nil
+}
+
+// dedupeTrades drops repeated trade IDs, keeping the first occurrence
+func dedupeTrades(trades []*models.TradeInput) []*models.TradeInput {
+	seen := make(map[string]bool, len(trades))
+	unique := make([]*models.TradeInput, 0, len(trades))
+	for _, trade := range trades {
+		if seen[trade.TradeID] {
+			continue
+		}
+		seen[trade.TradeID] = true
+		unique = append(unique, trade)
+	}
+	return unique
+}
+
+// dedupeFundingPayments drops repeated payment IDs, keeping the first occurrence
+func dedupeFundingPayments(payments []*models.FundingPaymentInput) []*models.FundingPaymentInput {
+	seen := make(map[string]bool, len(payments))
+	unique := make([]*models.FundingPaymentInput, 0, len(payments))
+	for _, payment := range payments {
+		if seen[payment.PaymentID] {
+			continue
+		}
+		seen[payment.PaymentID] = true
+		unique = append(unique, payment)
+	}
+	return unique
+}
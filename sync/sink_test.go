@@ -0,0 +1,94 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zif-terminal/lib/models"
+)
+
+// Ensure DBSink satisfies Sink
+var _ Sink = (*DBSink)(nil)
+
+// memorySink keeps written rows in memory and ignores IDs it has already seen
+type memorySink struct {
+	trades   map[string]*models.TradeInput
+	payments map[string]*models.FundingPaymentInput
+	err      error
+}
+
+func newMemorySink() *memorySink {
+	return &memorySink{
+		trades:   make(map[string]*models.TradeInput),
+		payments: make(map[string]*models.FundingPaymentInput),
+	}
+}
+
+func (s *memorySink) WriteTrades(ctx context.Context, trades []*models.TradeInput) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	written := 0
+	for _, trade := range trades {
+		if _, ok := s.trades[trade.TradeID]; !ok {
+			s.trades[trade.TradeID] = trade
+			written++
+		}
+	}
+	return written, nil
+}
+
+func (s *memorySink) WriteFundingPayments(ctx context.Context, payments []*models.FundingPaymentInput) (int, error) {
+	written := 0
+	for _, payment := range payments {
+		if _, ok := s.payments[payment.PaymentID]; ok {
+			return written, errors.New("duplicate payment " + payment.PaymentID)
+		}
+		s.payments[payment.PaymentID] = payment
+		written++
+	}
+	return written, nil
+}
+
+func TestRunSync_WritesFetchedData(t *testing.T) {
+	ex := &fakeExchange{
+		trades: testTrades("t1", "t2", "t1"), // t1 repeated by the exchange
+		payments: []*models.FundingPaymentInput{
+			{PaymentID: "p1", Timestamp: time.Unix(1, 0)},
+			{PaymentID: "p1", Timestamp: time.Unix(1, 0)},
+			{PaymentID: "p2", Timestamp: time.Unix(2, 0)},
+		},
+	}
+	sink := newMemorySink()
+
+	report, err := RunSync(context.Background(), ex, sink, testAccount(), time.Time{})
+	if err != nil {
+		t.Fatalf("RunSync failed: %v", err)
+	}
+
+	if report.TradesFetched != 3 || report.TradesInserted != 2 {
+		t.Errorf("Expected 3 trades fetched and 2 written, got %+v", report)
+	}
+	if report.FundingFetched != 3 || report.FundingInserted != 2 {
+		t.Errorf("Expected 3 payments fetched and 2 written, got %+v", report)
+	}
+	if len(sink.trades) != 2 || len(sink.payments) != 2 {
+		t.Errorf("Expected 2 trades and 2 payments in sink, got %d and %d", len(sink.trades), len(sink.payments))
+	}
+}
+
+func TestRunSync_SinkError(t *testing.T) {
+	ex := &fakeExchange{trades: testTrades("t1")}
+	sink := newMemorySink()
+	sink.err = errors.New("write failed")
+
+	_, err := RunSync(context.Background(), ex, sink, testAccount(), time.Time{})
+	if !errors.Is(err, sink.err) {
+		t.Fatalf("Expected sink error, got: %v", err)
+	}
+	if len(sink.payments) != 0 {
+		t.Error("Expected funding payments not to be written after a trade write failure")
+	}
+}
@@ -0,0 +1,383 @@
+// Package sync fetches trades and funding payments for an exchange account and stores them
+package sync
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/clock"
+	"github.com/zif-terminal/lib/exchange/iface"
+	"github.com/zif-terminal/lib/models"
+)
+
+// Store is the subset of the database client the sync helper needs
+// *db.Client satisfies it
+type Store interface {
+	LatestTrade(ctx context.Context, exchangeAccountIDs []uuid.UUID) (map[uuid.UUID]*models.Trade, error)
+	GetLatestFundingPayment(ctx context.Context, exchangeAccountID uuid.UUID) (*models.FundingPayment, error)
+	AddTrades(ctx context.Context, inputs []*models.TradeInput) ([]*models.Trade, error)
+	AddFundingPayments(ctx context.Context, inputs []*models.FundingPaymentInput) ([]*models.FundingPayment, error)
+	ExistingTradeIDs(ctx context.Context, exchangeAccountID uuid.UUID, tradeIDs []string) (map[string]bool, error)
+}
+
+// RunRecorder is implemented by stores that keep a durable record of sync runs
+// When the Store passed to Account implements it, one row is written per ingestion step
+// *db.Client satisfies it
+type RunRecorder interface {
+	CreateSyncRun(ctx context.Context, input *models.SyncRunInput) (*models.SyncRun, error)
+}
+
+// defaultSampleSize is the number of would-be-inserted inputs kept in a dry-run Report
+const defaultSampleSize = 10
+
+// Options configures a sync run
+type Options struct {
+	// Enrichers run in order on every fetched trade before it is stored
+	Enrichers []Enricher
+	// EnrichErrorPolicy decides what happens when an enricher fails (default: fail the batch)
+	EnrichErrorPolicy EnrichErrorPolicy
+	// DryRun fetches and enriches as usual but stores nothing; the Report describes what
+	// would have been inserted
+	DryRun bool
+	// SampleSize caps the inputs kept in the Report samples during a dry run (default 10)
+	SampleSize int
+	// Limits flags (and in guarded mode pauses on) suspiciously large fetches
+	Limits SoftLimits
+	// Metrics records sync duration, inserted counts, rate-limit hits and freshness (nil = off)
+	Metrics *Metrics
+	// Clock times sync runs, metrics and the pauses RunAll takes after rate limits (nil = real clock)
+	Clock clock.Clock
+	// DeadLetterAfter is how many times a row that fails to persist on its own is tried before it
+	// is recorded as a dead letter and the rest of its batch stored. Requires a Store that
+	// implements DeadLetterRecorder (0 = off: a failing row fails the whole batch)
+	DeadLetterAfter int
+	// Logger receives warnings such as dead-lettered rows and failed sync run records
+	// (nil = slog.Default())
+	Logger *slog.Logger
+}
+
+// Report summarizes a sync run for one account
+type Report struct {
+	AccountID       uuid.UUID
+	TradesFetched   int
+	TradesInserted  int
+	TradesSkipped   int     // Trades dropped because an enricher failed (SkipRow policy)
+	EnrichErrors    []error // Enricher failures for skipped trades
+	FundingFetched  int
+	FundingInserted int
+	IngestLag       LagStats  // Delay between exchange timestamp and ingestion for inserted rows
+	LatestTradeAt   time.Time // Timestamp of the newest stored trade after the run (zero if none)
+
+	// Rows set aside after repeatedly failing to persist (see Options.DeadLetterAfter)
+	TradesDeadLettered  int
+	FundingDeadLettered int
+
+	// Dry-run results (only set when Options.DryRun is true)
+	DryRun         bool
+	TradesNew      int                           // Fetched trades not yet stored
+	TradesExisting int                           // Fetched trades already stored
+	FundingNew     int                           // Funding payments that would be sent for insertion (already stored ones are skipped on insert)
+	TradeSample    []*models.TradeInput          // First new trades, up to SampleSize
+	FundingSample  []*models.FundingPaymentInput // First new funding payments, up to SampleSize
+}
+
+// Account syncs trades and funding payments for one account
+// Trades are fetched from the latest stored trade onwards and inserted idempotently;
+// funding payments from the latest stored payment's timestamp onwards are inserted, skipping
+// ones already stored
+func Account(
+	ctx context.Context,
+	ex iface.ExchangeClient,
+	store Store,
+	account *models.ExchangeAccount,
+	opts Options,
+) (*Report, error) {
+	accountID, err := uuid.Parse(account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID: %w", err)
+	}
+
+	report := &Report{AccountID: accountID, DryRun: opts.DryRun}
+
+	// Inserted counts are recorded even when a later step fails, since those rows are stored
+	done := opts.Metrics.start(ex.Name(), clock.OrReal(opts.Clock))
+	defer func() {
+		opts.Metrics.observeReport(ex.Name(), account, report, clock.OrReal(opts.Clock))
+		done()
+	}()
+
+	started := clock.OrReal(opts.Clock).Now()
+	err = syncTrades(ctx, ex, store, account, accountID, opts, report)
+	recordRun(ctx, store, opts, &models.SyncRunInput{
+		ExchangeAccountID: accountID,
+		Kind:              models.SyncRunKindTrades,
+		Fetched:           report.TradesFetched,
+		Inserted:          report.TradesInserted,
+		Skipped:           report.TradesSkipped + report.TradesDeadLettered,
+		StartedAt:         started,
+	}, err)
+	if err != nil {
+		opts.Metrics.observeError(ex.Name(), err)
+		return report, err
+	}
+
+	started = clock.OrReal(opts.Clock).Now()
+	err = syncFunding(ctx, ex, store, account, accountID, opts, report)
+	recordRun(ctx, store, opts, &models.SyncRunInput{
+		ExchangeAccountID: accountID,
+		Kind:              models.SyncRunKindFundingPayments,
+		Fetched:           report.FundingFetched,
+		Inserted:          report.FundingInserted,
+		Skipped:           report.FundingDeadLettered,
+		StartedAt:         started,
+	}, err)
+	if err != nil {
+		opts.Metrics.observeError(ex.Name(), err)
+		return report, err
+	}
+
+	return report, nil
+}
+
+// recordRun writes a sync run row if store is a RunRecorder; dry runs are not recorded
+// Recording is best-effort: a failure is logged and never fails the sync
+func recordRun(ctx context.Context, store Store, opts Options, input *models.SyncRunInput, syncErr error) {
+	recorder, ok := store.(RunRecorder)
+	if !ok || opts.DryRun {
+		return
+	}
+	input.Duration = clock.OrReal(opts.Clock).Now().Sub(input.StartedAt)
+	if syncErr != nil {
+		message := syncErr.Error()
+		input.Error = &message
+	}
+	// Record even when the sync was canceled, since that is when the row matters most
+	ctx = context.WithoutCancel(ctx)
+	if _, err := recorder.CreateSyncRun(ctx, input); err != nil {
+		logger(opts).Warn("failed to record sync run",
+			"account_id", input.ExchangeAccountID,
+			"kind", input.Kind,
+			"error", err,
+		)
+	}
+}
+
+// syncTrades fetches, enriches and stores trades
+func syncTrades(
+	ctx context.Context,
+	ex iface.ExchangeClient,
+	store Store,
+	account *models.ExchangeAccount,
+	accountID uuid.UUID,
+	opts Options,
+	report *Report,
+) error {
+	latest, err := store.LatestTrade(ctx, []uuid.UUID{accountID})
+	if err != nil {
+		return fmt.Errorf("failed to get latest trade: %w", err)
+	}
+
+	var since time.Time
+	if trade := latest[accountID]; trade != nil {
+		since = trade.Timestamp
+	}
+	report.LatestTradeAt = since
+
+	trades, err := ex.FetchTrades(ctx, account, since)
+	if err != nil {
+		return fmt.Errorf("failed to fetch trades: %w", err)
+	}
+	report.TradesFetched = len(trades)
+
+	if err := opts.Limits.check(ctx, LimitWarning{
+		AccountID: accountID,
+		Kind:      "trades",
+		Count:     len(trades),
+		Threshold: opts.Limits.MaxTrades,
+		Since:     since,
+	}); err != nil {
+		return err
+	}
+
+	trades, err = enrichTrades(ctx, trades, opts, report)
+	if err != nil {
+		return err
+	}
+	defaultTradeSource(trades)
+
+	if opts.DryRun {
+		return previewTrades(ctx, store, accountID, trades, opts, report)
+	}
+
+	inserted, deadLettered, err := storeRows(ctx, store, opts, accountID, models.SyncRunKindTrades, trades,
+		func(trade *models.TradeInput) string { return trade.TradeID }, store.AddTrades)
+	report.TradesDeadLettered = deadLettered
+	if err != nil {
+		return fmt.Errorf("failed to store trades: %w", err)
+	}
+	report.TradesInserted = len(inserted)
+	for _, trade := range inserted {
+		if trade != nil {
+			report.IngestLag.observe(trade.Timestamp, trade.CreatedAt)
+			if trade.Timestamp.After(report.LatestTradeAt) {
+				report.LatestTradeAt = trade.Timestamp
+			}
+		}
+	}
+
+	return nil
+}
+
+// syncFunding fetches and stores funding payments newer than the latest stored payment
+func syncFunding(
+	ctx context.Context,
+	ex iface.ExchangeClient,
+	store Store,
+	account *models.ExchangeAccount,
+	accountID uuid.UUID,
+	opts Options,
+	report *Report,
+) error {
+	latest, err := store.GetLatestFundingPayment(ctx, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to get latest funding payment: %w", err)
+	}
+
+	var since time.Time
+	if latest != nil {
+		since = latest.Timestamp
+	}
+
+	payments, err := ex.FetchFundingPayments(ctx, account, since)
+	if err != nil {
+		return fmt.Errorf("failed to fetch funding payments: %w", err)
+	}
+	report.FundingFetched = len(payments)
+
+	if err := opts.Limits.check(ctx, LimitWarning{
+		AccountID: accountID,
+		Kind:      "funding_payments",
+		Count:     len(payments),
+		Threshold: opts.Limits.MaxFundingPayments,
+		Since:     since,
+	}); err != nil {
+		return err
+	}
+
+	// since is inclusive and payments at the latest stored timestamp are not necessarily all stored
+	// (funding for every coin shares one timestamp), so already stored ones are left to the insert's
+	// conflict handling rather than filtered out here
+	defaultFundingSource(payments)
+
+	if opts.DryRun {
+		report.FundingNew = len(payments)
+		report.FundingSample = payments[:min(len(payments), sampleSize(opts))]
+		return nil
+	}
+
+	inserted, deadLettered, err := storeRows(ctx, store, opts, accountID, models.SyncRunKindFundingPayments, payments,
+		func(payment *models.FundingPaymentInput) string { return payment.PaymentID }, store.AddFundingPayments)
+	report.FundingDeadLettered = deadLettered
+	if err != nil {
+		return fmt.Errorf("failed to store funding payments: %w", err)
+	}
+	report.FundingInserted = len(inserted)
+	for _, payment := range inserted {
+		if payment != nil {
+			report.IngestLag.observe(payment.Timestamp, payment.CreatedAt)
+		}
+	}
+
+	return nil
+}
+
+// previewTrades fills the dry-run part of the Report without storing anything
+func previewTrades(
+	ctx context.Context,
+	store Store,
+	accountID uuid.UUID,
+	trades []*models.TradeInput,
+	opts Options,
+	report *Report,
+) error {
+	tradeIDs := make([]string, len(trades))
+	for i, trade := range trades {
+		tradeIDs[i] = trade.TradeID
+	}
+
+	existing, err := store.ExistingTradeIDs(ctx, accountID, tradeIDs)
+	if err != nil {
+		return fmt.Errorf("failed to check existing trades: %w", err)
+	}
+
+	limit := sampleSize(opts)
+	for _, trade := range trades {
+		if existing[trade.TradeID] {
+			report.TradesExisting++
+			continue
+		}
+		report.TradesNew++
+		if len(report.TradeSample) < limit {
+			report.TradeSample = append(report.TradeSample, trade)
+		}
+	}
+
+	return nil
+}
+
+// sampleSize returns the configured dry-run sample size
+func sampleSize(opts Options) int {
+	if opts.SampleSize <= 0 {
+		return defaultSampleSize
+	}
+	return opts.SampleSize
+}
+
+// logger returns the configured logger
+func logger(opts Options) *slog.Logger {
+	if opts.Logger == nil {
+		return slog.Default()
+	}
+	return opts.Logger
+}
+
+// LagStats summarizes ingest lag (CreatedAt - Timestamp) across stored rows
+type LagStats struct {
+	Count int           // Rows with a known ingest time
+	Mean  time.Duration // Average lag
+	Max   time.Duration // Largest lag
+}
+
+// observe adds one row's lag; rows without a CreatedAt are ignored
+func (l *LagStats) observe(timestamp, createdAt time.Time) {
+	if createdAt.IsZero() || timestamp.IsZero() {
+		return
+	}
+	lag := createdAt.Sub(timestamp)
+	l.Mean += (lag - l.Mean) / time.Duration(l.Count+1) // Incremental, so long backfills can't overflow
+	l.Count++
+	if lag > l.Max {
+		l.Max = lag
+	}
+}
+
+// defaultTradeSource marks trades without a Source as fetched by exchange sync
+func defaultTradeSource(trades []*models.TradeInput) {
+	for _, trade := range trades {
+		if trade.Source == "" {
+			trade.Source = models.SourceExchangeSync
+		}
+	}
+}
+
+// defaultFundingSource marks payments without a Source as fetched by exchange sync
+func defaultFundingSource(payments []*models.FundingPaymentInput) {
+	for _, payment := range payments {
+		if payment.Source == "" {
+			payment.Source = models.SourceExchangeSync
+		}
+	}
+}
@@ -0,0 +1,548 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zif-terminal/lib/db"
+	"github.com/zif-terminal/lib/models"
+)
+
+// Ensure the database client can be used as a Store
+var _ Store = (*db.Client)(nil)
+var _ RunRecorder = (*db.Client)(nil)
+var _ DeadLetterRecorder = (*db.Client)(nil)
+
+// fakeExchange returns fixed trades and funding payments
+type fakeExchange struct {
+	trades   []*models.TradeInput
+	payments []*models.FundingPaymentInput
+}
+
+func (f *fakeExchange) Name() string { return "fake" }
+
+func (f *fakeExchange) FetchTrades(ctx context.Context, account *models.ExchangeAccount, since time.Time) ([]*models.TradeInput, error) {
+	return f.trades, nil
+}
+
+func (f *fakeExchange) FetchFundingPayments(ctx context.Context, account *models.ExchangeAccount, since time.Time) ([]*models.FundingPaymentInput, error) {
+	return f.payments, nil
+}
+
+// fakeStore records everything written to it
+type fakeStore struct {
+	latestTrade      *models.Trade
+	latestPayment    *models.FundingPayment
+	existingTrades   map[string]bool
+	existingPayments map[string]bool // Payment IDs already stored, ignored on insert like the DB's on_conflict
+	createdAt        time.Time       // CreatedAt stamped on inserted rows
+	trades           []*models.TradeInput
+	payments         []*models.FundingPaymentInput
+}
+
+func (f *fakeStore) LatestTrade(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Trade, error) {
+	result := make(map[uuid.UUID]*models.Trade)
+	if f.latestTrade != nil {
+		result[ids[0]] = f.latestTrade
+	}
+	return result, nil
+}
+
+func (f *fakeStore) GetLatestFundingPayment(ctx context.Context, id uuid.UUID) (*models.FundingPayment, error) {
+	return f.latestPayment, nil
+}
+
+func (f *fakeStore) AddTrades(ctx context.Context, inputs []*models.TradeInput) ([]*models.Trade, error) {
+	f.trades = append(f.trades, inputs...)
+	inserted := make([]*models.Trade, len(inputs))
+	for i, input := range inputs {
+		inserted[i] = &models.Trade{TradeID: input.TradeID, Timestamp: input.Timestamp, CreatedAt: f.createdAt}
+	}
+	return inserted, nil
+}
+
+func (f *fakeStore) AddFundingPayments(ctx context.Context, inputs []*models.FundingPaymentInput) ([]*models.FundingPayment, error) {
+	var inserted []*models.FundingPayment
+	for _, input := range inputs {
+		if f.existingPayments[input.PaymentID] {
+			continue
+		}
+		f.payments = append(f.payments, input)
+		inserted = append(inserted, &models.FundingPayment{PaymentID: input.PaymentID, Timestamp: input.Timestamp, CreatedAt: f.createdAt})
+	}
+	return inserted, nil
+}
+
+func (f *fakeStore) ExistingTradeIDs(ctx context.Context, id uuid.UUID, tradeIDs []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+	for _, tradeID := range tradeIDs {
+		if f.existingTrades[tradeID] {
+			result[tradeID] = true
+		}
+	}
+	return result, nil
+}
+
+// readOnlyStore wraps a Store and fails the test on any write
+type readOnlyStore struct {
+	Store
+	t *testing.T
+}
+
+func (r readOnlyStore) AddTrades(ctx context.Context, inputs []*models.TradeInput) ([]*models.Trade, error) {
+	r.t.Fatalf("AddTrades called on read-only store with %d trades", len(inputs))
+	return nil, nil
+}
+
+func (r readOnlyStore) AddFundingPayments(ctx context.Context, inputs []*models.FundingPaymentInput) ([]*models.FundingPayment, error) {
+	r.t.Fatalf("AddFundingPayments called on read-only store with %d payments", len(inputs))
+	return nil, nil
+}
+
+func testAccount() *models.ExchangeAccount {
+	return &models.ExchangeAccount{ID: uuid.New().String(), AccountIdentifier: "0xabc"}
+}
+
+func testTrades(ids ...string) []*models.TradeInput {
+	trades := make([]*models.TradeInput, len(ids))
+	for i, id := range ids {
+		trades[i] = &models.TradeInput{
+			TradeID:    id,
+			BaseAsset:  "BTC",
+			QuoteAsset: "USDC",
+			Timestamp:  time.Unix(int64(i), 0),
+		}
+	}
+	return trades
+}
+
+func TestAccount_StoresFetchedData(t *testing.T) {
+	latest := time.Unix(100, 0)
+	ex := &fakeExchange{
+		trades: testTrades("t1", "t2"),
+		payments: []*models.FundingPaymentInput{
+			{PaymentID: "old", Timestamp: latest},
+			{PaymentID: "new", Timestamp: latest.Add(time.Hour)},
+		},
+	}
+	store := &fakeStore{
+		latestPayment:    &models.FundingPayment{PaymentID: "old", Timestamp: latest},
+		existingPayments: map[string]bool{"old": true},
+	}
+
+	report, err := Account(context.Background(), ex, store, testAccount(), Options{})
+	if err != nil {
+		t.Fatalf("Account failed: %v", err)
+	}
+
+	if report.TradesFetched != 2 || report.TradesInserted != 2 {
+		t.Errorf("Expected 2 trades fetched and inserted, got %+v", report)
+	}
+	if report.FundingFetched != 2 || report.FundingInserted != 1 {
+		t.Errorf("Expected 2 payments fetched and 1 inserted, got %+v", report)
+	}
+	if len(store.payments) != 1 || store.payments[0].PaymentID != "new" {
+		t.Errorf("Expected only the payment not yet stored, got %+v", store.payments)
+	}
+}
+
+func TestAccount_StoresRestOfPartlyStoredFundingTimestamp(t *testing.T) {
+	// Hourly funding for every coin shares one timestamp; an earlier run stored only BTC's
+	latest := time.Unix(3600, 0)
+	ex := &fakeExchange{
+		payments: []*models.FundingPaymentInput{
+			{PaymentID: "btc", BaseAsset: "BTC", Timestamp: latest},
+			{PaymentID: "eth", BaseAsset: "ETH", Timestamp: latest},
+			{PaymentID: "sol", BaseAsset: "SOL", Timestamp: latest},
+		},
+	}
+	store := &fakeStore{
+		latestPayment:    &models.FundingPayment{PaymentID: "btc", Timestamp: latest},
+		existingPayments: map[string]bool{"btc": true},
+	}
+
+	report, err := Account(context.Background(), ex, store, testAccount(), Options{})
+	if err != nil {
+		t.Fatalf("Account failed: %v", err)
+	}
+
+	if report.FundingFetched != 3 || report.FundingInserted != 2 {
+		t.Errorf("Expected 3 payments fetched and 2 inserted, got %+v", report)
+	}
+	var ids []string
+	for _, payment := range store.payments {
+		ids = append(ids, payment.PaymentID)
+	}
+	if strings.Join(ids, ",") != "eth,sol" {
+		t.Errorf("Expected the payments missing at the latest timestamp to be stored, got %v", ids)
+	}
+}
+
+func TestAccount_DefaultsSource(t *testing.T) {
+	trades := testTrades("t1", "t2")
+	trades[1].Source = models.SourceBackfill
+	ex := &fakeExchange{
+		trades:   trades,
+		payments: []*models.FundingPaymentInput{{PaymentID: "p1", Timestamp: time.Unix(1, 0)}},
+	}
+	store := &fakeStore{}
+
+	if _, err := Account(context.Background(), ex, store, testAccount(), Options{}); err != nil {
+		t.Fatalf("Account failed: %v", err)
+	}
+
+	if store.trades[0].Source != models.SourceExchangeSync {
+		t.Errorf("Expected unset trade source to default to %q, got %q", models.SourceExchangeSync, store.trades[0].Source)
+	}
+	if store.trades[1].Source != models.SourceBackfill {
+		t.Errorf("Expected explicit trade source to be kept, got %q", store.trades[1].Source)
+	}
+	if store.payments[0].Source != models.SourceExchangeSync {
+		t.Errorf("Expected unset funding source to default to %q, got %q", models.SourceExchangeSync, store.payments[0].Source)
+	}
+}
+
+func TestAccount_EnrichersRunInOrder(t *testing.T) {
+	ex := &fakeExchange{trades: testTrades("t1")}
+	store := &fakeStore{}
+
+	var order []string
+	tag := func(name string) Enricher {
+		return func(ctx context.Context, trade *models.TradeInput) error {
+			order = append(order, name)
+			trade.OrderID += name
+			return nil
+		}
+	}
+
+	_, err := Account(context.Background(), ex, store, testAccount(), Options{
+		Enrichers: []Enricher{tag("a"), tag("b"), DefaultFeeAsset},
+	})
+	if err != nil {
+		t.Fatalf("Account failed: %v", err)
+	}
+
+	if strings.Join(order, ",") != "a,b" {
+		t.Errorf("Expected enrichers to run in order a,b, got %v", order)
+	}
+	if len(store.trades) != 1 {
+		t.Fatalf("Expected 1 stored trade, got %d", len(store.trades))
+	}
+	if store.trades[0].OrderID != "ab" {
+		t.Errorf("Expected OrderID 'ab', got %q", store.trades[0].OrderID)
+	}
+	if store.trades[0].FeeAsset != "USDC" {
+		t.Errorf("Expected DefaultFeeAsset to fill quote asset, got %q", store.trades[0].FeeAsset)
+	}
+}
+
+func TestAccount_EnrichErrorFailsBatch(t *testing.T) {
+	ex := &fakeExchange{trades: testTrades("t1", "t2")}
+	store := &fakeStore{}
+	errBoom := errors.New("boom")
+
+	failOn := func(ctx context.Context, trade *models.TradeInput) error {
+		if trade.TradeID == "t2" {
+			return errBoom
+		}
+		return nil
+	}
+
+	_, err := Account(context.Background(), ex, store, testAccount(), Options{
+		Enrichers: []Enricher{failOn},
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("Expected enricher error, got: %v", err)
+	}
+	if len(store.trades) != 0 {
+		t.Errorf("Expected no trades stored, got %d", len(store.trades))
+	}
+}
+
+func TestAccount_EnrichErrorSkipsRow(t *testing.T) {
+	ex := &fakeExchange{trades: testTrades("t1", "t2", "t3")}
+	store := &fakeStore{}
+
+	failOn := func(ctx context.Context, trade *models.TradeInput) error {
+		if trade.TradeID == "t2" {
+			return errors.New("boom")
+		}
+		return nil
+	}
+
+	report, err := Account(context.Background(), ex, store, testAccount(), Options{
+		Enrichers:         []Enricher{failOn},
+		EnrichErrorPolicy: SkipRow,
+	})
+	if err != nil {
+		t.Fatalf("Account failed: %v", err)
+	}
+
+	if len(store.trades) != 2 || store.trades[0].TradeID != "t1" || store.trades[1].TradeID != "t3" {
+		t.Errorf("Expected t1 and t3 stored, got %+v", store.trades)
+	}
+	if report.TradesSkipped != 1 || len(report.EnrichErrors) != 1 {
+		t.Errorf("Expected 1 skipped trade with error, got %+v", report)
+	}
+}
+
+func TestAccount_DryRun(t *testing.T) {
+	latest := time.Unix(100, 0)
+	ex := &fakeExchange{
+		trades: testTrades("t1", "t2", "t3", "t4"),
+		payments: []*models.FundingPaymentInput{
+			{PaymentID: "old", Timestamp: latest},
+			{PaymentID: "new-1", Timestamp: latest.Add(time.Hour)},
+			{PaymentID: "new-2", Timestamp: latest.Add(2 * time.Hour)},
+		},
+	}
+	store := &fakeStore{
+		latestPayment:  &models.FundingPayment{Timestamp: latest},
+		existingTrades: map[string]bool{"t1": true, "t3": true},
+	}
+
+	report, err := Account(context.Background(), ex, readOnlyStore{Store: store, t: t}, testAccount(), Options{
+		DryRun:     true,
+		SampleSize: 1,
+		Enrichers:  []Enricher{DefaultFeeAsset},
+	})
+	if err != nil {
+		t.Fatalf("Account failed: %v", err)
+	}
+
+	if !report.DryRun {
+		t.Error("Expected report to be marked as dry run")
+	}
+	if report.TradesFetched != 4 || report.TradesNew != 2 || report.TradesExisting != 2 {
+		t.Errorf("Expected 4 fetched, 2 new, 2 existing trades, got %+v", report)
+	}
+	if report.TradesInserted != 0 || report.FundingInserted != 0 {
+		t.Errorf("Expected nothing inserted in dry run, got %+v", report)
+	}
+	if len(report.TradeSample) != 1 || report.TradeSample[0].TradeID != "t2" {
+		t.Errorf("Expected trade sample [t2], got %+v", report.TradeSample)
+	}
+	if report.TradeSample[0].FeeAsset != "USDC" {
+		t.Errorf("Expected sample to be enriched, got FeeAsset %q", report.TradeSample[0].FeeAsset)
+	}
+	if report.FundingNew != 3 {
+		t.Errorf("Expected 3 funding payments sent for insertion, got %d", report.FundingNew)
+	}
+	if len(report.FundingSample) != 1 || report.FundingSample[0].PaymentID != "old" {
+		t.Errorf("Expected funding sample [old], got %+v", report.FundingSample)
+	}
+}
+
+func TestAccount_SoftLimitWarns(t *testing.T) {
+	ex := &fakeExchange{trades: testTrades("t1", "t2", "t3")}
+	store := &fakeStore{}
+
+	var warnings []LimitWarning
+	report, err := Account(context.Background(), ex, store, testAccount(), Options{
+		Limits: SoftLimits{
+			MaxTrades: 2,
+			OnExceeded: func(ctx context.Context, warning LimitWarning) {
+				warnings = append(warnings, warning)
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Account failed: %v", err)
+	}
+
+	if len(warnings) != 1 || warnings[0].Kind != "trades" || warnings[0].Count != 3 || warnings[0].Threshold != 2 {
+		t.Errorf("Expected one trades warning for 3 > 2, got %+v", warnings)
+	}
+	if report.TradesInserted != 3 {
+		t.Errorf("Expected non-guarded sync to continue, got %d inserted", report.TradesInserted)
+	}
+}
+
+func TestAccount_SoftLimitGuarded(t *testing.T) {
+	for _, confirm := range []bool{false, true} {
+		ex := &fakeExchange{trades: testTrades("t1", "t2", "t3")}
+		store := &fakeStore{}
+
+		paused := 0
+		_, err := Account(context.Background(), ex, store, testAccount(), Options{
+			Limits: SoftLimits{
+				MaxTrades:  2,
+				OnExceeded: func(context.Context, LimitWarning) {},
+				Guarded:    true,
+				Confirm: func(ctx context.Context, warning LimitWarning) bool {
+					paused++
+					return confirm
+				},
+			},
+		})
+
+		if paused != 1 {
+			t.Errorf("confirm=%v: expected one confirmation request, got %d", confirm, paused)
+		}
+		if confirm {
+			if err != nil || len(store.trades) != 3 {
+				t.Errorf("confirm=true: expected trades stored, got err=%v stored=%d", err, len(store.trades))
+			}
+			continue
+		}
+		if !errors.Is(err, ErrFetchNotConfirmed) {
+			t.Errorf("confirm=false: expected ErrFetchNotConfirmed, got %v", err)
+		}
+		if len(store.trades) != 0 {
+			t.Errorf("confirm=false: expected nothing stored, got %d trades", len(store.trades))
+		}
+	}
+}
+
+func TestAccount_ReportsIngestLag(t *testing.T) {
+	// testTrades timestamps are Unix 0s and 1s; ingestion happens at Unix 10s
+	ex := &fakeExchange{
+		trades:   testTrades("t1", "t2"),
+		payments: []*models.FundingPaymentInput{{PaymentID: "p1", Timestamp: time.Unix(4, 0)}},
+	}
+	store := &fakeStore{createdAt: time.Unix(10, 0)}
+
+	report, err := Account(context.Background(), ex, store, testAccount(), Options{})
+	if err != nil {
+		t.Fatalf("Account failed: %v", err)
+	}
+
+	// Lags: 10s, 9s (trades) and 6s (funding)
+	if report.IngestLag.Count != 3 {
+		t.Errorf("Expected 3 lag samples, got %d", report.IngestLag.Count)
+	}
+	if report.IngestLag.Max != 10*time.Second {
+		t.Errorf("Expected max lag 10s, got %v", report.IngestLag.Max)
+	}
+	// The incremental mean may round by a nanosecond per sample
+	if diff := report.IngestLag.Mean - 25*time.Second/3; diff < -3 || diff > 3 {
+		t.Errorf("Expected mean lag %v, got %v", 25*time.Second/3, report.IngestLag.Mean)
+	}
+}
+
+func TestLagStats_LongBackfillDoesNotOverflow(t *testing.T) {
+	// A 30-day lag over a million rows overflowed a running total of Mean*Count
+	var lag LagStats
+	timestamp := time.Unix(0, 0)
+	createdAt := timestamp.Add(30 * 24 * time.Hour)
+	for i := 0; i < 1_000_000; i++ {
+		lag.observe(timestamp, createdAt)
+	}
+
+	if lag.Count != 1_000_000 || lag.Mean != 30*24*time.Hour || lag.Max != 30*24*time.Hour {
+		t.Errorf("Expected a 720h mean and max over 1000000 rows, got %+v", lag)
+	}
+}
+
+// recordingStore is a fakeStore that also records sync runs
+type recordingStore struct {
+	*fakeStore
+	runs []*models.SyncRunInput
+	err  error // Returned by CreateSyncRun when set
+}
+
+func (r *recordingStore) CreateSyncRun(ctx context.Context, input *models.SyncRunInput) (*models.SyncRun, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	r.runs = append(r.runs, input)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &models.SyncRun{ExchangeAccountID: input.ExchangeAccountID, Kind: input.Kind}, nil
+}
+
+func TestAccount_RecordsSyncRuns(t *testing.T) {
+	ex := &fakeExchange{
+		trades:   testTrades("t1", "t2", "t3"),
+		payments: []*models.FundingPaymentInput{{PaymentID: "p1", Timestamp: time.Unix(1, 0)}},
+	}
+	store := &recordingStore{fakeStore: &fakeStore{}}
+	account := testAccount()
+
+	failOn := func(ctx context.Context, trade *models.TradeInput) error {
+		if trade.TradeID == "t2" {
+			return errors.New("boom")
+		}
+		return nil
+	}
+
+	if _, err := Account(context.Background(), ex, store, account, Options{
+		Enrichers:         []Enricher{failOn},
+		EnrichErrorPolicy: SkipRow,
+	}); err != nil {
+		t.Fatalf("Account failed: %v", err)
+	}
+
+	if len(store.runs) != 2 {
+		t.Fatalf("Expected 2 sync runs, got %d", len(store.runs))
+	}
+	trades, funding := store.runs[0], store.runs[1]
+	if trades.Kind != models.SyncRunKindTrades || trades.ExchangeAccountID.String() != account.ID {
+		t.Errorf("Unexpected trades run identity: %+v", trades)
+	}
+	if trades.Fetched != 3 || trades.Inserted != 2 || trades.Skipped != 1 || trades.Error != nil {
+		t.Errorf("Expected 3 fetched, 2 inserted, 1 skipped and no error, got %+v", trades)
+	}
+	if funding.Kind != models.SyncRunKindFundingPayments || funding.Fetched != 1 || funding.Inserted != 1 || funding.Error != nil {
+		t.Errorf("Expected 1 funding payment fetched and inserted, got %+v", funding)
+	}
+	if trades.StartedAt.IsZero() || funding.StartedAt.Before(trades.StartedAt) {
+		t.Errorf("Expected ordered start times, got %v and %v", trades.StartedAt, funding.StartedAt)
+	}
+}
+
+func TestAccount_RecordsFailedSyncRun(t *testing.T) {
+	ex := &fakeExchange{trades: testTrades("t1")}
+	store := &recordingStore{fakeStore: &fakeStore{}}
+
+	failAll := func(ctx context.Context, trade *models.TradeInput) error {
+		return errors.New("boom")
+	}
+
+	_, err := Account(context.Background(), ex, store, testAccount(), Options{Enrichers: []Enricher{failAll}})
+	if err == nil {
+		t.Fatal("Expected enricher error")
+	}
+
+	// Funding is not synced after trades fail, so only the failed step is recorded
+	if len(store.runs) != 1 {
+		t.Fatalf("Expected 1 sync run, got %d", len(store.runs))
+	}
+	run := store.runs[0]
+	if run.Kind != models.SyncRunKindTrades || run.Fetched != 1 || run.Inserted != 0 {
+		t.Errorf("Unexpected failed run: %+v", run)
+	}
+	if run.Error == nil || *run.Error != err.Error() {
+		t.Errorf("Expected run error %q, got %v", err.Error(), run.Error)
+	}
+}
+
+func TestAccount_SyncRunRecordingIsBestEffort(t *testing.T) {
+	ex := &fakeExchange{trades: testTrades("t1")}
+	store := &recordingStore{fakeStore: &fakeStore{}, err: errors.New("sync_runs unavailable")}
+
+	report, err := Account(context.Background(), ex, store, testAccount(), Options{})
+	if err != nil {
+		t.Fatalf("Expected recording failure not to fail the sync, got: %v", err)
+	}
+	if report.TradesInserted != 1 || len(store.runs) != 2 {
+		t.Errorf("Expected sync to finish and attempt both records, got %+v with %d runs", report, len(store.runs))
+	}
+}
+
+func TestAccount_DryRunRecordsNoSyncRuns(t *testing.T) {
+	ex := &fakeExchange{trades: testTrades("t1")}
+	store := &recordingStore{fakeStore: &fakeStore{}}
+
+	if _, err := Account(context.Background(), ex, store, testAccount(), Options{DryRun: true}); err != nil {
+		t.Fatalf("Account failed: %v", err)
+	}
+	if len(store.runs) != 0 {
+		t.Errorf("Expected no sync runs for a dry run, got %d", len(store.runs))
+	}
+}